@@ -0,0 +1,38 @@
+-- =============================================================================
+-- Agent State History
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Audit trail of call center agent state changes
+--              (login / logout / break) for break-time reporting
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.agent_state_history (
+    id BIGSERIAL PRIMARY KEY,
+    extension_id INT NOT NULL REFERENCES voip.extensions(id) ON DELETE CASCADE,
+    previous_state VARCHAR(50),
+    state VARCHAR(50) NOT NULL,
+    reason VARCHAR(255),
+    source VARCHAR(50) NOT NULL DEFAULT 'api',
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE voip.agent_state_history
+DROP CONSTRAINT IF EXISTS chk_agent_state_history_state;
+
+ALTER TABLE voip.agent_state_history
+ADD CONSTRAINT chk_agent_state_history_state
+CHECK (state IN ('Available', 'On Break', 'Logged Out'));
+
+-- Reporting queries always filter by agent and time range
+CREATE INDEX IF NOT EXISTS idx_agent_state_history_extension
+ON voip.agent_state_history(extension_id, changed_at);
+
+COMMENT ON TABLE voip.agent_state_history IS 'Append-only history of agent state changes';
+COMMENT ON COLUMN voip.agent_state_history.source IS 'Origin of the change (api)';
+
+-- =============================================================================
+-- END OF AGENT STATE SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Initial agent state history table
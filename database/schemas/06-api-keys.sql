@@ -0,0 +1,67 @@
+-- =============================================================================
+-- Database-managed API Keys
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Hashed API key storage with per-resource permissions,
+--              expiry and optional domain (tenant) scoping
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: New Columns
+-- =============================================================================
+
+ALTER TABLE voip.api_keys
+ADD COLUMN IF NOT EXISTS key_hash VARCHAR(64),
+ADD COLUMN IF NOT EXISTS key_prefix VARCHAR(16),
+ADD COLUMN IF NOT EXISTS domain_id INT REFERENCES voip.domains(id) ON DELETE CASCADE,
+ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP;
+
+COMMENT ON COLUMN voip.api_keys.key_hash IS 'Hex SHA-256 of the API key (plaintext is never stored)';
+COMMENT ON COLUMN voip.api_keys.key_prefix IS 'First characters of the key, shown in listings';
+COMMENT ON COLUMN voip.api_keys.domain_id IS 'Tenant scope; NULL = all domains';
+COMMENT ON COLUMN voip.api_keys.permissions IS 'Resource to access level, e.g. {"cdr": "read", "*": "write"}';
+
+-- =============================================================================
+-- PART 2: Migrate Plaintext Keys to Hashes
+-- =============================================================================
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'voip'
+        AND table_name = 'api_keys'
+        AND column_name = 'api_key'
+    ) THEN
+        UPDATE voip.api_keys
+        SET key_hash = encode(sha256(api_key::bytea), 'hex'),
+            key_prefix = LEFT(api_key, 12)
+        WHERE key_hash IS NULL;
+
+        ALTER TABLE voip.api_keys DROP COLUMN api_key;
+
+        RAISE NOTICE 'Migrated plaintext API keys to SHA-256 hashes';
+    END IF;
+END $$;
+
+ALTER TABLE voip.api_keys ALTER COLUMN key_hash SET NOT NULL;
+
+-- Keys without explicit permissions keep their previous full access
+UPDATE voip.api_keys SET permissions = '{"*": "write"}'::jsonb WHERE permissions IS NULL;
+ALTER TABLE voip.api_keys ALTER COLUMN permissions SET NOT NULL;
+
+-- =============================================================================
+-- PART 3: Indexes
+-- =============================================================================
+
+-- Every admin API request looks its key up by hash
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_hash ON voip.api_keys(key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_domain ON voip.api_keys(domain_id);
+
+-- =============================================================================
+-- END OF API KEYS SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Hashed keys, domain scoping, revocation and usage tracking
@@ -0,0 +1,85 @@
+-- =============================================================================
+-- Admin Users and Sessions
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Password login for voip.users, login sessions backing the
+--              signed access tokens, and queue supervisor assignments
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: User Credentials and Roles
+-- =============================================================================
+
+ALTER TABLE voip.users
+ADD COLUMN IF NOT EXISTS password_hash VARCHAR(100),
+ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP;
+
+COMMENT ON COLUMN voip.users.password_hash IS 'bcrypt hash; NULL = login disabled';
+COMMENT ON COLUMN voip.users.domain_id IS 'Tenant of the user; NULL = global admin';
+
+UPDATE voip.users SET role = 'agent' WHERE role IS NULL;
+
+ALTER TABLE voip.users ALTER COLUMN role SET DEFAULT 'agent';
+ALTER TABLE voip.users ALTER COLUMN role SET NOT NULL;
+
+ALTER TABLE voip.users
+DROP CONSTRAINT IF EXISTS chk_users_role;
+
+ALTER TABLE voip.users
+ADD CONSTRAINT chk_users_role
+CHECK (role IN ('agent', 'supervisor', 'admin'));
+
+-- Only admins may be global (without a domain)
+ALTER TABLE voip.users
+DROP CONSTRAINT IF EXISTS chk_users_global_admin;
+
+ALTER TABLE voip.users
+ADD CONSTRAINT chk_users_global_admin
+CHECK (domain_id IS NOT NULL OR role = 'admin');
+
+-- UNIQUE(domain_id, username) does not cover NULL domains
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_global_username
+ON voip.users(username) WHERE domain_id IS NULL;
+
+-- =============================================================================
+-- PART 2: Login Sessions
+-- =============================================================================
+
+-- Access tokens reference a session so logout and user deactivation
+-- take effect immediately
+CREATE TABLE IF NOT EXISTS voip.user_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES voip.users(id) ON DELETE CASCADE,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON voip.user_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_expires ON voip.user_sessions(expires_at);
+
+COMMENT ON TABLE voip.user_sessions IS 'Login sessions of admin API users';
+
+-- =============================================================================
+-- PART 3: Queue Supervisors
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.queue_supervisors (
+    queue_id INT NOT NULL REFERENCES voip.queues(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES voip.users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (queue_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_queue_supervisors_user ON voip.queue_supervisors(user_id);
+
+COMMENT ON TABLE voip.queue_supervisors IS 'Queues a supervisor may monitor and manage agents of';
+
+-- =============================================================================
+-- END OF USERS SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Password login, sessions and queue supervisors
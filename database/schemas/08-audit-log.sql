@@ -0,0 +1,58 @@
+-- =============================================================================
+-- Audit Log
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Append-only record of administrative changes made through
+--              the voip-admin API, for compliance reviews
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Who
+    actor_type VARCHAR(20) NOT NULL, -- 'static_key', 'api_key', 'user'
+    actor_id BIGINT,                 -- voip.api_keys.id or voip.users.id
+    actor_name VARCHAR(100),
+
+    -- What
+    action VARCHAR(50) NOT NULL,     -- 'create', 'update', 'delete', ...
+    resource VARCHAR(50) NOT NULL,   -- API resource, e.g. 'extensions'
+    resource_id VARCHAR(100),
+    domain_id INT,                   -- Tenant of the changed object (no FK: outlives the domain)
+    changes JSONB,                   -- {"before": {...}, "after": {...}}, secrets redacted
+
+    -- Where from
+    source_ip VARCHAR(45),
+    request_id VARCHAR(64)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred ON voip.audit_log(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON voip.audit_log(resource, resource_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON voip.audit_log(actor_type, actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_domain ON voip.audit_log(domain_id, occurred_at);
+
+COMMENT ON TABLE voip.audit_log IS 'Append-only audit trail of administrative API changes';
+
+-- =============================================================================
+-- Append-only Enforcement
+-- =============================================================================
+
+CREATE OR REPLACE FUNCTION voip.audit_log_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'voip.audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_log_immutable ON voip.audit_log;
+
+CREATE TRIGGER trg_audit_log_immutable
+BEFORE UPDATE OR DELETE ON voip.audit_log
+FOR EACH ROW EXECUTE FUNCTION voip.audit_log_immutable();
+
+-- =============================================================================
+-- END OF AUDIT LOG SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Initial append-only audit log
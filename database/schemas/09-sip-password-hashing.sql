@@ -0,0 +1,86 @@
+-- =============================================================================
+-- SIP Password Hashing
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Stop storing plaintext SIP passwords. voipadmind computes
+--              sip_ha1/sip_ha1b itself; this migration hashes existing rows,
+--              clears sip_password and keeps legacy writers (kamctl via
+--              kamailio.subscriber) working by hashing on their behalf.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Migrate Existing Passwords
+-- =============================================================================
+
+-- Recompute the digests from the plaintext one last time, then drop it
+UPDATE voip.extensions e
+SET
+    sip_ha1 = MD5(e.extension || ':' || d.domain || ':' || e.sip_password),
+    sip_ha1b = MD5(e.extension || '@' || d.domain || ':' || d.domain || ':' || e.sip_password)
+FROM voip.domains d
+WHERE e.domain_id = d.id
+  AND e.sip_password IS NOT NULL
+  AND e.sip_password != '';
+
+UPDATE voip.extensions
+SET sip_password = NULL
+WHERE sip_password IS NOT NULL;
+
+-- =============================================================================
+-- PART 2: Hash Legacy Writes
+-- =============================================================================
+
+-- Writers that still set sip_password get it hashed and discarded before the
+-- row is stored
+CREATE OR REPLACE FUNCTION voip.extensions_calc_ha1_trigger() RETURNS TRIGGER AS $$
+DECLARE
+    v_domain VARCHAR;
+    v_ha1_result RECORD;
+BEGIN
+    IF NEW.sip_password IS NOT NULL AND NEW.sip_password != '' THEN
+        -- Get domain name
+        SELECT domain INTO v_domain
+        FROM voip.domains
+        WHERE id = NEW.domain_id;
+
+        IF v_domain IS NULL THEN
+            RAISE EXCEPTION 'Domain not found for domain_id: %', NEW.domain_id;
+        END IF;
+
+        -- Calculate HA1 values
+        SELECT * INTO v_ha1_result
+        FROM voip.calculate_sip_ha1(NEW.extension, v_domain, NEW.sip_password);
+
+        NEW.sip_ha1 := v_ha1_result.ha1;
+        NEW.sip_ha1b := v_ha1_result.ha1b;
+        NEW.updated_at := NOW();
+    END IF;
+
+    -- Never persist the plaintext
+    NEW.sip_password := NULL;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON TRIGGER extensions_calc_ha1_trigger ON voip.extensions IS 'Hash and discard plaintext SIP passwords from legacy writers';
+
+-- =============================================================================
+-- PART 3: Enforce
+-- =============================================================================
+
+ALTER TABLE voip.extensions
+DROP CONSTRAINT IF EXISTS chk_extensions_no_plaintext_password;
+
+ALTER TABLE voip.extensions
+ADD CONSTRAINT chk_extensions_no_plaintext_password
+CHECK (sip_password IS NULL);
+
+COMMENT ON COLUMN voip.extensions.sip_password IS 'Deprecated: always NULL, kept for kamailio.subscriber compatibility';
+
+-- =============================================================================
+-- END OF SIP PASSWORD HASHING SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Hash SIP passwords in voipadmind, clear stored plaintext
@@ -0,0 +1,63 @@
+-- =============================================================================
+-- Numbering Plan
+-- Version: 1.1
+-- Date: 2026-10-19
+-- Description: Per-domain ranges of internal numbers reserved for users,
+--              queues, IVR menus and conferences. Domains without ranges use
+--              the built-in default plan (3xxx conferences, 8xxx queues,
+--              9xxx IVR, other 4-digit numbers users), plus the pattern of
+--              outbound numbers. voipadmind compiles all plans into the
+--              matcher shared by dialplan routing and CDR classification.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Number Ranges
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.number_ranges (
+    id SERIAL PRIMARY KEY,
+    domain_id INT NOT NULL REFERENCES voip.domains(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    range_start VARCHAR(20) NOT NULL,
+    range_end VARCHAR(20) NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_number_ranges_type
+        CHECK (type IN ('user', 'queue', 'ivr', 'conference')),
+    -- Bounds are digit strings of equal length, compared as text
+    CONSTRAINT chk_number_ranges_bounds
+        CHECK (range_start ~ '^[0-9]+$'
+           AND range_end ~ '^[0-9]+$'
+           AND LENGTH(range_start) = LENGTH(range_end)
+           AND range_start <= range_end)
+);
+
+CREATE INDEX IF NOT EXISTS idx_number_ranges_domain ON voip.number_ranges(domain_id);
+
+COMMENT ON TABLE voip.number_ranges IS 'Internal number ranges per domain; none = default plan';
+
+-- Allocation looks up taken numbers of queues and IVR menus by domain
+CREATE INDEX IF NOT EXISTS idx_queues_domain_extension ON voip.queues(domain_id, extension);
+CREATE INDEX IF NOT EXISTS idx_ivr_menus_domain_extension ON voip.ivr_menus(domain_id, extension);
+
+-- =============================================================================
+-- PART 2: Outbound Pattern
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.numbering_plans (
+    domain_id INT PRIMARY KEY REFERENCES voip.domains(id) ON DELETE CASCADE,
+    outbound_pattern VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE voip.numbering_plans IS 'Per-domain numbering plan settings; none = defaults';
+COMMENT ON COLUMN voip.numbering_plans.outbound_pattern IS 'Go regular expression matching outbound (PSTN) numbers';
+
+-- =============================================================================
+-- END OF NUMBERING PLAN SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Per-domain number ranges
+-- v1.1 (2026-10-19): Per-domain outbound pattern
@@ -0,0 +1,43 @@
+-- =============================================================================
+-- Call Handling
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Per-extension call forwarding (unconditional, busy, no
+--              answer), do-not-disturb and follow-me. Extensions without a
+--              row ring normally and fall back to voicemail.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Call Handling Settings
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.extension_call_handling (
+    extension_id INT PRIMARY KEY REFERENCES voip.extensions(id) ON DELETE CASCADE,
+    dnd BOOLEAN NOT NULL DEFAULT false,
+    forward_always VARCHAR(32),
+    forward_busy VARCHAR(32),
+    forward_no_answer VARCHAR(32),
+    follow_me TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_call_handling_forward
+        CHECK ((forward_always IS NULL OR forward_always ~ '^\+?[0-9]{2,31}$')
+           AND (forward_busy IS NULL OR forward_busy ~ '^\+?[0-9]{2,31}$')
+           AND (forward_no_answer IS NULL OR forward_no_answer ~ '^\+?[0-9]{2,31}$')),
+    CONSTRAINT chk_call_handling_follow_me
+        CHECK (COALESCE(array_length(follow_me, 1), 0) <= 5)
+);
+
+COMMENT ON TABLE voip.extension_call_handling IS 'Forwarding, DND and follow-me per extension; none = ring, then voicemail';
+COMMENT ON COLUMN voip.extension_call_handling.dnd IS 'Do not ring; callers get the busy treatment';
+COMMENT ON COLUMN voip.extension_call_handling.forward_always IS 'Forward every call without ringing the extension';
+COMMENT ON COLUMN voip.extension_call_handling.forward_busy IS 'Forward when busy, rejected or on DND instead of voicemail';
+COMMENT ON COLUMN voip.extension_call_handling.forward_no_answer IS 'Forward on no answer or when not registered instead of voicemail';
+COMMENT ON COLUMN voip.extension_call_handling.follow_me IS 'Numbers rung together with the extension';
+
+-- =============================================================================
+-- END OF CALL HANDLING SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Call forwarding, DND and follow-me per extension
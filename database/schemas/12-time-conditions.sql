@@ -0,0 +1,105 @@
+-- =============================================================================
+-- Time Conditions
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Business hours for extensions, queues and IVR menus. A time
+--              condition is open during its weekly schedule (in its own
+--              timezone) except on holidays, unless overridden. While closed,
+--              calls to its destinations go to voicemail, an announcement or
+--              another number instead.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Time Conditions
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.time_conditions (
+    id SERIAL PRIMARY KEY,
+    domain_id INT NOT NULL REFERENCES voip.domains(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    override VARCHAR(10),
+    closed_action VARCHAR(20) NOT NULL,
+    closed_target VARCHAR(500),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE(domain_id, name),
+    CONSTRAINT chk_time_conditions_override
+        CHECK (override IS NULL OR override IN ('open', 'closed')),
+    CONSTRAINT chk_time_conditions_closed_action
+        CHECK (closed_action IN ('voicemail', 'announcement', 'destination'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_time_conditions_domain ON voip.time_conditions(domain_id);
+
+COMMENT ON TABLE voip.time_conditions IS 'Business hours with holidays and an open/closed override';
+COMMENT ON COLUMN voip.time_conditions.timezone IS 'IANA timezone the schedule and holidays are in';
+COMMENT ON COLUMN voip.time_conditions.override IS 'NULL = follow schedule; open/closed = forced';
+COMMENT ON COLUMN voip.time_conditions.closed_target IS 'Mailbox, sound file or number, depending on closed_action';
+
+-- =============================================================================
+-- PART 2: Weekly Schedule
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.time_condition_rules (
+    id SERIAL PRIMARY KEY,
+    time_condition_id INT NOT NULL REFERENCES voip.time_conditions(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+
+    -- 0 = Sunday; end 24:00 is midnight at the end of the day
+    CONSTRAINT chk_time_condition_rules_weekday CHECK (weekday BETWEEN 0 AND 6),
+    CONSTRAINT chk_time_condition_rules_range CHECK (start_time < end_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_time_condition_rules_condition ON voip.time_condition_rules(time_condition_id);
+
+COMMENT ON TABLE voip.time_condition_rules IS 'Open hours per weekday; no rules = open all week';
+
+-- =============================================================================
+-- PART 3: Holidays
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.time_condition_holidays (
+    id SERIAL PRIMARY KEY,
+    time_condition_id INT NOT NULL REFERENCES voip.time_conditions(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+
+    CONSTRAINT chk_time_condition_holidays_range CHECK (start_date <= end_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_time_condition_holidays_condition ON voip.time_condition_holidays(time_condition_id);
+
+COMMENT ON TABLE voip.time_condition_holidays IS 'Closed date ranges (inclusive) of a time condition';
+
+-- =============================================================================
+-- PART 4: Destinations
+-- =============================================================================
+
+-- Exactly one of extension_id, queue_id and ivr_id is set. A destination
+-- can be guarded by one time condition only.
+CREATE TABLE IF NOT EXISTS voip.time_condition_destinations (
+    id SERIAL PRIMARY KEY,
+    time_condition_id INT NOT NULL REFERENCES voip.time_conditions(id) ON DELETE CASCADE,
+    extension_id INT UNIQUE REFERENCES voip.extensions(id) ON DELETE CASCADE,
+    queue_id INT UNIQUE REFERENCES voip.queues(id) ON DELETE CASCADE,
+    ivr_id INT UNIQUE REFERENCES voip.ivr_menus(id) ON DELETE CASCADE,
+
+    CONSTRAINT chk_time_condition_destinations_one
+        CHECK (num_nonnulls(extension_id, queue_id, ivr_id) = 1)
+);
+
+CREATE INDEX IF NOT EXISTS idx_time_condition_destinations_condition ON voip.time_condition_destinations(time_condition_id);
+
+COMMENT ON TABLE voip.time_condition_destinations IS 'Extensions, queues and IVR menus guarded by a time condition';
+
+-- =============================================================================
+-- END OF TIME CONDITIONS SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Weekly schedules, holidays, override and destinations
@@ -0,0 +1,51 @@
+-- =============================================================================
+-- DID Routing
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: External numbers (DIDs) delivered by carriers into the
+--              "public" dialplan context, mapped to an extension, queue, IVR
+--              menu or time condition of a domain. voipadmind looks up the
+--              DID, prefixes the caller ID name and transfers the call into
+--              the domain's internal dialplan.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: DIDs
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.dids (
+    id SERIAL PRIMARY KEY,
+    domain_id INT NOT NULL REFERENCES voip.domains(id) ON DELETE CASCADE,
+    number VARCHAR(32) NOT NULL,
+    destination_type VARCHAR(20) NOT NULL,
+    destination VARCHAR(50),
+    time_condition_id INT REFERENCES voip.time_conditions(id) ON DELETE RESTRICT,
+    caller_id_name_prefix VARCHAR(20),
+    description VARCHAR(255),
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_dids_number CHECK (number ~ '^\+?[0-9]{3,20}$'),
+    CONSTRAINT chk_dids_destination_type
+        CHECK (destination_type IN ('user', 'queue', 'ivr', 'time_condition')),
+    -- Time conditions are referenced by ID, other destinations by number
+    CONSTRAINT chk_dids_destination
+        CHECK ((destination_type = 'time_condition') = (time_condition_id IS NOT NULL)
+           AND (destination_type = 'time_condition') = (destination IS NULL))
+);
+
+-- Carriers send numbers with or without the leading +
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dids_number ON voip.dids(LTRIM(number, '+'));
+CREATE INDEX IF NOT EXISTS idx_dids_domain ON voip.dids(domain_id);
+
+COMMENT ON TABLE voip.dids IS 'Inbound numbers from the public context and their internal destinations';
+COMMENT ON COLUMN voip.dids.destination IS 'Extension, queue or IVR number in the domain';
+COMMENT ON COLUMN voip.dids.caller_id_name_prefix IS 'Prepended to the caller ID name, e.g. SALES';
+
+-- =============================================================================
+-- END OF DID ROUTING SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): DIDs routed to extensions, queues, IVR menus and time conditions
@@ -0,0 +1,49 @@
+-- =============================================================================
+-- Call Recording
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Links recordings to the domain and recording policy they were
+--              made under. voipadmind starts record_session from the
+--              dialplan when the effective policy of a call asks for it, and
+--              registers the file in voip.recordings when the CDR arrives.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Policy references
+-- =============================================================================
+
+-- Extensions keep no policy once it is deleted
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'fk_extensions_recording_policy'
+    ) THEN
+        ALTER TABLE voip.extensions
+            ADD CONSTRAINT fk_extensions_recording_policy
+            FOREIGN KEY (recording_policy_id) REFERENCES voip.recording_policies(id) ON DELETE SET NULL;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_extensions_recording_policy ON voip.extensions(recording_policy_id);
+
+-- =============================================================================
+-- PART 2: Recordings
+-- =============================================================================
+
+ALTER TABLE voip.recordings
+ADD COLUMN IF NOT EXISTS domain_id INT REFERENCES voip.domains(id) ON DELETE CASCADE,
+ADD COLUMN IF NOT EXISTS recording_policy_id INT REFERENCES voip.recording_policies(id) ON DELETE SET NULL;
+
+-- A CDR may be processed twice; the file is registered once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recordings_file_path ON voip.recordings(file_path);
+CREATE INDEX IF NOT EXISTS idx_recordings_domain ON voip.recordings(domain_id);
+CREATE INDEX IF NOT EXISTS idx_recordings_start_time ON voip.recordings(start_time);
+
+COMMENT ON COLUMN voip.recordings.recording_policy_id IS 'Policy the call was recorded under; its retention applies';
+
+-- =============================================================================
+-- END OF CALL RECORDING SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Recording domain and policy, unique file paths
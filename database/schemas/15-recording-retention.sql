@@ -0,0 +1,33 @@
+-- =============================================================================
+-- Recording Retention
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Legal hold on recordings. voipadmind deletes recordings (file
+--              and row) once they are older than the retention_days of their
+--              recording policy, except those on legal hold.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Legal hold
+-- =============================================================================
+
+ALTER TABLE voip.recordings
+ADD COLUMN IF NOT EXISTS legal_hold BOOLEAN NOT NULL DEFAULT false,
+ADD COLUMN IF NOT EXISTS legal_hold_reason VARCHAR(255),
+ADD COLUMN IF NOT EXISTS legal_hold_by VARCHAR(100),
+ADD COLUMN IF NOT EXISTS legal_hold_at TIMESTAMP;
+
+-- The retention worker only scans recordings it may delete
+CREATE INDEX IF NOT EXISTS idx_recordings_retention
+    ON voip.recordings(COALESCE(start_time, created_at))
+    WHERE legal_hold = false;
+
+COMMENT ON COLUMN voip.recordings.legal_hold IS 'Kept past the retention of its policy until released';
+COMMENT ON COLUMN voip.recordings.legal_hold_by IS 'User or API key that placed the hold';
+
+-- =============================================================================
+-- END OF RECORDING RETENTION SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Legal hold on recordings
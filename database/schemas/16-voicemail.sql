@@ -0,0 +1,65 @@
+-- =============================================================================
+-- Voicemail
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: mod_voicemail message index in PostgreSQL. Both FreeSWITCH
+--              nodes store voicemail through odbc-dsn (voicemail.conf.xml)
+--              in voip.voicemail_msgs instead of a per-node sqlite file, so
+--              a mailbox is the same on either node and voipadmind can read
+--              it. Also tracks voicemail-to-email notifications.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: mod_voicemail tables
+-- =============================================================================
+
+-- Same definition mod_voicemail creates on first load; created here so
+-- voipadmind works before FreeSWITCH has started
+CREATE TABLE IF NOT EXISTS voip.voicemail_msgs (
+    created_epoch INTEGER,
+    read_epoch INTEGER,
+    username VARCHAR(255),
+    domain VARCHAR(255),
+    uuid VARCHAR(255),
+    cid_name VARCHAR(255),
+    cid_number VARCHAR(255),
+    in_folder VARCHAR(255),
+    file_path VARCHAR(255),
+    message_len INTEGER,
+    flags VARCHAR(255),
+    read_flags VARCHAR(255),
+    forwarded_by VARCHAR(255)
+);
+
+CREATE INDEX IF NOT EXISTS voicemail_msgs_idx1 ON voip.voicemail_msgs(created_epoch);
+CREATE INDEX IF NOT EXISTS voicemail_msgs_idx2 ON voip.voicemail_msgs(username);
+CREATE INDEX IF NOT EXISTS voicemail_msgs_idx3 ON voip.voicemail_msgs(domain);
+CREATE INDEX IF NOT EXISTS voicemail_msgs_idx4 ON voip.voicemail_msgs(uuid);
+CREATE INDEX IF NOT EXISTS voicemail_msgs_idx5 ON voip.voicemail_msgs(in_folder);
+CREATE INDEX IF NOT EXISTS voicemail_msgs_idx6 ON voip.voicemail_msgs(read_flags);
+CREATE INDEX IF NOT EXISTS voicemail_msgs_idx7 ON voip.voicemail_msgs(forwarded_by);
+CREATE INDEX IF NOT EXISTS voicemail_msgs_idx8 ON voip.voicemail_msgs(read_epoch);
+CREATE INDEX IF NOT EXISTS voicemail_msgs_idx9 ON voip.voicemail_msgs(flags);
+
+-- =============================================================================
+-- PART 2: Email notifications
+-- =============================================================================
+
+-- One row per message claimed by a voipadmind node for emailing; claims of
+-- failed sends are removed so the message is retried
+CREATE TABLE IF NOT EXISTS voip.voicemail_notifications (
+    message_uuid VARCHAR(255) PRIMARY KEY,
+    claimed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_voicemail_notifications_claimed ON voip.voicemail_notifications(claimed_at);
+
+COMMENT ON TABLE voip.voicemail_notifications IS 'Voicemail messages emailed (or being emailed) by voipadmind';
+
+-- =============================================================================
+-- END OF VOICEMAIL SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Shared mod_voicemail storage and email notifications
@@ -0,0 +1,56 @@
+-- =============================================================================
+-- Conference Rooms
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Conference rooms of a domain, reached on a number of the
+--              conference range. The dialplan only lets callers into a room
+--              that exists, asks for its participant or moderator PIN and
+--              applies its flags; rooms are controlled live over the Event
+--              Socket.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Conference rooms
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.conference_rooms (
+    id SERIAL PRIMARY KEY,
+    domain_id INT NOT NULL REFERENCES voip.domains(id) ON DELETE CASCADE,
+    extension VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    participant_pin VARCHAR(12),
+    moderator_pin VARCHAR(12),
+    max_members INT NOT NULL DEFAULT 0,
+    record BOOLEAN NOT NULL DEFAULT false,
+    profile VARCHAR(50) NOT NULL DEFAULT 'default',
+    mute_on_join BOOLEAN NOT NULL DEFAULT false,
+    wait_for_moderator BOOLEAN NOT NULL DEFAULT false,
+    description VARCHAR(255),
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_conference_rooms_extension UNIQUE (domain_id, extension),
+    CONSTRAINT chk_conference_rooms_participant_pin CHECK (participant_pin ~ '^[0-9]{3,12}$'),
+    CONSTRAINT chk_conference_rooms_moderator_pin CHECK (moderator_pin ~ '^[0-9]{3,12}$'),
+    CONSTRAINT chk_conference_rooms_pins CHECK (participant_pin IS DISTINCT FROM moderator_pin OR participant_pin IS NULL),
+    CONSTRAINT chk_conference_rooms_max_members CHECK (max_members >= 0),
+    -- Participants would wait forever without a way in for the moderator
+    CONSTRAINT chk_conference_rooms_wait_for_moderator
+        CHECK (NOT wait_for_moderator OR moderator_pin IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_conference_rooms_domain ON voip.conference_rooms(domain_id);
+
+COMMENT ON TABLE voip.conference_rooms IS 'Conference rooms and their PINs, reached on numbers of the conference range';
+COMMENT ON COLUMN voip.conference_rooms.participant_pin IS 'Asked from callers; NULL lets anyone in unless a moderator PIN is set';
+COMMENT ON COLUMN voip.conference_rooms.moderator_pin IS 'Joins as moderator';
+COMMENT ON COLUMN voip.conference_rooms.max_members IS 'Maximum members per FreeSWITCH node (0 = unlimited)';
+COMMENT ON COLUMN voip.conference_rooms.profile IS 'mod_conference profile of conference.conf.xml';
+
+-- =============================================================================
+-- END OF CONFERENCE ROOMS SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Conference rooms with participant and moderator PINs
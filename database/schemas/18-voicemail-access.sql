@@ -0,0 +1,47 @@
+-- =============================================================================
+-- Voicemail Access
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: How callers reach voicemail. *97 checks the caller's own
+--              mailbox, *98 and the domain's voicemail main number ask for a
+--              mailbox number, *99<ext> leaves a message without ringing.
+--              Logging in to one's own mailbox without the voicemail password
+--              is a per-extension setting.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Caller ID based auto-login
+-- =============================================================================
+
+ALTER TABLE voip.extensions
+    ADD COLUMN IF NOT EXISTS vm_auto_login BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN voip.extensions.vm_auto_login IS
+    'Skip the voicemail password on *97 when calling from the registered extension';
+
+-- =============================================================================
+-- PART 2: Voicemail main number
+-- =============================================================================
+
+ALTER TABLE voip.domains
+    ADD COLUMN IF NOT EXISTS voicemail_main_number VARCHAR(20);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'chk_domains_voicemail_main_number'
+    ) THEN
+        ALTER TABLE voip.domains ADD CONSTRAINT chk_domains_voicemail_main_number
+            CHECK (voicemail_main_number ~ '^[0-9]{2,20}$');
+    END IF;
+END $$;
+
+COMMENT ON COLUMN voip.domains.voicemail_main_number IS
+    'Internal number that asks for a mailbox and its password, e.g. for DIDs';
+
+-- =============================================================================
+-- END OF VOICEMAIL ACCESS SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): vm_auto_login per extension, voicemail main number per domain
@@ -0,0 +1,115 @@
+-- =============================================================================
+-- SIP Profiles and Trunks
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Sofia SIP profiles and carrier gateways served to FreeSWITCH
+--              as sofia.conf over mod_xml_curl. Every trunk is a gateway of
+--              a profile; adding a carrier is a row in voip.trunks followed
+--              by a gateway reload over the Event Socket.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: SIP profiles
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.sip_profiles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    sip_ip VARCHAR(64),
+    sip_port INT NOT NULL,
+    rtp_ip VARCHAR(64),
+    ext_sip_ip VARCHAR(64),
+    ext_rtp_ip VARCHAR(64),
+    context VARCHAR(50) NOT NULL DEFAULT 'public',
+    inbound_codec_prefs VARCHAR(255) NOT NULL DEFAULT 'OPUS,PCMU,PCMA,G729',
+    outbound_codec_prefs VARCHAR(255) NOT NULL DEFAULT 'OPUS,PCMU,PCMA,G729',
+    auth_calls BOOLEAN NOT NULL DEFAULT false,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_sip_profiles_name UNIQUE (name),
+    CONSTRAINT chk_sip_profiles_name CHECK (name ~ '^[A-Za-z0-9_-]{1,50}$'),
+    CONSTRAINT chk_sip_profiles_sip_port CHECK (sip_port BETWEEN 1 AND 65535)
+);
+
+COMMENT ON TABLE voip.sip_profiles IS 'Sofia SIP profiles rendered into sofia.conf';
+COMMENT ON COLUMN voip.sip_profiles.sip_ip IS 'NULL binds to $${local_ip_v4} of each FreeSWITCH node';
+COMMENT ON COLUMN voip.sip_profiles.rtp_ip IS 'NULL uses $${local_ip_v4} of each FreeSWITCH node';
+COMMENT ON COLUMN voip.sip_profiles.ext_sip_ip IS 'NULL uses auto-nat';
+COMMENT ON COLUMN voip.sip_profiles.ext_rtp_ip IS 'NULL uses auto-nat';
+
+-- "internal" receives calls from Kamailio, as configured in the static
+-- sofia.conf.xml; "external" hosts the carrier gateways
+INSERT INTO voip.sip_profiles (name, sip_port, context, auth_calls)
+VALUES
+    ('internal', 5080, 'default', false),
+    ('external', 5060, 'public', false)
+ON CONFLICT (name) DO NOTHING;
+
+-- =============================================================================
+-- PART 2: Trunk gateway settings
+-- =============================================================================
+
+ALTER TABLE voip.trunks
+    ADD COLUMN IF NOT EXISTS sip_profile VARCHAR(50) NOT NULL DEFAULT 'external',
+    ADD COLUMN IF NOT EXISTS transport VARCHAR(3) NOT NULL DEFAULT 'udp',
+    ADD COLUMN IF NOT EXISTS register BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS realm VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS expire_seconds INT NOT NULL DEFAULT 3600,
+    ADD COLUMN IF NOT EXISTS codec_prefs VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS caller_id_in_from BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS description VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_trunks_sip_profile') THEN
+        ALTER TABLE voip.trunks ADD CONSTRAINT fk_trunks_sip_profile
+            FOREIGN KEY (sip_profile) REFERENCES voip.sip_profiles(name)
+            ON UPDATE CASCADE ON DELETE RESTRICT;
+    END IF;
+
+    -- Trunk names are FreeSWITCH gateway names, used in dial strings
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_trunks_name') THEN
+        ALTER TABLE voip.trunks ADD CONSTRAINT chk_trunks_name
+            CHECK (name ~ '^[A-Za-z0-9_.-]{1,100}$');
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_trunks_transport') THEN
+        ALTER TABLE voip.trunks ADD CONSTRAINT chk_trunks_transport
+            CHECK (transport IN ('udp', 'tcp', 'tls'));
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_trunks_port') THEN
+        ALTER TABLE voip.trunks ADD CONSTRAINT chk_trunks_port
+            CHECK (port BETWEEN 1 AND 65535);
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_trunks_expire_seconds') THEN
+        ALTER TABLE voip.trunks ADD CONSTRAINT chk_trunks_expire_seconds
+            CHECK (expire_seconds BETWEEN 60 AND 86400);
+    END IF;
+
+    -- A carrier cannot be registered with without credentials
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_trunks_register') THEN
+        ALTER TABLE voip.trunks ADD CONSTRAINT chk_trunks_register
+            CHECK (NOT register OR (COALESCE(username, '') <> '' AND COALESCE(password, '') <> ''));
+    END IF;
+END $$;
+
+-- Gateway names are global to a FreeSWITCH node
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trunks_name ON voip.trunks(name);
+CREATE INDEX IF NOT EXISTS idx_trunks_domain ON voip.trunks(domain_id);
+
+COMMENT ON COLUMN voip.trunks.sip_profile IS 'Sofia profile the gateway is created in';
+COMMENT ON COLUMN voip.trunks.realm IS 'Authentication realm; NULL uses host';
+COMMENT ON COLUMN voip.trunks.codec_prefs IS 'Codecs offered on calls through the gateway, e.g. PCMA,PCMU';
+COMMENT ON COLUMN voip.trunks.caller_id_in_from IS 'Send the caller ID in the From header instead of the username';
+
+-- =============================================================================
+-- END OF SIP PROFILES AND TRUNKS SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): SIP profiles and gateway settings of trunks
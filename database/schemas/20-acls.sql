@@ -0,0 +1,121 @@
+-- =============================================================================
+-- Network ACLs
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Network lists (carrier IPs, office subnets, Kamailio proxies)
+--              managed by voip-admin. FreeSWITCH fetches them as acl.conf
+--              over mod_xml_curl; Kamailio reads the allowed networks of
+--              published lists through the kamailio.address view, so both
+--              layers trust the same peers.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: ACL lists and entries
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.acl_lists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    default_action VARCHAR(5) NOT NULL DEFAULT 'deny',
+    kamailio_group INT,
+    description VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_acl_lists_name UNIQUE (name),
+    CONSTRAINT uq_acl_lists_kamailio_group UNIQUE (kamailio_group),
+    CONSTRAINT chk_acl_lists_name CHECK (name ~ '^[A-Za-z0-9_-]{1,50}$'),
+    CONSTRAINT chk_acl_lists_default_action CHECK (default_action IN ('allow', 'deny')),
+    CONSTRAINT chk_acl_lists_kamailio_group CHECK (kamailio_group > 0)
+);
+
+COMMENT ON TABLE voip.acl_lists IS 'Network lists rendered into acl.conf';
+COMMENT ON COLUMN voip.acl_lists.kamailio_group IS 'Group of the allowed networks in kamailio.address; NULL keeps the list out of Kamailio';
+
+CREATE TABLE IF NOT EXISTS voip.acl_entries (
+    id SERIAL PRIMARY KEY,
+    acl_list_id INT NOT NULL REFERENCES voip.acl_lists(id) ON DELETE CASCADE,
+    cidr CIDR NOT NULL,
+    action VARCHAR(5) NOT NULL DEFAULT 'allow',
+    port INT NOT NULL DEFAULT 0,
+    description VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_acl_entries_cidr UNIQUE (acl_list_id, cidr),
+    CONSTRAINT chk_acl_entries_action CHECK (action IN ('allow', 'deny')),
+    CONSTRAINT chk_acl_entries_port CHECK (port BETWEEN 0 AND 65535)
+);
+
+COMMENT ON TABLE voip.acl_entries IS 'Networks of an ACL list';
+COMMENT ON COLUMN voip.acl_entries.port IS 'Source port matched by Kamailio (0 = any); FreeSWITCH ignores it';
+
+-- Profiles that only accept calls from listed networks, e.g. carriers on
+-- the external profile
+ALTER TABLE voip.sip_profiles
+    ADD COLUMN IF NOT EXISTS inbound_acl VARCHAR(50);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_sip_profiles_inbound_acl') THEN
+        ALTER TABLE voip.sip_profiles ADD CONSTRAINT fk_sip_profiles_inbound_acl
+            FOREIGN KEY (inbound_acl) REFERENCES voip.acl_lists(name)
+            ON UPDATE CASCADE ON DELETE RESTRICT;
+    END IF;
+END $$;
+
+COMMENT ON COLUMN voip.sip_profiles.inbound_acl IS 'ACL list applied to inbound calls (apply-inbound-acl)';
+
+-- =============================================================================
+-- PART 2: Kamailio address view
+-- =============================================================================
+
+-- Existing kamailio.address rows become one list per group before the
+-- table is replaced by the view
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM pg_class c
+        INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE n.nspname = 'kamailio' AND c.relname = 'address' AND c.relkind = 'r'
+    ) THEN
+        INSERT INTO voip.acl_lists (name, default_action, kamailio_group, description)
+        SELECT DISTINCT 'kamailio-grp-' || grp, 'deny', grp, 'Imported from kamailio.address'
+        FROM kamailio.address
+        WHERE grp > 0
+        ON CONFLICT DO NOTHING;
+
+        INSERT INTO voip.acl_entries (acl_list_id, cidr, action, port, description)
+        SELECT l.id, network((a.ip_addr || '/' || a.mask)::inet), 'allow', a.port, a.tag
+        FROM kamailio.address a
+        INNER JOIN voip.acl_lists l ON l.kamailio_group = a.grp
+        ON CONFLICT DO NOTHING;
+
+        DROP TABLE kamailio.address;
+    END IF;
+END $$;
+
+-- Kamailio only allows; deny entries and the default action apply to
+-- FreeSWITCH alone
+CREATE OR REPLACE VIEW kamailio.address AS
+SELECT
+    e.id,
+    l.kamailio_group AS grp,
+    host(e.cidr)::VARCHAR(50) AS ip_addr,
+    masklen(e.cidr) AS mask,
+    e.port,
+    l.name::VARCHAR(64) AS tag
+FROM voip.acl_entries e
+INNER JOIN voip.acl_lists l ON l.id = e.acl_list_id
+WHERE l.kamailio_group IS NOT NULL
+  AND e.action = 'allow';
+
+COMMENT ON VIEW kamailio.address IS 'Kamailio permissions address view - allowed networks of voip.acl_lists';
+
+GRANT SELECT ON kamailio.address TO kamailio, kamailioro;
+
+-- =============================================================================
+-- END OF NETWORK ACLS SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): ACL lists for acl.conf and kamailio.address
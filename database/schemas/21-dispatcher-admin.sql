@@ -0,0 +1,37 @@
+-- =============================================================================
+-- Dispatcher Administration
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Lets voip-admin manage the FreeSWITCH destinations in
+--              kamailio.dispatcher (add, remove, weight, drain). Changes are
+--              applied to each Kamailio node over JSON-RPC.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Grants
+-- =============================================================================
+
+GRANT USAGE ON SCHEMA kamailio TO voipadmin;
+GRANT SELECT, INSERT, UPDATE, DELETE ON kamailio.dispatcher TO voipadmin;
+GRANT USAGE, SELECT ON SEQUENCE kamailio.dispatcher_id_seq TO voipadmin;
+
+-- =============================================================================
+-- PART 2: Constraints
+-- =============================================================================
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_dispatcher_setid') THEN
+        ALTER TABLE kamailio.dispatcher ADD CONSTRAINT chk_dispatcher_setid CHECK (setid > 0);
+    END IF;
+END $$;
+
+COMMENT ON COLUMN kamailio.dispatcher.flags IS 'Bit flags: 1 = inactive, 2 = probing, 4 = disabled (drained by voip-admin)';
+COMMENT ON COLUMN kamailio.dispatcher.attrs IS 'Attributes, e.g. weight=50 (used by algorithm 9 in route[DISPATCH])';
+
+-- =============================================================================
+-- END OF DISPATCHER ADMINISTRATION SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): voipadmin grants on kamailio.dispatcher
@@ -0,0 +1,85 @@
+-- =============================================================================
+-- Fraud Detection
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Alerts raised by voipadmind's fraud detector over outbound
+--              calls (international spikes, high-cost prefixes, off-hours
+--              bursts, concurrent calls above max_concurrent), and the CDR
+--              checkpoint the detector shares between nodes.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Alerts
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.fraud_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    domain_id INT NOT NULL REFERENCES voip.domains(id) ON DELETE CASCADE,
+    extension_id INT REFERENCES voip.extensions(id) ON DELETE SET NULL,
+    extension VARCHAR(20) NOT NULL,
+    rule VARCHAR(30) NOT NULL,
+    message VARCHAR(255) NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    suspended BOOLEAN NOT NULL DEFAULT false,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    acknowledged_at TIMESTAMP,
+    acknowledged_by VARCHAR(255),
+    note VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_fraud_alerts_rule CHECK (rule IN (
+        'international_spike', 'high_cost_prefix', 'off_hours_burst', 'concurrent_calls'
+    )),
+    CONSTRAINT chk_fraud_alerts_status CHECK (status IN ('open', 'acknowledged'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_fraud_alerts_domain ON voip.fraud_alerts(domain_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_fraud_alerts_open ON voip.fraud_alerts(extension_id, rule, created_at)
+    WHERE status = 'open';
+
+COMMENT ON TABLE voip.fraud_alerts IS 'Outbound calling anomalies found by the voipadmind fraud detector';
+COMMENT ON COLUMN voip.fraud_alerts.suspended IS 'The extension was deactivated because of this alert';
+
+-- =============================================================================
+-- PART 2: Detector checkpoint
+-- =============================================================================
+
+-- Highest voip.cdr id scanned; both nodes advance it with compare-and-set,
+-- so each CDR is scanned once
+CREATE TABLE IF NOT EXISTS voip.fraud_checkpoint (
+    id INT PRIMARY KEY DEFAULT 1,
+    last_cdr_id BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_fraud_checkpoint_single CHECK (id = 1)
+);
+
+-- Start from the current CDRs instead of scanning the history
+INSERT INTO voip.fraud_checkpoint (id, last_cdr_id)
+SELECT 1, COALESCE(MAX(id), 0) FROM voip.cdr
+ON CONFLICT (id) DO NOTHING;
+
+-- Calls per calling extension, as read by the detector (voipadmind's CDR
+-- columns; skipped where voip.cdr still has the 01 layout). FreeSWITCH
+-- records the A-leg of a phone as inbound, so the detector classifies
+-- destinations itself instead of filtering on direction.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'voip' AND table_name = 'cdr' AND column_name = 'start_stamp'
+    ) AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'voip' AND table_name = 'cdr' AND column_name = 'domain'
+    ) THEN
+        CREATE INDEX IF NOT EXISTS idx_cdr_caller
+            ON voip.cdr(domain, (COALESCE(NULLIF(sip_from_user, ''), caller_id_number)), start_stamp);
+    END IF;
+END $$;
+
+-- =============================================================================
+-- END OF FRAUD DETECTION SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Fraud alerts and detector checkpoint
@@ -0,0 +1,130 @@
+-- =============================================================================
+-- Rating and Billing
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Rate decks (destination prefix -> per-minute rate, connection
+--              fee, billing increments), bought per trunk and sold per
+--              tenant. voipadmind's CDR processor rates outbound calls with
+--              the longest matching prefix of both decks and stores the buy
+--              and sell cost on the CDR; invoices sum the sell cost per
+--              domain and month.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Rate decks
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.rate_decks (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(4) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    description VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_rate_decks_name UNIQUE (name),
+    CONSTRAINT chk_rate_decks_type CHECK (type IN ('buy', 'sell')),
+    CONSTRAINT chk_rate_decks_currency CHECK (currency ~ '^[A-Z]{3}$')
+);
+
+COMMENT ON TABLE voip.rate_decks IS 'Price lists: buy decks are assigned to trunks, sell decks to domains';
+
+CREATE TABLE IF NOT EXISTS voip.rates (
+    id BIGSERIAL PRIMARY KEY,
+    rate_deck_id INT NOT NULL REFERENCES voip.rate_decks(id) ON DELETE CASCADE,
+    prefix VARCHAR(20) NOT NULL,
+    description VARCHAR(255),
+    rate NUMERIC(12,6) NOT NULL,
+    connection_fee NUMERIC(12,6) NOT NULL DEFAULT 0,
+    initial_increment INT NOT NULL DEFAULT 60,
+    increment INT NOT NULL DEFAULT 60,
+
+    CONSTRAINT uq_rates_prefix UNIQUE (rate_deck_id, prefix),
+    CONSTRAINT chk_rates_prefix CHECK (prefix ~ '^[0-9]{1,20}$'),
+    CONSTRAINT chk_rates_rate CHECK (rate >= 0 AND connection_fee >= 0),
+    CONSTRAINT chk_rates_increments CHECK (initial_increment >= 1 AND increment >= 1)
+);
+
+COMMENT ON TABLE voip.rates IS 'Rates of a deck by destination prefix; the longest matching prefix wins';
+COMMENT ON COLUMN voip.rates.prefix IS 'Digits of the number as dialed, without a leading + or 00';
+COMMENT ON COLUMN voip.rates.rate IS 'Price per minute in the deck currency';
+COMMENT ON COLUMN voip.rates.initial_increment IS 'Seconds billed at least once answered, e.g. 60 of 60/6';
+COMMENT ON COLUMN voip.rates.increment IS 'Seconds billed per step after the initial increment, e.g. 6 of 60/6';
+
+-- =============================================================================
+-- PART 2: Deck assignment
+-- =============================================================================
+
+ALTER TABLE voip.trunks
+    ADD COLUMN IF NOT EXISTS rate_deck_id INT REFERENCES voip.rate_decks(id) ON DELETE SET NULL;
+
+ALTER TABLE voip.domains
+    ADD COLUMN IF NOT EXISTS rate_deck_id INT REFERENCES voip.rate_decks(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN voip.trunks.rate_deck_id IS 'Buy deck: what the carrier charges for calls over this trunk';
+COMMENT ON COLUMN voip.domains.rate_deck_id IS 'Sell deck: what the tenant is invoiced for outbound calls';
+
+-- =============================================================================
+-- PART 3: Rated CDRs
+-- =============================================================================
+
+ALTER TABLE voip.cdr
+    ADD COLUMN IF NOT EXISTS trunk VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS rate_prefix VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS rate_description VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS billed_seconds INT,
+    ADD COLUMN IF NOT EXISTS sell_cost NUMERIC(14,6),
+    ADD COLUMN IF NOT EXISTS buy_cost NUMERIC(14,6),
+    ADD COLUMN IF NOT EXISTS rated_at TIMESTAMP;
+
+COMMENT ON COLUMN voip.cdr.trunk IS 'Gateway name of the trunk the call left through (sip_gateway_name)';
+COMMENT ON COLUMN voip.cdr.rate_prefix IS 'Matched prefix of the sell deck';
+COMMENT ON COLUMN voip.cdr.billed_seconds IS 'billsec rounded up to the sell rate increments';
+COMMENT ON COLUMN voip.cdr.sell_cost IS 'Price for the tenant; NULL if the domain has no matching sell rate';
+COMMENT ON COLUMN voip.cdr.buy_cost IS 'Carrier cost; NULL if the trunk is unknown or has no matching buy rate';
+
+-- Seconds billed for billsec: nothing for unanswered calls, otherwise the
+-- initial increment, then whole increments
+CREATE OR REPLACE FUNCTION voip.billed_seconds(billsec INT, initial_increment INT, increment INT)
+RETURNS INT AS $$
+    SELECT CASE
+        WHEN billsec <= 0 THEN 0
+        WHEN billsec <= initial_increment THEN initial_increment
+        ELSE initial_increment + CEIL((billsec - initial_increment)::NUMERIC / increment)::INT * increment
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Price of a call: connection fee plus the per-minute rate of the billed
+-- seconds; unanswered calls cost nothing
+CREATE OR REPLACE FUNCTION voip.call_cost(billsec INT, rate NUMERIC, connection_fee NUMERIC,
+                                          initial_increment INT, increment INT)
+RETURNS NUMERIC AS $$
+    SELECT CASE
+        WHEN billsec <= 0 THEN 0
+        ELSE ROUND(connection_fee + rate * voip.billed_seconds(billsec, initial_increment, increment) / 60, 6)
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Invoices sum the rated calls of a domain per month (voipadmind's CDR
+-- columns; skipped where voip.cdr still has the 01 layout)
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'voip' AND table_name = 'cdr' AND column_name = 'start_stamp'
+    ) AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'voip' AND table_name = 'cdr' AND column_name = 'domain'
+    ) THEN
+        CREATE INDEX IF NOT EXISTS idx_cdr_rated ON voip.cdr(domain, start_stamp)
+            WHERE sell_cost IS NOT NULL OR buy_cost IS NOT NULL;
+    END IF;
+END $$;
+
+-- =============================================================================
+-- END OF RATING SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Rate decks, deck assignment and rated CDRs
@@ -0,0 +1,69 @@
+-- =============================================================================
+-- Credit Control
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Monthly spending caps and concurrent outbound call limits per
+--              domain and per extension. voipadmind's dialplan checks them
+--              before bridging an outbound call and caps its duration to the
+--              remaining credit; its credit monitor alerts when a cap is 80%
+--              used and when it is reached.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Limits
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.credit_limits (
+    id SERIAL PRIMARY KEY,
+    domain_id INT NOT NULL REFERENCES voip.domains(id) ON DELETE CASCADE,
+    extension_id INT REFERENCES voip.extensions(id) ON DELETE CASCADE,
+    monthly_limit NUMERIC(12,2),
+    max_outbound_calls INT,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_credit_limits_monthly_limit CHECK (monthly_limit >= 0),
+    CONSTRAINT chk_credit_limits_max_outbound_calls CHECK (max_outbound_calls >= 0)
+);
+
+-- One limit per domain (extension_id NULL) and one per extension
+CREATE UNIQUE INDEX IF NOT EXISTS idx_credit_limits_domain ON voip.credit_limits(domain_id)
+    WHERE extension_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_credit_limits_extension ON voip.credit_limits(extension_id)
+    WHERE extension_id IS NOT NULL;
+
+COMMENT ON TABLE voip.credit_limits IS 'Outbound calling limits of a domain (extension_id NULL) or of one extension';
+COMMENT ON COLUMN voip.credit_limits.monthly_limit IS 'Cap on the sell cost of a calendar month, in the currency of the domain sell deck; NULL = no cap';
+COMMENT ON COLUMN voip.credit_limits.max_outbound_calls IS 'Concurrent outbound calls, counted per FreeSWITCH node; NULL = no limit, 0 = none allowed';
+
+-- =============================================================================
+-- PART 2: Alerts
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS voip.credit_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    domain_id INT NOT NULL REFERENCES voip.domains(id) ON DELETE CASCADE,
+    extension_id INT REFERENCES voip.extensions(id) ON DELETE CASCADE,
+    extension VARCHAR(20),
+    month DATE NOT NULL,
+    threshold INT NOT NULL,
+    spent NUMERIC(14,6) NOT NULL,
+    monthly_limit NUMERIC(12,2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_credit_alerts_threshold CHECK (threshold BETWEEN 1 AND 100)
+);
+
+-- Each threshold alerts once per limit and month, whichever node sees it
+-- first
+CREATE UNIQUE INDEX IF NOT EXISTS idx_credit_alerts_once
+    ON voip.credit_alerts(domain_id, COALESCE(extension_id, 0), month, threshold);
+CREATE INDEX IF NOT EXISTS idx_credit_alerts_domain ON voip.credit_alerts(domain_id, created_at DESC);
+
+COMMENT ON TABLE voip.credit_alerts IS 'Monthly spending caps used up to a threshold (percent), raised by the voipadmind credit monitor';
+
+-- =============================================================================
+-- END OF CREDIT CONTROL SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Spending caps, outbound call limits and credit alerts
@@ -0,0 +1,49 @@
+-- =============================================================================
+-- CDR Forwarding
+-- Version: 1.0
+-- Date: 2026-10-19
+-- Description: Extension that forwarded a call (call forwarding and
+--              follow-me), set by voipadmind's outbound dialplan as
+--              forwarded_by. Outbound legs of forwarded calls are charged
+--              to that extension: credit control, rating and the fraud
+--              detector attribute calls to forwarded_by, else to the
+--              calling user.
+-- =============================================================================
+
+-- =============================================================================
+-- PART 1: Forwarding Extension
+-- =============================================================================
+
+ALTER TABLE voip.cdr
+    ADD COLUMN IF NOT EXISTS forwarded_by VARCHAR(100);
+
+COMMENT ON COLUMN voip.cdr.forwarded_by IS 'Extension that forwarded the call (forwarded_by channel variable); calls are charged to it';
+
+-- =============================================================================
+-- PART 2: Calls per Extension
+-- =============================================================================
+
+-- Replaces idx_cdr_caller of 22-fraud-detection.sql with the expression
+-- that includes the forwarding extension (skipped where voip.cdr still has
+-- the 01 layout)
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'voip' AND table_name = 'cdr' AND column_name = 'start_stamp'
+    ) AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'voip' AND table_name = 'cdr' AND column_name = 'domain'
+    ) THEN
+        CREATE INDEX IF NOT EXISTS idx_cdr_charged_user
+            ON voip.cdr(domain, (COALESCE(NULLIF(forwarded_by, ''), NULLIF(sip_from_user, ''), caller_id_number)), start_stamp);
+        DROP INDEX IF EXISTS voip.idx_cdr_caller;
+    END IF;
+END $$;
+
+-- =============================================================================
+-- END OF CDR FORWARDING SCHEMA
+-- =============================================================================
+
+-- Changelog:
+-- v1.0 (2026-10-19): Forwarding extension on CDRs
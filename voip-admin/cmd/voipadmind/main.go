@@ -10,6 +10,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Time conditions must not depend on the host's zoneinfo
 
 	"github.com/gorilla/mux"
 	"gopkg.in/yaml.v3"
@@ -17,7 +18,14 @@ import (
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/api"
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/cache"
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/esl"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/kamailio"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/mailer"
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/middleware"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/numbering"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/sipauth"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/webhook"
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/workers"
 )
 
@@ -63,8 +71,91 @@ type Config struct {
 		FreeSwitchUser     string   `yaml:"freeswitch_user"`
 		FreeSwitchPassword string   `yaml:"freeswitch_password"`
 		APIKeys            []string `yaml:"api_keys"`
+
+		// User login (disabled when token_secret is empty)
+		TokenSecret string        `yaml:"token_secret"`
+		TokenTTL    time.Duration `yaml:"token_ttl"`
+		SessionTTL  time.Duration `yaml:"session_ttl"`
 	} `yaml:"auth"`
 
+	ESL struct {
+		Host     string        `yaml:"host"`
+		Port     int           `yaml:"port"`
+		Password string        `yaml:"password"`
+		Timeout  time.Duration `yaml:"timeout"`
+	} `yaml:"esl"`
+
+	Kamailio struct {
+		RPCURLs []string      `yaml:"rpc_urls"` // jsonrpcs over xhttp, one per node
+		Timeout time.Duration `yaml:"timeout"`
+	} `yaml:"kamailio"`
+
+	Agents struct {
+		ReconcileInterval time.Duration `yaml:"reconcile_interval"`
+	} `yaml:"agents"`
+
+	SIPPasswords sipauth.Policy `yaml:"sip_passwords"`
+
+	Numbering struct {
+		ReloadInterval time.Duration `yaml:"reload_interval"`
+	} `yaml:"numbering"`
+
+	Recordings struct {
+		StoragePath       string        `yaml:"storage_path"`
+		RetentionInterval time.Duration `yaml:"retention_interval"`
+		RetentionDays     *int          `yaml:"retention_days"` // nil = 90, 0 = keep
+	} `yaml:"recordings"`
+
+	Voicemail struct {
+		StoragePath     string        `yaml:"storage_path"`
+		NotifyInterval  time.Duration `yaml:"notify_interval"`
+		SubjectTemplate string        `yaml:"subject_template"`
+		BodyTemplate    string        `yaml:"body_template"`
+		SMTP            struct {
+			Host     string        `yaml:"host"` // Empty disables voicemail emails
+			Port     int           `yaml:"port"`
+			Username string        `yaml:"username"`
+			Password string        `yaml:"password"`
+			From     string        `yaml:"from"`
+			StartTLS bool          `yaml:"starttls"`
+			Timeout  time.Duration `yaml:"timeout"`
+		} `yaml:"smtp"`
+	} `yaml:"voicemail"`
+
+	Fraud struct {
+		Enabled                bool          `yaml:"enabled"`
+		Interval               time.Duration `yaml:"interval"`
+		Window                 time.Duration `yaml:"window"`
+		Rules                  []string      `yaml:"rules"`        // Empty runs all rules
+		AutoSuspend            []string      `yaml:"auto_suspend"` // Rules that deactivate the extension
+		InternationalPrefixes  []string      `yaml:"international_prefixes"`
+		InternationalThreshold int           `yaml:"international_threshold"`
+		HighCostPrefixes       []string      `yaml:"high_cost_prefixes"`
+		OffHours               struct {
+			Start     string `yaml:"start"` // HH:MM
+			End       string `yaml:"end"`
+			Weekends  bool   `yaml:"weekends"`
+			Timezone  string `yaml:"timezone"`
+			Threshold int    `yaml:"threshold"`
+		} `yaml:"off_hours"`
+		Webhook struct {
+			URL     string        `yaml:"url"` // Empty disables notifications
+			Secret  string        `yaml:"secret"`
+			Timeout time.Duration `yaml:"timeout"`
+		} `yaml:"webhook"`
+	} `yaml:"fraud"`
+
+	Credit struct {
+		MonitorInterval time.Duration `yaml:"monitor_interval"`
+		AlertPercent    int           `yaml:"alert_percent"` // First alert; reaching the limit alerts again
+		Announcement    string        `yaml:"announcement"`  // Played on rejected outbound calls
+		Webhook         struct {
+			URL     string        `yaml:"url"` // Empty disables notifications
+			Secret  string        `yaml:"secret"`
+			Timeout time.Duration `yaml:"timeout"`
+		} `yaml:"webhook"`
+	} `yaml:"credit"`
+
 	CORS struct {
 		Enabled          bool     `yaml:"enabled"`
 		AllowedOrigins   []string `yaml:"allowed_origins"`
@@ -82,6 +173,14 @@ type Application struct {
 	Router       *mux.Router
 	CDRProcessor *workers.CDRProcessor
 	CDRCleanup   *workers.CleanupWorker
+	ESL          *esl.Client
+	Kamailio     *kamailio.Client
+	AgentSync    *workers.AgentReconciler
+	Numbering    *numbering.Matcher
+	Retention    *workers.RecordingRetention
+	VMNotifier   *workers.VoicemailNotifier // nil when SMTP is not configured
+	Fraud        *workers.FraudDetector     // nil when fraud detection is disabled
+	Credit       *workers.CreditMonitor
 }
 
 func main() {
@@ -125,16 +224,117 @@ func main() {
 		CleanupInterval: config.Cache.CleanupInterval,
 	})
 
+	// Load numbering plans
+	log.Println("Loading numbering plans...")
+	numbers := numbering.NewMatcher(db, config.Numbering.ReloadInterval)
+	if err := numbers.Reload(context.Background()); err != nil {
+		log.Printf("Warning: failed to load numbering plans, using defaults: %v", err)
+	}
+
 	// Initialize CDR processor
 	log.Println("Initializing CDR processor...")
 	cdrProcessor := workers.NewCDRProcessor(db, &workers.CDRProcessorConfig{
 		BatchSize:          config.CDR.BatchSize,
 		ProcessingInterval: config.CDR.ProcessingInterval,
+		Numbers:            numbers,
 	})
 
 	// Initialize CDR cleanup worker
 	cdrCleanup := workers.NewCleanupWorker(db, config.CDR.CleanupInterval, config.CDR.RetentionDays)
 
+	// Initialize recording retention worker
+	retention := workers.NewRecordingRetention(db, config.Recordings.StoragePath,
+		config.Recordings.RetentionInterval, *config.Recordings.RetentionDays)
+
+	// Initialize voicemail-to-email notifier
+	var vmNotifier *workers.VoicemailNotifier
+	if smtp := config.Voicemail.SMTP; smtp.Host != "" {
+		vmMailer := mailer.New(&mailer.Config{
+			Host:     smtp.Host,
+			Port:     smtp.Port,
+			Username: smtp.Username,
+			Password: smtp.Password,
+			From:     smtp.From,
+			StartTLS: smtp.StartTLS,
+			Timeout:  smtp.Timeout,
+		})
+		vmNotifier, err = workers.NewVoicemailNotifier(db, vmMailer, config.Voicemail.StoragePath,
+			config.Voicemail.NotifyInterval, config.Voicemail.SubjectTemplate, config.Voicemail.BodyTemplate)
+		if err != nil {
+			log.Fatalf("Failed to initialize voicemail notifier: %v", err)
+		}
+	}
+
+	// Initialize FreeSWITCH Event Socket client
+	eslClient := esl.NewClient(&esl.Config{
+		Host:     config.ESL.Host,
+		Port:     config.ESL.Port,
+		Password: config.ESL.Password,
+		Timeout:  config.ESL.Timeout,
+	})
+
+	// Initialize Kamailio JSON-RPC client
+	kamailioClient := kamailio.NewClient(&kamailio.Config{
+		URLs:    config.Kamailio.RPCURLs,
+		Timeout: config.Kamailio.Timeout,
+	})
+
+	// Initialize agent state reconciler
+	agentSync := workers.NewAgentReconciler(db, eslClient, config.Agents.ReconcileInterval)
+
+	// Initialize fraud detector
+	var fraud *workers.FraudDetector
+	if fc := config.Fraud; fc.Enabled {
+		var notifier *webhook.Notifier
+		if fc.Webhook.URL != "" {
+			notifier = webhook.New(&webhook.Config{
+				URL:     fc.Webhook.URL,
+				Secret:  fc.Webhook.Secret,
+				Timeout: fc.Webhook.Timeout,
+			})
+		}
+		fraud, err = workers.NewFraudDetector(db, &workers.FraudDetectorConfig{
+			Interval:               fc.Interval,
+			Window:                 fc.Window,
+			Rules:                  fc.Rules,
+			AutoSuspend:            fc.AutoSuspend,
+			InternationalPrefixes:  fc.InternationalPrefixes,
+			InternationalThreshold: fc.InternationalThreshold,
+			HighCostPrefixes:       fc.HighCostPrefixes,
+			OffHoursStart:          fc.OffHours.Start,
+			OffHoursEnd:            fc.OffHours.End,
+			OffHoursWeekends:       fc.OffHours.Weekends,
+			OffHoursTimezone:       fc.OffHours.Timezone,
+			OffHoursThreshold:      fc.OffHours.Threshold,
+			Numbers:                numbers,
+			Cache:                  cacheManager,
+			ESL:                    eslClient,
+			Kamailio:               kamailioClient,
+			Webhook:                notifier,
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize fraud detector: %v", err)
+		}
+	}
+
+	// Initialize credit monitor
+	var creditNotifier *webhook.Notifier
+	if cc := config.Credit; cc.Webhook.URL != "" {
+		creditNotifier = webhook.New(&webhook.Config{
+			URL:     cc.Webhook.URL,
+			Secret:  cc.Webhook.Secret,
+			Timeout: cc.Webhook.Timeout,
+		})
+	}
+	credit, err := workers.NewCreditMonitor(db, &workers.CreditMonitorConfig{
+		Interval:     config.Credit.MonitorInterval,
+		AlertPercent: config.Credit.AlertPercent,
+		Webhook:      creditNotifier,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize credit monitor: %v", err)
+	}
+
 	// Create application
 	app := &Application{
 		Config:       config,
@@ -143,6 +343,14 @@ func main() {
 		Router:       mux.NewRouter(),
 		CDRProcessor: cdrProcessor,
 		CDRCleanup:   cdrCleanup,
+		ESL:          eslClient,
+		Kamailio:     kamailioClient,
+		AgentSync:    agentSync,
+		Numbering:    numbers,
+		Retention:    retention,
+		VMNotifier:   vmNotifier,
+		Fraud:        fraud,
+		Credit:       credit,
 	}
 
 	// Setup routes
@@ -170,6 +378,16 @@ func main() {
 	go cacheManager.Start(ctx)
 	go cdrProcessor.Start(ctx)
 	go cdrCleanup.Start(ctx)
+	go agentSync.Start(ctx)
+	go numbers.Start(ctx)
+	go retention.Start(ctx)
+	if vmNotifier != nil {
+		go vmNotifier.Start(ctx)
+	}
+	if fraud != nil {
+		go fraud.Start(ctx)
+	}
+	go credit.Start(ctx)
 
 	// Start HTTP server
 	go func() {
@@ -193,6 +411,16 @@ func main() {
 	cacheManager.Stop()
 	cdrProcessor.Stop()
 	cdrCleanup.Stop()
+	agentSync.Stop()
+	numbers.Stop()
+	retention.Stop()
+	if vmNotifier != nil {
+		vmNotifier.Stop()
+	}
+	if fraud != nil {
+		fraud.Stop()
+	}
+	credit.Stop()
 
 	// Shutdown HTTP server
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -254,6 +482,70 @@ func loadConfig(filename string) (*Config, error) {
 	if config.CDR.RetentionDays == 0 {
 		config.CDR.RetentionDays = 7
 	}
+	if config.Auth.TokenTTL == 0 {
+		config.Auth.TokenTTL = 15 * time.Minute
+	}
+	if config.Auth.SessionTTL == 0 {
+		config.Auth.SessionTTL = 12 * time.Hour
+	}
+	if config.Auth.TokenSecret != "" && len(config.Auth.TokenSecret) < 32 {
+		return nil, fmt.Errorf("auth.token_secret must be at least 32 characters")
+	}
+	if config.ESL.Host == "" {
+		config.ESL.Host = "127.0.0.1"
+	}
+	if config.ESL.Port == 0 {
+		config.ESL.Port = 8021
+	}
+	if config.ESL.Timeout == 0 {
+		config.ESL.Timeout = 5 * time.Second
+	}
+	if config.Kamailio.Timeout == 0 {
+		config.Kamailio.Timeout = 5 * time.Second
+	}
+	if config.Agents.ReconcileInterval == 0 {
+		config.Agents.ReconcileInterval = 60 * time.Second
+	}
+	if config.Numbering.ReloadInterval == 0 {
+		config.Numbering.ReloadInterval = 60 * time.Second
+	}
+	if config.Recordings.StoragePath == "" {
+		config.Recordings.StoragePath = models.DefaultRecordingStoragePath
+	}
+	if config.Recordings.RetentionInterval == 0 {
+		config.Recordings.RetentionInterval = time.Hour
+	}
+	if config.Recordings.RetentionDays == nil {
+		days := 90
+		config.Recordings.RetentionDays = &days
+	}
+	if config.Voicemail.StoragePath == "" {
+		config.Voicemail.StoragePath = models.DefaultVoicemailStoragePath
+	}
+	if config.Voicemail.NotifyInterval == 0 {
+		config.Voicemail.NotifyInterval = 30 * time.Second
+	}
+	if config.Voicemail.SMTP.Port == 0 {
+		config.Voicemail.SMTP.Port = 25
+	}
+	if config.Voicemail.SMTP.Host != "" && config.Voicemail.SMTP.From == "" {
+		return nil, fmt.Errorf("voicemail.smtp.from is required when voicemail.smtp.host is set")
+	}
+	if config.Fraud.Interval == 0 {
+		config.Fraud.Interval = 30 * time.Second
+	}
+	if config.Fraud.Window == 0 {
+		config.Fraud.Window = time.Hour
+	}
+	if config.Credit.MonitorInterval == 0 {
+		config.Credit.MonitorInterval = time.Minute
+	}
+	if config.Credit.AlertPercent == 0 {
+		config.Credit.AlertPercent = models.DefaultCreditAlertPercent
+	}
+	if err := config.SIPPasswords.Load(); err != nil {
+		return nil, fmt.Errorf("sip_passwords: %w", err)
+	}
 
 	return &config, nil
 }
@@ -262,10 +554,32 @@ func loadConfig(filename string) (*Config, error) {
 func (app *Application) setupRoutes() error {
 	// Initialize API handlers
 	healthHandler := api.NewHealthHandler(app.DB, app.Cache, version)
-	extensionHandler := api.NewExtensionHandler(app.DB)
+	extensionHandler := api.NewExtensionHandler(app.DB, &app.Config.SIPPasswords)
 	cdrHandler := api.NewCDRHandler(app.DB)
-
-	freeSwitchHandler, err := api.NewFreeSwitchHandler(app.DB, app.Cache)
+	agentHandler := api.NewAgentHandler(app.DB, app.ESL)
+	domainHandler := api.NewDomainHandler(app.DB, app.Cache)
+	numberingHandler := api.NewNumberingHandler(app.DB, app.Numbering)
+	timeConditionHandler := api.NewTimeConditionHandler(app.DB)
+	didHandler := api.NewDIDHandler(app.DB)
+	recordingHandler := api.NewRecordingHandler(app.DB, app.Config.Recordings.StoragePath)
+	voicemailHandler := api.NewVoicemailHandler(app.DB, app.ESL, app.Config.Voicemail.StoragePath)
+	conferenceHandler := api.NewConferenceHandler(app.DB, app.ESL)
+	trunkHandler := api.NewTrunkHandler(app.DB, app.ESL)
+	aclHandler := api.NewACLHandler(app.DB, app.ESL, app.Kamailio)
+	dispatcherHandler := api.NewDispatcherHandler(app.DB, app.Kamailio)
+	registrationHandler := api.NewRegistrationHandler(app.DB, app.Kamailio)
+	fraudHandler := api.NewFraudHandler(app.DB)
+	rateDeckHandler := api.NewRateDeckHandler(app.DB)
+	invoiceHandler := api.NewInvoiceHandler(app.DB)
+	creditHandler := api.NewCreditHandler(app.DB)
+	apiKeyHandler := api.NewAPIKeyHandler(app.DB)
+	queueHandler := api.NewQueueHandler(app.DB)
+	userHandler := api.NewUserHandler(app.DB)
+	auditHandler := api.NewAuditHandler(app.DB)
+	authHandler := api.NewAuthHandler(app.DB, []byte(app.Config.Auth.TokenSecret),
+		app.Config.Auth.TokenTTL, app.Config.Auth.SessionTTL)
+
+	freeSwitchHandler, err := api.NewFreeSwitchHandler(app.DB, app.Cache, app.Numbering, app.Config.Credit.Announcement)
 	if err != nil {
 		return fmt.Errorf("create freeswitch handler: %w", err)
 	}
@@ -275,10 +589,14 @@ func (app *Application) setupRoutes() error {
 		FreeSwitchUser:     app.Config.Auth.FreeSwitchUser,
 		FreeSwitchPassword: app.Config.Auth.FreeSwitchPassword,
 		APIKeys:            app.Config.Auth.APIKeys,
+		KeyStore:           app.DB,
+		TokenSecret:        []byte(app.Config.Auth.TokenSecret),
+		Sessions:           app.DB,
 	}
 
 	// Apply global middleware
 	app.Router.Use(middleware.Recovery)
+	app.Router.Use(middleware.RequestID)
 	app.Router.Use(middleware.Logging)
 
 	// CORS middleware (optional)
@@ -306,7 +624,10 @@ func (app *Application) setupRoutes() error {
 	// CDR ingest endpoint (Basic Auth - from FreeSWITCH)
 	app.Router.Handle("/api/v1/cdr", middleware.BasicAuth(authConfig)(http.HandlerFunc(cdrHandler.Ingest))).Methods("POST")
 
-	// Admin API endpoints (API Key Auth)
+	// User login (public)
+	app.Router.HandleFunc("/api/v1/auth/login", authHandler.Login).Methods("POST")
+
+	// Admin API endpoints (API Key or user token auth)
 	apiRouter := app.Router.PathPrefix("/api/v1").Subrouter()
 	apiRouter.Use(middleware.APIKeyAuth(authConfig))
 
@@ -315,13 +636,165 @@ func (app *Application) setupRoutes() error {
 	apiRouter.HandleFunc("/cdr/{uuid}", cdrHandler.Get).Methods("GET")
 	apiRouter.HandleFunc("/cdr/stats", cdrHandler.Stats).Methods("GET")
 
+	// Domain API
+	apiRouter.HandleFunc("/domains", domainHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/domains", domainHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/domains/{id}", domainHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/domains/{id}", domainHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/domains/{id}", domainHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/domains/{id}/numbering-plan", numberingHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/domains/{id}/numbering-plan", numberingHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/domains/{id}/numbering-plan/next", numberingHandler.Next).Methods("GET")
+	apiRouter.HandleFunc("/domains/{id}/registrations", registrationHandler.ListDomain).Methods("GET")
+	apiRouter.HandleFunc("/domains/{id}/credit-limit", creditHandler.GetDomain).Methods("GET")
+	apiRouter.HandleFunc("/domains/{id}/credit-limit", creditHandler.UpdateDomain).Methods("PUT")
+	apiRouter.HandleFunc("/domains/{id}/credit-alerts", creditHandler.Alerts).Methods("GET")
+
+	// Time condition routes
+	apiRouter.HandleFunc("/time-conditions", timeConditionHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/time-conditions", timeConditionHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/time-conditions/evaluate", timeConditionHandler.Evaluate).Methods("GET")
+	apiRouter.HandleFunc("/time-conditions/{id}", timeConditionHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/time-conditions/{id}", timeConditionHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/time-conditions/{id}", timeConditionHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/time-conditions/{id}/override", timeConditionHandler.Override).Methods("PUT")
+
+	// DID routes
+	apiRouter.HandleFunc("/dids", didHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/dids", didHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/dids/{id}", didHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/dids/{id}", didHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/dids/{id}", didHandler.Delete).Methods("DELETE")
+
+	// Recording routes
+	apiRouter.HandleFunc("/recordings", recordingHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/recordings/{id}", recordingHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/recordings/{id}/download", recordingHandler.Download).Methods("GET")
+	apiRouter.HandleFunc("/recordings/{id}/legal-hold", recordingHandler.LegalHold).Methods("PUT")
+
+	// Conference rooms
+	apiRouter.HandleFunc("/conferences", conferenceHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/conferences", conferenceHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/conferences/{id}", conferenceHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/conferences/{id}", conferenceHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/conferences/{id}", conferenceHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/conferences/{id}/live", conferenceHandler.Live).Methods("GET")
+	apiRouter.HandleFunc("/conferences/{id}/lock", conferenceHandler.Lock).Methods("PUT")
+	apiRouter.HandleFunc("/conferences/{id}/members/{member_id}/{action}", conferenceHandler.MemberAction).Methods("POST")
+
+	// Trunks (sofia gateways)
+	apiRouter.HandleFunc("/trunks", trunkHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/trunks", trunkHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/trunks/reload", trunkHandler.Reload).Methods("POST")
+	apiRouter.HandleFunc("/trunks/{id}", trunkHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/trunks/{id}", trunkHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/trunks/{id}", trunkHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/trunks/{id}/status", trunkHandler.Status).Methods("GET")
+
+	// Network ACLs (acl.conf and kamailio.address)
+	apiRouter.HandleFunc("/acls", aclHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/acls", aclHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/acls/reload", aclHandler.Reload).Methods("POST")
+	apiRouter.HandleFunc("/acls/{id}", aclHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/acls/{id}", aclHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/acls/{id}", aclHandler.Delete).Methods("DELETE")
+
+	// Kamailio dispatcher (FreeSWITCH destinations)
+	apiRouter.HandleFunc("/dispatchers", dispatcherHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/dispatchers", dispatcherHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/dispatchers/reload", dispatcherHandler.Reload).Methods("POST")
+	apiRouter.HandleFunc("/dispatchers/{id}", dispatcherHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/dispatchers/{id}", dispatcherHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/dispatchers/{id}", dispatcherHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/dispatchers/{id}/drain", dispatcherHandler.Drain).Methods("POST")
+	apiRouter.HandleFunc("/dispatchers/{id}/activate", dispatcherHandler.Activate).Methods("POST")
+
+	// Fraud alerts (raised by the fraud detector)
+	apiRouter.HandleFunc("/fraud/alerts", fraudHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/fraud/alerts/{id}", fraudHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/fraud/alerts/{id}/acknowledge", fraudHandler.Acknowledge).Methods("POST")
+
+	// Rating (buy decks on trunks, sell decks on domains) and invoices
+	apiRouter.HandleFunc("/rate-decks", rateDeckHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/rate-decks", rateDeckHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/rate-decks/{id}", rateDeckHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/rate-decks/{id}", rateDeckHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/rate-decks/{id}", rateDeckHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/rate-decks/{id}/rates", rateDeckHandler.Rates).Methods("GET")
+	apiRouter.HandleFunc("/rate-decks/{id}/rates/import", rateDeckHandler.Import).Methods("POST")
+	apiRouter.HandleFunc("/rate-decks/{id}/rates/export", rateDeckHandler.Export).Methods("GET")
+	apiRouter.HandleFunc("/invoices", invoiceHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/invoices/{domain_id}/{month}", invoiceHandler.Get).Methods("GET")
+
+	// Voicemail
+	apiRouter.HandleFunc("/voicemail/{id}", voicemailHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/voicemail/{id}/messages/{uuid}", voicemailHandler.UpdateMessage).Methods("PUT")
+	apiRouter.HandleFunc("/voicemail/{id}/messages/{uuid}", voicemailHandler.DeleteMessage).Methods("DELETE")
+	apiRouter.HandleFunc("/voicemail/{id}/messages/{uuid}/audio", voicemailHandler.Audio).Methods("GET")
+
 	// Extension API
 	apiRouter.HandleFunc("/extensions", extensionHandler.List).Methods("GET")
 	apiRouter.HandleFunc("/extensions", extensionHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/extensions/generate-password", extensionHandler.GeneratePassword).Methods("GET")
+	apiRouter.HandleFunc("/extensions/import", extensionHandler.Import).Methods("POST")
+	apiRouter.HandleFunc("/extensions/export", extensionHandler.Export).Methods("GET")
 	apiRouter.HandleFunc("/extensions/{id}", extensionHandler.Get).Methods("GET")
 	apiRouter.HandleFunc("/extensions/{id}", extensionHandler.Update).Methods("PUT")
 	apiRouter.HandleFunc("/extensions/{id}", extensionHandler.Delete).Methods("DELETE")
 	apiRouter.HandleFunc("/extensions/{id}/password", extensionHandler.UpdatePassword).Methods("POST")
+	apiRouter.HandleFunc("/extensions/{id}/call-handling", extensionHandler.GetCallHandling).Methods("GET")
+	apiRouter.HandleFunc("/extensions/{id}/call-handling", extensionHandler.UpdateCallHandling).Methods("PUT")
+	apiRouter.HandleFunc("/extensions/{id}/credit-limit", creditHandler.GetExtension).Methods("GET")
+	apiRouter.HandleFunc("/extensions/{id}/credit-limit", creditHandler.UpdateExtension).Methods("PUT")
+	apiRouter.HandleFunc("/extensions/{id}/registrations", registrationHandler.ListExtension).Methods("GET")
+	apiRouter.HandleFunc("/extensions/{id}/registrations", registrationHandler.Unregister).Methods("DELETE")
+	apiRouter.HandleFunc("/extensions/{id}/registrations/{ruid}", registrationHandler.UnregisterContact).Methods("DELETE")
+
+	// Agent state API (id = agent extension ID)
+	apiRouter.HandleFunc("/agents/{id}", agentHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/agents/{id}/login", agentHandler.Login).Methods("POST")
+	apiRouter.HandleFunc("/agents/{id}/logout", agentHandler.Logout).Methods("POST")
+	apiRouter.HandleFunc("/agents/{id}/break", agentHandler.Break).Methods("POST")
+	apiRouter.HandleFunc("/agents/{id}/history", agentHandler.History).Methods("GET")
+	apiRouter.HandleFunc("/agents/{id}/summary", agentHandler.Summary).Methods("GET")
+	apiRouter.HandleFunc("/agents/{id}/voicemail-pin", agentHandler.VoicemailPIN).Methods("POST")
+
+	// Self-service for logged-in users
+	apiRouter.HandleFunc("/auth/me", authHandler.Me).Methods("GET")
+	apiRouter.HandleFunc("/auth/refresh", authHandler.Refresh).Methods("POST")
+	apiRouter.HandleFunc("/auth/logout", authHandler.Logout).Methods("POST")
+	apiRouter.HandleFunc("/auth/extensions/{id}/password", extensionHandler.UpdateOwnPassword).Methods("POST")
+	apiRouter.HandleFunc("/auth/extensions/{id}/call-handling", extensionHandler.GetOwnCallHandling).Methods("GET")
+	apiRouter.HandleFunc("/auth/extensions/{id}/call-handling", extensionHandler.UpdateOwnCallHandling).Methods("PUT")
+	apiRouter.HandleFunc("/auth/voicemail/{id}", voicemailHandler.GetOwn).Methods("GET")
+	apiRouter.HandleFunc("/auth/voicemail/{id}/messages/{uuid}", voicemailHandler.UpdateOwnMessage).Methods("PUT")
+	apiRouter.HandleFunc("/auth/voicemail/{id}/messages/{uuid}", voicemailHandler.DeleteOwnMessage).Methods("DELETE")
+	apiRouter.HandleFunc("/auth/voicemail/{id}/messages/{uuid}/audio", voicemailHandler.OwnAudio).Methods("GET")
+
+	// Users
+	apiRouter.HandleFunc("/users", userHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/users", userHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/users/{id}", userHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/users/{id}", userHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/users/{id}", userHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/users/{id}/password", userHandler.SetPassword).Methods("POST")
+	apiRouter.HandleFunc("/users/{id}/queues", userHandler.Queues).Methods("GET")
+	apiRouter.HandleFunc("/users/{id}/queues", userHandler.SetQueues).Methods("PUT")
+
+	// Queues
+	apiRouter.HandleFunc("/queues", queueHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/queues/{id}", queueHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/queues/{id}/agents", queueHandler.Agents).Methods("GET")
+
+	// Audit log
+	apiRouter.HandleFunc("/audit", auditHandler.List).Methods("GET")
+
+	// API keys
+	apiRouter.HandleFunc("/api-keys", apiKeyHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/api-keys", apiKeyHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/api-keys/{id}", apiKeyHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/api-keys/{id}", apiKeyHandler.Revoke).Methods("DELETE")
+	apiRouter.HandleFunc("/api-keys/{id}/rotate", apiKeyHandler.Rotate).Methods("POST")
 
 	return nil
 }
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/esl"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/kamailio"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// aclNamePattern matches an ACL list name. Dots are excluded so names
+// cannot collide with FreeSWITCH's own .auto lists.
+var aclNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+
+// maxACLEntries limits the number of networks per list
+const maxACLEntries = 1000
+
+// ACLHandler handles network ACL HTTP requests. Lists are stored in the
+// database, served to FreeSWITCH as acl.conf and to Kamailio through the
+// kamailio.address view. ACLs are shared by all domains, so domain-scoped
+// API keys cannot manage them.
+type ACLHandler struct {
+	db       *database.DB
+	esl      *esl.Client
+	kamailio *kamailio.Client
+}
+
+// NewACLHandler creates a new ACL handler
+func NewACLHandler(db *database.DB, eslClient *esl.Client, kamailioClient *kamailio.Client) *ACLHandler {
+	return &ACLHandler{
+		db:       db,
+		esl:      eslClient,
+		kamailio: kamailioClient,
+	}
+}
+
+// List handles GET /api/v1/acls
+func (h *ACLHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !h.unscoped(w, r) {
+		return
+	}
+
+	lists, err := h.db.ListACLLists(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list ACLs", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lists)
+}
+
+// Get handles GET /api/v1/acls/{id}
+func (h *ACLHandler) Get(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadList(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, list)
+}
+
+// Create handles POST /api/v1/acls
+// FreeSWITCH and Kamailio apply the list after a reload.
+func (h *ACLHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.unscoped(w, r) {
+		return
+	}
+
+	var req models.ACLListCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if !aclNamePattern.MatchString(req.Name) {
+		respondError(w, http.StatusBadRequest, "Validation failed",
+			errValidation("name must be 1-50 letters, digits, '_' or '-'"))
+		return
+	}
+	if _, err := h.db.GetACLListByName(ctx, req.Name); err == nil {
+		respondError(w, http.StatusConflict, "ACL list already exists", nil)
+		return
+	}
+
+	if req.DefaultAction == "" {
+		req.DefaultAction = models.ACLDeny
+	}
+
+	list := &models.ACLList{
+		Name:          req.Name,
+		DefaultAction: req.DefaultAction,
+		KamailioGroup: req.KamailioGroup,
+		Description:   req.Description,
+		Entries:       req.Entries,
+	}
+	if err := h.validateList(r, list); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	list, err := h.db.CreateACLList(ctx, list)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create ACL list", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditCreate, "acls", strconv.FormatInt(list.ID, 10), nil, nil, list)
+
+	respondJSON(w, http.StatusCreated, list)
+}
+
+// Update handles PUT /api/v1/acls/{id}
+func (h *ACLHandler) Update(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadList(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ACLListUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	candidate := *current
+	if req.DefaultAction != nil {
+		candidate.DefaultAction = *req.DefaultAction
+	}
+	if req.KamailioGroup != nil {
+		candidate.KamailioGroup = req.KamailioGroup
+		if *req.KamailioGroup == 0 {
+			candidate.KamailioGroup = nil
+		}
+	}
+	if req.Description != nil {
+		candidate.Description = *req.Description
+	}
+	if req.Entries != nil {
+		candidate.Entries = req.Entries
+	}
+
+	if err := h.validateList(r, &candidate); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	list, err := h.db.UpdateACLList(r.Context(), &candidate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update ACL list", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "acls", strconv.FormatInt(list.ID, 10), nil, current, list)
+
+	respondJSON(w, http.StatusOK, list)
+}
+
+// Delete handles DELETE /api/v1/acls/{id}
+// Lists applied by a SIP profile cannot be deleted.
+func (h *ACLHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	current, ok := h.loadList(w, r)
+	if !ok {
+		return
+	}
+
+	profiles, err := h.db.CountACLListProfiles(ctx, current.Name)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to check ACL list usage", err)
+		return
+	}
+	if profiles > 0 {
+		respondError(w, http.StatusConflict, fmt.Sprintf("ACL list is applied by %d SIP profiles", profiles), nil)
+		return
+	}
+
+	if err := h.db.DeleteACLList(ctx, current.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete ACL list", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "acls", strconv.FormatInt(current.ID, 10), nil, current, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Reload handles POST /api/v1/acls/reload
+// FreeSWITCH re-reads acl.conf and every Kamailio node its address table.
+func (h *ACLHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if !h.unscoped(w, r) {
+		return
+	}
+
+	if err := h.esl.ReloadACL(r.Context()); err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to reload ACLs on FreeSWITCH", err)
+		return
+	}
+	// Without RPC endpoints, Kamailio picks changes up on restart
+	if err := h.kamailio.ReloadAddresses(r.Context()); err != nil && !errors.Is(err, kamailio.ErrNoNodes) {
+		respondError(w, http.StatusBadGateway, "Failed to reload ACLs on Kamailio", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "acls", "reload", nil, nil, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unscoped responds 403 to domain-scoped callers
+func (h *ACLHandler) unscoped(w http.ResponseWriter, r *http.Request) bool {
+	if domainScope(r) != nil {
+		respondError(w, http.StatusForbidden, "Domain-scoped API keys cannot manage ACLs", nil)
+		return false
+	}
+	return true
+}
+
+// loadList loads the {id} ACL list
+func (h *ACLHandler) loadList(w http.ResponseWriter, r *http.Request) (*models.ACLList, bool) {
+	if !h.unscoped(w, r) {
+		return nil, false
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ACL list ID", err)
+		return nil, false
+	}
+
+	list, err := h.db.GetACLList(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "ACL list not found", err)
+		return nil, false
+	}
+
+	return list, true
+}
+
+// validateList checks an ACL list and normalizes the networks of its
+// entries
+func (h *ACLHandler) validateList(r *http.Request, list *models.ACLList) error {
+	if list.DefaultAction != models.ACLAllow && list.DefaultAction != models.ACLDeny {
+		return errValidation("default_action must be allow or deny")
+	}
+	if len(list.Description) > 255 {
+		return errValidation("description must be at most 255 characters")
+	}
+
+	if list.KamailioGroup != nil {
+		if *list.KamailioGroup <= 0 {
+			return errValidation("kamailio_group must be positive")
+		}
+		lists, err := h.db.ListACLLists(r.Context())
+		if err != nil {
+			return err
+		}
+		for _, other := range lists {
+			if other.ID != list.ID && other.KamailioGroup != nil && *other.KamailioGroup == *list.KamailioGroup {
+				return errValidation(fmt.Sprintf("kamailio_group %d is used by ACL list %s", *list.KamailioGroup, other.Name))
+			}
+		}
+	}
+
+	if len(list.Entries) > maxACLEntries {
+		return errValidation(fmt.Sprintf("at most %d entries per list", maxACLEntries))
+	}
+
+	seen := make(map[string]bool, len(list.Entries))
+	for i, e := range list.Entries {
+		if e == nil {
+			return errValidation(fmt.Sprintf("entry %d is empty", i+1))
+		}
+
+		cidr, err := normalizeCIDR(e.CIDR)
+		if err != nil {
+			return errValidation(fmt.Sprintf("entry %d: %v", i+1, err))
+		}
+		if seen[cidr] {
+			return errValidation(fmt.Sprintf("entry %d: %s is listed twice", i+1, cidr))
+		}
+		seen[cidr] = true
+		e.CIDR = cidr
+
+		if e.Action == "" {
+			e.Action = models.ACLAllow
+		}
+		if e.Action != models.ACLAllow && e.Action != models.ACLDeny {
+			return errValidation(fmt.Sprintf("entry %d: action must be allow or deny", i+1))
+		}
+		if e.Port < 0 || e.Port > 65535 {
+			return errValidation(fmt.Sprintf("entry %d: port must be between 0 (any) and 65535", i+1))
+		}
+		if len(e.Description) > 255 {
+			return errValidation(fmt.Sprintf("entry %d: description must be at most 255 characters", i+1))
+		}
+	}
+
+	return nil
+}
+
+// normalizeCIDR parses a network in CIDR notation, or a single address,
+// and returns it with the host bits cleared, e.g. 10.0.0.5/24 becomes
+// 10.0.0.0/24 and 192.0.2.1 becomes 192.0.2.1/32
+func normalizeCIDR(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return "", fmt.Errorf("invalid address %q", s)
+		}
+		if ip.To4() != nil {
+			return ip.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid CIDR %q", s)
+	}
+	return network.String(), nil
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/esl"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// voicemailPINPattern matches a numeric voicemail PIN
+var voicemailPINPattern = regexp.MustCompile(`^[0-9]{4,10}$`)
+
+// AgentHandler handles call center agent state requests
+type AgentHandler struct {
+	db  *database.DB
+	esl *esl.Client
+}
+
+// NewAgentHandler creates a new agent handler
+func NewAgentHandler(db *database.DB, eslClient *esl.Client) *AgentHandler {
+	return &AgentHandler{
+		db:  db,
+		esl: eslClient,
+	}
+}
+
+// Get handles GET /api/v1/agents/{id}
+func (h *AgentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ext, ok := h.loadAgentExtension(w, r)
+	if !ok {
+		return
+	}
+
+	queues, err := h.db.ListAgentQueues(ctx, ext.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get agent queues", err)
+		return
+	}
+	if len(queues) == 0 {
+		respondError(w, http.StatusNotFound, "Extension is not an agent of any queue", nil)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, buildAgentState(ext, queues, true))
+}
+
+// Login handles POST /api/v1/agents/{id}/login
+func (h *AgentHandler) Login(w http.ResponseWriter, r *http.Request) {
+	h.changeState(w, r, models.AgentStateAvailable)
+}
+
+// Logout handles POST /api/v1/agents/{id}/logout
+func (h *AgentHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	h.changeState(w, r, models.AgentStateLoggedOut)
+}
+
+// Break handles POST /api/v1/agents/{id}/break
+func (h *AgentHandler) Break(w http.ResponseWriter, r *http.Request) {
+	h.changeState(w, r, models.AgentStateOnBreak)
+}
+
+// History handles GET /api/v1/agents/{id}/history
+func (h *AgentHandler) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ext, ok := h.loadAgentExtension(w, r)
+	if !ok {
+		return
+	}
+
+	startDate, endDate := parseDateRange(r)
+
+	history, err := h.db.ListAgentStateHistory(ctx, ext.ID, startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get agent state history", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, history)
+}
+
+// Summary handles GET /api/v1/agents/{id}/summary
+func (h *AgentHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ext, ok := h.loadAgentExtension(w, r)
+	if !ok {
+		return
+	}
+
+	startDate, endDate := parseDateRange(r)
+
+	summary, err := h.db.GetAgentStateSummary(ctx, ext.ID, startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get agent state summary", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"extension_id": ext.ID,
+		"start_date":   startDate,
+		"end_date":     endDate,
+		"states":       summary,
+	})
+}
+
+// VoicemailPIN handles POST /api/v1/agents/{id}/voicemail-pin
+// Lets agents change the voicemail PIN of their own extension.
+func (h *AgentHandler) VoicemailPIN(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ext, ok := h.loadAgentExtension(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.VoicemailPINUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if !voicemailPINPattern.MatchString(req.PIN) {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("pin must be 4-10 digits"))
+		return
+	}
+
+	if err := h.db.UpdateExtensionVoicemailPIN(ctx, ext.ID, req.PIN); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update voicemail PIN", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditChangePassword, "extensions", strconv.FormatInt(ext.ID, 10), &ext.DomainID,
+		nil, map[string]string{"vm_password": req.PIN})
+
+	respondSuccess(w, "Voicemail PIN updated successfully", nil)
+}
+
+// changeState persists a new agent state and pushes it to mod_callcenter
+func (h *AgentHandler) changeState(w http.ResponseWriter, r *http.Request, state string) {
+	ctx := r.Context()
+
+	ext, ok := h.loadAgentExtension(w, r)
+	if !ok {
+		return
+	}
+
+	// Body is optional (reason for break/logout)
+	var req models.AgentStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if len(req.Reason) > 255 {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("reason must be at most 255 characters"))
+		return
+	}
+
+	if err := h.db.SetAgentState(ctx, ext.ID, state, req.Reason, "api"); err != nil {
+		if errors.Is(err, database.ErrAgentNotFound) {
+			respondError(w, http.StatusNotFound, "Agent not found", err)
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to update agent state", err)
+		return
+	}
+
+	// The database is the source of truth. If FreeSWITCH is unreachable the
+	// reconciler pushes the state on its next run.
+	synced := true
+	agent := models.AgentName(ext.Extension, ext.Domain)
+	if err := h.esl.SetAgentStatus(ctx, agent, state); errors.Is(err, esl.ErrAgentNotFound) {
+		// The state is stored; the reconciler pushes it once the agent is loaded
+		log.Printf("[Agents] %s not loaded in mod_callcenter, status %q not pushed", agent, state)
+		synced = false
+	} else if err != nil {
+		log.Printf("[Agents] Failed to push status %q for %s: %v", state, agent, err)
+		synced = false
+	} else if state == models.AgentStateAvailable {
+		// A freshly logged-in agent must be Waiting to receive calls
+		if err := h.esl.SetAgentState(ctx, agent, "Waiting"); err != nil {
+			log.Printf("[Agents] Failed to reset state for %s: %v", agent, err)
+			synced = false
+		}
+	}
+
+	queues, err := h.db.ListAgentQueues(ctx, ext.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get agent queues", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, buildAgentState(ext, queues, synced))
+}
+
+// loadAgentExtension resolves the {id} path variable to a user extension
+func (h *AgentHandler) loadAgentExtension(w http.ResponseWriter, r *http.Request) (*models.Extension, bool) {
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid extension ID", err)
+		return nil, false
+	}
+
+	ext, err := h.db.GetExtensionByID(r.Context(), id)
+	if err != nil || !canAccessDomain(r, ext.DomainID) {
+		respondError(w, http.StatusNotFound, "Extension not found", err)
+		return nil, false
+	}
+
+	allowed, err := canManageAgent(r, h.db, ext)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to check agent access", err)
+		return nil, false
+	}
+	if !allowed {
+		respondError(w, http.StatusForbidden, "Not allowed to manage this agent", nil)
+		return nil, false
+	}
+
+	if ext.Type != "user" {
+		respondError(w, http.StatusBadRequest, "Only user extensions can be agents", nil)
+		return nil, false
+	}
+
+	return ext, true
+}
+
+// buildAgentState builds the agent state response from its queue memberships
+func buildAgentState(ext *models.Extension, queues []*models.QueueAgent, synced bool) *models.AgentState {
+	state := &models.AgentState{
+		ExtensionID:      ext.ID,
+		Extension:        ext.Extension,
+		Domain:           ext.Domain,
+		Agent:            models.AgentName(ext.Extension, ext.Domain),
+		Queues:           queues,
+		FreeSwitchSynced: synced,
+	}
+
+	if len(queues) > 0 {
+		state.State = queues[0].State
+		state.Status = queues[0].Status
+	}
+
+	return state
+}
+
+// parseDateRange parses start_date/end_date query parameters (default: last 24 hours)
+func parseDateRange(r *http.Request) (time.Time, time.Time) {
+	endDate := time.Now()
+	startDate := endDate.Add(-24 * time.Hour)
+
+	if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
+		if t, err := time.Parse(time.RFC3339, startDateStr); err == nil {
+			startDate = t
+		}
+	}
+
+	if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
+		if t, err := time.Parse(time.RFC3339, endDateStr); err == nil {
+			endDate = t
+		}
+	}
+
+	return startDate, endDate
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/middleware"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// APIKeyHandler handles API key management requests
+type APIKeyHandler struct {
+	db *database.DB
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(db *database.DB) *APIKeyHandler {
+	return &APIKeyHandler{
+		db: db,
+	}
+}
+
+// List handles GET /api/v1/api-keys
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	domainID := domainScope(r)
+	if domainID == nil {
+		if domainIDStr := r.URL.Query().Get("domain_id"); domainIDStr != "" {
+			if id, err := strconv.ParseInt(domainIDStr, 10, 64); err == nil {
+				domainID = &id
+			}
+		}
+	}
+
+	keys, err := h.db.ListAPIKeys(ctx, domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list API keys", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, keys)
+}
+
+// Get handles GET /api/v1/api-keys/{id}
+func (h *APIKeyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	key, ok := h.loadKey(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, key)
+}
+
+// Create handles POST /api/v1/api-keys
+// The plaintext key is only returned in this response.
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.APIKeyCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := validateAPIKeyCreateRequest(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	// A key can only issue keys with the same or narrower scope
+	principal := middleware.PrincipalFromContext(ctx)
+	if principal.DomainID != nil {
+		if req.DomainID != nil && *req.DomainID != *principal.DomainID {
+			respondError(w, http.StatusForbidden, "API key is not allowed to access this domain", nil)
+			return
+		}
+		req.DomainID = principal.DomainID
+	}
+	if !principal.Permissions.Covers(req.Permissions) {
+		respondError(w, http.StatusForbidden, "Cannot grant permissions beyond your own", nil)
+		return
+	}
+
+	if req.DomainID != nil {
+		if _, err := h.db.GetDomain(ctx, *req.DomainID); err != nil {
+			respondError(w, http.StatusBadRequest, "Domain not found", err)
+			return
+		}
+	}
+
+	plaintext, prefix, hash, err := middleware.GenerateAPIKey()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to generate API key", err)
+		return
+	}
+
+	key, err := h.db.CreateAPIKey(ctx, &req, hash, prefix)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create API key", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditCreate, "api-keys", strconv.FormatInt(key.ID, 10), key.DomainID, nil, key)
+
+	respondJSON(w, http.StatusCreated, &models.APIKeyIssued{APIKey: key, Key: plaintext})
+}
+
+// Rotate handles POST /api/v1/api-keys/{id}/rotate
+// The old key stops working immediately; the new plaintext key is returned once.
+func (h *APIKeyHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	key, ok := h.loadManagedKey(w, r)
+	if !ok {
+		return
+	}
+
+	plaintext, prefix, hash, err := middleware.GenerateAPIKey()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to generate API key", err)
+		return
+	}
+
+	rotated, err := h.db.RotateAPIKey(ctx, key.ID, hash, prefix)
+	if err != nil {
+		respondError(w, http.StatusConflict, "Failed to rotate API key", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditRotate, "api-keys", strconv.FormatInt(key.ID, 10), key.DomainID, key, rotated)
+
+	respondJSON(w, http.StatusOK, &models.APIKeyIssued{APIKey: rotated, Key: plaintext})
+}
+
+// Revoke handles DELETE /api/v1/api-keys/{id}
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	key, ok := h.loadManagedKey(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.RevokeAPIKey(ctx, key.ID); err != nil {
+		respondError(w, http.StatusConflict, "Failed to revoke API key", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditRevoke, "api-keys", strconv.FormatInt(key.ID, 10), key.DomainID, key, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadKey loads the key from the {id} path variable, hiding keys outside the
+// caller's domain
+func (h *APIKeyHandler) loadKey(w http.ResponseWriter, r *http.Request) (*models.APIKey, bool) {
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid API key ID", err)
+		return nil, false
+	}
+
+	key, err := h.db.GetAPIKey(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "API key not found", err)
+		return nil, false
+	}
+
+	if scope := domainScope(r); scope != nil && (key.DomainID == nil || *key.DomainID != *scope) {
+		respondError(w, http.StatusNotFound, "API key not found", nil)
+		return nil, false
+	}
+
+	return key, true
+}
+
+// loadManagedKey loads the {id} key if the caller's permissions cover it.
+// Rotating a broader key would hand its new secret to a narrower one.
+func (h *APIKeyHandler) loadManagedKey(w http.ResponseWriter, r *http.Request) (*models.APIKey, bool) {
+	key, ok := h.loadKey(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	principal := middleware.PrincipalFromContext(r.Context())
+	if !principal.Permissions.Covers(key.Permissions) {
+		respondError(w, http.StatusForbidden, "Cannot manage an API key with permissions beyond your own", nil)
+		return nil, false
+	}
+
+	return key, true
+}
+
+// validateAPIKeyCreateRequest validates an API key create request
+func validateAPIKeyCreateRequest(req *models.APIKeyCreateRequest) error {
+	if req.Name == "" || len(req.Name) > 100 {
+		return errValidation("name must be 1-100 characters")
+	}
+	if len(req.Permissions) == 0 {
+		return errValidation("permissions are required")
+	}
+
+	validResources := make(map[string]bool, len(models.APIResources))
+	for _, resource := range models.APIResources {
+		validResources[resource] = true
+	}
+
+	for resource, access := range req.Permissions {
+		if !validResources[resource] {
+			return errValidation("unknown resource in permissions: " + resource)
+		}
+		if access != models.AccessRead && access != models.AccessWrite {
+			return errValidation("access for " + resource + " must be read or write")
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return errValidation("expires_at must be in the future")
+	}
+
+	return nil
+}
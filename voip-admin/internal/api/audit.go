@@ -0,0 +1,225 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/middleware"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// auditRedacted replaces the value of secret fields in audit diffs
+const auditRedacted = "[REDACTED]"
+
+// auditSecretFields are substrings of field names whose values are never
+// written to the audit log
+var auditSecretFields = []string{"password", "pin", "secret", "token", "ha1", "hash", "key"}
+
+// auditIgnoredFields change on every update and would only add noise
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+// AuditHandler handles audit log queries
+type AuditHandler struct {
+	db *database.DB
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(db *database.DB) *AuditHandler {
+	return &AuditHandler{
+		db: db,
+	}
+}
+
+// List handles GET /api/v1/audit
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	req := &models.AuditListRequest{
+		Page:    1,
+		PerPage: 50,
+	}
+
+	if pageStr := query.Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			req.Page = p
+		}
+	}
+
+	if perPageStr := query.Get("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 1000 {
+			req.PerPage = pp
+		}
+	}
+
+	if startDateStr := query.Get("start_date"); startDateStr != "" {
+		if t, err := time.Parse(time.RFC3339, startDateStr); err == nil {
+			req.StartDate = &t
+		}
+	}
+
+	if endDateStr := query.Get("end_date"); endDateStr != "" {
+		if t, err := time.Parse(time.RFC3339, endDateStr); err == nil {
+			req.EndDate = &t
+		}
+	}
+
+	if actorType := query.Get("actor_type"); actorType != "" {
+		req.ActorType = &actorType
+	}
+
+	if actorIDStr := query.Get("actor_id"); actorIDStr != "" {
+		if id, err := strconv.ParseInt(actorIDStr, 10, 64); err == nil {
+			req.ActorID = &id
+		}
+	}
+
+	if action := query.Get("action"); action != "" {
+		req.Action = &action
+	}
+
+	if resource := query.Get("resource"); resource != "" {
+		req.Resource = &resource
+	}
+
+	if resourceID := query.Get("resource_id"); resourceID != "" {
+		req.ResourceID = &resourceID
+	}
+
+	if domainIDStr := query.Get("domain_id"); domainIDStr != "" {
+		if id, err := strconv.ParseInt(domainIDStr, 10, 64); err == nil {
+			req.DomainID = &id
+		}
+	}
+
+	if requestID := query.Get("request_id"); requestID != "" {
+		req.RequestID = &requestID
+	}
+
+	// Scoped callers only see changes to their own domain
+	if scope := domainScope(r); scope != nil {
+		req.DomainID = scope
+	}
+
+	result, err := h.db.ListAuditEntries(ctx, req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list audit entries", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// recordAudit writes an audit entry for a change made by the current caller.
+// before and after are the object before and after the change (nil for
+// create and delete respectively); only changed fields are stored and secrets
+// are redacted. Failures are logged and do not fail the request, because the
+// change itself has already been committed.
+func recordAudit(r *http.Request, db *database.DB, action, resource, resourceID string, domainID *int64, before, after interface{}) {
+	entry := &models.AuditEntry{
+		ActorType:  "anonymous",
+		Action:     action,
+		Resource:   resource,
+		ResourceID: resourceID,
+		DomainID:   domainID,
+		SourceIP:   clientIP(r),
+		RequestID:  middleware.RequestIDFromContext(r.Context()),
+	}
+
+	if principal := middleware.PrincipalFromContext(r.Context()); principal != nil {
+		entry.ActorType = principal.Type
+		entry.ActorName = principal.Name
+		switch principal.Type {
+		case middleware.PrincipalAPIKey:
+			entry.ActorID = &principal.KeyID
+		case middleware.PrincipalUser:
+			entry.ActorID = &principal.UserID
+		}
+	}
+
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		log.Printf("[Audit] Failed to diff %s %s/%s: %v", action, resource, resourceID, err)
+	}
+	entry.Changes = changes
+
+	if err := db.InsertAuditEntry(r.Context(), entry); err != nil {
+		log.Printf("[Audit] Failed to record %s %s/%s: %v", action, resource, resourceID, err)
+	}
+}
+
+// auditDiff returns {"before": {...}, "after": {...}} holding only the
+// top-level fields that differ, with secret values redacted
+func auditDiff(before, after interface{}) (json.RawMessage, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := map[string]map[string]interface{}{
+		"before": {},
+		"after":  {},
+	}
+
+	for name, value := range beforeFields {
+		if auditIgnoredFields[name] {
+			continue
+		}
+		if newValue, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, newValue) {
+			diff["before"][name] = auditValue(name, value)
+		}
+	}
+	for name, value := range afterFields {
+		if auditIgnoredFields[name] {
+			continue
+		}
+		if oldValue, ok := beforeFields[name]; !ok || !reflect.DeepEqual(value, oldValue) {
+			diff["after"][name] = auditValue(name, value)
+		}
+	}
+
+	return json.Marshal(diff)
+}
+
+// auditFields converts an object to its JSON fields. Fields hidden from JSON
+// (json:"-") never reach the audit log.
+func auditFields(v interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if v == nil {
+		return fields, nil
+	}
+	if rv := reflect.ValueOf(v); (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Map) && rv.IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// auditValue redacts the value of secret fields
+func auditValue(name string, value interface{}) interface{} {
+	lower := strings.ToLower(name)
+	for _, secret := range auditSecretFields {
+		if strings.Contains(lower, secret) {
+			return auditRedacted
+		}
+	}
+	return value
+}
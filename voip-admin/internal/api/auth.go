@@ -0,0 +1,219 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/middleware"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyPasswordHash is compared against when the user does not exist, so
+// login takes the same time for unknown users and wrong passwords
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("voip-admin-dummy-password"), bcrypt.DefaultCost)
+
+// AuthHandler handles user login and session requests
+type AuthHandler struct {
+	db         *database.DB
+	secret     []byte
+	tokenTTL   time.Duration
+	sessionTTL time.Duration
+}
+
+// NewAuthHandler creates a new auth handler. Access tokens live for tokenTTL
+// and can be refreshed until the session is sessionTTL old.
+func NewAuthHandler(db *database.DB, secret []byte, tokenTTL, sessionTTL time.Duration) *AuthHandler {
+	return &AuthHandler{
+		db:         db,
+		secret:     secret,
+		tokenTTL:   tokenTTL,
+		sessionTTL: sessionTTL,
+	}
+}
+
+// Login handles POST /api/v1/auth/login
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if len(h.secret) == 0 {
+		respondError(w, http.StatusServiceUnavailable, "User login is not configured", nil)
+		return
+	}
+
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.Domain = strings.ToLower(strings.TrimSpace(req.Domain))
+	if req.Username == "" || req.Password == "" {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("username and password are required"))
+		return
+	}
+
+	user, err := h.db.GetUserForLogin(ctx, req.Domain, req.Username)
+	if err != nil || !user.Active || user.PasswordHash == "" {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		log.Printf("[Auth] Failed login for %s@%s", req.Username, req.Domain)
+		respondError(w, http.StatusUnauthorized, "Invalid credentials", nil)
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		log.Printf("[Auth] Failed login for %s@%s", req.Username, req.Domain)
+		respondError(w, http.StatusUnauthorized, "Invalid credentials", nil)
+		return
+	}
+
+	session, err := h.db.CreateUserSession(ctx, user.ID, clientIP(r), truncate(r.UserAgent(), 255), time.Now().Add(h.sessionTTL))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create session", err)
+		return
+	}
+
+	h.issueToken(w, user, session)
+}
+
+// Refresh handles POST /api/v1/auth/refresh
+// Issues a new access token for the current session.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.db.GetUser(ctx, principal.UserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get user", err)
+		return
+	}
+
+	session, err := h.db.GetUserSession(ctx, principal.SessionID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get session", err)
+		return
+	}
+
+	h.issueToken(w, user, session)
+}
+
+// Logout handles POST /api/v1/auth/logout
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.RevokeUserSession(r.Context(), principal.SessionID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to end session", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Me handles GET /api/v1/auth/me
+// Returns the logged-in user with its extensions and supervised queues.
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.db.GetUser(ctx, principal.UserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get user", err)
+		return
+	}
+
+	extensions, err := h.db.ListUserExtensions(ctx, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get user extensions", err)
+		return
+	}
+	for _, ext := range extensions {
+		ext.SIPHA1 = ""
+		ext.SIPHA1B = ""
+		ext.VMPassword = ""
+	}
+
+	queueIDs, err := h.db.ListSupervisedQueueIDs(ctx, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get supervised queues", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user":              user,
+		"permissions":       principal.Permissions,
+		"extensions":        extensions,
+		"supervised_queues": queueIDs,
+	})
+}
+
+// issueToken signs an access token for a session and writes the login response
+func (h *AuthHandler) issueToken(w http.ResponseWriter, user *models.User, session *models.UserSession) {
+	now := time.Now()
+	expiresAt := now.Add(h.tokenTTL)
+	if session.ExpiresAt.Before(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+
+	token, err := middleware.SignToken(h.secret, &middleware.TokenClaims{
+		SessionID: session.ID,
+		UserID:    user.ID,
+		Role:      user.Role,
+		DomainID:  user.DomainID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to sign token", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, &models.LoginResponse{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: expiresAt,
+		User:      user,
+	})
+}
+
+// requireUser returns the logged-in user principal, rejecting API keys
+func (h *AuthHandler) requireUser(w http.ResponseWriter, r *http.Request) (*middleware.Principal, bool) {
+	principal := middleware.PrincipalFromContext(r.Context())
+	if principal == nil || principal.Type != middleware.PrincipalUser {
+		respondError(w, http.StatusBadRequest, "Only available to logged-in users", nil)
+		return nil, false
+	}
+	return principal, true
+}
+
+// clientIP returns the client address without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
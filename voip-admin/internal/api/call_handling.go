@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// forwardDestinationPattern matches a forward or follow-me destination:
+// an internal number or an external number with optional leading +
+var forwardDestinationPattern = regexp.MustCompile(`^\+?[0-9]{2,31}$`)
+
+// GetCallHandling handles GET /api/v1/extensions/{id}/call-handling
+func (h *ExtensionHandler) GetCallHandling(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid extension ID", err)
+		return
+	}
+
+	ext, ok := h.loadExtension(w, r, id)
+	if !ok {
+		return
+	}
+
+	h.getCallHandling(w, r, ext)
+}
+
+// UpdateCallHandling handles PUT /api/v1/extensions/{id}/call-handling
+func (h *ExtensionHandler) UpdateCallHandling(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid extension ID", err)
+		return
+	}
+
+	ext, ok := h.loadExtension(w, r, id)
+	if !ok {
+		return
+	}
+
+	h.setCallHandling(w, r, ext)
+}
+
+// GetOwnCallHandling handles GET /api/v1/auth/extensions/{id}/call-handling
+func (h *ExtensionHandler) GetOwnCallHandling(w http.ResponseWriter, r *http.Request) {
+	ext, ok := h.loadOwnExtension(w, r)
+	if !ok {
+		return
+	}
+
+	h.getCallHandling(w, r, ext)
+}
+
+// UpdateOwnCallHandling handles PUT /api/v1/auth/extensions/{id}/call-handling
+// Lets logged-in users set forwarding, DND and follow-me on their own extensions.
+func (h *ExtensionHandler) UpdateOwnCallHandling(w http.ResponseWriter, r *http.Request) {
+	ext, ok := h.loadOwnExtension(w, r)
+	if !ok {
+		return
+	}
+
+	h.setCallHandling(w, r, ext)
+}
+
+// loadOwnExtension loads the {id} extension if the caller owns it
+func (h *ExtensionHandler) loadOwnExtension(w http.ResponseWriter, r *http.Request) (*models.Extension, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid extension ID", err)
+		return nil, false
+	}
+
+	ext, err := h.db.GetExtensionByID(r.Context(), id)
+	if err != nil || !ownsExtension(r, ext) {
+		respondError(w, http.StatusNotFound, "Extension not found", err)
+		return nil, false
+	}
+
+	return ext, true
+}
+
+// getCallHandling responds with the call handling settings of an extension
+func (h *ExtensionHandler) getCallHandling(w http.ResponseWriter, r *http.Request, ext *models.Extension) {
+	ch, err := h.db.GetCallHandling(r.Context(), ext.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get call handling", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ch)
+}
+
+// setCallHandling validates and replaces the call handling settings of an
+// extension
+func (h *ExtensionHandler) setCallHandling(w http.ResponseWriter, r *http.Request, ext *models.Extension) {
+	ctx := r.Context()
+
+	var req models.CallHandlingUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if ext.Type != "user" {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("call handling is only available for user extensions"))
+		return
+	}
+	if err := validateCallHandlingUpdate(&req, ext.Extension); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	current, err := h.db.GetCallHandling(ctx, ext.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get call handling", err)
+		return
+	}
+
+	ch, err := h.db.SetCallHandling(ctx, ext.ID, &req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update call handling", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "extensions", strconv.FormatInt(ext.ID, 10), &ext.DomainID, current, ch)
+
+	respondJSON(w, http.StatusOK, ch)
+}
+
+// validateCallHandlingUpdate validates the destinations of a call handling
+// update. An extension cannot forward to or follow itself.
+func validateCallHandlingUpdate(req *models.CallHandlingUpdate, extension string) error {
+	forwards := []struct {
+		field string
+		value string
+	}{
+		{"forward_always", req.ForwardAlways},
+		{"forward_busy", req.ForwardBusy},
+		{"forward_no_answer", req.ForwardNoAnswer},
+	}
+	for _, f := range forwards {
+		if f.value == "" {
+			continue
+		}
+		if err := validateForwardDestination(f.value, extension); err != nil {
+			return errValidation(f.field + ": " + err.Error())
+		}
+	}
+
+	if len(req.FollowMe) > models.MaxFollowMeNumbers {
+		return errValidation(fmt.Sprintf("follow_me can have at most %d numbers", models.MaxFollowMeNumbers))
+	}
+	seen := make(map[string]bool, len(req.FollowMe))
+	for i, number := range req.FollowMe {
+		if err := validateForwardDestination(number, extension); err != nil {
+			return errValidation(fmt.Sprintf("follow_me %d: %s", i+1, err.Error()))
+		}
+		if seen[number] {
+			return errValidation(fmt.Sprintf("follow_me %d: %s is listed twice", i+1, number))
+		}
+		seen[number] = true
+	}
+
+	return nil
+}
+
+// validateForwardDestination validates a forward or follow-me destination
+func validateForwardDestination(number, extension string) error {
+	if !forwardDestinationPattern.MatchString(number) {
+		return fmt.Errorf("must be 2-31 digits with optional leading +")
+	}
+	if number == extension {
+		return fmt.Errorf("cannot be the extension itself")
+	}
+	return nil
+}
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"encoding/json"
 	"io"
 	"net/http"
 	"strconv"
@@ -62,62 +61,16 @@ func (h *CDRHandler) Ingest(w http.ResponseWriter, r *http.Request) {
 func (h *CDRHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Parse query parameters
-	req := &models.CDRListRequest{
-		Page:    1,
-		PerPage: 50,
-	}
-
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			req.Page = p
-		}
-	}
-
-	if perPageStr := r.URL.Query().Get("per_page"); perPageStr != "" {
-		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 1000 {
-			req.PerPage = pp
-		}
-	}
-
-	if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
-		if t, err := time.Parse(time.RFC3339, startDateStr); err == nil {
-			req.StartDate = &t
-		}
-	}
-
-	if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
-		if t, err := time.Parse(time.RFC3339, endDateStr); err == nil {
-			req.EndDate = &t
-		}
-	}
-
-	if callerID := r.URL.Query().Get("caller_id"); callerID != "" {
-		req.CallerIDNumber = &callerID
-	}
+	req := parseCDRListRequest(r)
 
-	if destNumber := r.URL.Query().Get("destination_number"); destNumber != "" {
-		req.DestNumber = &destNumber
-	}
-
-	if direction := r.URL.Query().Get("direction"); direction != "" {
-		req.Direction = &direction
-	}
-
-	if hangupCause := r.URL.Query().Get("hangup_cause"); hangupCause != "" {
-		req.HangupCause = &hangupCause
+	// Scoped callers only ever see their own domain
+	scoped, err := scopedDomainName(r, h.db)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to resolve domain scope", err)
+		return
 	}
-
-	if queueIDStr := r.URL.Query().Get("queue_id"); queueIDStr != "" {
-		if id, err := strconv.ParseInt(queueIDStr, 10, 64); err == nil {
-			req.QueueID = &id
-		}
-	}
-
-	if minDurStr := r.URL.Query().Get("min_duration"); minDurStr != "" {
-		if dur, err := strconv.Atoi(minDurStr); err == nil {
-			req.MinDuration = &dur
-		}
+	if scoped != nil {
+		req.Domain = scoped
 	}
 
 	// Query database
@@ -127,6 +80,13 @@ func (h *CDRHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Carrier costs are not shown to tenants
+	if scoped != nil {
+		for _, cdr := range result.CDRs {
+			cdr.BuyCost = nil
+		}
+	}
+
 	respondJSON(w, http.StatusOK, result)
 }
 
@@ -146,6 +106,19 @@ func (h *CDRHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	scoped, err := scopedDomainName(r, h.db)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to resolve domain scope", err)
+		return
+	}
+	if scoped != nil && *scoped != cdr.Domain {
+		respondError(w, http.StatusNotFound, "CDR not found", nil)
+		return
+	}
+	if scoped != nil {
+		cdr.BuyCost = nil
+	}
+
 	respondJSON(w, http.StatusOK, cdr)
 }
 
@@ -169,7 +142,21 @@ func (h *CDRHandler) Stats(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	stats, err := h.db.GetCDRStats(ctx, startDate, endDate)
+	var domain *string
+	if domainStr := r.URL.Query().Get("domain"); domainStr != "" {
+		domain = &domainStr
+	}
+
+	scoped, err := scopedDomainName(r, h.db)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to resolve domain scope", err)
+		return
+	}
+	if scoped != nil {
+		domain = scoped
+	}
+
+	stats, err := h.db.GetCDRStats(ctx, startDate, endDate, domain)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to get CDR stats", err)
 		return
@@ -177,3 +164,72 @@ func (h *CDRHandler) Stats(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, stats)
 }
+
+// parseCDRListRequest parses the paging and call filters of a CDR search
+// from the query string; invalid values are ignored
+func parseCDRListRequest(r *http.Request) *models.CDRListRequest {
+	query := r.URL.Query()
+
+	req := &models.CDRListRequest{
+		Page:    1,
+		PerPage: 50,
+	}
+
+	if pageStr := query.Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			req.Page = p
+		}
+	}
+
+	if perPageStr := query.Get("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 1000 {
+			req.PerPage = pp
+		}
+	}
+
+	if startDateStr := query.Get("start_date"); startDateStr != "" {
+		if t, err := time.Parse(time.RFC3339, startDateStr); err == nil {
+			req.StartDate = &t
+		}
+	}
+
+	if endDateStr := query.Get("end_date"); endDateStr != "" {
+		if t, err := time.Parse(time.RFC3339, endDateStr); err == nil {
+			req.EndDate = &t
+		}
+	}
+
+	if callerID := query.Get("caller_id"); callerID != "" {
+		req.CallerIDNumber = &callerID
+	}
+
+	if destNumber := query.Get("destination_number"); destNumber != "" {
+		req.DestNumber = &destNumber
+	}
+
+	if direction := query.Get("direction"); direction != "" {
+		req.Direction = &direction
+	}
+
+	if hangupCause := query.Get("hangup_cause"); hangupCause != "" {
+		req.HangupCause = &hangupCause
+	}
+
+	if queueIDStr := query.Get("queue_id"); queueIDStr != "" {
+		if id, err := strconv.ParseInt(queueIDStr, 10, 64); err == nil {
+			req.QueueID = &id
+		}
+	}
+
+	if minDurStr := query.Get("min_duration"); minDurStr != "" {
+		if dur, err := strconv.Atoi(minDurStr); err == nil {
+			req.MinDuration = &dur
+		}
+	}
+
+	if domain := query.Get("domain"); domain != "" {
+		req.Domain = &domain
+	}
+
+	return req
+}
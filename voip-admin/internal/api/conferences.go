@@ -0,0 +1,411 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/esl"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// conferencePINPattern matches a conference PIN
+var conferencePINPattern = regexp.MustCompile(`^[0-9]{3,12}$`)
+
+// maxConferenceMembers bounds max_members
+const maxConferenceMembers = 1000
+
+// ConferenceHandler handles conference room HTTP requests. Rooms are stored
+// in the database; running conferences are controlled over the Event
+// Socket.
+type ConferenceHandler struct {
+	db  *database.DB
+	esl *esl.Client
+}
+
+// NewConferenceHandler creates a new conference handler
+func NewConferenceHandler(db *database.DB, eslClient *esl.Client) *ConferenceHandler {
+	return &ConferenceHandler{
+		db:  db,
+		esl: eslClient,
+	}
+}
+
+// List handles GET /api/v1/conferences
+func (h *ConferenceHandler) List(w http.ResponseWriter, r *http.Request) {
+	var domainID *int64
+	if domainIDStr := r.URL.Query().Get("domain_id"); domainIDStr != "" {
+		id, err := strconv.ParseInt(domainIDStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+			return
+		}
+		domainID = &id
+	}
+
+	// Scoped callers only see their own domain
+	if scope := domainScope(r); scope != nil {
+		if domainID != nil && *domainID != *scope {
+			respondJSON(w, http.StatusOK, []*models.ConferenceRoom{})
+			return
+		}
+		domainID = scope
+	}
+
+	rooms, err := h.db.ListConferenceRooms(r.Context(), domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list conference rooms", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rooms)
+}
+
+// Get handles GET /api/v1/conferences/{id}
+func (h *ConferenceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	room, ok := h.loadRoom(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, room)
+}
+
+// Create handles POST /api/v1/conferences
+func (h *ConferenceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.ConferenceRoomCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.DomainID == 0 {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("domain_id is required"))
+		return
+	}
+	if _, err := h.db.GetDomain(ctx, req.DomainID); err != nil || !canAccessDomain(r, req.DomainID) {
+		respondError(w, http.StatusBadRequest, "Domain not found", err)
+		return
+	}
+
+	if req.Extension == "" {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("extension is required"))
+		return
+	}
+	if err := checkNumberingPlan(ctx, h.db, req.DomainID, req.Extension, models.NumberTypeConference); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	if req.Profile == "" {
+		req.Profile = "default"
+	}
+
+	room := &models.ConferenceRoom{
+		DomainID:         req.DomainID,
+		Extension:        req.Extension,
+		Name:             req.Name,
+		ParticipantPIN:   req.ParticipantPIN,
+		ModeratorPIN:     req.ModeratorPIN,
+		MaxMembers:       req.MaxMembers,
+		Record:           req.Record,
+		Profile:          req.Profile,
+		MuteOnJoin:       req.MuteOnJoin,
+		WaitForModerator: req.WaitForModerator,
+		Description:      req.Description,
+		Active:           req.Active,
+	}
+	if err := validateConferenceRoom(room); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	room, err := h.db.CreateConferenceRoom(ctx, room)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create conference room", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditCreate, "conferences", strconv.FormatInt(room.ID, 10), &room.DomainID, nil, room)
+
+	respondJSON(w, http.StatusCreated, room)
+}
+
+// Update handles PUT /api/v1/conferences/{id}
+// The number of a room cannot change. New PINs apply to callers joining
+// from then on.
+func (h *ConferenceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadRoom(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ConferenceRoomUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	candidate := *current
+	if req.Name != nil {
+		candidate.Name = *req.Name
+	}
+	if req.ParticipantPIN != nil {
+		candidate.ParticipantPIN = *req.ParticipantPIN
+	}
+	if req.ModeratorPIN != nil {
+		candidate.ModeratorPIN = *req.ModeratorPIN
+	}
+	if req.MaxMembers != nil {
+		candidate.MaxMembers = *req.MaxMembers
+	}
+	if req.Record != nil {
+		candidate.Record = *req.Record
+	}
+	if req.Profile != nil {
+		candidate.Profile = *req.Profile
+	}
+	if req.MuteOnJoin != nil {
+		candidate.MuteOnJoin = *req.MuteOnJoin
+	}
+	if req.WaitForModerator != nil {
+		candidate.WaitForModerator = *req.WaitForModerator
+	}
+	if req.Description != nil {
+		candidate.Description = *req.Description
+	}
+	if req.Active != nil {
+		candidate.Active = *req.Active
+	}
+
+	if err := validateConferenceRoom(&candidate); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	room, err := h.db.UpdateConferenceRoom(r.Context(), &candidate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update conference room", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "conferences", strconv.FormatInt(room.ID, 10), &room.DomainID, current, room)
+
+	respondJSON(w, http.StatusOK, room)
+}
+
+// Delete handles DELETE /api/v1/conferences/{id}
+// A running conference of the room keeps running until its members leave.
+func (h *ConferenceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadRoom(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteConferenceRoom(r.Context(), current.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete conference room", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "conferences", strconv.FormatInt(current.ID, 10), &current.DomainID, current, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Live handles GET /api/v1/conferences/{id}/live
+// Returns the running conference of the room and its members.
+func (h *ConferenceHandler) Live(w http.ResponseWriter, r *http.Request) {
+	room, ok := h.loadRoom(w, r)
+	if !ok {
+		return
+	}
+
+	name := models.ConferenceName(room.Extension, room.Domain)
+	conf, err := h.esl.GetConference(r.Context(), name)
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to get conference from FreeSWITCH", err)
+		return
+	}
+
+	live := &models.ConferenceLive{
+		RoomID:  room.ID,
+		Name:    name,
+		Members: []*models.ConferenceMember{},
+	}
+	if conf != nil {
+		live.Running = true
+		live.Locked = conf.Locked
+		live.Recording = conf.Recording
+		live.RunTime = conf.RunTime
+
+		for _, m := range conf.Members {
+			// Recording nodes are not callers
+			if m.Type != "" && m.Type != "caller" {
+				continue
+			}
+			live.Members = append(live.Members, &models.ConferenceMember{
+				ID:             m.ID,
+				UUID:           m.UUID,
+				CallerIDName:   m.CallerIDName,
+				CallerIDNumber: m.CallerIDNumber,
+				Moderator:      m.Flags.IsModerator,
+				Muted:          !m.Flags.CanSpeak,
+				Talking:        m.Flags.Talking,
+				JoinTime:       m.JoinTime,
+			})
+		}
+	}
+
+	respondJSON(w, http.StatusOK, live)
+}
+
+// Lock handles PUT /api/v1/conferences/{id}/lock
+func (h *ConferenceHandler) Lock(w http.ResponseWriter, r *http.Request) {
+	room, ok := h.loadRoom(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ConferenceLockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	name, ok := h.runningConference(w, r, room)
+	if !ok {
+		return
+	}
+
+	if err := h.esl.LockConference(r.Context(), name, req.Locked); err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to lock conference", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "conferences", strconv.FormatInt(room.ID, 10), &room.DomainID, nil, &req)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MemberAction handles POST /api/v1/conferences/{id}/members/{member_id}/{action}
+// Actions are mute, unmute and kick.
+func (h *ConferenceHandler) MemberAction(w http.ResponseWriter, r *http.Request) {
+	room, ok := h.loadRoom(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	memberID, err := strconv.Atoi(vars["member_id"])
+	if err != nil || memberID <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid member ID", err)
+		return
+	}
+
+	action := vars["action"]
+	switch action {
+	case models.ConferenceActionMute, models.ConferenceActionUnmute, models.ConferenceActionKick:
+	default:
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("action must be one of: mute, unmute, kick"))
+		return
+	}
+
+	name, ok := h.runningConference(w, r, room)
+	if !ok {
+		return
+	}
+
+	if err := h.esl.ConferenceMemberAction(r.Context(), name, action, memberID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondError(w, http.StatusNotFound, "Conference member not found", err)
+			return
+		}
+		respondError(w, http.StatusBadGateway, fmt.Sprintf("Failed to %s conference member", action), err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "conferences", strconv.FormatInt(room.ID, 10), &room.DomainID, nil,
+		map[string]interface{}{"action": action, "member_id": memberID})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadRoom loads the {id} conference room, hiding rooms outside the
+// caller's scope
+func (h *ConferenceHandler) loadRoom(w http.ResponseWriter, r *http.Request) (*models.ConferenceRoom, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid conference room ID", err)
+		return nil, false
+	}
+
+	room, err := h.db.GetConferenceRoom(r.Context(), id)
+	if err != nil || !canAccessDomain(r, room.DomainID) {
+		respondError(w, http.StatusNotFound, "Conference room not found", err)
+		return nil, false
+	}
+
+	return room, true
+}
+
+// runningConference returns the mod_conference name of a room, responding
+// 409 if it is not running
+func (h *ConferenceHandler) runningConference(w http.ResponseWriter, r *http.Request, room *models.ConferenceRoom) (string, bool) {
+	name := models.ConferenceName(room.Extension, room.Domain)
+
+	conf, err := h.esl.GetConference(r.Context(), name)
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to get conference from FreeSWITCH", err)
+		return "", false
+	}
+	if conf == nil {
+		respondError(w, http.StatusConflict, "Conference is not running", nil)
+		return "", false
+	}
+
+	return name, true
+}
+
+// validateConferenceRoom validates a new or updated conference room
+func validateConferenceRoom(room *models.ConferenceRoom) error {
+	if strings.TrimSpace(room.Name) == "" {
+		return errValidation("name is required")
+	}
+	if len(room.Name) > 100 {
+		return errValidation("name must be at most 100 characters")
+	}
+	if len(room.Description) > 255 {
+		return errValidation("description must be at most 255 characters")
+	}
+
+	if room.ParticipantPIN != "" && !conferencePINPattern.MatchString(room.ParticipantPIN) {
+		return errValidation("participant_pin must be 3-12 digits")
+	}
+	if room.ModeratorPIN != "" && !conferencePINPattern.MatchString(room.ModeratorPIN) {
+		return errValidation("moderator_pin must be 3-12 digits")
+	}
+	if room.ParticipantPIN != "" && room.ParticipantPIN == room.ModeratorPIN {
+		return errValidation("participant_pin and moderator_pin must differ")
+	}
+	if room.WaitForModerator && room.ModeratorPIN == "" {
+		return errValidation("wait_for_moderator requires a moderator_pin")
+	}
+
+	if room.MaxMembers < 0 || room.MaxMembers > maxConferenceMembers {
+		return errValidation(fmt.Sprintf("max_members must be between 0 (unlimited) and %d", maxConferenceMembers))
+	}
+
+	for _, p := range models.ConferenceProfiles {
+		if room.Profile == p {
+			return nil
+		}
+	}
+	return errValidation("profile must be one of: " + strings.Join(models.ConferenceProfiles, ", "))
+}
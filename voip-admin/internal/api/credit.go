@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// CreditHandler handles spending limit HTTP requests. Limits are enforced
+// by the dialplan on outbound calls; alerts are raised by the credit
+// monitor worker.
+type CreditHandler struct {
+	db *database.DB
+}
+
+// NewCreditHandler creates a new credit handler
+func NewCreditHandler(db *database.DB) *CreditHandler {
+	return &CreditHandler{
+		db: db,
+	}
+}
+
+// GetDomain handles GET /api/v1/domains/{id}/credit-limit
+func (h *CreditHandler) GetDomain(w http.ResponseWriter, r *http.Request) {
+	domainID, ok := h.loadDomainID(w, r)
+	if !ok {
+		return
+	}
+
+	h.getLimit(w, r, domainID, nil)
+}
+
+// UpdateDomain handles PUT /api/v1/domains/{id}/credit-limit
+// Tenants cannot change the limits of their own domain.
+func (h *CreditHandler) UpdateDomain(w http.ResponseWriter, r *http.Request) {
+	domainID, ok := h.loadDomainID(w, r)
+	if !ok {
+		return
+	}
+
+	if domainScope(r) != nil {
+		respondError(w, http.StatusForbidden, "Domain limits can only be set by unscoped callers", nil)
+		return
+	}
+
+	h.setLimit(w, r, "domains", domainID, domainID, nil)
+}
+
+// GetExtension handles GET /api/v1/extensions/{id}/credit-limit
+func (h *CreditHandler) GetExtension(w http.ResponseWriter, r *http.Request) {
+	ext, ok := h.loadExtension(w, r)
+	if !ok {
+		return
+	}
+
+	h.getLimit(w, r, ext.DomainID, &ext.ID)
+}
+
+// UpdateExtension handles PUT /api/v1/extensions/{id}/credit-limit
+// The domain limit still applies on top of the extension limit.
+func (h *CreditHandler) UpdateExtension(w http.ResponseWriter, r *http.Request) {
+	ext, ok := h.loadExtension(w, r)
+	if !ok {
+		return
+	}
+
+	h.setLimit(w, r, "extensions", ext.ID, ext.DomainID, &ext.ID)
+}
+
+// Alerts handles GET /api/v1/domains/{id}/credit-alerts
+func (h *CreditHandler) Alerts(w http.ResponseWriter, r *http.Request) {
+	domainID, ok := h.loadDomainID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page, perPage := 1, 50
+
+	if pageStr := query.Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if perPageStr := query.Get("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 1000 {
+			perPage = pp
+		}
+	}
+
+	result, err := h.db.ListCreditAlerts(r.Context(), domainID, page, perPage)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list credit alerts", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// getLimit responds with the limits of a domain or extension and the
+// spend of the current month
+func (h *CreditHandler) getLimit(w http.ResponseWriter, r *http.Request, domainID int64, extensionID *int64) {
+	limit, err := h.db.GetCreditLimit(r.Context(), domainID, extensionID, currentMonth())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get credit limit", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, limit)
+}
+
+// setLimit validates and replaces the limits of a domain or extension
+func (h *CreditHandler) setLimit(w http.ResponseWriter, r *http.Request, resource string, resourceID, domainID int64, extensionID *int64) {
+	ctx := r.Context()
+
+	var req models.CreditLimitUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.MonthlyLimit != nil && *req.MonthlyLimit < 0 {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("monthly_limit cannot be negative"))
+		return
+	}
+	if req.MaxOutboundCalls != nil && *req.MaxOutboundCalls < 0 {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("max_outbound_calls cannot be negative"))
+		return
+	}
+
+	month := currentMonth()
+	current, err := h.db.GetCreditLimit(ctx, domainID, extensionID, month)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get credit limit", err)
+		return
+	}
+
+	if err := h.db.SetCreditLimit(ctx, domainID, extensionID, &req); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update credit limit", err)
+		return
+	}
+
+	limit, err := h.db.GetCreditLimit(ctx, domainID, extensionID, month)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get credit limit", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, resource, strconv.FormatInt(resourceID, 10), &domainID, current, limit)
+
+	respondJSON(w, http.StatusOK, limit)
+}
+
+// loadDomainID parses the {id} domain and checks the caller may access it
+func (h *CreditHandler) loadDomainID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+		return 0, false
+	}
+
+	if _, err := h.db.GetDomain(r.Context(), id); err != nil || !canAccessDomain(r, id) {
+		respondError(w, http.StatusNotFound, "Domain not found", err)
+		return 0, false
+	}
+
+	return id, true
+}
+
+// loadExtension loads the {id} extension if the caller may access it
+func (h *CreditHandler) loadExtension(w http.ResponseWriter, r *http.Request) (*models.Extension, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid extension ID", err)
+		return nil, false
+	}
+
+	ext, err := h.db.GetExtensionByID(r.Context(), id)
+	if err != nil || !canAccessDomain(r, ext.DomainID) {
+		respondError(w, http.StatusNotFound, "Extension not found", err)
+		return nil, false
+	}
+
+	return ext, true
+}
+
+// currentMonth returns the start of the current UTC month, the period
+// spending limits apply to
+func currentMonth() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
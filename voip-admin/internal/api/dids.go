@@ -0,0 +1,269 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+var (
+	// didNumberPattern matches an external number, with optional leading +
+	didNumberPattern = regexp.MustCompile(`^\+?[0-9]{3,20}$`)
+
+	// callerIDPrefixPattern matches a caller ID name prefix. Spaces are not
+	// allowed; the dialplan separates prefix and name with one.
+	callerIDPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9._:#*+@\[\]-]{1,20}$`)
+)
+
+// DIDHandler handles DID (inbound number) HTTP requests
+type DIDHandler struct {
+	db *database.DB
+}
+
+// NewDIDHandler creates a new DID handler
+func NewDIDHandler(db *database.DB) *DIDHandler {
+	return &DIDHandler{
+		db: db,
+	}
+}
+
+// List handles GET /api/v1/dids
+func (h *DIDHandler) List(w http.ResponseWriter, r *http.Request) {
+	var domainID *int64
+	if domainIDStr := r.URL.Query().Get("domain_id"); domainIDStr != "" {
+		id, err := strconv.ParseInt(domainIDStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+			return
+		}
+		domainID = &id
+	}
+
+	// Scoped callers only see their own domain
+	if scope := domainScope(r); scope != nil {
+		if domainID != nil && *domainID != *scope {
+			respondJSON(w, http.StatusOK, []*models.DID{})
+			return
+		}
+		domainID = scope
+	}
+
+	dids, err := h.db.ListDIDs(r.Context(), domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list DIDs", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dids)
+}
+
+// Get handles GET /api/v1/dids/{id}
+func (h *DIDHandler) Get(w http.ResponseWriter, r *http.Request) {
+	did, ok := h.loadDID(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, did)
+}
+
+// Create handles POST /api/v1/dids
+func (h *DIDHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.DIDCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.DomainID == 0 {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("domain_id is required"))
+		return
+	}
+	if _, err := h.db.GetDomain(ctx, req.DomainID); err != nil || !canAccessDomain(r, req.DomainID) {
+		respondError(w, http.StatusBadRequest, "Domain not found", err)
+		return
+	}
+
+	if !didNumberPattern.MatchString(req.Number) {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("number must be 3-20 digits with optional leading +"))
+		return
+	}
+	if existing, err := h.db.GetDIDByNumber(ctx, req.Number); err == nil {
+		// Do not reveal the domain of a DID owned by another tenant
+		msg := "DID already exists"
+		if canAccessDomain(r, existing.DomainID) {
+			msg = fmt.Sprintf("DID already exists (id %d)", existing.ID)
+		}
+		respondError(w, http.StatusConflict, msg, nil)
+		return
+	}
+
+	candidate := &models.DID{
+		DomainID:           req.DomainID,
+		Number:             req.Number,
+		DestinationType:    req.DestinationType,
+		Destination:        req.Destination,
+		TimeConditionID:    req.TimeConditionID,
+		CallerIDNamePrefix: req.CallerIDNamePrefix,
+		Description:        req.Description,
+		Active:             req.Active,
+	}
+	if err := h.validateDID(r, candidate); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	did, err := h.db.CreateDID(ctx, &req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create DID", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditCreate, "dids", strconv.FormatInt(did.ID, 10), &did.DomainID, nil, did)
+
+	respondJSON(w, http.StatusCreated, did)
+}
+
+// Update handles PUT /api/v1/dids/{id}
+func (h *DIDHandler) Update(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadDID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.DIDUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	candidate := *current
+	if req.DestinationType != nil && *req.DestinationType != current.DestinationType {
+		// The old destination does not apply to the new type
+		candidate.DestinationType = *req.DestinationType
+		candidate.Destination = ""
+		candidate.TimeConditionID = nil
+	}
+	if req.Destination != nil {
+		candidate.Destination = *req.Destination
+	}
+	if req.TimeConditionID != nil {
+		candidate.TimeConditionID = req.TimeConditionID
+	}
+	if req.CallerIDNamePrefix != nil {
+		candidate.CallerIDNamePrefix = *req.CallerIDNamePrefix
+	}
+	if req.Description != nil {
+		candidate.Description = *req.Description
+	}
+	if req.Active != nil {
+		candidate.Active = *req.Active
+	}
+
+	if err := h.validateDID(r, &candidate); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	did, err := h.db.UpdateDID(r.Context(), &candidate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update DID", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "dids", strconv.FormatInt(did.ID, 10), &did.DomainID, current, did)
+
+	respondJSON(w, http.StatusOK, did)
+}
+
+// Delete handles DELETE /api/v1/dids/{id}
+func (h *DIDHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadDID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteDID(r.Context(), current.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete DID", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "dids", strconv.FormatInt(current.ID, 10), &current.DomainID, current, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadDID loads the {id} DID, hiding DIDs outside the caller's scope
+func (h *DIDHandler) loadDID(w http.ResponseWriter, r *http.Request) (*models.DID, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid DID ID", err)
+		return nil, false
+	}
+
+	did, err := h.db.GetDID(r.Context(), id)
+	if err != nil || !canAccessDomain(r, did.DomainID) {
+		respondError(w, http.StatusNotFound, "DID not found", err)
+		return nil, false
+	}
+
+	return did, true
+}
+
+// validateDID validates a new or updated DID and checks its destination
+// exists in the DID's domain
+func (h *DIDHandler) validateDID(r *http.Request, did *models.DID) error {
+	ctx := r.Context()
+
+	if did.CallerIDNamePrefix != "" && !callerIDPrefixPattern.MatchString(did.CallerIDNamePrefix) {
+		return errValidation("caller_id_name_prefix must be 1-20 letters, digits or ._:#*+@[]- without spaces")
+	}
+	if len(did.Description) > 255 {
+		return errValidation("description must be at most 255 characters")
+	}
+
+	switch did.DestinationType {
+	case models.NumberTypeUser, models.NumberTypeQueue, models.NumberTypeIVR:
+		if did.Destination == "" {
+			return errValidation("destination is required")
+		}
+		if did.TimeConditionID != nil {
+			return errValidation("time_condition_id is only valid for time_condition destinations")
+		}
+		inUse, err := h.db.FindNumberInUse(ctx, did.DomainID, did.Destination)
+		if err != nil {
+			return err
+		}
+		if inUse == nil || inUse.Kind != did.DestinationType {
+			return errValidation(fmt.Sprintf("destination %s is not a %s in the domain", did.Destination, did.DestinationType))
+		}
+
+	case models.DIDDestinationTimeCondition:
+		if did.TimeConditionID == nil {
+			return errValidation("time_condition_id is required")
+		}
+		if did.Destination != "" {
+			return errValidation("destination is not valid for time_condition destinations")
+		}
+		tc, err := h.db.GetTimeCondition(ctx, *did.TimeConditionID)
+		if err != nil || tc.DomainID != did.DomainID {
+			return errValidation("time condition not found in the domain")
+		}
+		if len(tc.Destinations) == 0 {
+			return errValidation(fmt.Sprintf("time condition %q has no destinations to route to while open", tc.Name))
+		}
+
+	default:
+		return errValidation("destination_type must be one of: user, queue, ivr, time_condition")
+	}
+
+	return nil
+}
@@ -0,0 +1,384 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/kamailio"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// dispatcherDestinationPattern matches a destination SIP URI, e.g.
+// sip:172.16.91.101:5080 or sip:fs1.example.com:5080;transport=tcp
+var dispatcherDestinationPattern = regexp.MustCompile(`^sips?:[A-Za-z0-9.-]+(:[0-9]{1,5})?(;[a-z]+=[A-Za-z0-9.-]+)*$`)
+
+// dispatcherAttrsPattern matches destination attributes other than weight,
+// e.g. duid=fs1;maxload=400
+var dispatcherAttrsPattern = regexp.MustCompile(`^[a-z_]+=[^;=\s]*(;[a-z_]+=[^;=\s]*)*$`)
+
+// DispatcherHandler handles Kamailio dispatcher HTTP requests. Destinations
+// are stored in kamailio.dispatcher and applied to every Kamailio node over
+// JSON-RPC. They are shared by all domains, so domain-scoped API keys
+// cannot manage them.
+type DispatcherHandler struct {
+	db       *database.DB
+	kamailio *kamailio.Client
+}
+
+// NewDispatcherHandler creates a new dispatcher handler
+func NewDispatcherHandler(db *database.DB, kamailioClient *kamailio.Client) *DispatcherHandler {
+	return &DispatcherHandler{
+		db:       db,
+		kamailio: kamailioClient,
+	}
+}
+
+// List handles GET /api/v1/dispatchers
+// Each destination includes its live state on every Kamailio node.
+func (h *DispatcherHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !h.unscoped(w, r) {
+		return
+	}
+
+	dispatchers, err := h.db.ListDispatchers(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list dispatcher destinations", err)
+		return
+	}
+
+	h.addState(r, dispatchers)
+
+	respondJSON(w, http.StatusOK, dispatchers)
+}
+
+// Get handles GET /api/v1/dispatchers/{id}
+func (h *DispatcherHandler) Get(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.loadDispatcher(w, r)
+	if !ok {
+		return
+	}
+
+	h.addState(r, []*models.Dispatcher{d})
+
+	respondJSON(w, http.StatusOK, d)
+}
+
+// Create handles POST /api/v1/dispatchers
+func (h *DispatcherHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.unscoped(w, r) {
+		return
+	}
+
+	var req models.DispatcherCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.SetID == 0 {
+		req.SetID = models.DefaultDispatcherSet
+	}
+
+	d := &models.Dispatcher{
+		SetID:       req.SetID,
+		Destination: req.Destination,
+		Priority:    req.Priority,
+		Weight:      req.Weight,
+		Attrs:       req.Attrs,
+		Description: req.Description,
+	}
+	if err := validateDispatcher(d); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	if _, err := h.db.GetDispatcherByDestination(ctx, d.SetID, d.Destination); err == nil {
+		respondError(w, http.StatusConflict, "Dispatcher destination already exists", nil)
+		return
+	}
+
+	d, err := h.db.CreateDispatcher(ctx, d)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create dispatcher destination", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditCreate, "dispatchers", strconv.FormatInt(d.ID, 10), nil, nil, d)
+
+	if err := h.kamailio.ReloadDispatcher(ctx); err != nil {
+		respondError(w, http.StatusBadGateway, "Destination saved, but Kamailio failed to reload the dispatcher", err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, d)
+}
+
+// Update handles PUT /api/v1/dispatchers/{id}
+// Used to change the weight or priority of a destination.
+func (h *DispatcherHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	current, ok := h.loadDispatcher(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.DispatcherUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	candidate := *current
+	if req.Priority != nil {
+		candidate.Priority = *req.Priority
+	}
+	if req.Weight != nil {
+		candidate.Weight = *req.Weight
+	}
+	if req.Attrs != nil {
+		candidate.Attrs = *req.Attrs
+	}
+	if req.Description != nil {
+		candidate.Description = *req.Description
+	}
+
+	if err := validateDispatcher(&candidate); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	d, err := h.db.UpdateDispatcher(ctx, &candidate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update dispatcher destination", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "dispatchers", strconv.FormatInt(d.ID, 10), nil, current, d)
+
+	if err := h.kamailio.ReloadDispatcher(ctx); err != nil {
+		respondError(w, http.StatusBadGateway, "Destination saved, but Kamailio failed to reload the dispatcher", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, d)
+}
+
+// Delete handles DELETE /api/v1/dispatchers/{id}
+// The last active destination of a set is only removed with ?force=true.
+func (h *DispatcherHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	current, ok := h.loadDispatcher(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkRemaining(w, r, current) {
+		return
+	}
+
+	if err := h.db.DeleteDispatcher(ctx, current.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete dispatcher destination", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "dispatchers", strconv.FormatInt(current.ID, 10), nil, current, nil)
+
+	if err := h.kamailio.ReloadDispatcher(ctx); err != nil {
+		respondError(w, http.StatusBadGateway, "Destination removed, but Kamailio failed to reload the dispatcher", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Drain handles POST /api/v1/dispatchers/{id}/drain
+// The destination is disabled on every node: it gets no new calls and is
+// no longer probed, while calls in progress continue. The state is stored
+// so it survives dispatcher reloads. The last active destination of a set
+// is only drained with ?force=true.
+func (h *DispatcherHandler) Drain(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadDispatcher(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkRemaining(w, r, current) {
+		return
+	}
+
+	h.setState(w, r, current, current.Flags|models.DispatcherFlagDisabled, kamailio.DispatcherDisabled)
+}
+
+// Activate handles POST /api/v1/dispatchers/{id}/activate
+// Returns a drained or inactive destination to service.
+func (h *DispatcherHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadDispatcher(w, r)
+	if !ok {
+		return
+	}
+
+	flags := current.Flags &^ (models.DispatcherFlagDisabled | models.DispatcherFlagInactive)
+	h.setState(w, r, current, flags, kamailio.DispatcherActive)
+}
+
+// Reload handles POST /api/v1/dispatchers/reload
+// Every Kamailio node re-reads kamailio.dispatcher.
+func (h *DispatcherHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if !h.unscoped(w, r) {
+		return
+	}
+
+	if err := h.kamailio.ReloadDispatcher(r.Context()); err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to reload the dispatcher on Kamailio", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "dispatchers", "reload", nil, nil,
+		map[string]interface{}{"nodes": h.kamailio.Nodes()})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setState stores the flags of a destination and applies the state to
+// every Kamailio node
+func (h *DispatcherHandler) setState(w http.ResponseWriter, r *http.Request, current *models.Dispatcher, flags int, state string) {
+	ctx := r.Context()
+
+	candidate := *current
+	candidate.Flags = flags
+
+	d, err := h.db.UpdateDispatcher(ctx, &candidate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update dispatcher destination", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "dispatchers", strconv.FormatInt(d.ID, 10), nil, current, d)
+
+	if err := h.kamailio.SetDispatcherState(ctx, state, d.SetID, d.Destination); err != nil {
+		respondError(w, http.StatusBadGateway, "State saved, but Kamailio failed to apply it", err)
+		return
+	}
+
+	h.addState(r, []*models.Dispatcher{d})
+
+	respondJSON(w, http.StatusOK, d)
+}
+
+// checkRemaining responds 409 unless the set keeps another active
+// destination without d, or the caller passed ?force=true
+func (h *DispatcherHandler) checkRemaining(w http.ResponseWriter, r *http.Request, d *models.Dispatcher) bool {
+	if r.URL.Query().Get("force") == "true" || d.Drained {
+		return true
+	}
+
+	dispatchers, err := h.db.ListDispatchers(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list dispatcher destinations", err)
+		return false
+	}
+
+	for _, other := range dispatchers {
+		if other.ID != d.ID && other.SetID == d.SetID && !other.Drained {
+			return true
+		}
+	}
+
+	respondError(w, http.StatusConflict,
+		fmt.Sprintf("Last active destination of set %d; use ?force=true", d.SetID), nil)
+	return false
+}
+
+// addState adds the live state of the destinations on every Kamailio node.
+// Unreachable nodes are reported per destination instead of failing the
+// request.
+func (h *DispatcherHandler) addState(r *http.Request, dispatchers []*models.Dispatcher) {
+	for _, node := range h.kamailio.Nodes() {
+		targets, err := h.kamailio.ListDispatcher(r.Context(), node)
+
+		loaded := make(map[string]*kamailio.DispatcherTarget, len(targets))
+		for _, t := range targets {
+			loaded[strconv.Itoa(t.SetID)+" "+t.URI] = t
+		}
+
+		for _, d := range dispatchers {
+			state := &models.DispatcherNodeState{Node: node}
+			if err != nil {
+				state.Error = err.Error()
+			} else if t, ok := loaded[strconv.Itoa(d.SetID)+" "+d.Destination]; ok {
+				state.Flags = t.Flags
+			} else {
+				state.Error = "not loaded"
+			}
+			d.State = append(d.State, state)
+		}
+	}
+}
+
+// unscoped responds 403 to domain-scoped callers
+func (h *DispatcherHandler) unscoped(w http.ResponseWriter, r *http.Request) bool {
+	if domainScope(r) != nil {
+		respondError(w, http.StatusForbidden, "Domain-scoped API keys cannot manage the dispatcher", nil)
+		return false
+	}
+	return true
+}
+
+// loadDispatcher loads the {id} destination
+func (h *DispatcherHandler) loadDispatcher(w http.ResponseWriter, r *http.Request) (*models.Dispatcher, bool) {
+	if !h.unscoped(w, r) {
+		return nil, false
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid dispatcher destination ID", err)
+		return nil, false
+	}
+
+	d, err := h.db.GetDispatcher(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Dispatcher destination not found", err)
+		return nil, false
+	}
+
+	return d, true
+}
+
+// validateDispatcher checks a dispatcher destination
+func validateDispatcher(d *models.Dispatcher) error {
+	if d.SetID < 1 {
+		return errValidation("set_id must be positive")
+	}
+	if len(d.Destination) > 192 || !dispatcherDestinationPattern.MatchString(d.Destination) {
+		return errValidation("destination must be a SIP URI, e.g. sip:172.16.91.101:5080")
+	}
+	if d.Priority < 0 {
+		return errValidation("priority must not be negative")
+	}
+	if d.Weight < 0 || d.Weight > 100 {
+		return errValidation("weight must be between 1 and 100, or 0 for none")
+	}
+	if d.Attrs != "" {
+		if !dispatcherAttrsPattern.MatchString(d.Attrs) || strings.Contains(";"+d.Attrs, ";weight=") {
+			return errValidation("attrs must be name=value pairs separated by ';', without weight")
+		}
+	}
+	// weight=100; plus the other attributes must fit the attrs column
+	if len(d.Attrs)+len("weight=100;") > 128 {
+		return errValidation("attrs too long")
+	}
+	if len(d.Description) > 64 {
+		return errValidation("description must be at most 64 characters")
+	}
+	return nil
+}
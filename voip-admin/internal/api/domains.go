@@ -0,0 +1,285 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/cache"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/xmlcurl"
+)
+
+// domainNamePattern matches a DNS hostname (labels of letters, digits and hyphens)
+var domainNamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// voicemailMainNumberPattern matches an internal voicemail main number
+var voicemailMainNumberPattern = regexp.MustCompile(`^[0-9]{2,20}$`)
+
+// DomainHandler handles domain (tenant) HTTP requests
+type DomainHandler struct {
+	db    *database.DB
+	cache *cache.Manager
+}
+
+// NewDomainHandler creates a new domain handler
+func NewDomainHandler(db *database.DB, cache *cache.Manager) *DomainHandler {
+	return &DomainHandler{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// List handles GET /api/v1/domains
+func (h *DomainHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var active *bool
+	if activeStr := r.URL.Query().Get("active"); activeStr != "" {
+		activeBool := activeStr == "true" || activeStr == "1"
+		active = &activeBool
+	}
+
+	page := 1
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	perPage := 50
+	if perPageStr := r.URL.Query().Get("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 1000 {
+			perPage = pp
+		}
+	}
+
+	// Scoped callers only see their own domain
+	if scope := domainScope(r); scope != nil {
+		domain, err := h.db.GetDomain(ctx, *scope)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to list domains", err)
+			return
+		}
+
+		result := &models.DomainListResponse{Page: 1, PerPage: perPage}
+		if active == nil || *active == domain.Active {
+			result.Domains = []*models.Domain{domain}
+			result.Total = 1
+		}
+		respondJSON(w, http.StatusOK, result)
+		return
+	}
+
+	result, err := h.db.ListDomains(ctx, active, page, perPage)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list domains", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// Get handles GET /api/v1/domains/{id}
+func (h *DomainHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+		return
+	}
+
+	domain, err := h.db.GetDomain(ctx, id)
+	if err != nil || !canAccessDomain(r, id) {
+		respondError(w, http.StatusNotFound, "Domain not found", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, domain)
+}
+
+// Create handles POST /api/v1/domains
+func (h *DomainHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if domainScope(r) != nil {
+		respondError(w, http.StatusForbidden, "Domain-scoped API keys cannot create domains", nil)
+		return
+	}
+
+	var req models.DomainCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.Domain = strings.ToLower(strings.TrimSpace(req.Domain))
+
+	if err := validateDomainCreateRequest(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	if _, err := h.db.GetDomainByName(ctx, req.Domain); err == nil {
+		respondError(w, http.StatusConflict, "Domain already exists", nil)
+		return
+	}
+
+	domain, err := h.db.CreateDomain(ctx, &req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create domain", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditCreate, "domains", strconv.FormatInt(domain.ID, 10), &domain.ID, nil, domain)
+
+	respondJSON(w, http.StatusCreated, domain)
+}
+
+// Update handles PUT /api/v1/domains/{id}
+func (h *DomainHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+		return
+	}
+
+	current, err := h.db.GetDomain(ctx, id)
+	if err != nil || !canAccessDomain(r, id) {
+		respondError(w, http.StatusNotFound, "Domain not found", err)
+		return
+	}
+
+	var req models.DomainUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.TenantName != nil && (*req.TenantName == "" || len(*req.TenantName) > 255) {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("tenant_name must be 1-255 characters"))
+		return
+	}
+
+	// The main number shares the domain's internal numbers, but is outside
+	// the numbering plan ranges
+	if req.VoicemailMainNumber != nil && *req.VoicemailMainNumber != "" &&
+		*req.VoicemailMainNumber != current.VoicemailMainNumber {
+		number := *req.VoicemailMainNumber
+		if !voicemailMainNumberPattern.MatchString(number) {
+			respondError(w, http.StatusBadRequest, "Validation failed", errValidation("voicemail_main_number must be 2-20 digits"))
+			return
+		}
+		if err := checkNumberingPlan(ctx, h.db, id, number, "voicemail"); err != nil {
+			respondError(w, http.StatusBadRequest, "Validation failed", err)
+			return
+		}
+	}
+
+	// Tenants cannot choose their own prices
+	if req.RateDeckID != nil {
+		if domainScope(r) != nil {
+			respondError(w, http.StatusForbidden, "Rate decks can only be assigned by unscoped callers", nil)
+			return
+		}
+		if *req.RateDeckID != 0 {
+			if err := checkRateDeck(r, h.db, req.RateDeckID, models.RateDeckSell); err != nil {
+				respondError(w, http.StatusBadRequest, "Validation failed", err)
+				return
+			}
+		}
+	}
+
+	domain, err := h.db.UpdateDomain(ctx, id, &req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update domain", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "domains", vars["id"], &domain.ID, current, domain)
+
+	// Deactivation must take effect immediately, not after the directory TTL
+	if !domain.Active {
+		xmlcurl.InvalidateDomainCache(h.cache, domain.Domain)
+	}
+
+	respondJSON(w, http.StatusOK, domain)
+}
+
+// Delete handles DELETE /api/v1/domains/{id}
+// Domains that still own extensions are only deleted with ?force=true.
+func (h *DomainHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	if domainScope(r) != nil {
+		respondError(w, http.StatusForbidden, "Domain-scoped API keys cannot delete domains", nil)
+		return
+	}
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+		return
+	}
+
+	domain, err := h.db.GetDomain(ctx, id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Domain not found", err)
+		return
+	}
+
+	usage, err := h.db.GetDomainUsage(ctx, id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to check domain usage", err)
+		return
+	}
+
+	force := r.URL.Query().Get("force") == "true"
+	if usage.Extensions > 0 && !force {
+		respondJSON(w, http.StatusConflict, &models.APIResponse{
+			Success: false,
+			Message: "Domain has extensions; deleting it also deletes them. Deactivate it instead, or retry with ?force=true",
+			Data:    usage,
+			Error: &models.APIError{
+				Code:    "E409",
+				Message: "Domain has extensions",
+			},
+		})
+		return
+	}
+
+	if err := h.db.DeleteDomain(ctx, id); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete domain", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "domains", vars["id"], &domain.ID, domain, nil)
+
+	xmlcurl.InvalidateDomainCache(h.cache, domain.Domain)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateDomainCreateRequest validates a domain create request
+func validateDomainCreateRequest(req *models.DomainCreateRequest) error {
+	if req.Domain == "" {
+		return errValidation("domain is required")
+	}
+	if len(req.Domain) > 255 || !domainNamePattern.MatchString(req.Domain) {
+		return errValidation("domain must be a valid hostname")
+	}
+	if req.TenantName == "" || len(req.TenantName) > 255 {
+		return errValidation("tenant_name must be 1-255 characters")
+	}
+	return nil
+}
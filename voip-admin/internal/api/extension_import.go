@@ -0,0 +1,421 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/middleware"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+const (
+	// maxImportBodyBytes limits the size of an import upload
+	maxImportBodyBytes = 10 << 20
+
+	// maxImportRows limits the number of extensions per import
+	maxImportRows = 5000
+)
+
+// extensionCSVColumns is the column order of CSV exports. Imports accept
+// the columns in any order; only the required ones must be present.
+var extensionCSVColumns = []string{
+	"domain_id", "extension", "type", "display_name", "email", "sip_password",
+	"vm_password", "vm_email", "vm_auto_login", "active", "max_concurrent", "call_timeout", "user_id",
+}
+
+// extensionCSVRequired are the columns an import CSV must have
+var extensionCSVRequired = []string{"domain_id", "extension", "type", "display_name"}
+
+// Import handles POST /api/v1/extensions/import
+// Accepts a CSV file (Content-Type: text/csv) or a JSON array of extension
+// create requests. Every row is validated; with ?dry_run=true only the
+// validation report is returned. Otherwise all rows are created in one
+// transaction, or none if any row is invalid. Rows without sip_password get
+// a generated one, returned once in the response.
+func (h *ExtensionHandler) Import(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	dryRun := r.URL.Query().Get("dry_run") == "true" || r.URL.Query().Get("dry_run") == "1"
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodyBytes)
+
+	var reqs []*models.ExtensionCreateRequest
+	var parseErrors []*models.ExtensionImportError
+	var err error
+
+	if strings.Contains(r.Header.Get("Content-Type"), "csv") {
+		reqs, parseErrors, err = parseExtensionCSV(body)
+	} else {
+		err = json.NewDecoder(body).Decode(&reqs)
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid import file", err)
+		return
+	}
+
+	if len(reqs) == 0 {
+		respondError(w, http.StatusBadRequest, "Import file contains no extensions", nil)
+		return
+	}
+	if len(reqs) > maxImportRows {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Import is limited to %d extensions", maxImportRows), nil)
+		return
+	}
+
+	result := &models.ExtensionImportResult{
+		DryRun: dryRun,
+		Total:  len(reqs),
+		Errors: parseErrors,
+	}
+
+	// CSV rows that failed to parse are already reported
+	reported := make(map[int]bool, len(parseErrors))
+	for _, parseError := range parseErrors {
+		reported[parseError.Row] = true
+	}
+
+	domains := make(map[int64]*models.Domain)
+	plans := make(map[int64]*models.NumberingPlan)
+	seen := make(map[string]int)
+
+	for i, req := range reqs {
+		row := i + 1
+		if req == nil {
+			if !reported[row] {
+				result.Errors = append(result.Errors, &models.ExtensionImportError{Row: row, Error: "row is empty"})
+			}
+			continue
+		}
+
+		req.Extension = strings.TrimSpace(req.Extension)
+		fail := func(err error) {
+			result.Errors = append(result.Errors, &models.ExtensionImportError{
+				Row:       row,
+				Extension: req.Extension,
+				Error:     strings.TrimPrefix(err.Error(), "validation error: "),
+			})
+		}
+
+		// Look up the domain first so a generated password can be checked
+		// against it
+		domain, ok := domains[req.DomainID]
+		if !ok && req.DomainID != 0 && canAccessDomain(r, req.DomainID) {
+			if domain, err = h.db.GetDomain(ctx, req.DomainID); err != nil {
+				domain = nil
+			}
+			domains[req.DomainID] = domain
+		}
+		if domain == nil {
+			fail(errValidation("domain not found"))
+			continue
+		}
+		if !domain.Active {
+			fail(errValidation("domain is inactive"))
+			continue
+		}
+
+		generated := false
+		if req.SIPPassword == "" && req.Extension != "" {
+			password, err := h.policy.Generate(req.Extension, domain.Domain)
+			if err != nil {
+				respondError(w, http.StatusInternalServerError, "Failed to generate password", err)
+				return
+			}
+			req.SIPPassword = password
+			generated = true
+		}
+
+		if err := validateExtensionCreateRequest(req); err != nil {
+			fail(err)
+			continue
+		}
+		if !generated {
+			if err := h.policy.Check(req.SIPPassword, req.Extension, domain.Domain); err != nil {
+				fail(errValidation("sip_password: " + err.Error()))
+				continue
+			}
+		}
+
+		key := strconv.FormatInt(req.DomainID, 10) + "/" + req.Extension
+		if first, dup := seen[key]; dup {
+			fail(errValidation(fmt.Sprintf("duplicate of row %d", first)))
+			continue
+		}
+		seen[key] = row
+
+		plan, ok := plans[req.DomainID]
+		if !ok {
+			if plan, err = h.db.GetNumberingPlan(ctx, req.DomainID); err != nil {
+				respondError(w, http.StatusInternalServerError, "Failed to get numbering plan", err)
+				return
+			}
+			plans[req.DomainID] = plan
+		}
+		if err := numberPlanError(plan, req.Extension, req.Type); err != nil {
+			fail(err)
+			continue
+		}
+
+		inUse, err := h.db.FindNumberInUse(ctx, req.DomainID, req.Extension)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to check extension", err)
+			return
+		}
+		if inUse != nil {
+			fail(errValidation("already used by a " + inUse.Kind))
+			continue
+		}
+
+		if req.UserID != nil {
+			user, err := h.db.GetUser(ctx, *req.UserID)
+			if err != nil || user.DomainID == nil || *user.DomainID != req.DomainID {
+				fail(errValidation("user not found in this domain"))
+				continue
+			}
+		}
+
+		if generated {
+			result.Passwords = append(result.Passwords, &models.ExtensionImportPassword{
+				Row:       row,
+				DomainID:  req.DomainID,
+				Extension: req.Extension,
+				Password:  req.SIPPassword,
+			})
+		}
+	}
+
+	if dryRun {
+		// Passwords are generated again on the real import
+		result.Passwords = nil
+		respondJSON(w, http.StatusOK, result)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		result.Passwords = nil
+		respondJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	ids, err := h.db.CreateExtensions(ctx, reqs)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to import extensions", err)
+		return
+	}
+	result.Created = len(ids)
+
+	created := make([]string, len(reqs))
+	for i, req := range reqs {
+		created[i] = req.Extension + "@" + domains[req.DomainID].Domain
+	}
+	recordAudit(r, h.db, models.AuditImport, "extensions", "", domainScope(r), nil,
+		map[string]interface{}{"created": created})
+
+	respondJSON(w, http.StatusCreated, result)
+}
+
+// Export handles GET /api/v1/extensions/export
+// Writes all extensions as a JSON array (default) or CSV (?format=csv) in
+// the import format. SIP passwords are never exported; re-importing the
+// file generates new ones. Voicemail PINs are only exported with
+// ?include_secrets=true, which needs write access and is audited.
+func (h *ExtensionHandler) Export(w http.ResponseWriter, r *http.Request) {
+	includeSecrets := r.URL.Query().Get("include_secrets") == "true"
+	if includeSecrets {
+		principal := middleware.PrincipalFromContext(r.Context())
+		if !principal.Permissions.Allows("extensions", models.AccessWrite) {
+			respondError(w, http.StatusForbidden, "Exporting secrets requires write access to extensions", nil)
+			return
+		}
+	}
+
+	var domainID *int64
+	if domainIDStr := r.URL.Query().Get("domain_id"); domainIDStr != "" {
+		if id, err := strconv.ParseInt(domainIDStr, 10, 64); err == nil {
+			domainID = &id
+		}
+	}
+	if scope := domainScope(r); scope != nil {
+		domainID = scope
+	}
+
+	extensions, err := h.db.ExportExtensions(r.Context(), domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to export extensions", err)
+		return
+	}
+
+	rows := make([]*models.ExtensionCreateRequest, len(extensions))
+	for i, ext := range extensions {
+		rows[i] = &models.ExtensionCreateRequest{
+			DomainID:      ext.DomainID,
+			Extension:     ext.Extension,
+			Type:          ext.Type,
+			DisplayName:   ext.DisplayName,
+			Email:         ext.Email,
+			VMEmail:       ext.VMEmail,
+			VMAutoLogin:   ext.VMAutoLogin,
+			Active:        ext.Active,
+			MaxConcurrent: ext.MaxConcurrent,
+			CallTimeout:   ext.CallTimeout,
+			UserID:        ext.UserID,
+		}
+		if includeSecrets {
+			rows[i].VMPassword = ext.VMPassword
+		}
+	}
+
+	if includeSecrets {
+		recordAudit(r, h.db, models.AuditExport, "extensions", "", domainID, nil,
+			map[string]interface{}{"include_secrets": true, "exported": len(rows)})
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		w.Header().Set("Content-Disposition", `attachment; filename="extensions.json"`)
+		respondJSON(w, http.StatusOK, rows)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="extensions.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write(extensionCSVColumns)
+	for _, row := range rows {
+		userID := ""
+		if row.UserID != nil {
+			userID = strconv.FormatInt(*row.UserID, 10)
+		}
+		writer.Write([]string{
+			strconv.FormatInt(row.DomainID, 10), row.Extension, row.Type, row.DisplayName,
+			row.Email, "", row.VMPassword, row.VMEmail, strconv.FormatBool(row.VMAutoLogin), strconv.FormatBool(row.Active),
+			strconv.Itoa(row.MaxConcurrent), strconv.Itoa(row.CallTimeout), userID,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("[Extensions] Failed to write CSV export: %v", err)
+	}
+}
+
+// parseExtensionCSV reads extension create requests from a CSV file with a
+// header row. Rows that cannot be parsed are returned as nil requests with
+// a matching import error, so row numbers stay aligned.
+func parseExtensionCSV(body io.Reader) ([]*models.ExtensionCreateRequest, []*models.ExtensionImportError, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	known := make(map[string]bool, len(extensionCSVColumns))
+	for _, name := range extensionCSVColumns {
+		known[name] = true
+	}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !known[name] {
+			return nil, nil, fmt.Errorf("unknown CSV column: %s", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range extensionCSVRequired {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("missing CSV column: %s", name)
+		}
+	}
+
+	var reqs []*models.ExtensionCreateRequest
+	var parseErrors []*models.ExtensionImportError
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return nil, nil, fmt.Errorf("read CSV: %w", err)
+			}
+			reqs = append(reqs, nil)
+			parseErrors = append(parseErrors, &models.ExtensionImportError{Row: len(reqs), Error: err.Error()})
+			continue
+		}
+
+		req, err := parseExtensionCSVRecord(record, columns)
+		reqs = append(reqs, req)
+		if err != nil {
+			parseErrors = append(parseErrors, &models.ExtensionImportError{Row: len(reqs), Error: err.Error()})
+		}
+		if len(reqs) > maxImportRows {
+			break
+		}
+	}
+
+	return reqs, parseErrors, nil
+}
+
+// parseExtensionCSVRecord converts one CSV record; it returns a nil request
+// if a field has the wrong type
+func parseExtensionCSVRecord(record []string, columns map[string]int) (*models.ExtensionCreateRequest, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	req := &models.ExtensionCreateRequest{
+		Extension:   field("extension"),
+		Type:        field("type"),
+		DisplayName: field("display_name"),
+		Email:       field("email"),
+		SIPPassword: field("sip_password"),
+		VMPassword:  field("vm_password"),
+		VMEmail:     field("vm_email"),
+	}
+
+	var err error
+	if req.DomainID, err = strconv.ParseInt(field("domain_id"), 10, 64); err != nil {
+		return nil, fmt.Errorf("domain_id must be a number")
+	}
+	if v := field("active"); v != "" {
+		if req.Active, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("active must be true or false")
+		}
+	}
+	if v := field("vm_auto_login"); v != "" {
+		if req.VMAutoLogin, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("vm_auto_login must be true or false")
+		}
+	}
+	if v := field("max_concurrent"); v != "" {
+		if req.MaxConcurrent, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("max_concurrent must be a number")
+		}
+	}
+	if v := field("call_timeout"); v != "" {
+		if req.CallTimeout, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("call_timeout must be a number")
+		}
+	}
+	if v := field("user_id"); v != "" {
+		userID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("user_id must be a number")
+		}
+		req.UserID = &userID
+	}
+
+	return req, nil
+}
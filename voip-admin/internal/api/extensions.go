@@ -8,17 +8,20 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/sipauth"
 )
 
 // ExtensionHandler handles extension-related HTTP requests
 type ExtensionHandler struct {
-	db *database.DB
+	db     *database.DB
+	policy *sipauth.Policy
 }
 
 // NewExtensionHandler creates a new extension handler
-func NewExtensionHandler(db *database.DB) *ExtensionHandler {
+func NewExtensionHandler(db *database.DB, policy *sipauth.Policy) *ExtensionHandler {
 	return &ExtensionHandler{
-		db: db,
+		db:     db,
+		policy: policy,
 	}
 }
 
@@ -35,6 +38,11 @@ func (h *ExtensionHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Scoped callers only ever see their own domain
+	if scope := domainScope(r); scope != nil {
+		domainID = scope
+	}
+
 	var extType *string
 	if typeStr := r.URL.Query().Get("type"); typeStr != "" {
 		extType = &typeStr
@@ -69,7 +77,6 @@ func (h *ExtensionHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	// Remove sensitive data before sending
 	for _, ext := range result.Extensions {
-		ext.SIPPassword = ""
 		ext.SIPHA1 = ""
 		ext.SIPHA1B = ""
 	}
@@ -89,13 +96,20 @@ func (h *ExtensionHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ext, err := h.db.GetExtensionByID(ctx, id)
-	if err != nil {
+	if err != nil || !canAccessDomain(r, ext.DomainID) {
 		respondError(w, http.StatusNotFound, "Extension not found", err)
 		return
 	}
 
+	if ext.Type == "user" {
+		ext.CallHandling, err = h.db.GetCallHandling(ctx, ext.ID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to get call handling", err)
+			return
+		}
+	}
+
 	// Remove sensitive data
-	ext.SIPPassword = ""
 	ext.SIPHA1 = ""
 	ext.SIPHA1B = ""
 
@@ -118,6 +132,36 @@ func (h *ExtensionHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !canAccessDomain(r, req.DomainID) {
+		respondError(w, http.StatusForbidden, "API key is not allowed to access this domain", nil)
+		return
+	}
+
+	// Extensions can only be created in an existing, active domain
+	domain, err := h.db.GetDomain(ctx, req.DomainID)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Domain not found", err)
+		return
+	}
+	if !domain.Active {
+		respondError(w, http.StatusBadRequest, "Domain is inactive", nil)
+		return
+	}
+
+	if err := h.policy.Check(req.SIPPassword, req.Extension, domain.Domain); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("sip_password: "+err.Error()))
+		return
+	}
+
+	if err := checkNumberingPlan(ctx, h.db, req.DomainID, req.Extension, req.Type); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	if req.UserID != nil && !h.checkExtensionUser(w, r, *req.UserID, req.DomainID) {
+		return
+	}
+
 	// Create extension
 	ext, err := h.db.CreateExtension(ctx, &req)
 	if err != nil {
@@ -125,8 +169,9 @@ func (h *ExtensionHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, h.db, models.AuditCreate, "extensions", strconv.FormatInt(ext.ID, 10), &ext.DomainID, nil, ext)
+
 	// Remove sensitive data
-	ext.SIPPassword = ""
 	ext.SIPHA1 = ""
 	ext.SIPHA1B = ""
 
@@ -144,6 +189,11 @@ func (h *ExtensionHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	current, ok := h.loadExtension(w, r, id)
+	if !ok {
+		return
+	}
+
 	var req models.ExtensionUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid request body", err)
@@ -156,6 +206,10 @@ func (h *ExtensionHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.UserID != nil && !h.checkExtensionUser(w, r, *req.UserID, current.DomainID) {
+		return
+	}
+
 	// Update extension
 	ext, err := h.db.UpdateExtension(ctx, id, &req)
 	if err != nil {
@@ -163,8 +217,9 @@ func (h *ExtensionHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, h.db, models.AuditUpdate, "extensions", vars["id"], &ext.DomainID, current, ext)
+
 	// Remove sensitive data
-	ext.SIPPassword = ""
 	ext.SIPHA1 = ""
 	ext.SIPHA1B = ""
 
@@ -182,17 +237,23 @@ func (h *ExtensionHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	current, ok := h.loadExtension(w, r, id)
+	if !ok {
+		return
+	}
+
 	if err := h.db.DeleteExtension(ctx, id); err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to delete extension", err)
 		return
 	}
 
+	recordAudit(r, h.db, models.AuditDelete, "extensions", vars["id"], &current.DomainID, current, nil)
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
 // UpdatePassword handles POST /api/v1/extensions/{id}/password
 func (h *ExtensionHandler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
 	vars := mux.Vars(r)
 
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
@@ -201,29 +262,137 @@ func (h *ExtensionHandler) UpdatePassword(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	current, ok := h.loadExtension(w, r, id)
+	if !ok {
+		return
+	}
+
+	h.changePassword(w, r, current)
+}
+
+// UpdateOwnPassword handles POST /api/v1/auth/extensions/{id}/password
+// Lets logged-in users change the SIP password of their own extensions.
+func (h *ExtensionHandler) UpdateOwnPassword(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid extension ID", err)
+		return
+	}
+
+	current, err := h.db.GetExtensionByID(r.Context(), id)
+	if err != nil || !ownsExtension(r, current) {
+		respondError(w, http.StatusNotFound, "Extension not found", err)
+		return
+	}
+
+	h.changePassword(w, r, current)
+}
+
+// GeneratePassword handles GET /api/v1/extensions/generate-password
+// Returns a random password that passes the policy. With ?extension= and
+// ?domain_id= it is also checked against that extension and domain.
+func (h *ExtensionHandler) GeneratePassword(w http.ResponseWriter, r *http.Request) {
+	extension := r.URL.Query().Get("extension")
+
+	var domainName string
+	if domainIDStr := r.URL.Query().Get("domain_id"); domainIDStr != "" {
+		domainID, err := strconv.ParseInt(domainIDStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+			return
+		}
+		domain, err := h.db.GetDomain(r.Context(), domainID)
+		if err != nil || !canAccessDomain(r, domainID) {
+			respondError(w, http.StatusNotFound, "Domain not found", err)
+			return
+		}
+		domainName = domain.Domain
+	}
+
+	password, err := h.policy.Generate(extension, domainName)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to generate password", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, &models.GeneratedPassword{
+		Password:    password,
+		EntropyBits: sipauth.Entropy(password),
+	})
+}
+
+// changePassword validates and stores a new SIP password. Users changing
+// their own extension must prove the old password; admins and API keys
+// resetting someone else's do not.
+func (h *ExtensionHandler) changePassword(w http.ResponseWriter, r *http.Request, current *models.Extension) {
 	var req models.ExtensionPasswordUpdate
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
-	// Validate passwords
-	if req.NewPassword == "" || len(req.NewPassword) < 8 {
-		respondError(w, http.StatusBadRequest, "New password must be at least 8 characters", nil)
-		return
+	if ownsExtension(r, current) {
+		if req.OldPassword == "" {
+			respondError(w, http.StatusBadRequest, "Validation failed", errValidation("old_password is required"))
+			return
+		}
+		if !sipauth.Verify(current.Extension, current.Domain, req.OldPassword, current.SIPHA1) {
+			respondError(w, http.StatusForbidden, "Old password is incorrect", nil)
+			return
+		}
 	}
 
-	// TODO: Verify old password before updating
-	// For now, just update with new password
+	if err := h.policy.Check(req.NewPassword, current.Extension, current.Domain); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("new_password: "+err.Error()))
+		return
+	}
+	if sipauth.Verify(current.Extension, current.Domain, req.NewPassword, current.SIPHA1) {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("new_password must differ from the current password"))
+		return
+	}
 
-	if err := h.db.UpdateExtensionPassword(ctx, id, req.NewPassword); err != nil {
+	if err := h.db.UpdateExtensionPassword(r.Context(), current.ID, req.NewPassword); err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to update password", err)
 		return
 	}
 
+	recordAudit(r, h.db, models.AuditChangePassword, "extensions", strconv.FormatInt(current.ID, 10), &current.DomainID,
+		nil, map[string]string{"sip_password": req.NewPassword})
+
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Password updated successfully"})
 }
 
+// loadExtension loads an extension the caller may access, responding 404
+// if it does not exist or belongs to another domain
+func (h *ExtensionHandler) loadExtension(w http.ResponseWriter, r *http.Request, id int64) (*models.Extension, bool) {
+	ext, err := h.db.GetExtensionByID(r.Context(), id)
+	if err != nil || !canAccessDomain(r, ext.DomainID) {
+		respondError(w, http.StatusNotFound, "Extension not found", err)
+		return nil, false
+	}
+
+	return ext, true
+}
+
+// checkExtensionUser verifies the owning user exists in the extension's
+// domain, responding 400 if not
+func (h *ExtensionHandler) checkExtensionUser(w http.ResponseWriter, r *http.Request, userID, domainID int64) bool {
+	user, err := h.db.GetUser(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "User not found", err)
+		return false
+	}
+
+	if user.DomainID == nil || *user.DomainID != domainID {
+		respondError(w, http.StatusBadRequest, "User belongs to a different domain", nil)
+		return false
+	}
+
+	return true
+}
+
 // Validation helpers
 func validateExtensionCreateRequest(req *models.ExtensionCreateRequest) error {
 	if req.DomainID == 0 {
@@ -245,8 +414,8 @@ func validateExtensionCreateRequest(req *models.ExtensionCreateRequest) error {
 	if req.DisplayName == "" {
 		return errValidation("display_name is required")
 	}
-	if req.SIPPassword == "" || len(req.SIPPassword) < 8 {
-		return errValidation("sip_password must be at least 8 characters")
+	if req.SIPPassword == "" {
+		return errValidation("sip_password is required")
 	}
 	if req.MaxConcurrent < 1 || req.MaxConcurrent > 100 {
 		return errValidation("max_concurrent must be 1-100")
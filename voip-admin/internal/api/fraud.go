@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/middleware"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// FraudHandler handles fraud alert HTTP requests. Alerts are raised by the
+// fraud detector worker; the API lists and acknowledges them.
+type FraudHandler struct {
+	db *database.DB
+}
+
+// NewFraudHandler creates a new fraud handler
+func NewFraudHandler(db *database.DB) *FraudHandler {
+	return &FraudHandler{
+		db: db,
+	}
+}
+
+// List handles GET /api/v1/fraud/alerts
+func (h *FraudHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	req := &models.FraudAlertListRequest{
+		Page:    1,
+		PerPage: 50,
+	}
+
+	if pageStr := query.Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			req.Page = p
+		}
+	}
+
+	if perPageStr := query.Get("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 1000 {
+			req.PerPage = pp
+		}
+	}
+
+	if domainIDStr := query.Get("domain_id"); domainIDStr != "" {
+		if id, err := strconv.ParseInt(domainIDStr, 10, 64); err == nil {
+			req.DomainID = &id
+		}
+	}
+
+	if extensionIDStr := query.Get("extension_id"); extensionIDStr != "" {
+		if id, err := strconv.ParseInt(extensionIDStr, 10, 64); err == nil {
+			req.ExtensionID = &id
+		}
+	}
+
+	if rule := query.Get("rule"); rule != "" {
+		req.Rule = &rule
+	}
+
+	if status := query.Get("status"); status != "" {
+		if status != models.FraudAlertOpen && status != models.FraudAlertAcknowledged {
+			respondError(w, http.StatusBadRequest, "Validation failed",
+				errValidation("status must be open or acknowledged"))
+			return
+		}
+		req.Status = &status
+	}
+
+	// Scoped callers only see alerts of their own domain
+	if scope := domainScope(r); scope != nil {
+		req.DomainID = scope
+	}
+
+	result, err := h.db.ListFraudAlerts(r.Context(), req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list fraud alerts", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// Get handles GET /api/v1/fraud/alerts/{id}
+func (h *FraudHandler) Get(w http.ResponseWriter, r *http.Request) {
+	alert, ok := h.loadAlert(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, alert)
+}
+
+// Acknowledge handles POST /api/v1/fraud/alerts/{id}/acknowledge
+// Closes the alert. A suspended extension stays inactive until it is
+// reactivated with an extension update.
+func (h *FraudHandler) Acknowledge(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadAlert(w, r)
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req models.FraudAlertAcknowledgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.Note = strings.TrimSpace(req.Note)
+	if len(req.Note) > 255 {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("note must be at most 255 characters"))
+		return
+	}
+
+	if current.Status != models.FraudAlertOpen {
+		respondError(w, http.StatusConflict, "Alert is already acknowledged", nil)
+		return
+	}
+
+	var by string
+	if principal := middleware.PrincipalFromContext(r.Context()); principal != nil {
+		by = principal.Name
+	}
+
+	alert, err := h.db.AcknowledgeFraudAlert(r.Context(), current.ID, by, req.Note)
+	if err != nil {
+		respondError(w, http.StatusConflict, "Alert is already acknowledged", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditAcknowledge, "fraud", strconv.FormatInt(alert.ID, 10), &alert.DomainID,
+		current, alert)
+
+	respondJSON(w, http.StatusOK, alert)
+}
+
+// loadAlert loads the {id} alert, hiding alerts outside the caller's scope
+func (h *FraudHandler) loadAlert(w http.ResponseWriter, r *http.Request) (*models.FraudAlert, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid alert ID", err)
+		return nil, false
+	}
+
+	alert, err := h.db.GetFraudAlert(r.Context(), id)
+	if err != nil || !canAccessDomain(r, alert.DomainID) {
+		respondError(w, http.StatusNotFound, "Alert not found", err)
+		return nil, false
+	}
+
+	return alert, true
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/numbering"
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/xmlcurl"
 )
 
@@ -16,13 +17,13 @@ type FreeSwitchHandler struct {
 }
 
 // NewFreeSwitchHandler creates a new FreeSWITCH handler
-func NewFreeSwitchHandler(db *database.DB, cache xmlcurl.Cache) (*FreeSwitchHandler, error) {
+func NewFreeSwitchHandler(db *database.DB, cache xmlcurl.Cache, numbers *numbering.Matcher, creditAnnouncement string) (*FreeSwitchHandler, error) {
 	directoryHandler, err := xmlcurl.NewDirectoryHandler(db, cache)
 	if err != nil {
 		return nil, err
 	}
 
-	dialplanHandler, err := xmlcurl.NewDialplanHandler(db)
+	dialplanHandler, err := xmlcurl.NewDialplanHandler(db, numbers, creditAnnouncement)
 	if err != nil {
 		return nil, err
 	}
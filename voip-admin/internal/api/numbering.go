@@ -0,0 +1,329 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/numbering"
+)
+
+// rangeBoundPattern matches a number range bound
+var rangeBoundPattern = regexp.MustCompile(`^[0-9]{1,18}$`)
+
+// maxNumberRanges limits the number of ranges in one plan
+const maxNumberRanges = 100
+
+// NumberingHandler handles numbering plan HTTP requests
+type NumberingHandler struct {
+	db      *database.DB
+	numbers *numbering.Matcher
+}
+
+// NewNumberingHandler creates a new numbering plan handler
+func NewNumberingHandler(db *database.DB, numbers *numbering.Matcher) *NumberingHandler {
+	return &NumberingHandler{
+		db:      db,
+		numbers: numbers,
+	}
+}
+
+// Get handles GET /api/v1/domains/{id}/numbering-plan
+func (h *NumberingHandler) Get(w http.ResponseWriter, r *http.Request) {
+	domainID, ok := h.loadDomainID(w, r)
+	if !ok {
+		return
+	}
+
+	plan, err := h.db.GetNumberingPlan(r.Context(), domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get numbering plan", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, plan)
+}
+
+// Update handles PUT /api/v1/domains/{id}/numbering-plan
+// Replaces all ranges of the domain. Existing extensions, queues and IVR
+// menus must still fit the new plan; conflicts are returned with 409.
+func (h *NumberingHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	domainID, ok := h.loadDomainID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.NumberingPlanUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := validateNumberRanges(req.Ranges); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+	if err := validateOutboundPattern(req.OutboundPattern); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	current, err := h.db.GetNumberingPlan(ctx, domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get numbering plan", err)
+		return
+	}
+
+	plan := &models.NumberingPlan{DomainID: domainID, Ranges: req.Ranges}
+	if len(req.Ranges) == 0 {
+		plan.Default = true
+		plan.Ranges = models.DefaultNumberRanges()
+	}
+
+	numbers, err := h.db.ListNumbersInUse(ctx, domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list numbers in use", err)
+		return
+	}
+
+	conflicts := []map[string]string{}
+	for _, n := range numbers {
+		if err := numberPlanError(plan, n.Number, n.Kind); err != nil {
+			conflicts = append(conflicts, map[string]string{
+				"number": n.Number,
+				"kind":   n.Kind,
+				"error":  err.Error(),
+			})
+		}
+	}
+	if len(conflicts) > 0 {
+		respondJSON(w, http.StatusConflict, map[string]interface{}{
+			"message":   "Numbers in use do not fit the new plan",
+			"conflicts": conflicts,
+		})
+		return
+	}
+
+	if err := h.db.SetNumberingPlan(ctx, domainID, req.Ranges, req.OutboundPattern); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update numbering plan", err)
+		return
+	}
+
+	// Route with the new plan right away; the other node reloads on its timer
+	if err := h.numbers.Reload(ctx); err != nil {
+		log.Printf("[Numbering] Failed to reload numbering plans: %v", err)
+	}
+
+	updated, err := h.db.GetNumberingPlan(ctx, domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get numbering plan", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "domains", strconv.FormatInt(domainID, 10), &domainID, current, updated)
+
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// Next handles GET /api/v1/domains/{id}/numbering-plan/next?type=user
+// Returns the lowest free number in the ranges of a type. The number is not
+// reserved; creating the object can still fail if someone takes it first.
+func (h *NumberingHandler) Next(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	domainID, ok := h.loadDomainID(w, r)
+	if !ok {
+		return
+	}
+
+	numberType := r.URL.Query().Get("type")
+	if !isValidNumberType(numberType) {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("type must be one of: user, queue, ivr, conference"))
+		return
+	}
+
+	plan, err := h.db.GetNumberingPlan(ctx, domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get numbering plan", err)
+		return
+	}
+
+	numbers, err := h.db.ListNumbersInUse(ctx, domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list numbers in use", err)
+		return
+	}
+
+	taken := make(map[string]bool, len(numbers))
+	for _, n := range numbers {
+		taken[n.Number] = true
+	}
+
+	for _, nr := range plan.Ranges {
+		if nr.Type != numberType {
+			continue
+		}
+		if number, ok := nextFreeNumber(nr, taken); ok {
+			respondJSON(w, http.StatusOK, &models.NextNumberResponse{
+				DomainID: domainID,
+				Type:     numberType,
+				Number:   number,
+				Range:    nr,
+			})
+			return
+		}
+	}
+
+	respondError(w, http.StatusConflict, fmt.Sprintf("No free %s number left in the numbering plan", numberType), nil)
+}
+
+// loadDomainID parses the {id} path variable, hiding domains outside the
+// caller's scope
+func (h *NumberingHandler) loadDomainID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+		return 0, false
+	}
+
+	if _, err := h.db.GetDomain(r.Context(), id); err != nil || !canAccessDomain(r, id) {
+		respondError(w, http.StatusNotFound, "Domain not found", err)
+		return 0, false
+	}
+
+	return id, true
+}
+
+// nextFreeNumber returns the lowest number of a range not in taken. Only
+// numbers already taken are skipped, so the loop is bounded by the size of
+// taken rather than of the range.
+func nextFreeNumber(nr *models.NumberRange, taken map[string]bool) (string, bool) {
+	start, err := strconv.ParseUint(nr.Start, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	end, err := strconv.ParseUint(nr.End, 10, 64)
+	if err != nil {
+		return "", false
+	}
+
+	for n := start; n <= end; n++ {
+		number := fmt.Sprintf("%0*d", len(nr.Start), n)
+		if !taken[number] {
+			return number, true
+		}
+	}
+
+	return "", false
+}
+
+// checkNumberingPlan validates that a new number of the given type fits the
+// domain's numbering plan and is not used by an extension, queue, IVR menu
+// or conference room
+func checkNumberingPlan(ctx context.Context, db *database.DB, domainID int64, number, numberType string) error {
+	plan, err := db.GetNumberingPlan(ctx, domainID)
+	if err != nil {
+		return err
+	}
+	if err := numberPlanError(plan, number, numberType); err != nil {
+		return errValidation(err.Error())
+	}
+
+	inUse, err := db.FindNumberInUse(ctx, domainID, number)
+	if err != nil {
+		return err
+	}
+	if inUse != nil {
+		return errValidation(fmt.Sprintf("%s is already used by a %s", number, inUse.Kind))
+	}
+
+	return nil
+}
+
+// numberPlanError reports why a number of a type does not fit a plan.
+// Kinds outside the plan (e.g. voicemail or trunk extensions) always fit.
+func numberPlanError(plan *models.NumberingPlan, number, numberType string) error {
+	if !isValidNumberType(numberType) {
+		return nil
+	}
+
+	nr := plan.RangeFor(number)
+	if nr == nil {
+		return fmt.Errorf("%s is outside the %s ranges of the numbering plan", number, numberType)
+	}
+	if nr.Type != numberType {
+		return fmt.Errorf("%s is reserved for %s numbers (%s-%s)", number, nr.Type, nr.Start, nr.End)
+	}
+
+	return nil
+}
+
+// isValidNumberType reports whether t is a numbering plan type
+func isValidNumberType(t string) bool {
+	for _, numberType := range models.NumberTypes {
+		if t == numberType {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOutboundPattern validates the outbound number pattern of a plan
+// update; empty means the default pattern
+func validateOutboundPattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	if len(pattern) > 255 {
+		return errValidation("outbound_pattern must be at most 255 characters")
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return errValidation("outbound_pattern is not a valid regular expression: " + err.Error())
+	}
+	return nil
+}
+
+// validateNumberRanges validates the ranges of a plan update
+func validateNumberRanges(ranges []*models.NumberRange) error {
+	if len(ranges) > maxNumberRanges {
+		return errValidation(fmt.Sprintf("a numbering plan can have at most %d ranges", maxNumberRanges))
+	}
+
+	for i, nr := range ranges {
+		if nr == nil {
+			return errValidation(fmt.Sprintf("range %d is empty", i+1))
+		}
+		if !isValidNumberType(nr.Type) {
+			return errValidation(fmt.Sprintf("range %d: type must be one of: user, queue, ivr, conference", i+1))
+		}
+		if !rangeBoundPattern.MatchString(nr.Start) || !rangeBoundPattern.MatchString(nr.End) {
+			return errValidation(fmt.Sprintf("range %d: start and end must be 1-18 digits", i+1))
+		}
+		if len(nr.Start) != len(nr.End) {
+			return errValidation(fmt.Sprintf("range %d: start and end must have the same number of digits", i+1))
+		}
+		if nr.Start > nr.End {
+			return errValidation(fmt.Sprintf("range %d: start must not be greater than end", i+1))
+		}
+		if len(nr.Description) > 255 {
+			return errValidation(fmt.Sprintf("range %d: description must be at most 255 characters", i+1))
+		}
+
+		for j := 0; j < i; j++ {
+			if nr.Overlaps(ranges[j]) {
+				return errValidation(fmt.Sprintf("range %d overlaps range %d", i+1, j+1))
+			}
+		}
+	}
+
+	return nil
+}
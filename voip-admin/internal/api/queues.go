@@ -0,0 +1,147 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/middleware"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// QueueHandler handles call queue HTTP requests
+type QueueHandler struct {
+	db *database.DB
+}
+
+// NewQueueHandler creates a new queue handler
+func NewQueueHandler(db *database.DB) *QueueHandler {
+	return &QueueHandler{
+		db: db,
+	}
+}
+
+// List handles GET /api/v1/queues
+// Supervisors only see the queues they supervise.
+func (h *QueueHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var domainID *int64
+	if domainIDStr := r.URL.Query().Get("domain_id"); domainIDStr != "" {
+		if id, err := strconv.ParseInt(domainIDStr, 10, 64); err == nil {
+			domainID = &id
+		}
+	}
+	if scope := domainScope(r); scope != nil {
+		domainID = scope
+	}
+
+	var active *bool
+	if activeStr := r.URL.Query().Get("active"); activeStr != "" {
+		activeBool := activeStr == "true" || activeStr == "1"
+		active = &activeBool
+	}
+
+	queues, err := h.db.ListQueues(ctx, domainID, active)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list queues", err)
+		return
+	}
+
+	supervised, err := h.supervisedQueues(r)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list supervised queues", err)
+		return
+	}
+	if supervised != nil {
+		visible := []*models.Queue{}
+		for _, queue := range queues {
+			if supervised[queue.ID] {
+				visible = append(visible, queue)
+			}
+		}
+		queues = visible
+	}
+
+	respondJSON(w, http.StatusOK, queues)
+}
+
+// Get handles GET /api/v1/queues/{id}
+func (h *QueueHandler) Get(w http.ResponseWriter, r *http.Request) {
+	queue, ok := h.loadQueue(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, queue)
+}
+
+// Agents handles GET /api/v1/queues/{id}/agents
+func (h *QueueHandler) Agents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	queue, ok := h.loadQueue(w, r)
+	if !ok {
+		return
+	}
+
+	active := true
+	agents, err := h.db.ListQueueAgents(ctx, queue.ID, &active)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list queue agents", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, agents)
+}
+
+// loadQueue loads the queue from the {id} path variable, hiding queues the
+// caller may not see
+func (h *QueueHandler) loadQueue(w http.ResponseWriter, r *http.Request) (*models.Queue, bool) {
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid queue ID", err)
+		return nil, false
+	}
+
+	queue, err := h.db.GetQueue(r.Context(), id)
+	if err != nil || !canAccessDomain(r, queue.DomainID) {
+		respondError(w, http.StatusNotFound, "Queue not found", err)
+		return nil, false
+	}
+
+	supervised, err := h.supervisedQueues(r)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list supervised queues", err)
+		return nil, false
+	}
+	if supervised != nil && !supervised[queue.ID] {
+		respondError(w, http.StatusNotFound, "Queue not found", nil)
+		return nil, false
+	}
+
+	return queue, true
+}
+
+// supervisedQueues returns the set of queues a supervisor may see, or nil if
+// the caller is not restricted to supervised queues
+func (h *QueueHandler) supervisedQueues(r *http.Request) (map[int64]bool, error) {
+	principal := middleware.PrincipalFromContext(r.Context())
+	if principal == nil || principal.Type != middleware.PrincipalUser || principal.Role != models.RoleSupervisor {
+		return nil, nil
+	}
+
+	queueIDs, err := h.db.ListSupervisedQueueIDs(r.Context(), principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	supervised := make(map[int64]bool, len(queueIDs))
+	for _, id := range queueIDs {
+		supervised[id] = true
+	}
+	return supervised, nil
+}
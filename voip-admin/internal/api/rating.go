@@ -0,0 +1,693 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// maxRateImportRows limits the number of prefixes per rate deck import
+const maxRateImportRows = 100000
+
+// ratePrefixPattern matches a rate prefix: the digits of a number without
+// its leading + or 00
+var ratePrefixPattern = regexp.MustCompile(`^[0-9]{1,20}$`)
+
+// currencyPattern matches an ISO 4217 currency code
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// rateCSVColumns is the column order of rate deck CSV exports. increment
+// is "initial/next" in seconds, e.g. 60/6; a single number is used for
+// both.
+var rateCSVColumns = []string{"prefix", "description", "rate", "connection_fee", "increment"}
+
+// rateCSVRequired are the columns a rate deck import CSV must have
+var rateCSVRequired = []string{"prefix", "rate"}
+
+// RateDeckHandler handles rate deck HTTP requests. Decks price the calls of
+// every domain, so domain-scoped API keys cannot manage them.
+type RateDeckHandler struct {
+	db *database.DB
+}
+
+// NewRateDeckHandler creates a new rate deck handler
+func NewRateDeckHandler(db *database.DB) *RateDeckHandler {
+	return &RateDeckHandler{
+		db: db,
+	}
+}
+
+// List handles GET /api/v1/rate-decks
+func (h *RateDeckHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !h.unscoped(w, r) {
+		return
+	}
+
+	var deckType *string
+	if t := r.URL.Query().Get("type"); t != "" {
+		if t != models.RateDeckBuy && t != models.RateDeckSell {
+			respondError(w, http.StatusBadRequest, "Validation failed", errValidation("type must be buy or sell"))
+			return
+		}
+		deckType = &t
+	}
+
+	decks, err := h.db.ListRateDecks(r.Context(), deckType)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list rate decks", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, decks)
+}
+
+// Get handles GET /api/v1/rate-decks/{id}
+func (h *RateDeckHandler) Get(w http.ResponseWriter, r *http.Request) {
+	deck, ok := h.loadRateDeck(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, deck)
+}
+
+// Create handles POST /api/v1/rate-decks
+func (h *RateDeckHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.unscoped(w, r) {
+		return
+	}
+
+	var req models.RateDeckCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.Type != models.RateDeckBuy && req.Type != models.RateDeckSell {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("type must be buy or sell"))
+		return
+	}
+	if req.Currency == "" {
+		req.Currency = models.DefaultCurrency
+	}
+
+	deck := &models.RateDeck{
+		Name:        strings.TrimSpace(req.Name),
+		Type:        req.Type,
+		Currency:    strings.ToUpper(req.Currency),
+		Description: req.Description,
+	}
+	if err := validateRateDeck(deck); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	if _, err := h.db.GetRateDeckByName(ctx, deck.Name); err == nil {
+		respondError(w, http.StatusConflict, "Rate deck already exists", nil)
+		return
+	}
+
+	deck, err := h.db.CreateRateDeck(ctx, deck)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create rate deck", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditCreate, "rate-decks", strconv.FormatInt(deck.ID, 10), nil, nil, deck)
+
+	respondJSON(w, http.StatusCreated, deck)
+}
+
+// Update handles PUT /api/v1/rate-decks/{id}
+// New prices apply to calls rated after the change.
+func (h *RateDeckHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current, ok := h.loadRateDeck(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.RateDeckUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	candidate := *current
+	if req.Name != nil {
+		candidate.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Currency != nil {
+		candidate.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.Description != nil {
+		candidate.Description = *req.Description
+	}
+
+	if err := validateRateDeck(&candidate); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	if candidate.Name != current.Name {
+		if _, err := h.db.GetRateDeckByName(ctx, candidate.Name); err == nil {
+			respondError(w, http.StatusConflict, "Rate deck already exists", nil)
+			return
+		}
+	}
+
+	deck, err := h.db.UpdateRateDeck(ctx, &candidate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update rate deck", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "rate-decks", strconv.FormatInt(deck.ID, 10), nil, current, deck)
+
+	respondJSON(w, http.StatusOK, deck)
+}
+
+// Delete handles DELETE /api/v1/rate-decks/{id}
+// Decks still assigned to trunks or domains are only deleted with
+// ?force=true; their calls are no longer rated afterwards.
+func (h *RateDeckHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	deck, ok := h.loadRateDeck(w, r)
+	if !ok {
+		return
+	}
+
+	force := r.URL.Query().Get("force") == "true"
+	if (deck.Trunks > 0 || deck.Domains > 0) && !force {
+		respondJSON(w, http.StatusConflict, &models.APIResponse{
+			Success: false,
+			Message: "Rate deck is assigned to trunks or domains; their calls are no longer rated if it is deleted. Retry with ?force=true",
+			Data:    deck,
+			Error: &models.APIError{
+				Code:    "E409",
+				Message: "Rate deck is in use",
+			},
+		})
+		return
+	}
+
+	if err := h.db.DeleteRateDeck(r.Context(), deck.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete rate deck", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "rate-decks", strconv.FormatInt(deck.ID, 10), nil, deck, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Rates handles GET /api/v1/rate-decks/{id}/rates
+// ?prefix= lists the prefixes starting with the given digits.
+func (h *RateDeckHandler) Rates(w http.ResponseWriter, r *http.Request) {
+	deck, ok := h.loadRateDeck(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page, perPage := 1, 50
+
+	if pageStr := query.Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if perPageStr := query.Get("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 1000 {
+			perPage = pp
+		}
+	}
+
+	prefix := strings.TrimPrefix(query.Get("prefix"), "+")
+	if prefix != "" && !ratePrefixPattern.MatchString(prefix) {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("prefix must be 1-20 digits"))
+		return
+	}
+
+	result, err := h.db.ListRates(r.Context(), deck.ID, prefix, page, perPage)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list rates", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// Import handles POST /api/v1/rate-decks/{id}/rates/import
+// Accepts a CSV file (Content-Type: text/csv) or a JSON array of rates.
+// Every row is validated; with ?dry_run=true only the validation report is
+// returned. Otherwise the rates are written in one transaction, or none if
+// any row is invalid: new prefixes are added and existing ones updated.
+// With ?replace=true prefixes missing from the file are deleted.
+func (h *RateDeckHandler) Import(w http.ResponseWriter, r *http.Request) {
+	deck, ok := h.loadRateDeck(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	dryRun := query.Get("dry_run") == "true" || query.Get("dry_run") == "1"
+	replace := query.Get("replace") == "true" || query.Get("replace") == "1"
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodyBytes)
+
+	var rates []*models.Rate
+	var parseErrors []*models.RateImportError
+	var err error
+
+	if strings.Contains(r.Header.Get("Content-Type"), "csv") {
+		rates, parseErrors, err = parseRateCSV(body)
+	} else {
+		err = json.NewDecoder(body).Decode(&rates)
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid import file", err)
+		return
+	}
+
+	if len(rates) == 0 {
+		respondError(w, http.StatusBadRequest, "Import file contains no rates", nil)
+		return
+	}
+	if len(rates) > maxRateImportRows {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Import is limited to %d rates", maxRateImportRows), nil)
+		return
+	}
+
+	result := &models.RateImportResult{
+		DryRun:  dryRun,
+		Replace: replace,
+		Total:   len(rates),
+		Errors:  parseErrors,
+	}
+
+	// CSV rows that failed to parse are already reported
+	reported := make(map[int]bool, len(parseErrors))
+	for _, parseError := range parseErrors {
+		reported[parseError.Row] = true
+	}
+
+	seen := make(map[string]int)
+	for i, rate := range rates {
+		row := i + 1
+		if rate == nil {
+			if !reported[row] {
+				result.Errors = append(result.Errors, &models.RateImportError{Row: row, Error: "row is empty"})
+			}
+			continue
+		}
+
+		rate.Prefix = strings.TrimPrefix(strings.TrimSpace(rate.Prefix), "+")
+		if rate.InitialIncrement == 0 {
+			rate.InitialIncrement = 60
+		}
+		if rate.Increment == 0 {
+			rate.Increment = rate.InitialIncrement
+		}
+
+		if err := validateRate(rate); err != nil {
+			result.Errors = append(result.Errors, &models.RateImportError{
+				Row:    row,
+				Prefix: rate.Prefix,
+				Error:  strings.TrimPrefix(err.Error(), "validation error: "),
+			})
+			continue
+		}
+
+		if first, dup := seen[rate.Prefix]; dup {
+			result.Errors = append(result.Errors, &models.RateImportError{
+				Row:    row,
+				Prefix: rate.Prefix,
+				Error:  fmt.Sprintf("duplicate of row %d", first),
+			})
+			continue
+		}
+		seen[rate.Prefix] = row
+	}
+
+	if dryRun {
+		respondJSON(w, http.StatusOK, result)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		respondJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	if err := h.db.ImportRates(r.Context(), deck.ID, rates, replace); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to import rates", err)
+		return
+	}
+	result.Imported = len(rates)
+
+	recordAudit(r, h.db, models.AuditImport, "rate-decks", strconv.FormatInt(deck.ID, 10), nil, nil,
+		map[string]interface{}{"rates": len(rates), "replace": replace})
+
+	respondJSON(w, http.StatusCreated, result)
+}
+
+// Export handles GET /api/v1/rate-decks/{id}/rates/export
+// Writes all rates of the deck as CSV in the import format.
+func (h *RateDeckHandler) Export(w http.ResponseWriter, r *http.Request) {
+	deck, ok := h.loadRateDeck(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.db.ListRates(r.Context(), deck.ID, "", 1, 0)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to export rates", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="rate-deck-%d.csv"`, deck.ID))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write(rateCSVColumns)
+	for _, rate := range result.Rates {
+		writer.Write([]string{
+			rate.Prefix, rate.Description,
+			strconv.FormatFloat(rate.Rate, 'f', -1, 64), strconv.FormatFloat(rate.ConnectionFee, 'f', -1, 64),
+			fmt.Sprintf("%d/%d", rate.InitialIncrement, rate.Increment),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("[RateDecks] Failed to write CSV export: %v", err)
+	}
+}
+
+// loadRateDeck loads the {id} rate deck
+func (h *RateDeckHandler) loadRateDeck(w http.ResponseWriter, r *http.Request) (*models.RateDeck, bool) {
+	if !h.unscoped(w, r) {
+		return nil, false
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid rate deck ID", err)
+		return nil, false
+	}
+
+	deck, err := h.db.GetRateDeck(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Rate deck not found", err)
+		return nil, false
+	}
+
+	return deck, true
+}
+
+// unscoped responds 403 to domain-scoped callers
+func (h *RateDeckHandler) unscoped(w http.ResponseWriter, r *http.Request) bool {
+	if domainScope(r) != nil {
+		respondError(w, http.StatusForbidden, "Domain-scoped API keys cannot manage rate decks", nil)
+		return false
+	}
+	return true
+}
+
+// validateRateDeck checks the fields of a rate deck
+func validateRateDeck(deck *models.RateDeck) error {
+	if deck.Name == "" || len(deck.Name) > 100 {
+		return errValidation("name must be 1-100 characters")
+	}
+	if !currencyPattern.MatchString(deck.Currency) {
+		return errValidation("currency must be a 3-letter ISO 4217 code, e.g. USD")
+	}
+	if len(deck.Description) > 255 {
+		return errValidation("description must be at most 255 characters")
+	}
+	return nil
+}
+
+// validateRate checks the fields of a rate
+func validateRate(rate *models.Rate) error {
+	if !ratePrefixPattern.MatchString(rate.Prefix) {
+		return errValidation("prefix must be 1-20 digits")
+	}
+	if len(rate.Description) > 255 {
+		return errValidation("description must be at most 255 characters")
+	}
+	if rate.Rate < 0 || rate.Rate >= 1000000 {
+		return errValidation("rate must be between 0 and 999999")
+	}
+	if rate.ConnectionFee < 0 || rate.ConnectionFee >= 1000000 {
+		return errValidation("connection_fee must be between 0 and 999999")
+	}
+	if rate.InitialIncrement < 1 || rate.InitialIncrement > 3600 || rate.Increment < 1 || rate.Increment > 3600 {
+		return errValidation("increments must be between 1 and 3600 seconds")
+	}
+	return nil
+}
+
+// checkRateDeck checks that an assigned rate deck exists and has the given
+// type; a nil ID removes the assignment
+func checkRateDeck(r *http.Request, db *database.DB, id *int64, deckType string) error {
+	if id == nil {
+		return nil
+	}
+
+	deck, err := db.GetRateDeck(r.Context(), *id)
+	if err != nil {
+		return errValidation("rate deck not found")
+	}
+	if deck.Type != deckType {
+		return errValidation("rate deck must be a " + deckType + " deck")
+	}
+
+	return nil
+}
+
+// parseRateCSV reads rates from a CSV file with a header row. Rows that
+// cannot be parsed are returned as nil rates with a matching import error,
+// so row numbers stay aligned.
+func parseRateCSV(body io.Reader) ([]*models.Rate, []*models.RateImportError, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	known := make(map[string]bool, len(rateCSVColumns))
+	for _, name := range rateCSVColumns {
+		known[name] = true
+	}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !known[name] {
+			return nil, nil, fmt.Errorf("unknown CSV column: %s", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range rateCSVRequired {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("missing CSV column: %s", name)
+		}
+	}
+
+	var rates []*models.Rate
+	var parseErrors []*models.RateImportError
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return nil, nil, fmt.Errorf("read CSV: %w", err)
+			}
+			rates = append(rates, nil)
+			parseErrors = append(parseErrors, &models.RateImportError{Row: len(rates), Error: err.Error()})
+			continue
+		}
+
+		rate, err := parseRateCSVRecord(record, columns)
+		rates = append(rates, rate)
+		if err != nil {
+			parseErrors = append(parseErrors, &models.RateImportError{Row: len(rates), Error: err.Error()})
+		}
+		if len(rates) > maxRateImportRows {
+			break
+		}
+	}
+
+	return rates, parseErrors, nil
+}
+
+// parseRateCSVRecord converts one CSV record; it returns a nil rate if a
+// field has the wrong type
+func parseRateCSVRecord(record []string, columns map[string]int) (*models.Rate, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rate := &models.Rate{
+		Prefix:      field("prefix"),
+		Description: field("description"),
+	}
+
+	var err error
+	if rate.Rate, err = strconv.ParseFloat(field("rate"), 64); err != nil {
+		return nil, fmt.Errorf("rate must be a number")
+	}
+	if v := field("connection_fee"); v != "" {
+		if rate.ConnectionFee, err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("connection_fee must be a number")
+		}
+	}
+	if v := field("increment"); v != "" {
+		initial, next, found := strings.Cut(v, "/")
+		if rate.InitialIncrement, err = strconv.Atoi(strings.TrimSpace(initial)); err != nil {
+			return nil, fmt.Errorf("increment must be seconds, e.g. 60/6 or 60")
+		}
+		rate.Increment = rate.InitialIncrement
+		if found {
+			if rate.Increment, err = strconv.Atoi(strings.TrimSpace(next)); err != nil {
+				return nil, fmt.Errorf("increment must be seconds, e.g. 60/6 or 60")
+			}
+		}
+	}
+
+	return rate, nil
+}
+
+// InvoiceHandler handles invoice HTTP requests. Invoices sum the sell cost
+// of the rated outbound calls of a domain per calendar month.
+type InvoiceHandler struct {
+	db *database.DB
+}
+
+// NewInvoiceHandler creates a new invoice handler
+func NewInvoiceHandler(db *database.DB) *InvoiceHandler {
+	return &InvoiceHandler{
+		db: db,
+	}
+}
+
+// List handles GET /api/v1/invoices
+// ?month=YYYY-MM (default: the current month) and ?domain_id= filter the
+// summaries. Scoped callers only see their own domain.
+func (h *InvoiceHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	month := time.Now().UTC()
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if monthStr := query.Get("month"); monthStr != "" {
+		var err error
+		if month, err = parseInvoiceMonth(monthStr); err != nil {
+			respondError(w, http.StatusBadRequest, "Validation failed", err)
+			return
+		}
+	}
+
+	var domainID *int64
+	if domainIDStr := query.Get("domain_id"); domainIDStr != "" {
+		if id, err := strconv.ParseInt(domainIDStr, 10, 64); err == nil {
+			domainID = &id
+		}
+	}
+	if scope := domainScope(r); scope != nil {
+		domainID = scope
+	}
+
+	invoices, err := h.db.ListInvoices(r.Context(), domainID, month)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list invoices", err)
+		return
+	}
+
+	for _, inv := range invoices {
+		presentInvoice(r, inv)
+	}
+
+	respondJSON(w, http.StatusOK, invoices)
+}
+
+// Get handles GET /api/v1/invoices/{domain_id}/{month}
+// Returns the invoice of a domain with one line per destination prefix.
+func (h *InvoiceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	domainID, err := strconv.ParseInt(vars["domain_id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+		return
+	}
+
+	month, err := parseInvoiceMonth(vars["month"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	domain, err := h.db.GetDomain(r.Context(), domainID)
+	if err != nil || !canAccessDomain(r, domainID) {
+		respondError(w, http.StatusNotFound, "Domain not found", err)
+		return
+	}
+
+	inv, err := h.db.GetInvoice(r.Context(), domain, month)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get invoice", err)
+		return
+	}
+
+	presentInvoice(r, inv)
+
+	respondJSON(w, http.StatusOK, inv)
+}
+
+// presentInvoice adds the margin of an invoice for unscoped callers and
+// hides the carrier cost from tenants
+func presentInvoice(r *http.Request, inv *models.Invoice) {
+	if domainScope(r) != nil {
+		inv.Cost = nil
+		for _, line := range inv.Lines {
+			line.Cost = nil
+		}
+		return
+	}
+
+	if inv.Cost != nil {
+		margin := inv.Amount - *inv.Cost
+		inv.Margin = &margin
+	}
+}
+
+// parseInvoiceMonth parses a YYYY-MM month into its first instant in UTC
+func parseInvoiceMonth(s string) (time.Time, error) {
+	month, err := time.Parse("2006-01", s)
+	if err != nil {
+		return time.Time{}, errValidation("month must be YYYY-MM")
+	}
+	return month, nil
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/middleware"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// RecordingHandler handles call recording HTTP requests
+type RecordingHandler struct {
+	db      *database.DB
+	storage string // Recordings root as mounted on this host
+}
+
+// NewRecordingHandler creates a new recording handler serving files from
+// the storage directory
+func NewRecordingHandler(db *database.DB, storage string) *RecordingHandler {
+	return &RecordingHandler{
+		db:      db,
+		storage: storage,
+	}
+}
+
+// List handles GET /api/v1/recordings
+// Accepts the CDR filters of GET /api/v1/cdr and legal_hold=true|false.
+func (h *RecordingHandler) List(w http.ResponseWriter, r *http.Request) {
+	req := &models.RecordingListRequest{CDRListRequest: *parseCDRListRequest(r)}
+
+	if legalHoldStr := r.URL.Query().Get("legal_hold"); legalHoldStr != "" {
+		legalHold, err := strconv.ParseBool(legalHoldStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid legal_hold", err)
+			return
+		}
+		req.LegalHold = &legalHold
+	}
+
+	// Scoped callers only ever see their own domain
+	scoped, err := scopedDomainName(r, h.db)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to resolve domain scope", err)
+		return
+	}
+	if scoped != nil {
+		req.Domain = scoped
+	}
+
+	result, err := h.db.ListRecordings(r.Context(), req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list recordings", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// Get handles GET /api/v1/recordings/{id}
+func (h *RecordingHandler) Get(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.loadRecording(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rec)
+}
+
+// Download handles GET /api/v1/recordings/{id}/download
+// Streams the audio file; Range requests are supported for seeking.
+func (h *RecordingHandler) Download(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.loadRecording(w, r)
+	if !ok {
+		return
+	}
+
+	file, ok := rec.LocalPath(h.storage)
+	if !ok {
+		log.Printf("[Recordings] Recording %d is outside the storage %s: %s", rec.ID, h.storage, rec.FilePath)
+		respondError(w, http.StatusNotFound, "Recording file not found", nil)
+		return
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Recording file not found", err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to read recording file", err)
+		return
+	}
+
+	// Players fetch a recording in many ranges; audit the first one only
+	if rangeHeader := r.Header.Get("Range"); rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-") {
+		recordAudit(r, h.db, models.AuditDownload, "recordings", strconv.FormatInt(rec.ID, 10), rec.DomainID, nil, nil)
+	}
+
+	contentType, ok := models.AudioContentTypes[rec.Format]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	name := fmt.Sprintf("%s.%s", rec.CallUUID, rec.Format)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+// LegalHold handles PUT /api/v1/recordings/{id}/legal-hold
+// Recordings on legal hold are kept past the retention of their policy.
+func (h *RecordingHandler) LegalHold(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadRecording(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.RecordingLegalHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if len(req.Reason) > 255 {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("reason must be at most 255 characters"))
+		return
+	}
+	if req.LegalHold && strings.TrimSpace(req.Reason) == "" {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("reason is required to place a legal hold"))
+		return
+	}
+
+	var by string
+	if principal := middleware.PrincipalFromContext(r.Context()); principal != nil {
+		by = principal.Name
+	}
+
+	rec, err := h.db.SetRecordingLegalHold(r.Context(), current.ID, req.LegalHold, strings.TrimSpace(req.Reason), by)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update legal hold", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "recordings", strconv.FormatInt(rec.ID, 10), rec.DomainID, current, rec)
+
+	respondJSON(w, http.StatusOK, rec)
+}
+
+// loadRecording loads the {id} recording, hiding recordings outside the
+// caller's scope. Recordings without a domain are only visible unscoped.
+func (h *RecordingHandler) loadRecording(w http.ResponseWriter, r *http.Request) (*models.Recording, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid recording ID", err)
+		return nil, false
+	}
+
+	rec, err := h.db.GetRecording(r.Context(), id)
+	if err == nil && domainScope(r) != nil && (rec.DomainID == nil || !canAccessDomain(r, *rec.DomainID)) {
+		err = fmt.Errorf("recording not found: %d", id)
+	}
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Recording not found", err)
+		return nil, false
+	}
+
+	return rec, true
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/kamailio"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// natBranchFlag is the usrloc nat_bflag of kamailio.cfg, stored in cflags
+const natBranchFlag = 1 << 5
+
+// RegistrationHandler handles SIP registration HTTP requests. Contacts are
+// read from Kamailio's location table; force-unregister goes over JSON-RPC
+// to every Kamailio node.
+type RegistrationHandler struct {
+	db       *database.DB
+	kamailio *kamailio.Client
+}
+
+// NewRegistrationHandler creates a new registration handler
+func NewRegistrationHandler(db *database.DB, kamailioClient *kamailio.Client) *RegistrationHandler {
+	return &RegistrationHandler{
+		db:       db,
+		kamailio: kamailioClient,
+	}
+}
+
+// ListDomain handles GET /api/v1/domains/{id}/registrations
+// Supports ?flag=expired|nated|duplicate to only list flagged contacts.
+func (h *RegistrationHandler) ListDomain(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+		return
+	}
+
+	if _, err := h.db.GetDomain(r.Context(), id); err != nil || !canAccessDomain(r, id) {
+		respondError(w, http.StatusNotFound, "Domain not found", err)
+		return
+	}
+
+	flag := r.URL.Query().Get("flag")
+	if flag != "" && flag != "expired" && flag != "nated" && flag != "duplicate" {
+		respondError(w, http.StatusBadRequest, "Validation failed",
+			errValidation("flag must be expired, nated or duplicate"))
+		return
+	}
+
+	registrations, err := h.db.ListRegistrations(r.Context(), id, nil)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list registrations", err)
+		return
+	}
+
+	list := flagRegistrations(registrations)
+	if flag != "" {
+		filtered := []*models.Registration{}
+		for _, reg := range list.Registrations {
+			if (flag == "expired" && reg.Expired) || (flag == "nated" && reg.NATed) ||
+				(flag == "duplicate" && reg.Duplicate) {
+				filtered = append(filtered, reg)
+			}
+		}
+		list.Registrations = filtered
+	}
+
+	respondJSON(w, http.StatusOK, list)
+}
+
+// ListExtension handles GET /api/v1/extensions/{id}/registrations
+func (h *RegistrationHandler) ListExtension(w http.ResponseWriter, r *http.Request) {
+	_, registrations, ok := h.loadRegistrations(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, flagRegistrations(registrations))
+}
+
+// Unregister handles DELETE /api/v1/extensions/{id}/registrations
+// Removes all contacts of the extension from Kamailio; devices must
+// register again to receive calls.
+func (h *RegistrationHandler) Unregister(w http.ResponseWriter, r *http.Request) {
+	ext, registrations, ok := h.loadRegistrations(w, r)
+	if !ok {
+		return
+	}
+
+	// Kamailio's memory may be ahead of the location table, so the AOR is
+	// tried even without stored contacts
+	aors := []string{ext.Extension + "@" + ext.Domain}
+	for _, reg := range registrations {
+		if !containsString(aors, reg.AOR) {
+			aors = append(aors, reg.AOR)
+		}
+	}
+
+	removed := false
+	for _, aor := range aors {
+		err := h.kamailio.RemoveAOR(r.Context(), aor)
+		if errors.Is(err, kamailio.ErrNotRegistered) {
+			continue
+		}
+		if err != nil {
+			respondError(w, http.StatusBadGateway, "Failed to unregister on Kamailio", err)
+			return
+		}
+		removed = true
+	}
+	if !removed {
+		respondError(w, http.StatusNotFound, "Extension is not registered", nil)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "registrations", strconv.FormatInt(ext.ID, 10), &ext.DomainID,
+		registrations, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnregisterContact handles DELETE /api/v1/extensions/{id}/registrations/{ruid}
+// Removes a single contact, e.g. a stale duplicate binding.
+func (h *RegistrationHandler) UnregisterContact(w http.ResponseWriter, r *http.Request) {
+	ext, registrations, ok := h.loadRegistrations(w, r)
+	if !ok {
+		return
+	}
+
+	ruid := mux.Vars(r)["ruid"]
+	var reg *models.Registration
+	for _, candidate := range registrations {
+		if candidate.RUID == ruid {
+			reg = candidate
+			break
+		}
+	}
+	if reg == nil {
+		respondError(w, http.StatusNotFound, "Registration not found", nil)
+		return
+	}
+
+	err := h.kamailio.RemoveContact(r.Context(), reg.AOR, reg.Contact)
+	if errors.Is(err, kamailio.ErrNotRegistered) {
+		respondError(w, http.StatusNotFound, "Contact is no longer registered", err)
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to unregister on Kamailio", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "registrations", strconv.FormatInt(ext.ID, 10), &ext.DomainID,
+		reg, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadRegistrations loads the {id} extension and its contacts
+func (h *RegistrationHandler) loadRegistrations(w http.ResponseWriter, r *http.Request) (*models.Extension, []*models.Registration, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid extension ID", err)
+		return nil, nil, false
+	}
+
+	ext, err := h.db.GetExtensionByID(r.Context(), id)
+	if err != nil || !canAccessDomain(r, ext.DomainID) {
+		respondError(w, http.StatusNotFound, "Extension not found", err)
+		return nil, nil, false
+	}
+
+	registrations, err := h.db.ListRegistrations(r.Context(), ext.DomainID, &ext.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list registrations", err)
+		return nil, nil, false
+	}
+
+	return ext, registrations, true
+}
+
+// flagRegistrations sets the expired, NAT and duplicate flags of contacts
+// ordered newest first per extension, and counts them
+func flagRegistrations(registrations []*models.Registration) *models.RegistrationList {
+	list := &models.RegistrationList{Registrations: registrations, Total: len(registrations)}
+
+	registered := make(map[int64]bool)
+	devices := make(map[string]bool)
+	for _, reg := range registrations {
+		reg.Source = sipHostPort(reg.Received)
+		if reg.Source == "" {
+			reg.Source = sipHostPort(reg.Contact)
+		}
+
+		reg.Expired = reg.ExpiresIn < 0
+		reg.NATed = reg.Received != "" || reg.CFlags&natBranchFlag != 0
+
+		if !reg.Expired {
+			registered[reg.ExtensionID] = true
+
+			// The newest binding of a device is kept, older ones are
+			// duplicates
+			device := strconv.FormatInt(reg.ExtensionID, 10) + " "
+			if reg.Instance != "" {
+				device += "instance " + reg.Instance
+			} else {
+				host, _, err := net.SplitHostPort(reg.Source)
+				if err != nil {
+					host = reg.Source
+				}
+				device += "source " + host + " " + reg.UserAgent
+			}
+			reg.Duplicate = devices[device]
+			devices[device] = true
+		}
+
+		if reg.Expired {
+			list.Expired++
+		}
+		if reg.NATed {
+			list.NATed++
+		}
+		if reg.Duplicate {
+			list.Duplicate++
+		}
+	}
+	list.Registered = len(registered)
+
+	return list
+}
+
+// sipHostPort returns the host:port of a SIP URI, e.g. 192.0.2.10:5060 for
+// <sip:1001@192.0.2.10:5060;transport=udp>
+func sipHostPort(uri string) string {
+	uri = strings.Trim(strings.TrimSpace(uri), "<>")
+	if i := strings.Index(uri, ":"); i >= 0 && strings.HasPrefix(strings.ToLower(uri[:i]), "sip") {
+		uri = uri[i+1:]
+	}
+	if i := strings.LastIndex(uri, "@"); i >= 0 {
+		uri = uri[i+1:]
+	}
+	if i := strings.IndexAny(uri, ";?>"); i >= 0 {
+		uri = uri[:i]
+	}
+	return uri
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/middleware"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// domainScope returns the domain the caller is restricted to, or nil if the
+// caller may access all domains
+func domainScope(r *http.Request) *int64 {
+	principal := middleware.PrincipalFromContext(r.Context())
+	if principal == nil {
+		return nil
+	}
+	return principal.DomainID
+}
+
+// canAccessDomain reports whether the caller may access objects of a domain.
+// Handlers answer 404 rather than 403 so scoped keys cannot probe for
+// objects of other tenants.
+func canAccessDomain(r *http.Request, domainID int64) bool {
+	scope := domainScope(r)
+	return scope == nil || *scope == domainID
+}
+
+// scopedDomainName returns the domain name the caller is restricted to, or
+// nil. CDRs record the domain by name, so the scope ID is resolved first.
+func scopedDomainName(r *http.Request, db *database.DB) (*string, error) {
+	scope := domainScope(r)
+	if scope == nil {
+		return nil, nil
+	}
+
+	domain, err := db.GetDomain(r.Context(), *scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Domain, nil
+}
+
+// canManageAgent reports whether the caller may view or change the state of
+// an agent extension. Agents may only act on their own extensions, and
+// supervisors on agents of the queues they supervise; API keys and admins
+// are limited by domain only.
+func canManageAgent(r *http.Request, db *database.DB, ext *models.Extension) (bool, error) {
+	principal := middleware.PrincipalFromContext(r.Context())
+	if principal == nil || principal.Type != middleware.PrincipalUser {
+		return true, nil
+	}
+
+	switch principal.Role {
+	case models.RoleAdmin:
+		return true, nil
+	case models.RoleSupervisor:
+		return db.IsSupervisedAgent(r.Context(), principal.UserID, ext.ID)
+	case models.RoleAgent:
+		return ext.UserID != nil && *ext.UserID == principal.UserID, nil
+	default:
+		return false, nil
+	}
+}
+
+// ownsExtension reports whether the caller is the logged-in user the
+// extension belongs to
+func ownsExtension(r *http.Request, ext *models.Extension) bool {
+	principal := middleware.PrincipalFromContext(r.Context())
+	return principal != nil && principal.Type == middleware.PrincipalUser &&
+		ext.UserID != nil && *ext.UserID == principal.UserID
+}
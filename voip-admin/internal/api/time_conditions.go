@@ -0,0 +1,481 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+var (
+	// clockPattern matches a schedule time (HH:MM); 24:00 is only valid as end
+	clockPattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+	// mailboxPattern matches a voicemail box number
+	mailboxPattern = regexp.MustCompile(`^[0-9]{2,20}$`)
+
+	// soundPathPattern matches a FreeSWITCH sound file, relative to the
+	// sounds directory or absolute
+	soundPathPattern = regexp.MustCompile(`^[A-Za-z0-9_/][A-Za-z0-9_./-]{0,254}$`)
+)
+
+// Limits of the lists of one time condition
+const (
+	maxTimeRules                 = 100
+	maxHolidays                  = 366
+	maxTimeConditionDestinations = 100
+)
+
+// TimeConditionHandler handles time condition HTTP requests
+type TimeConditionHandler struct {
+	db *database.DB
+}
+
+// NewTimeConditionHandler creates a new time condition handler
+func NewTimeConditionHandler(db *database.DB) *TimeConditionHandler {
+	return &TimeConditionHandler{
+		db: db,
+	}
+}
+
+// List handles GET /api/v1/time-conditions
+func (h *TimeConditionHandler) List(w http.ResponseWriter, r *http.Request) {
+	var domainID *int64
+	if domainIDStr := r.URL.Query().Get("domain_id"); domainIDStr != "" {
+		id, err := strconv.ParseInt(domainIDStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+			return
+		}
+		domainID = &id
+	}
+
+	// Scoped callers only see their own domain
+	if scope := domainScope(r); scope != nil {
+		if domainID != nil && *domainID != *scope {
+			respondJSON(w, http.StatusOK, []*models.TimeCondition{})
+			return
+		}
+		domainID = scope
+	}
+
+	conditions, err := h.db.ListTimeConditions(r.Context(), domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list time conditions", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, conditions)
+}
+
+// Get handles GET /api/v1/time-conditions/{id}
+func (h *TimeConditionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tc, ok := h.loadTimeCondition(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tc)
+}
+
+// Create handles POST /api/v1/time-conditions
+func (h *TimeConditionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.TimeConditionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.DomainID == 0 {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("domain_id is required"))
+		return
+	}
+	domain, err := h.db.GetDomain(ctx, req.DomainID)
+	if err != nil || !canAccessDomain(r, req.DomainID) {
+		respondError(w, http.StatusBadRequest, "Domain not found", err)
+		return
+	}
+
+	if !h.checkRequest(w, r, &req, domain, 0) {
+		return
+	}
+
+	tc, err := h.db.CreateTimeCondition(ctx, &req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create time condition", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditCreate, "time-conditions", strconv.FormatInt(tc.ID, 10), &tc.DomainID, nil, tc)
+
+	respondJSON(w, http.StatusCreated, tc)
+}
+
+// Update handles PUT /api/v1/time-conditions/{id}
+// Replaces the whole condition, including schedule, holidays and destinations.
+func (h *TimeConditionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	current, ok := h.loadTimeCondition(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.TimeConditionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	domain, err := h.db.GetDomain(ctx, current.DomainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get domain", err)
+		return
+	}
+
+	if !h.checkRequest(w, r, &req, domain, current.ID) {
+		return
+	}
+
+	tc, err := h.db.UpdateTimeCondition(ctx, current.ID, &req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update time condition", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "time-conditions", strconv.FormatInt(tc.ID, 10), &tc.DomainID, current, tc)
+
+	respondJSON(w, http.StatusOK, tc)
+}
+
+// Override handles PUT /api/v1/time-conditions/{id}/override
+// Forces the condition open or closed; an empty override follows the
+// schedule again.
+func (h *TimeConditionHandler) Override(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	current, ok := h.loadTimeCondition(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.TimeConditionOverride
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.Override != "" && req.Override != models.OverrideOpen && req.Override != models.OverrideClosed {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("override must be one of: open, closed, or empty"))
+		return
+	}
+
+	if err := h.db.SetTimeConditionOverride(ctx, current.ID, req.Override); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update override", err)
+		return
+	}
+
+	tc, err := h.db.GetTimeCondition(ctx, current.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get time condition", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "time-conditions", strconv.FormatInt(tc.ID, 10), &tc.DomainID,
+		map[string]string{"override": current.Override}, map[string]string{"override": tc.Override})
+
+	respondJSON(w, http.StatusOK, tc)
+}
+
+// Delete handles DELETE /api/v1/time-conditions/{id}
+func (h *TimeConditionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadTimeCondition(w, r)
+	if !ok {
+		return
+	}
+
+	// DIDs reference the time condition; they must be moved first
+	dids, err := h.db.CountTimeConditionDIDs(r.Context(), current.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete time condition", err)
+		return
+	}
+	if dids > 0 {
+		respondError(w, http.StatusConflict, fmt.Sprintf("Time condition is used by %d DIDs", dids), nil)
+		return
+	}
+
+	if err := h.db.DeleteTimeCondition(r.Context(), current.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete time condition", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "time-conditions", strconv.FormatInt(current.ID, 10), &current.DomainID, current, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Evaluate handles GET /api/v1/time-conditions/evaluate?domain_id=1&number=8000&at=...
+// Reports what happens to a call to a number at a time (RFC 3339, default
+// now): routed normally, or the closed action of its time condition.
+func (h *TimeConditionHandler) Evaluate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	var domainID int64
+	if domainIDStr := query.Get("domain_id"); domainIDStr != "" {
+		id, err := strconv.ParseInt(domainIDStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+			return
+		}
+		domainID = id
+	} else if scope := domainScope(r); scope != nil {
+		domainID = *scope
+	} else {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("domain_id is required"))
+		return
+	}
+
+	number := query.Get("number")
+	if number == "" {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("number is required"))
+		return
+	}
+
+	at := time.Now()
+	if atStr := query.Get("at"); atStr != "" {
+		t, err := time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Validation failed", errValidation("at must be an RFC 3339 time"))
+			return
+		}
+		at = t
+	}
+
+	domain, err := h.db.GetDomain(ctx, domainID)
+	if err != nil || !canAccessDomain(r, domainID) {
+		respondError(w, http.StatusNotFound, "Domain not found", err)
+		return
+	}
+
+	inUse, err := h.db.FindNumberInUse(ctx, domainID, number)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to look up number", err)
+		return
+	}
+	if inUse == nil {
+		respondError(w, http.StatusNotFound, "Number not found", nil)
+		return
+	}
+
+	result := &models.TimeConditionEvaluation{
+		DomainID: domainID,
+		Number:   number,
+		Type:     inUse.Kind,
+		At:       at,
+		Action:   models.TimeActionRoute,
+		Target:   number,
+	}
+
+	tc, err := h.db.GetTimeConditionForNumber(ctx, domain.Domain, number)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get time condition", err)
+		return
+	}
+	if tc != nil {
+		state, err := tc.Evaluate(at)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to evaluate time condition", err)
+			return
+		}
+
+		result.TimeCondition = tc
+		result.State = state
+		if !state.Open {
+			result.Action = tc.ClosedAction
+			result.Target = tc.ClosedTargetFor(number)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// loadTimeCondition loads the {id} time condition, hiding conditions
+// outside the caller's scope
+func (h *TimeConditionHandler) loadTimeCondition(w http.ResponseWriter, r *http.Request) (*models.TimeCondition, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid time condition ID", err)
+		return nil, false
+	}
+
+	tc, err := h.db.GetTimeCondition(r.Context(), id)
+	if err != nil || !canAccessDomain(r, tc.DomainID) {
+		respondError(w, http.StatusNotFound, "Time condition not found", err)
+		return nil, false
+	}
+
+	return tc, true
+}
+
+// checkRequest validates a create or update request and checks its
+// destinations exist and are not guarded by another condition. id is 0 on
+// create.
+func (h *TimeConditionHandler) checkRequest(w http.ResponseWriter, r *http.Request, req *models.TimeConditionRequest, domain *models.Domain, id int64) bool {
+	ctx := r.Context()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+
+	if err := validateTimeConditionRequest(req); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return false
+	}
+
+	for _, dest := range req.Destinations {
+		inUse, err := h.db.FindNumberInUse(ctx, domain.ID, dest.Number)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to look up number", err)
+			return false
+		}
+		if inUse == nil || inUse.Kind != dest.Type {
+			respondError(w, http.StatusBadRequest, "Validation failed",
+				errValidation(fmt.Sprintf("destination %s is not a %s in the domain", dest.Number, dest.Type)))
+			return false
+		}
+
+		other, err := h.db.GetTimeConditionForNumber(ctx, domain.Domain, dest.Number)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to get time condition", err)
+			return false
+		}
+		if other != nil && other.ID != id {
+			respondError(w, http.StatusConflict,
+				fmt.Sprintf("Destination %s already uses time condition %q", dest.Number, other.Name), nil)
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateTimeConditionRequest validates a time condition request
+func validateTimeConditionRequest(req *models.TimeConditionRequest) error {
+	if req.Name == "" || len(req.Name) > 100 {
+		return errValidation("name must be 1-100 characters")
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil || len(req.Timezone) > 64 {
+		return errValidation("timezone must be an IANA timezone, e.g. Europe/Berlin")
+	}
+	if req.Override != "" && req.Override != models.OverrideOpen && req.Override != models.OverrideClosed {
+		return errValidation("override must be one of: open, closed, or empty")
+	}
+
+	switch req.ClosedAction {
+	case models.ClosedActionVoicemail:
+		if req.ClosedTarget != "" && !mailboxPattern.MatchString(req.ClosedTarget) {
+			return errValidation("closed_target must be a mailbox number")
+		}
+	case models.ClosedActionAnnouncement:
+		if !soundPathPattern.MatchString(req.ClosedTarget) || strings.Contains(req.ClosedTarget, "..") {
+			return errValidation("closed_target must be a sound file path")
+		}
+	case models.ClosedActionDestination:
+		if !forwardDestinationPattern.MatchString(req.ClosedTarget) {
+			return errValidation("closed_target must be 2-31 digits with optional leading +")
+		}
+	default:
+		return errValidation("closed_action must be one of: voicemail, announcement, destination")
+	}
+
+	if len(req.Schedule) > maxTimeRules {
+		return errValidation(fmt.Sprintf("schedule can have at most %d rules", maxTimeRules))
+	}
+	for i, rule := range req.Schedule {
+		if rule == nil {
+			return errValidation(fmt.Sprintf("schedule rule %d is empty", i+1))
+		}
+		if rule.Weekday < 0 || rule.Weekday > 6 {
+			return errValidation(fmt.Sprintf("schedule rule %d: weekday must be 0 (Sunday) to 6", i+1))
+		}
+		if !clockPattern.MatchString(rule.Start) || (rule.End != "24:00" && !clockPattern.MatchString(rule.End)) {
+			return errValidation(fmt.Sprintf("schedule rule %d: start and end must be HH:MM", i+1))
+		}
+		if rule.Start >= rule.End {
+			return errValidation(fmt.Sprintf("schedule rule %d: start must be before end", i+1))
+		}
+	}
+
+	if len(req.Holidays) > maxHolidays {
+		return errValidation(fmt.Sprintf("a time condition can have at most %d holidays", maxHolidays))
+	}
+	for i, hol := range req.Holidays {
+		if hol == nil {
+			return errValidation(fmt.Sprintf("holiday %d is empty", i+1))
+		}
+		if hol.Name == "" || len(hol.Name) > 100 {
+			return errValidation(fmt.Sprintf("holiday %d: name must be 1-100 characters", i+1))
+		}
+		if hol.EndDate == "" {
+			hol.EndDate = hol.StartDate
+		}
+		start, err := time.Parse("2006-01-02", hol.StartDate)
+		if err != nil {
+			return errValidation(fmt.Sprintf("holiday %d: start_date must be YYYY-MM-DD", i+1))
+		}
+		end, err := time.Parse("2006-01-02", hol.EndDate)
+		if err != nil {
+			return errValidation(fmt.Sprintf("holiday %d: end_date must be YYYY-MM-DD", i+1))
+		}
+		if end.Before(start) {
+			return errValidation(fmt.Sprintf("holiday %d: end_date must not be before start_date", i+1))
+		}
+	}
+
+	if len(req.Destinations) > maxTimeConditionDestinations {
+		return errValidation(fmt.Sprintf("a time condition can have at most %d destinations", maxTimeConditionDestinations))
+	}
+	seen := make(map[string]bool, len(req.Destinations))
+	for i, dest := range req.Destinations {
+		if dest == nil || dest.Number == "" {
+			return errValidation(fmt.Sprintf("destination %d: number is required", i+1))
+		}
+		if !isTimeConditionDestinationType(dest.Type) {
+			return errValidation(fmt.Sprintf("destination %d: type must be one of: user, queue, ivr", i+1))
+		}
+		if seen[dest.Number] {
+			return errValidation(fmt.Sprintf("destination %d: %s is listed twice", i+1, dest.Number))
+		}
+		seen[dest.Number] = true
+	}
+
+	// Transferring a closed call to a guarded number would loop
+	if req.ClosedAction == models.ClosedActionDestination && seen[req.ClosedTarget] {
+		return errValidation("closed_target cannot be one of the destinations")
+	}
+
+	return nil
+}
+
+// isTimeConditionDestinationType reports whether t can be guarded by a
+// time condition
+func isTimeConditionDestinationType(t string) bool {
+	for _, destType := range models.TimeConditionDestinationTypes {
+		if t == destType {
+			return true
+		}
+	}
+	return false
+}
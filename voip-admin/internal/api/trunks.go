@@ -0,0 +1,413 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/esl"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+var (
+	// trunkNamePattern matches a trunk name, which is the FreeSWITCH
+	// gateway name used in dial strings and ESL commands
+	trunkNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,100}$`)
+
+	// trunkHostPattern matches a carrier hostname or IPv4 address
+	trunkHostPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]{0,253}[A-Za-z0-9])?$`)
+
+	// codecPrefsPattern matches a codec list, e.g. PCMA,PCMU or OPUS@48000h@20i
+	codecPrefsPattern = regexp.MustCompile(`^[A-Za-z0-9.@]+(,[A-Za-z0-9.@]+)*$`)
+
+	// trunkPrefixPattern matches the digits dialed to select a trunk
+	trunkPrefixPattern = regexp.MustCompile(`^[0-9*#+]{1,20}$`)
+)
+
+// TrunkHandler handles trunk HTTP requests. Trunks are stored in the
+// database and served to FreeSWITCH as sofia gateways; gateways are
+// reloaded over the Event Socket.
+type TrunkHandler struct {
+	db  *database.DB
+	esl *esl.Client
+}
+
+// NewTrunkHandler creates a new trunk handler
+func NewTrunkHandler(db *database.DB, eslClient *esl.Client) *TrunkHandler {
+	return &TrunkHandler{
+		db:  db,
+		esl: eslClient,
+	}
+}
+
+// List handles GET /api/v1/trunks
+func (h *TrunkHandler) List(w http.ResponseWriter, r *http.Request) {
+	var domainID *int64
+	if domainIDStr := r.URL.Query().Get("domain_id"); domainIDStr != "" {
+		id, err := strconv.ParseInt(domainIDStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid domain ID", err)
+			return
+		}
+		domainID = &id
+	}
+
+	// Scoped callers only see the trunks of their own domain
+	if scope := domainScope(r); scope != nil {
+		if domainID != nil && *domainID != *scope {
+			respondJSON(w, http.StatusOK, []*models.Trunk{})
+			return
+		}
+		domainID = scope
+	}
+
+	trunks, err := h.db.ListTrunks(r.Context(), domainID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list trunks", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, trunks)
+}
+
+// Get handles GET /api/v1/trunks/{id}
+func (h *TrunkHandler) Get(w http.ResponseWriter, r *http.Request) {
+	trunk, ok := h.loadTrunk(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, trunk)
+}
+
+// Create handles POST /api/v1/trunks
+// The gateway is created on FreeSWITCH by the next reload.
+func (h *TrunkHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.TrunkCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Scoped callers cannot create trunks shared by all domains
+	if req.DomainID == nil {
+		req.DomainID = domainScope(r)
+	}
+	if req.DomainID != nil {
+		if _, err := h.db.GetDomain(ctx, *req.DomainID); err != nil || !canAccessDomain(r, *req.DomainID) {
+			respondError(w, http.StatusBadRequest, "Domain not found", err)
+			return
+		}
+	}
+
+	if !trunkNamePattern.MatchString(req.Name) {
+		respondError(w, http.StatusBadRequest, "Validation failed",
+			errValidation("name must be 1-100 letters, digits, '_', '.' or '-'"))
+		return
+	}
+	// Gateway names are global, so the owner of a taken name is not revealed
+	if _, err := h.db.GetTrunkByName(ctx, req.Name); err == nil {
+		respondError(w, http.StatusConflict, "Trunk already exists", nil)
+		return
+	}
+
+	if req.SIPProfile == "" {
+		req.SIPProfile = models.DefaultTrunkProfile
+	}
+	if req.Transport == "" {
+		req.Transport = "udp"
+	}
+	if req.Port == 0 {
+		req.Port = 5060
+		if req.Transport == "tls" {
+			req.Port = 5061
+		}
+	}
+	if req.ExpireSeconds == 0 {
+		req.ExpireSeconds = 3600
+	}
+
+	trunk := &models.Trunk{
+		DomainID:       req.DomainID,
+		Name:           req.Name,
+		Type:           req.Type,
+		SIPProfile:     req.SIPProfile,
+		Host:           req.Host,
+		Port:           req.Port,
+		Transport:      req.Transport,
+		Username:       req.Username,
+		Password:       req.Password,
+		Realm:          req.Realm,
+		Register:       req.Register,
+		ExpireSeconds:  req.ExpireSeconds,
+		CodecPrefs:     req.CodecPrefs,
+		CallerIDInFrom: req.CallerIDInFrom,
+		Prefix:         req.Prefix,
+		Description:    req.Description,
+		RateDeckID:     req.RateDeckID,
+		Active:         req.Active,
+	}
+	// Carrier costs are not managed by tenants
+	if trunk.RateDeckID != nil && domainScope(r) != nil {
+		respondError(w, http.StatusForbidden, "Rate decks can only be assigned by unscoped callers", nil)
+		return
+	}
+	if err := h.validateTrunk(r, trunk); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	trunk, err := h.db.CreateTrunk(ctx, trunk)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create trunk", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditCreate, "trunks", strconv.FormatInt(trunk.ID, 10), trunk.DomainID, nil, trunk)
+
+	respondJSON(w, http.StatusCreated, trunk)
+}
+
+// Update handles PUT /api/v1/trunks/{id}
+// An empty password removes it. Changes apply on the next reload.
+func (h *TrunkHandler) Update(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadTrunk(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.TrunkUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	candidate := *current
+	if req.Type != nil {
+		candidate.Type = *req.Type
+	}
+	if req.SIPProfile != nil {
+		candidate.SIPProfile = *req.SIPProfile
+	}
+	if req.Host != nil {
+		candidate.Host = *req.Host
+	}
+	if req.Port != nil {
+		candidate.Port = *req.Port
+	}
+	if req.Transport != nil {
+		candidate.Transport = *req.Transport
+	}
+	if req.Username != nil {
+		candidate.Username = *req.Username
+	}
+	if req.Password != nil {
+		candidate.Password = *req.Password
+	}
+	if req.Realm != nil {
+		candidate.Realm = *req.Realm
+	}
+	if req.Register != nil {
+		candidate.Register = *req.Register
+	}
+	if req.ExpireSeconds != nil {
+		candidate.ExpireSeconds = *req.ExpireSeconds
+	}
+	if req.CodecPrefs != nil {
+		candidate.CodecPrefs = *req.CodecPrefs
+	}
+	if req.CallerIDInFrom != nil {
+		candidate.CallerIDInFrom = *req.CallerIDInFrom
+	}
+	if req.Prefix != nil {
+		candidate.Prefix = *req.Prefix
+	}
+	if req.Description != nil {
+		candidate.Description = *req.Description
+	}
+	if req.Active != nil {
+		candidate.Active = *req.Active
+	}
+	if req.RateDeckID != nil {
+		if domainScope(r) != nil {
+			respondError(w, http.StatusForbidden, "Rate decks can only be assigned by unscoped callers", nil)
+			return
+		}
+		candidate.RateDeckID = req.RateDeckID
+		if *req.RateDeckID == 0 {
+			candidate.RateDeckID = nil
+		}
+	}
+
+	if err := h.validateTrunk(r, &candidate); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	trunk, err := h.db.UpdateTrunk(r.Context(), &candidate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update trunk", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "trunks", strconv.FormatInt(trunk.ID, 10), trunk.DomainID, current, trunk)
+
+	respondJSON(w, http.StatusOK, trunk)
+}
+
+// Delete handles DELETE /api/v1/trunks/{id}
+// The gateway is removed from FreeSWITCH by the next reload.
+func (h *TrunkHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	current, ok := h.loadTrunk(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteTrunk(r.Context(), current.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete trunk", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "trunks", strconv.FormatInt(current.ID, 10), current.DomainID, current, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Status handles GET /api/v1/trunks/{id}/status
+// Returns the registration state of the trunk's gateway, or 404 if the
+// gateway is not loaded on FreeSWITCH.
+func (h *TrunkHandler) Status(w http.ResponseWriter, r *http.Request) {
+	trunk, ok := h.loadTrunk(w, r)
+	if !ok {
+		return
+	}
+
+	gateways, err := h.esl.ListGateways(r.Context())
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to get gateways from FreeSWITCH", err)
+		return
+	}
+
+	for _, gw := range gateways {
+		if gw.Name == trunk.Name {
+			respondJSON(w, http.StatusOK, &models.GatewayStatus{
+				Name:    gw.Name,
+				Profile: gw.Profile,
+				State:   gw.State,
+				Status:  gw.Status,
+			})
+			return
+		}
+	}
+
+	respondError(w, http.StatusNotFound, "Gateway not loaded; reload the gateways", nil)
+}
+
+// Reload handles POST /api/v1/trunks/reload
+// Kills the gateways of all SIP profiles and re-reads them from sofia.conf,
+// so created, changed and deleted trunks take effect. Registrations are
+// renewed; calls in progress are not affected.
+func (h *TrunkHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Gateways are shared by all domains of a node
+	if domainScope(r) != nil {
+		respondError(w, http.StatusForbidden, "Domain-scoped API keys cannot reload gateways", nil)
+		return
+	}
+
+	profiles, err := h.db.ListSIPProfiles(ctx)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list SIP profiles", err)
+		return
+	}
+
+	names := make([]string, len(profiles))
+	for i, profile := range profiles {
+		names[i] = profile.Name
+	}
+
+	if err := h.esl.ReloadGateways(ctx, names); err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to reload gateways", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "trunks", "reload", nil, nil,
+		map[string]interface{}{"profiles": names})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadTrunk loads the {id} trunk, hiding trunks outside the caller's
+// scope. Scoped callers cannot see trunks shared by all domains.
+func (h *TrunkHandler) loadTrunk(w http.ResponseWriter, r *http.Request) (*models.Trunk, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid trunk ID", err)
+		return nil, false
+	}
+
+	trunk, err := h.db.GetTrunk(r.Context(), id)
+	if err == nil && domainScope(r) != nil && (trunk.DomainID == nil || !canAccessDomain(r, *trunk.DomainID)) {
+		err = errValidation("trunk outside domain scope")
+	}
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Trunk not found", err)
+		return nil, false
+	}
+
+	return trunk, true
+}
+
+// validateTrunk checks the gateway settings of a trunk
+func (h *TrunkHandler) validateTrunk(r *http.Request, t *models.Trunk) error {
+	if !trunkHostPattern.MatchString(t.Host) {
+		return errValidation("host must be a hostname or IPv4 address")
+	}
+	if t.Port < 1 || t.Port > 65535 {
+		return errValidation("port must be between 1 and 65535")
+	}
+	if !isTrunkTransport(t.Transport) {
+		return errValidation("transport must be one of: " + strings.Join(models.TrunkTransports, ", "))
+	}
+	if t.Register && (t.Username == "" || t.Password == "") {
+		return errValidation("register requires username and password")
+	}
+	if t.ExpireSeconds < 60 || t.ExpireSeconds > 86400 {
+		return errValidation("expire_seconds must be between 60 and 86400")
+	}
+	if t.CodecPrefs != "" && !codecPrefsPattern.MatchString(t.CodecPrefs) {
+		return errValidation("codec_prefs must be a comma-separated codec list, e.g. PCMA,PCMU")
+	}
+	if t.Prefix != "" && !trunkPrefixPattern.MatchString(t.Prefix) {
+		return errValidation("prefix must be 1-20 digits, '*', '#' or '+'")
+	}
+	if len(t.Username) > 100 || len(t.Password) > 255 || len(t.Realm) > 255 || len(t.Description) > 255 {
+		return errValidation("username, password, realm or description too long")
+	}
+
+	if _, err := h.db.GetSIPProfile(r.Context(), t.SIPProfile); err != nil {
+		return errValidation("sip_profile not found: " + t.SIPProfile)
+	}
+	if err := checkRateDeck(r, h.db, t.RateDeckID, models.RateDeckBuy); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// isTrunkTransport reports whether t is a SIP transport of a gateway
+func isTrunkTransport(t string) bool {
+	for _, transport := range models.TrunkTransports {
+		if t == transport {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,378 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/middleware"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// usernamePattern matches a login name
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9._@-]{1,100}$`)
+
+// UserHandler handles admin user HTTP requests
+type UserHandler struct {
+	db *database.DB
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(db *database.DB) *UserHandler {
+	return &UserHandler{
+		db: db,
+	}
+}
+
+// List handles GET /api/v1/users
+func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var domainID *int64
+	if domainIDStr := r.URL.Query().Get("domain_id"); domainIDStr != "" {
+		if id, err := strconv.ParseInt(domainIDStr, 10, 64); err == nil {
+			domainID = &id
+		}
+	}
+	if scope := domainScope(r); scope != nil {
+		domainID = scope
+	}
+
+	var role *string
+	if roleStr := r.URL.Query().Get("role"); roleStr != "" {
+		role = &roleStr
+	}
+
+	var active *bool
+	if activeStr := r.URL.Query().Get("active"); activeStr != "" {
+		activeBool := activeStr == "true" || activeStr == "1"
+		active = &activeBool
+	}
+
+	page := 1
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	perPage := 50
+	if perPageStr := r.URL.Query().Get("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 1000 {
+			perPage = pp
+		}
+	}
+
+	result, err := h.db.ListUsers(ctx, domainID, role, active, page, perPage)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list users", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// Get handles GET /api/v1/users/{id}
+func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.loadUser(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, user)
+}
+
+// Create handles POST /api/v1/users
+func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.UserCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.Username = strings.TrimSpace(req.Username)
+
+	// Domain-scoped callers can only create users in their own domain
+	if scope := domainScope(r); scope != nil {
+		if req.DomainID != nil && *req.DomainID != *scope {
+			respondError(w, http.StatusForbidden, "Not allowed to access this domain", nil)
+			return
+		}
+		req.DomainID = scope
+	}
+
+	if err := validateUserCreateRequest(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	if !canGrantRole(r, req.Role) {
+		respondError(w, http.StatusForbidden, "Cannot grant a role with permissions beyond your own", nil)
+		return
+	}
+
+	if req.DomainID != nil {
+		if _, err := h.db.GetDomain(ctx, *req.DomainID); err != nil {
+			respondError(w, http.StatusBadRequest, "Domain not found", err)
+			return
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to hash password", err)
+		return
+	}
+
+	user, err := h.db.CreateUser(ctx, &req, string(hash))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create user", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditCreate, "users", strconv.FormatInt(user.ID, 10), user.DomainID, nil, user)
+
+	respondJSON(w, http.StatusCreated, user)
+}
+
+// Update handles PUT /api/v1/users/{id}
+func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	current, ok := h.loadManagedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UserUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.Role != nil && !isValidRole(*req.Role) {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("role must be one of: agent, supervisor, admin"))
+		return
+	}
+	if req.Role != nil && *req.Role != models.RoleAdmin && current.DomainID == nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("users without a domain must be admins"))
+		return
+	}
+	if req.FullName != nil && len(*req.FullName) > 255 {
+		respondError(w, http.StatusBadRequest, "Validation failed", errValidation("full_name must be at most 255 characters"))
+		return
+	}
+	if req.Role != nil && !canGrantRole(r, *req.Role) {
+		respondError(w, http.StatusForbidden, "Cannot grant a role with permissions beyond your own", nil)
+		return
+	}
+
+	user, err := h.db.UpdateUser(ctx, current.ID, &req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update user", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "users", strconv.FormatInt(user.ID, 10), user.DomainID, current, user)
+
+	respondJSON(w, http.StatusOK, user)
+}
+
+// Delete handles DELETE /api/v1/users/{id}
+// Users are deactivated rather than removed; their sessions end immediately.
+func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.loadManagedUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteUser(r.Context(), user.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete user", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "users", strconv.FormatInt(user.ID, 10), user.DomainID, user, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetPassword handles POST /api/v1/users/{id}/password
+func (h *UserHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.loadManagedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UserPasswordUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := validateUserPassword(req.Password); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to hash password", err)
+		return
+	}
+
+	if err := h.db.SetUserPassword(r.Context(), user.ID, string(hash)); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update password", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditChangePassword, "users", strconv.FormatInt(user.ID, 10), user.DomainID,
+		nil, map[string]string{"password": req.Password})
+
+	respondSuccess(w, "Password updated successfully", nil)
+}
+
+// Queues handles GET /api/v1/users/{id}/queues
+func (h *UserHandler) Queues(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.loadUser(w, r)
+	if !ok {
+		return
+	}
+
+	queueIDs, err := h.db.ListSupervisedQueueIDs(r.Context(), user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list supervised queues", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, &models.UserQueuesUpdate{QueueIDs: queueIDs})
+}
+
+// SetQueues handles PUT /api/v1/users/{id}/queues
+// Replaces the queues a supervisor supervises.
+func (h *UserHandler) SetQueues(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, ok := h.loadUser(w, r)
+	if !ok {
+		return
+	}
+
+	if user.Role != models.RoleSupervisor {
+		respondError(w, http.StatusBadRequest, "Only supervisors can be assigned queues", nil)
+		return
+	}
+
+	var req models.UserQueuesUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	previous, err := h.db.ListSupervisedQueueIDs(ctx, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list supervised queues", err)
+		return
+	}
+
+	if err := h.db.SetSupervisedQueues(ctx, user.ID, req.QueueIDs); err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to update supervised queues", err)
+		return
+	}
+
+	queueIDs, err := h.db.ListSupervisedQueueIDs(ctx, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list supervised queues", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "users", strconv.FormatInt(user.ID, 10), user.DomainID,
+		&models.UserQueuesUpdate{QueueIDs: previous}, &models.UserQueuesUpdate{QueueIDs: queueIDs})
+
+	respondJSON(w, http.StatusOK, &models.UserQueuesUpdate{QueueIDs: queueIDs})
+}
+
+// loadUser loads the user from the {id} path variable, hiding users outside
+// the caller's domain
+func (h *UserHandler) loadUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return nil, false
+	}
+
+	user, err := h.db.GetUser(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "User not found", err)
+		return nil, false
+	}
+
+	if scope := domainScope(r); scope != nil && (user.DomainID == nil || *user.DomainID != *scope) {
+		respondError(w, http.StatusNotFound, "User not found", nil)
+		return nil, false
+	}
+
+	return user, true
+}
+
+// loadManagedUser loads the {id} user if the caller's permissions cover
+// the user's role. Changing a broader user, or resetting its password,
+// would hand the caller that user's permissions.
+func (h *UserHandler) loadManagedUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user, ok := h.loadUser(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	if !canGrantRole(r, user.Role) {
+		respondError(w, http.StatusForbidden, "Cannot manage a user with permissions beyond your own", nil)
+		return nil, false
+	}
+
+	return user, true
+}
+
+// canGrantRole reports whether the caller's permissions cover those of role
+func canGrantRole(r *http.Request, role string) bool {
+	principal := middleware.PrincipalFromContext(r.Context())
+	return principal.Permissions.Covers(models.RolePermissions(role))
+}
+
+// isValidRole reports whether role is a known user role
+func isValidRole(role string) bool {
+	return role == models.RoleAgent || role == models.RoleSupervisor || role == models.RoleAdmin
+}
+
+// validateUserCreateRequest validates a user create request
+func validateUserCreateRequest(req *models.UserCreateRequest) error {
+	if !usernamePattern.MatchString(req.Username) {
+		return errValidation("username must be 1-100 letters, digits or . _ @ -")
+	}
+	if !isValidRole(req.Role) {
+		return errValidation("role must be one of: agent, supervisor, admin")
+	}
+	if req.DomainID == nil && req.Role != models.RoleAdmin {
+		return errValidation("domain_id is required for agents and supervisors")
+	}
+	if len(req.FullName) > 255 {
+		return errValidation("full_name must be at most 255 characters")
+	}
+	return validateUserPassword(req.Password)
+}
+
+// validateUserPassword validates a login password. bcrypt only uses the
+// first 72 bytes, so longer passwords are rejected.
+func validateUserPassword(password string) error {
+	if len(password) < 8 || len(password) > 72 {
+		return errValidation("password must be 8-72 characters")
+	}
+	return nil
+}
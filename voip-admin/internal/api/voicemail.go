@@ -0,0 +1,248 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/esl"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// VoicemailHandler handles voicemail HTTP requests. Messages are read from
+// mod_voicemail's storage; changes go through FreeSWITCH so that it keeps
+// its files and the MWI state of the phones in sync.
+type VoicemailHandler struct {
+	db      *database.DB
+	esl     *esl.Client
+	storage string // Voicemail root as mounted on this host
+}
+
+// NewVoicemailHandler creates a new voicemail handler serving files from
+// the storage directory
+func NewVoicemailHandler(db *database.DB, eslClient *esl.Client, storage string) *VoicemailHandler {
+	return &VoicemailHandler{
+		db:      db,
+		esl:     eslClient,
+		storage: storage,
+	}
+}
+
+// Get handles GET /api/v1/voicemail/{id}
+// Returns the mailbox of the {id} extension: MWI state and messages.
+func (h *VoicemailHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if ext, ok := h.loadMailbox(w, r, false); ok {
+		h.getMailbox(w, r, ext)
+	}
+}
+
+// Audio handles GET /api/v1/voicemail/{id}/messages/{uuid}/audio
+func (h *VoicemailHandler) Audio(w http.ResponseWriter, r *http.Request) {
+	if ext, ok := h.loadMailbox(w, r, false); ok {
+		h.serveAudio(w, r, ext)
+	}
+}
+
+// UpdateMessage handles PUT /api/v1/voicemail/{id}/messages/{uuid}
+func (h *VoicemailHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
+	if ext, ok := h.loadMailbox(w, r, false); ok {
+		h.updateMessage(w, r, ext)
+	}
+}
+
+// DeleteMessage handles DELETE /api/v1/voicemail/{id}/messages/{uuid}
+func (h *VoicemailHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	if ext, ok := h.loadMailbox(w, r, false); ok {
+		h.deleteMessage(w, r, ext)
+	}
+}
+
+// GetOwn handles GET /api/v1/auth/voicemail/{id}
+// Lets logged-in users read the mailboxes of their own extensions.
+func (h *VoicemailHandler) GetOwn(w http.ResponseWriter, r *http.Request) {
+	if ext, ok := h.loadMailbox(w, r, true); ok {
+		h.getMailbox(w, r, ext)
+	}
+}
+
+// OwnAudio handles GET /api/v1/auth/voicemail/{id}/messages/{uuid}/audio
+func (h *VoicemailHandler) OwnAudio(w http.ResponseWriter, r *http.Request) {
+	if ext, ok := h.loadMailbox(w, r, true); ok {
+		h.serveAudio(w, r, ext)
+	}
+}
+
+// UpdateOwnMessage handles PUT /api/v1/auth/voicemail/{id}/messages/{uuid}
+func (h *VoicemailHandler) UpdateOwnMessage(w http.ResponseWriter, r *http.Request) {
+	if ext, ok := h.loadMailbox(w, r, true); ok {
+		h.updateMessage(w, r, ext)
+	}
+}
+
+// DeleteOwnMessage handles DELETE /api/v1/auth/voicemail/{id}/messages/{uuid}
+func (h *VoicemailHandler) DeleteOwnMessage(w http.ResponseWriter, r *http.Request) {
+	if ext, ok := h.loadMailbox(w, r, true); ok {
+		h.deleteMessage(w, r, ext)
+	}
+}
+
+// loadMailbox loads the {id} extension, hiding extensions outside the
+// caller's scope, or not owned by the caller when own is set. Only user
+// extensions have a mailbox.
+func (h *VoicemailHandler) loadMailbox(w http.ResponseWriter, r *http.Request, own bool) (*models.Extension, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid extension ID", err)
+		return nil, false
+	}
+
+	ext, err := h.db.GetExtensionByID(r.Context(), id)
+	if err == nil {
+		visible := canAccessDomain(r, ext.DomainID)
+		if own {
+			visible = ownsExtension(r, ext)
+		}
+		if !visible || ext.Type != "user" {
+			err = fmt.Errorf("extension not found: %d", id)
+		}
+	}
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Mailbox not found", err)
+		return nil, false
+	}
+
+	return ext, true
+}
+
+// loadMessage loads the {uuid} message of the mailbox of ext
+func (h *VoicemailHandler) loadMessage(w http.ResponseWriter, r *http.Request, ext *models.Extension) (*models.VoicemailMessage, bool) {
+	msg, err := h.db.GetVoicemailMessage(r.Context(), ext.Extension, ext.Domain, mux.Vars(r)["uuid"])
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Voicemail message not found", err)
+		return nil, false
+	}
+
+	return msg, true
+}
+
+// getMailbox responds with the MWI state and messages of a mailbox
+func (h *VoicemailHandler) getMailbox(w http.ResponseWriter, r *http.Request, ext *models.Extension) {
+	ctx := r.Context()
+
+	mwi, err := h.db.GetVoicemailMWI(ctx, ext.Extension, ext.Domain)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get message waiting state", err)
+		return
+	}
+
+	messages, err := h.db.ListVoicemailMessages(ctx, ext.Extension, ext.Domain)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list voicemail messages", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, &models.VoicemailBox{
+		ExtensionID: ext.ID,
+		Extension:   ext.Extension,
+		Domain:      ext.Domain,
+		MWI:         mwi,
+		Messages:    messages,
+	})
+}
+
+// serveAudio streams the audio file of a message; Range requests are
+// supported for seeking
+func (h *VoicemailHandler) serveAudio(w http.ResponseWriter, r *http.Request, ext *models.Extension) {
+	msg, ok := h.loadMessage(w, r, ext)
+	if !ok {
+		return
+	}
+
+	file, ok := msg.LocalPath(h.storage)
+	if !ok {
+		log.Printf("[Voicemail] Message %s is outside the storage %s: %s", msg.UUID, h.storage, msg.FilePath)
+		respondError(w, http.StatusNotFound, "Voicemail file not found", nil)
+		return
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Voicemail file not found", err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to read voicemail file", err)
+		return
+	}
+
+	format := strings.TrimPrefix(filepath.Ext(file), ".")
+	contentType, ok := models.AudioContentTypes[format]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	name := fmt.Sprintf("%s.%s", msg.UUID, format)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+// updateMessage marks a message read or unread
+func (h *VoicemailHandler) updateMessage(w http.ResponseWriter, r *http.Request, ext *models.Extension) {
+	ctx := r.Context()
+
+	current, ok := h.loadMessage(w, r, ext)
+	if !ok {
+		return
+	}
+
+	var req models.VoicemailMessageUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.Read != current.Read {
+		if err := h.esl.MarkVoicemailRead(ctx, ext.Extension, ext.Domain, current.UUID, req.Read); err != nil {
+			respondError(w, http.StatusBadGateway, "Failed to update voicemail message", err)
+			return
+		}
+	}
+
+	msg, err := h.db.GetVoicemailMessage(ctx, ext.Extension, ext.Domain, current.UUID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get voicemail message", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditUpdate, "voicemail", current.UUID, &ext.DomainID, current, msg)
+
+	respondJSON(w, http.StatusOK, msg)
+}
+
+// deleteMessage deletes a message and its audio file
+func (h *VoicemailHandler) deleteMessage(w http.ResponseWriter, r *http.Request, ext *models.Extension) {
+	current, ok := h.loadMessage(w, r, ext)
+	if !ok {
+		return
+	}
+
+	if err := h.esl.DeleteVoicemail(r.Context(), ext.Extension, ext.Domain, current.UUID); err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to delete voicemail message", err)
+		return
+	}
+
+	recordAudit(r, h.db, models.AuditDelete, "voicemail", current.UUID, &ext.DomainID, current, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
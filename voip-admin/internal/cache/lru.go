@@ -106,6 +106,22 @@ func (c *LRUCache) Delete(key string) {
 	}
 }
 
+// DeleteMatching removes all keys for which match returns true
+func (c *LRUCache) DeleteMatching(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, elem := range c.items {
+		if match(key) {
+			c.removeElement(elem)
+			removed++
+		}
+	}
+
+	return removed
+}
+
 // Clear removes all entries from the cache
 func (c *LRUCache) Clear() {
 	c.mu.Lock()
@@ -50,6 +50,11 @@ func (m *Manager) Delete(key string) {
 	m.cache.Delete(key)
 }
 
+// DeleteMatching removes all keys for which match returns true
+func (m *Manager) DeleteMatching(match func(key string) bool) int {
+	return m.cache.DeleteMatching(match)
+}
+
 // Clear removes all entries from cache
 func (m *Manager) Clear() {
 	m.cache.Clear()
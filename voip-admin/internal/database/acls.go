@@ -0,0 +1,257 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// aclListColumns are the voip.acl_lists columns scanned by scanACLList
+const aclListColumns = `
+	l.id, l.name, l.default_action, l.kamailio_group, COALESCE(l.description, ''),
+	l.created_at, l.updated_at
+`
+
+// scanACLList scans a row of aclListColumns
+func scanACLList(row interface{ Scan(...interface{}) error }) (*models.ACLList, error) {
+	var l models.ACLList
+	var group sql.NullInt64
+	err := row.Scan(
+		&l.ID, &l.Name, &l.DefaultAction, &group, &l.Description,
+		&l.CreatedAt, &l.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.Valid {
+		g := int(group.Int64)
+		l.KamailioGroup = &g
+	}
+	l.Entries = []*models.ACLEntry{}
+
+	return &l, nil
+}
+
+// GetACLList retrieves an ACL list and its entries by ID
+func (db *DB) GetACLList(ctx context.Context, id int64) (*models.ACLList, error) {
+	query := `SELECT ` + aclListColumns + ` FROM voip.acl_lists l WHERE l.id = $1`
+
+	list, err := scanACLList(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("ACL list not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query ACL list: %w", err)
+	}
+
+	if err := db.loadACLEntries(ctx, map[int64]*models.ACLList{list.ID: list}); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// GetACLListByName retrieves an ACL list by name, without its entries
+func (db *DB) GetACLListByName(ctx context.Context, name string) (*models.ACLList, error) {
+	query := `SELECT ` + aclListColumns + ` FROM voip.acl_lists l WHERE l.name = $1`
+
+	list, err := scanACLList(db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("ACL list not found: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query ACL list: %w", err)
+	}
+
+	return list, nil
+}
+
+// ListACLLists retrieves all ACL lists and their entries
+func (db *DB) ListACLLists(ctx context.Context) ([]*models.ACLList, error) {
+	query := `SELECT ` + aclListColumns + ` FROM voip.acl_lists l ORDER BY l.name`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query ACL lists: %w", err)
+	}
+	defer rows.Close()
+
+	lists := []*models.ACLList{}
+	byID := make(map[int64]*models.ACLList)
+	for rows.Next() {
+		list, err := scanACLList(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan ACL list: %w", err)
+		}
+		lists = append(lists, list)
+		byID[list.ID] = list
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := db.loadACLEntries(ctx, byID); err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+// loadACLEntries fills in the entries of the given lists, ordered by
+// network
+func (db *DB) loadACLEntries(ctx context.Context, lists map[int64]*models.ACLList) error {
+	if len(lists) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(lists))
+	for id := range lists {
+		ids = append(ids, id)
+	}
+
+	query := `
+		SELECT e.acl_list_id, e.id, e.cidr::text, e.action, e.port, COALESCE(e.description, '')
+		FROM voip.acl_entries e
+		WHERE e.acl_list_id = ANY($1)
+		ORDER BY e.acl_list_id, e.cidr
+	`
+
+	rows, err := db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("query ACL entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var listID int64
+		var e models.ACLEntry
+		if err := rows.Scan(&listID, &e.ID, &e.CIDR, &e.Action, &e.Port, &e.Description); err != nil {
+			return fmt.Errorf("scan ACL entry: %w", err)
+		}
+		if list, ok := lists[listID]; ok {
+			list.Entries = append(list.Entries, &e)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	return nil
+}
+
+// CreateACLList creates an ACL list with its entries
+func (db *DB) CreateACLList(ctx context.Context, list *models.ACLList) (*models.ACLList, error) {
+	var id int64
+	err := db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO voip.acl_lists (name, default_action, kamailio_group, description)
+			VALUES ($1, $2, $3, NULLIF($4, ''))
+			RETURNING id
+		`
+		if err := tx.QueryRowContext(ctx, query,
+			list.Name, list.DefaultAction, list.KamailioGroup, list.Description,
+		).Scan(&id); err != nil {
+			return fmt.Errorf("insert ACL list: %w", err)
+		}
+
+		return insertACLEntries(ctx, tx, id, list.Entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetACLList(ctx, id)
+}
+
+// UpdateACLList stores the mutable fields of an ACL list and replaces its
+// entries; the caller merges the update request into the current list and
+// validates the result
+func (db *DB) UpdateACLList(ctx context.Context, list *models.ACLList) (*models.ACLList, error) {
+	err := db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE voip.acl_lists
+			SET default_action = $1, kamailio_group = $2, description = NULLIF($3, ''),
+				updated_at = NOW()
+			WHERE id = $4
+		`
+		result, err := tx.ExecContext(ctx, query,
+			list.DefaultAction, list.KamailioGroup, list.Description, list.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("update ACL list: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("ACL list not found: %d", list.ID)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM voip.acl_entries WHERE acl_list_id = $1`, list.ID,
+		); err != nil {
+			return fmt.Errorf("delete ACL entries: %w", err)
+		}
+
+		return insertACLEntries(ctx, tx, list.ID, list.Entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetACLList(ctx, list.ID)
+}
+
+// insertACLEntries inserts the entries of an ACL list
+func insertACLEntries(ctx context.Context, tx *sql.Tx, listID int64, entries []*models.ACLEntry) error {
+	query := `
+		INSERT INTO voip.acl_entries (acl_list_id, cidr, action, port, description)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	`
+	for _, e := range entries {
+		if _, err := tx.ExecContext(ctx, query, listID, e.CIDR, e.Action, e.Port, e.Description); err != nil {
+			return fmt.Errorf("insert ACL entry %s: %w", e.CIDR, err)
+		}
+	}
+	return nil
+}
+
+// DeleteACLList deletes an ACL list and its entries
+func (db *DB) DeleteACLList(ctx context.Context, id int64) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM voip.acl_lists WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete ACL list: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("ACL list not found: %d", id)
+	}
+
+	return nil
+}
+
+// CountACLListProfiles counts the SIP profiles that apply an ACL list to
+// inbound calls
+func (db *DB) CountACLListProfiles(ctx context.Context, name string) (int64, error) {
+	var count int64
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM voip.sip_profiles WHERE inbound_acl = $1`, name,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count ACL list profiles: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,237 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// ErrAgentNotFound is returned when an extension has no active queue
+// membership
+var ErrAgentNotFound = errors.New("agent not found")
+
+// SetAgentState updates the state of every active queue membership of an
+// extension and records the change in voip.agent_state_history
+func (db *DB) SetAgentState(ctx context.Context, extensionID int64, state, reason, source string) error {
+	return db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		// Lock the agent rows so concurrent changes serialize
+		var previous sql.NullString
+		err := tx.QueryRowContext(ctx, `
+			SELECT state
+			FROM voip.queue_agents
+			WHERE extension_id = $1 AND active = true
+			ORDER BY updated_at DESC
+			LIMIT 1
+			FOR UPDATE
+		`, extensionID).Scan(&previous)
+
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %d", ErrAgentNotFound, extensionID)
+		}
+		if err != nil {
+			return fmt.Errorf("query agent state: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE voip.queue_agents
+			SET state = $1, updated_at = $2
+			WHERE extension_id = $3 AND active = true
+		`, state, time.Now(), extensionID); err != nil {
+			return fmt.Errorf("update agent state: %w", err)
+		}
+
+		if previous.Valid && previous.String == state {
+			return nil
+		}
+
+		var reasonArg interface{}
+		if reason != "" {
+			reasonArg = reason
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO voip.agent_state_history (
+				extension_id, previous_state, state, reason, source, changed_at
+			) VALUES ($1, $2, $3, $4, $5, $6)
+		`, extensionID, previous, state, reasonArg, source, time.Now()); err != nil {
+			return fmt.Errorf("insert agent state history: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// UpdateAgentStatus updates the call status of every active queue membership
+// of an extension (Waiting, Receiving, In a queue call)
+func (db *DB) UpdateAgentStatus(ctx context.Context, extensionID int64, status string) error {
+	query := `
+		UPDATE voip.queue_agents
+		SET status = $1, updated_at = $2
+		WHERE extension_id = $3 AND active = true AND status <> $1
+	`
+
+	if _, err := db.ExecContext(ctx, query, status, time.Now(), extensionID); err != nil {
+		return fmt.Errorf("update agent status: %w", err)
+	}
+
+	return nil
+}
+
+// ListAgentQueues retrieves all active queue memberships of an extension
+func (db *DB) ListAgentQueues(ctx context.Context, extensionID int64) ([]*models.QueueAgent, error) {
+	query := `
+		SELECT
+			qa.id, qa.queue_id, qa.extension_id, qa.state, qa.status,
+			qa.tier, qa.position, qa.active, qa.created_at, qa.updated_at,
+			e.extension, e.display_name
+		FROM voip.queue_agents qa
+		INNER JOIN voip.extensions e ON qa.extension_id = e.id
+		WHERE qa.extension_id = $1 AND qa.active = true
+		ORDER BY qa.queue_id
+	`
+
+	rows, err := db.QueryContext(ctx, query, extensionID)
+	if err != nil {
+		return nil, fmt.Errorf("query agent queues: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*models.QueueAgent
+	for rows.Next() {
+		var agent models.QueueAgent
+		if err := rows.Scan(
+			&agent.ID, &agent.QueueID, &agent.ExtensionID, &agent.State, &agent.Status,
+			&agent.Tier, &agent.Position, &agent.Active, &agent.CreatedAt, &agent.UpdatedAt,
+			&agent.Extension, &agent.DisplayName,
+		); err != nil {
+			return nil, fmt.Errorf("scan queue agent: %w", err)
+		}
+		agents = append(agents, &agent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return agents, nil
+}
+
+// ListAgentSyncStates retrieves one row per agent extension with its current
+// state, for reconciliation against mod_callcenter
+func (db *DB) ListAgentSyncStates(ctx context.Context) ([]*models.AgentSyncState, error) {
+	query := `
+		SELECT DISTINCT ON (qa.extension_id)
+			qa.extension_id, e.extension, d.domain, qa.state, qa.status
+		FROM voip.queue_agents qa
+		INNER JOIN voip.extensions e ON qa.extension_id = e.id
+		INNER JOIN voip.domains d ON e.domain_id = d.id
+		WHERE qa.active = true AND e.active = true
+		ORDER BY qa.extension_id, qa.updated_at DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query agent states: %w", err)
+	}
+	defer rows.Close()
+
+	var states []*models.AgentSyncState
+	for rows.Next() {
+		var s models.AgentSyncState
+		if err := rows.Scan(&s.ExtensionID, &s.Extension, &s.Domain, &s.State, &s.Status); err != nil {
+			return nil, fmt.Errorf("scan agent state: %w", err)
+		}
+		states = append(states, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return states, nil
+}
+
+// ListAgentStateHistory retrieves state changes of an agent in a time range
+func (db *DB) ListAgentStateHistory(ctx context.Context, extensionID int64, startDate, endDate time.Time) ([]*models.AgentStateChange, error) {
+	query := `
+		SELECT id, extension_id, previous_state, state, reason, source, changed_at
+		FROM voip.agent_state_history
+		WHERE extension_id = $1 AND changed_at >= $2 AND changed_at <= $3
+		ORDER BY changed_at
+	`
+
+	rows, err := db.QueryContext(ctx, query, extensionID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("query agent state history: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*models.AgentStateChange
+	for rows.Next() {
+		var c models.AgentStateChange
+		if err := rows.Scan(
+			&c.ID, &c.ExtensionID, &c.PreviousState, &c.State,
+			&c.Reason, &c.Source, &c.ChangedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan agent state change: %w", err)
+		}
+		changes = append(changes, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return changes, nil
+}
+
+// GetAgentStateSummary computes the time an agent spent in each state within
+// a time range. Each history row lasts until the next change (or the end of
+// the range), clipped to the range boundaries.
+func (db *DB) GetAgentStateSummary(ctx context.Context, extensionID int64, startDate, endDate time.Time) ([]*models.AgentStateSummary, error) {
+	query := `
+		WITH changes AS (
+			SELECT
+				state,
+				changed_at,
+				LEAD(changed_at) OVER (ORDER BY changed_at) AS next_changed_at
+			FROM voip.agent_state_history
+			WHERE extension_id = $1 AND changed_at <= $3
+		)
+		SELECT
+			state,
+			COUNT(*) AS occurrences,
+			COALESCE(SUM(EXTRACT(EPOCH FROM (
+				LEAST(COALESCE(next_changed_at, $3), $3) - GREATEST(changed_at, $2)
+			))), 0)::BIGINT AS total_seconds
+		FROM changes
+		WHERE COALESCE(next_changed_at, $3) > $2
+		GROUP BY state
+		ORDER BY state
+	`
+
+	rows, err := db.QueryContext(ctx, query, extensionID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("query agent state summary: %w", err)
+	}
+	defer rows.Close()
+
+	var summary []*models.AgentStateSummary
+	for rows.Next() {
+		var s models.AgentStateSummary
+		if err := rows.Scan(&s.State, &s.Occurrences, &s.TotalSeconds); err != nil {
+			return nil, fmt.Errorf("scan agent state summary: %w", err)
+		}
+		summary = append(summary, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// apiKeyColumns is the column list shared by all API key queries
+const apiKeyColumns = `
+	id, key_name, COALESCE(key_prefix, ''), key_hash, domain_id, permissions,
+	active, created_at, expires_at, last_used_at, revoked_at
+`
+
+// scanAPIKey scans one API key row
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	var permissions []byte
+
+	if err := row.Scan(
+		&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &key.DomainID, &permissions,
+		&key.Active, &key.CreatedAt, &key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(permissions, &key.Permissions); err != nil {
+		return nil, fmt.Errorf("decode permissions of key %d: %w", key.ID, err)
+	}
+
+	return &key, nil
+}
+
+// GetAPIKeyByHash retrieves an API key by the SHA-256 hash of its value
+func (db *DB) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM voip.api_keys WHERE key_hash = $1`
+
+	key, err := scanAPIKey(db.QueryRowContext(ctx, query, hash))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api key not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKey retrieves an API key by ID
+func (db *DB) GetAPIKey(ctx context.Context, id int64) (*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM voip.api_keys WHERE id = $1`
+
+	key, err := scanAPIKey(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api key not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys retrieves API keys, optionally restricted to one domain
+func (db *DB) ListAPIKeys(ctx context.Context, domainID *int64) ([]*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM voip.api_keys`
+	var args []interface{}
+
+	if domainID != nil {
+		query += ` WHERE domain_id = $1`
+		args = append(args, *domainID)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return keys, nil
+}
+
+// CreateAPIKey stores a new API key given its hash and display prefix
+func (db *DB) CreateAPIKey(ctx context.Context, req *models.APIKeyCreateRequest, hash, prefix string) (*models.APIKey, error) {
+	permissions, err := json.Marshal(req.Permissions)
+	if err != nil {
+		return nil, fmt.Errorf("encode permissions: %w", err)
+	}
+
+	query := `
+		INSERT INTO voip.api_keys (
+			key_name, key_hash, key_prefix, domain_id, permissions, active, expires_at
+		) VALUES ($1, $2, $3, $4, $5, true, $6)
+		RETURNING id
+	`
+
+	var id int64
+	if err := db.QueryRowContext(ctx, query,
+		req.Name, hash, prefix, req.DomainID, permissions, req.ExpiresAt,
+	).Scan(&id); err != nil {
+		return nil, fmt.Errorf("insert api key: %w", err)
+	}
+
+	return db.GetAPIKey(ctx, id)
+}
+
+// RotateAPIKey replaces the hash of an active key. The previous key value
+// stops working immediately.
+func (db *DB) RotateAPIKey(ctx context.Context, id int64, hash, prefix string) (*models.APIKey, error) {
+	query := `
+		UPDATE voip.api_keys
+		SET key_hash = $1, key_prefix = $2, last_used_at = NULL
+		WHERE id = $3 AND active = true AND revoked_at IS NULL
+	`
+
+	result, err := db.ExecContext(ctx, query, hash, prefix, id)
+	if err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("active api key not found: %d", id)
+	}
+
+	return db.GetAPIKey(ctx, id)
+}
+
+// RevokeAPIKey permanently disables an API key
+func (db *DB) RevokeAPIKey(ctx context.Context, id int64) error {
+	query := `
+		UPDATE voip.api_keys
+		SET active = false, revoked_at = $1
+		WHERE id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("api key not found or already revoked: %d", id)
+	}
+
+	return nil
+}
+
+// TouchAPIKey records that a key was used. Writes are throttled to one per
+// minute per key to keep authentication cheap.
+func (db *DB) TouchAPIKey(ctx context.Context, id int64) error {
+	query := `
+		UPDATE voip.api_keys
+		SET last_used_at = NOW()
+		WHERE id = $1
+		  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	if _, err := db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// InsertAuditEntry appends an entry to the audit log
+func (db *DB) InsertAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	query := `
+		INSERT INTO voip.audit_log (
+			actor_type, actor_id, actor_name, action, resource, resource_id,
+			domain_id, changes, source_ip, request_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	var changes interface{}
+	if len(entry.Changes) > 0 {
+		changes = []byte(entry.Changes)
+	}
+
+	if _, err := db.ExecContext(ctx, query,
+		entry.ActorType, entry.ActorID, entry.ActorName, entry.Action, entry.Resource,
+		entry.ResourceID, entry.DomainID, changes, entry.SourceIP, entry.RequestID,
+	); err != nil {
+		return fmt.Errorf("insert audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuditEntries retrieves audit log entries with pagination and filtering
+func (db *DB) ListAuditEntries(ctx context.Context, req *models.AuditListRequest) (*models.AuditListResponse, error) {
+	var conditions []string
+	var args []interface{}
+	argPos := 1
+
+	if req.StartDate != nil {
+		conditions = append(conditions, fmt.Sprintf("occurred_at >= $%d", argPos))
+		args = append(args, *req.StartDate)
+		argPos++
+	}
+
+	if req.EndDate != nil {
+		conditions = append(conditions, fmt.Sprintf("occurred_at <= $%d", argPos))
+		args = append(args, *req.EndDate)
+		argPos++
+	}
+
+	if req.ActorType != nil {
+		conditions = append(conditions, fmt.Sprintf("actor_type = $%d", argPos))
+		args = append(args, *req.ActorType)
+		argPos++
+	}
+
+	if req.ActorID != nil {
+		conditions = append(conditions, fmt.Sprintf("actor_id = $%d", argPos))
+		args = append(args, *req.ActorID)
+		argPos++
+	}
+
+	if req.Action != nil {
+		conditions = append(conditions, fmt.Sprintf("action = $%d", argPos))
+		args = append(args, *req.Action)
+		argPos++
+	}
+
+	if req.Resource != nil {
+		conditions = append(conditions, fmt.Sprintf("resource = $%d", argPos))
+		args = append(args, *req.Resource)
+		argPos++
+	}
+
+	if req.ResourceID != nil {
+		conditions = append(conditions, fmt.Sprintf("resource_id = $%d", argPos))
+		args = append(args, *req.ResourceID)
+		argPos++
+	}
+
+	if req.DomainID != nil {
+		conditions = append(conditions, fmt.Sprintf("domain_id = $%d", argPos))
+		args = append(args, *req.DomainID)
+		argPos++
+	}
+
+	if req.RequestID != nil {
+		conditions = append(conditions, fmt.Sprintf("request_id = $%d", argPos))
+		args = append(args, *req.RequestID)
+		argPos++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Count total
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM voip.audit_log
+		%s
+	`, whereClause)
+
+	var total int64
+	if err := db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count audit entries: %w", err)
+	}
+
+	// Fetch entries
+	offset := (req.Page - 1) * req.PerPage
+	args = append(args, req.PerPage, offset)
+
+	query := fmt.Sprintf(`
+		SELECT
+			id, occurred_at, actor_type, actor_id, COALESCE(actor_name, ''),
+			action, resource, COALESCE(resource_id, ''), domain_id, changes,
+			COALESCE(source_ip, ''), COALESCE(request_id, '')
+		FROM voip.audit_log
+		%s
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argPos, argPos+1)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		var changes []byte
+		if err := rows.Scan(
+			&entry.ID, &entry.OccurredAt, &entry.ActorType, &entry.ActorID, &entry.ActorName,
+			&entry.Action, &entry.Resource, &entry.ResourceID, &entry.DomainID, &changes,
+			&entry.SourceIP, &entry.RequestID,
+		); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		entry.Changes = changes
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &models.AuditListResponse{
+		Entries: entries,
+		Total:   total,
+		Page:    req.Page,
+		PerPage: req.PerPage,
+	}, nil
+}
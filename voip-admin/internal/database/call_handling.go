@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// GetCallHandling retrieves the call handling settings of an extension.
+// Extensions without settings get the defaults: ring, then voicemail.
+func (db *DB) GetCallHandling(ctx context.Context, extensionID int64) (*models.CallHandling, error) {
+	query := `
+		SELECT
+			extension_id, dnd, COALESCE(forward_always, ''),
+			COALESCE(forward_busy, ''), COALESCE(forward_no_answer, ''),
+			follow_me, updated_at
+		FROM voip.extension_call_handling
+		WHERE extension_id = $1
+	`
+
+	ch := models.CallHandling{FollowMe: []string{}}
+	err := db.QueryRowContext(ctx, query, extensionID).Scan(
+		&ch.ExtensionID, &ch.DND, &ch.ForwardAlways,
+		&ch.ForwardBusy, &ch.ForwardNoAnswer,
+		pq.Array(&ch.FollowMe), &ch.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return &models.CallHandling{ExtensionID: extensionID, FollowMe: []string{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query call handling: %w", err)
+	}
+
+	return &ch, nil
+}
+
+// SetCallHandling replaces the call handling settings of an extension
+func (db *DB) SetCallHandling(ctx context.Context, extensionID int64, req *models.CallHandlingUpdate) (*models.CallHandling, error) {
+	followMe := req.FollowMe
+	if followMe == nil {
+		followMe = []string{}
+	}
+
+	query := `
+		INSERT INTO voip.extension_call_handling (
+			extension_id, dnd, forward_always, forward_busy,
+			forward_no_answer, follow_me, updated_at
+		) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, NOW())
+		ON CONFLICT (extension_id) DO UPDATE
+		SET dnd = EXCLUDED.dnd,
+			forward_always = EXCLUDED.forward_always,
+			forward_busy = EXCLUDED.forward_busy,
+			forward_no_answer = EXCLUDED.forward_no_answer,
+			follow_me = EXCLUDED.follow_me,
+			updated_at = NOW()
+	`
+
+	if _, err := db.ExecContext(ctx, query,
+		extensionID, req.DND, req.ForwardAlways, req.ForwardBusy,
+		req.ForwardNoAnswer, pq.Array(followMe),
+	); err != nil {
+		return nil, fmt.Errorf("upsert call handling: %w", err)
+	}
+
+	return db.GetCallHandling(ctx, extensionID)
+}
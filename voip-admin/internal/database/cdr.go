@@ -125,12 +125,12 @@ func (db *DB) InsertCDR(ctx context.Context, cdr *models.CDR) error {
 			sip_from_user, sip_to_user, sip_call_id, user_agent,
 			read_codec, write_codec, remote_media_ip,
 			rtp_audio_in_mos, rtp_audio_in_packet_count, rtp_audio_in_packet_loss,
-			rtp_audio_in_jitter_min, rtp_audio_in_jitter_max
+			rtp_audio_in_jitter_min, rtp_audio_in_jitter_max, trunk, forwarded_by
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
 			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
 			$21, $22, $23, $24, $25, $26, $27, $28, $29, $30,
-			$31, $32, $33, $34, $35
+			$31, $32, $33, $34, $35, $36, $37
 		)
 		ON CONFLICT (uuid) DO NOTHING
 		RETURNING id, created_at
@@ -145,7 +145,7 @@ func (db *DB) InsertCDR(ctx context.Context, cdr *models.CDR) error {
 		cdr.SIPFromUser, cdr.SIPToUser, cdr.SIPCallID, cdr.UserAgent,
 		cdr.ReadCodec, cdr.WriteCodec, cdr.RemoteMediaIP,
 		cdr.RTPAudioInMOS, cdr.RTPAudioInPacketCount, cdr.RTPAudioInPacketLoss,
-		cdr.RTPAudioInJitterMin, cdr.RTPAudioInJitterMax,
+		cdr.RTPAudioInJitterMin, cdr.RTPAudioInJitterMax, cdr.Trunk, cdr.ForwardedBy,
 	).Scan(&cdr.ID, &cdr.CreatedAt)
 
 	if err != nil {
@@ -167,7 +167,8 @@ func (db *DB) GetCDRByUUID(ctx context.Context, uuid string) (*models.CDR, error
 			sip_from_user, sip_to_user, sip_call_id, user_agent,
 			read_codec, write_codec, remote_media_ip,
 			rtp_audio_in_mos, rtp_audio_in_packet_count, rtp_audio_in_packet_loss,
-			rtp_audio_in_jitter_min, rtp_audio_in_jitter_max, created_at
+			rtp_audio_in_jitter_min, rtp_audio_in_jitter_max, created_at,
+			trunk, forwarded_by, rate_prefix, rate_description, billed_seconds, sell_cost, buy_cost
 		FROM voip.cdr
 		WHERE uuid = $1
 	`
@@ -183,6 +184,7 @@ func (db *DB) GetCDRByUUID(ctx context.Context, uuid string) (*models.CDR, error
 		&cdr.ReadCodec, &cdr.WriteCodec, &cdr.RemoteMediaIP,
 		&cdr.RTPAudioInMOS, &cdr.RTPAudioInPacketCount, &cdr.RTPAudioInPacketLoss,
 		&cdr.RTPAudioInJitterMin, &cdr.RTPAudioInJitterMax, &cdr.CreatedAt,
+		&cdr.Trunk, &cdr.ForwardedBy, &cdr.RatePrefix, &cdr.RateDescription, &cdr.BilledSeconds, &cdr.SellCost, &cdr.BuyCost,
 	)
 
 	if err == sql.ErrNoRows {
@@ -198,55 +200,12 @@ func (db *DB) GetCDRByUUID(ctx context.Context, uuid string) (*models.CDR, error
 // ListCDRs retrieves CDRs with pagination and filtering
 func (db *DB) ListCDRs(ctx context.Context, req *models.CDRListRequest) (*models.CDRListResponse, error) {
 	// Build WHERE clause
-	var conditions []string
-	var args []interface{}
-	argPos := 1
-
-	if req.StartDate != nil {
-		conditions = append(conditions, fmt.Sprintf("start_stamp >= $%d", argPos))
-		args = append(args, *req.StartDate)
-		argPos++
-	}
-
-	if req.EndDate != nil {
-		conditions = append(conditions, fmt.Sprintf("start_stamp <= $%d", argPos))
-		args = append(args, *req.EndDate)
-		argPos++
-	}
-
-	if req.CallerIDNumber != nil {
-		conditions = append(conditions, fmt.Sprintf("caller_id_number LIKE $%d", argPos))
-		args = append(args, "%"+*req.CallerIDNumber+"%")
-		argPos++
-	}
+	conditions, args := cdrConditions(req, "")
+	argPos := len(args) + 1
 
-	if req.DestNumber != nil {
-		conditions = append(conditions, fmt.Sprintf("destination_number LIKE $%d", argPos))
-		args = append(args, "%"+*req.DestNumber+"%")
-		argPos++
-	}
-
-	if req.Direction != nil {
-		conditions = append(conditions, fmt.Sprintf("direction = $%d", argPos))
-		args = append(args, *req.Direction)
-		argPos++
-	}
-
-	if req.HangupCause != nil {
-		conditions = append(conditions, fmt.Sprintf("hangup_cause = $%d", argPos))
-		args = append(args, *req.HangupCause)
-		argPos++
-	}
-
-	if req.QueueID != nil {
-		conditions = append(conditions, fmt.Sprintf("queue_id = $%d", argPos))
-		args = append(args, *req.QueueID)
-		argPos++
-	}
-
-	if req.MinDuration != nil {
-		conditions = append(conditions, fmt.Sprintf("duration >= $%d", argPos))
-		args = append(args, *req.MinDuration)
+	if req.Domain != nil {
+		conditions = append(conditions, fmt.Sprintf("domain = $%d", argPos))
+		args = append(args, *req.Domain)
 		argPos++
 	}
 
@@ -281,7 +240,8 @@ func (db *DB) ListCDRs(ctx context.Context, req *models.CDRListRequest) (*models
 			sip_from_user, sip_to_user, sip_call_id, user_agent,
 			read_codec, write_codec, remote_media_ip,
 			rtp_audio_in_mos, rtp_audio_in_packet_count, rtp_audio_in_packet_loss,
-			rtp_audio_in_jitter_min, rtp_audio_in_jitter_max, created_at
+			rtp_audio_in_jitter_min, rtp_audio_in_jitter_max, created_at,
+			trunk, forwarded_by, rate_prefix, rate_description, billed_seconds, sell_cost, buy_cost
 		FROM voip.cdr
 		%s
 		ORDER BY start_stamp DESC
@@ -307,6 +267,7 @@ func (db *DB) ListCDRs(ctx context.Context, req *models.CDRListRequest) (*models
 			&cdr.ReadCodec, &cdr.WriteCodec, &cdr.RemoteMediaIP,
 			&cdr.RTPAudioInMOS, &cdr.RTPAudioInPacketCount, &cdr.RTPAudioInPacketLoss,
 			&cdr.RTPAudioInJitterMin, &cdr.RTPAudioInJitterMax, &cdr.CreatedAt,
+			&cdr.Trunk, &cdr.ForwardedBy, &cdr.RatePrefix, &cdr.RateDescription, &cdr.BilledSeconds, &cdr.SellCost, &cdr.BuyCost,
 		); err != nil {
 			return nil, fmt.Errorf("scan cdr: %w", err)
 		}
@@ -325,8 +286,49 @@ func (db *DB) ListCDRs(ctx context.Context, req *models.CDRListRequest) (*models
 	}, nil
 }
 
-// GetCDRStats retrieves CDR statistics for a given time period
-func (db *DB) GetCDRStats(ctx context.Context, startDate, endDate time.Time) (*models.CDRStats, error) {
+// cdrConditions builds the WHERE conditions of the call filters of a CDR
+// list request, except the domain, on the voip.cdr columns with prefix
+// (e.g. "c.")
+func cdrConditions(req *models.CDRListRequest, prefix string) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, prefix, len(args)))
+	}
+
+	if req.StartDate != nil {
+		add("%sstart_stamp >= $%d", *req.StartDate)
+	}
+	if req.EndDate != nil {
+		add("%sstart_stamp <= $%d", *req.EndDate)
+	}
+	if req.CallerIDNumber != nil {
+		add("%scaller_id_number LIKE $%d", "%"+*req.CallerIDNumber+"%")
+	}
+	if req.DestNumber != nil {
+		add("%sdestination_number LIKE $%d", "%"+*req.DestNumber+"%")
+	}
+	if req.Direction != nil {
+		add("%sdirection = $%d", *req.Direction)
+	}
+	if req.HangupCause != nil {
+		add("%shangup_cause = $%d", *req.HangupCause)
+	}
+	if req.QueueID != nil {
+		add("%squeue_id = $%d", *req.QueueID)
+	}
+	if req.MinDuration != nil {
+		add("%sduration >= $%d", *req.MinDuration)
+	}
+
+	return conditions, args
+}
+
+// GetCDRStats retrieves CDR statistics for a given time period, optionally
+// restricted to one domain
+func (db *DB) GetCDRStats(ctx context.Context, startDate, endDate time.Time, domain *string) (*models.CDRStats, error) {
 	query := `
 		SELECT
 			COUNT(*) as total_calls,
@@ -338,10 +340,11 @@ func (db *DB) GetCDRStats(ctx context.Context, startDate, endDate time.Time) (*m
 			COALESCE(SUM(billsec), 0) as total_billsec
 		FROM voip.cdr
 		WHERE start_stamp >= $1 AND start_stamp <= $2
+		  AND ($3::text IS NULL OR domain = $3)
 	`
 
 	var stats models.CDRStats
-	err := db.QueryRowContext(ctx, query, startDate, endDate).Scan(
+	err := db.QueryRowContext(ctx, query, startDate, endDate, domain).Scan(
 		&stats.TotalCalls,
 		&stats.AnsweredCalls,
 		&stats.MissedCalls,
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// conferenceRoomColumns are the columns scanned by scanConferenceRoom; the
+// query must alias voip.conference_rooms as cr and join voip.domains as d
+const conferenceRoomColumns = `
+	cr.id, cr.domain_id, cr.extension, cr.name,
+	COALESCE(cr.participant_pin, ''), COALESCE(cr.moderator_pin, ''),
+	cr.max_members, cr.record, cr.profile, cr.mute_on_join, cr.wait_for_moderator,
+	COALESCE(cr.description, ''), cr.active, cr.created_at, cr.updated_at, d.domain
+`
+
+// scanConferenceRoom scans a row of conferenceRoomColumns
+func scanConferenceRoom(row interface{ Scan(...interface{}) error }) (*models.ConferenceRoom, error) {
+	var c models.ConferenceRoom
+	err := row.Scan(
+		&c.ID, &c.DomainID, &c.Extension, &c.Name,
+		&c.ParticipantPIN, &c.ModeratorPIN,
+		&c.MaxMembers, &c.Record, &c.Profile, &c.MuteOnJoin, &c.WaitForModerator,
+		&c.Description, &c.Active, &c.CreatedAt, &c.UpdatedAt, &c.Domain,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetConferenceRoom retrieves a conference room by ID
+func (db *DB) GetConferenceRoom(ctx context.Context, id int64) (*models.ConferenceRoom, error) {
+	query := `
+		SELECT ` + conferenceRoomColumns + `
+		FROM voip.conference_rooms cr
+		INNER JOIN voip.domains d ON cr.domain_id = d.id
+		WHERE cr.id = $1
+	`
+
+	room, err := scanConferenceRoom(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("conference room not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query conference room: %w", err)
+	}
+
+	return room, nil
+}
+
+// GetConferenceRoomByExtension retrieves a conference room by number and
+// domain name
+func (db *DB) GetConferenceRoomByExtension(ctx context.Context, extension, domain string) (*models.ConferenceRoom, error) {
+	query := `
+		SELECT ` + conferenceRoomColumns + `
+		FROM voip.conference_rooms cr
+		INNER JOIN voip.domains d ON cr.domain_id = d.id
+		WHERE cr.extension = $1 AND d.domain = $2
+	`
+
+	room, err := scanConferenceRoom(db.QueryRowContext(ctx, query, extension, domain))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("conference room not found: %s@%s", extension, domain)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query conference room: %w", err)
+	}
+
+	return room, nil
+}
+
+// ListConferenceRooms retrieves all conference rooms, optionally of one
+// domain
+func (db *DB) ListConferenceRooms(ctx context.Context, domainID *int64) ([]*models.ConferenceRoom, error) {
+	query := `
+		SELECT ` + conferenceRoomColumns + `
+		FROM voip.conference_rooms cr
+		INNER JOIN voip.domains d ON cr.domain_id = d.id
+		WHERE ($1::bigint IS NULL OR cr.domain_id = $1)
+		ORDER BY d.domain, cr.extension
+	`
+
+	rows, err := db.QueryContext(ctx, query, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("query conference rooms: %w", err)
+	}
+	defer rows.Close()
+
+	rooms := []*models.ConferenceRoom{}
+	for rows.Next() {
+		room, err := scanConferenceRoom(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan conference room: %w", err)
+		}
+		rooms = append(rooms, room)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return rooms, nil
+}
+
+// CreateConferenceRoom creates a new conference room
+func (db *DB) CreateConferenceRoom(ctx context.Context, room *models.ConferenceRoom) (*models.ConferenceRoom, error) {
+	query := `
+		INSERT INTO voip.conference_rooms (
+			domain_id, extension, name, participant_pin, moderator_pin,
+			max_members, record, profile, mute_on_join, wait_for_moderator,
+			description, active
+		) VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $10, NULLIF($11, ''), $12)
+		RETURNING id
+	`
+
+	var id int64
+	err := db.QueryRowContext(ctx, query,
+		room.DomainID, room.Extension, room.Name, room.ParticipantPIN, room.ModeratorPIN,
+		room.MaxMembers, room.Record, room.Profile, room.MuteOnJoin, room.WaitForModerator,
+		room.Description, room.Active,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("insert conference room: %w", err)
+	}
+
+	return db.GetConferenceRoom(ctx, id)
+}
+
+// UpdateConferenceRoom stores the mutable fields of a conference room; the
+// caller merges the update request into the current room and validates the
+// result
+func (db *DB) UpdateConferenceRoom(ctx context.Context, room *models.ConferenceRoom) (*models.ConferenceRoom, error) {
+	query := `
+		UPDATE voip.conference_rooms
+		SET name = $1, participant_pin = NULLIF($2, ''), moderator_pin = NULLIF($3, ''),
+			max_members = $4, record = $5, profile = $6, mute_on_join = $7,
+			wait_for_moderator = $8, description = NULLIF($9, ''), active = $10,
+			updated_at = NOW()
+		WHERE id = $11
+	`
+
+	result, err := db.ExecContext(ctx, query,
+		room.Name, room.ParticipantPIN, room.ModeratorPIN,
+		room.MaxMembers, room.Record, room.Profile, room.MuteOnJoin,
+		room.WaitForModerator, room.Description, room.Active, room.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("update conference room: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("conference room not found: %d", room.ID)
+	}
+
+	return db.GetConferenceRoom(ctx, room.ID)
+}
+
+// DeleteConferenceRoom deletes a conference room; calls to its number are
+// no longer routed
+func (db *DB) DeleteConferenceRoom(ctx context.Context, id int64) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM voip.conference_rooms WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete conference room: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("conference room not found: %d", id)
+	}
+
+	return nil
+}
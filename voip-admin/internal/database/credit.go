@@ -0,0 +1,227 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// creditSpend returns the SQL summing the sell cost of the month's rated
+// calls of domain d, or of extension e when one is joined. from and to are
+// the parameter numbers of the month bounds.
+func creditSpend(from, to int) string {
+	return fmt.Sprintf(`
+		COALESCE((
+			SELECT SUM(c.sell_cost) FROM voip.cdr c
+			WHERE c.domain = d.domain AND c.sell_cost IS NOT NULL
+			  AND c.start_stamp >= $%d AND c.start_stamp < $%d
+			  AND (e.id IS NULL OR COALESCE(NULLIF(c.forwarded_by, ''), NULLIF(c.sip_from_user, ''), c.caller_id_number) = e.extension)
+		), 0)
+	`, from, to)
+}
+
+// GetCreditLimit retrieves the limits of a domain, or of one of its
+// extensions, with the spend of the month. Without limits set, the limit
+// fields are nil.
+func (db *DB) GetCreditLimit(ctx context.Context, domainID int64, extensionID *int64, month time.Time) (*models.CreditLimit, error) {
+	query := `
+		SELECT d.domain, COALESCE(e.extension, ''), cl.monthly_limit, cl.max_outbound_calls, cl.updated_at,
+			COALESCE(rd.currency, ''), ` + creditSpend(3, 4) + `
+		FROM voip.domains d
+		LEFT JOIN voip.extensions e ON e.id = $2 AND e.domain_id = d.id
+		LEFT JOIN voip.credit_limits cl ON cl.domain_id = d.id AND cl.extension_id IS NOT DISTINCT FROM $2::int
+		LEFT JOIN voip.rate_decks rd ON rd.id = d.rate_deck_id
+		WHERE d.id = $1
+	`
+
+	limit := &models.CreditLimit{
+		DomainID:    domainID,
+		ExtensionID: extensionID,
+		Month:       month.Format("2006-01"),
+	}
+	err := db.QueryRowContext(ctx, query, domainID, extensionID, month, month.AddDate(0, 1, 0)).Scan(
+		&limit.Domain, &limit.Extension, &limit.MonthlyLimit, &limit.MaxOutboundCalls, &limit.UpdatedAt,
+		&limit.Currency, &limit.Spent,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("domain not found: %d", domainID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query credit limit: %w", err)
+	}
+
+	setRemaining(limit)
+
+	return limit, nil
+}
+
+// setRemaining derives the remaining credit of a limit from its spend
+func setRemaining(limit *models.CreditLimit) {
+	if limit.MonthlyLimit == nil {
+		return
+	}
+	remaining := *limit.MonthlyLimit - limit.Spent
+	if remaining < 0 {
+		remaining = 0
+	}
+	limit.Remaining = &remaining
+}
+
+// SetCreditLimit replaces the limits of a domain, or of one of its
+// extensions. Removing both limits deletes the row.
+func (db *DB) SetCreditLimit(ctx context.Context, domainID int64, extensionID *int64, req *models.CreditLimitUpdate) error {
+	if req.MonthlyLimit == nil && req.MaxOutboundCalls == nil {
+		_, err := db.ExecContext(ctx, `
+			DELETE FROM voip.credit_limits
+			WHERE domain_id = $1 AND extension_id IS NOT DISTINCT FROM $2::int
+		`, domainID, extensionID)
+		if err != nil {
+			return fmt.Errorf("delete credit limit: %w", err)
+		}
+		return nil
+	}
+
+	// The unique index to conflict on depends on the kind of limit
+	target := `(domain_id) WHERE extension_id IS NULL`
+	if extensionID != nil {
+		target = `(extension_id) WHERE extension_id IS NOT NULL`
+	}
+
+	query := `
+		INSERT INTO voip.credit_limits (domain_id, extension_id, monthly_limit, max_outbound_calls)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT ` + target + ` DO UPDATE
+		SET monthly_limit = EXCLUDED.monthly_limit, max_outbound_calls = EXCLUDED.max_outbound_calls,
+			updated_at = NOW()
+	`
+
+	if _, err := db.ExecContext(ctx, query, domainID, extensionID, req.MonthlyLimit, req.MaxOutboundCalls); err != nil {
+		return fmt.Errorf("upsert credit limit: %w", err)
+	}
+
+	return nil
+}
+
+// ListCreditUsage retrieves every monthly limit with the spend of the month
+func (db *DB) ListCreditUsage(ctx context.Context, month time.Time) ([]*models.CreditLimit, error) {
+	query := `
+		SELECT cl.domain_id, d.domain, cl.extension_id, COALESCE(e.extension, ''),
+			cl.monthly_limit, cl.max_outbound_calls, cl.updated_at, COALESCE(rd.currency, ''),
+			` + creditSpend(1, 2) + `
+		FROM voip.credit_limits cl
+		INNER JOIN voip.domains d ON d.id = cl.domain_id
+		LEFT JOIN voip.extensions e ON e.id = cl.extension_id
+		LEFT JOIN voip.rate_decks rd ON rd.id = d.rate_deck_id
+		WHERE cl.monthly_limit IS NOT NULL
+		ORDER BY d.domain, e.extension NULLS FIRST
+	`
+
+	rows, err := db.QueryContext(ctx, query, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("query credit usage: %w", err)
+	}
+	defer rows.Close()
+
+	limits := []*models.CreditLimit{}
+	for rows.Next() {
+		limit := &models.CreditLimit{Month: month.Format("2006-01")}
+		if err := rows.Scan(
+			&limit.DomainID, &limit.Domain, &limit.ExtensionID, &limit.Extension,
+			&limit.MonthlyLimit, &limit.MaxOutboundCalls, &limit.UpdatedAt, &limit.Currency,
+			&limit.Spent,
+		); err != nil {
+			return nil, fmt.Errorf("scan credit usage: %w", err)
+		}
+		setRemaining(limit)
+		limits = append(limits, limit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return limits, nil
+}
+
+// CreateCreditAlert stores an alert unless the limit already alerted at
+// this threshold in the month. It reports whether the alert was stored.
+func (db *DB) CreateCreditAlert(ctx context.Context, alert *models.CreditAlert) (bool, error) {
+	month, err := time.Parse("2006-01", alert.Month)
+	if err != nil {
+		return false, fmt.Errorf("parse month: %w", err)
+	}
+
+	query := `
+		INSERT INTO voip.credit_alerts (
+			domain_id, extension_id, extension, month, threshold, spent, monthly_limit
+		) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+		ON CONFLICT (domain_id, COALESCE(extension_id, 0), month, threshold) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err = db.QueryRowContext(ctx, query,
+		alert.DomainID, alert.ExtensionID, alert.Extension, month, alert.Threshold, alert.Spent, alert.MonthlyLimit,
+	).Scan(&alert.ID, &alert.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("insert credit alert: %w", err)
+	}
+
+	return true, nil
+}
+
+// ListCreditAlerts retrieves the credit alerts of a domain, newest first
+func (db *DB) ListCreditAlerts(ctx context.Context, domainID int64, page, perPage int) (*models.CreditAlertListResponse, error) {
+	var total int64
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM voip.credit_alerts WHERE domain_id = $1`, domainID,
+	).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count credit alerts: %w", err)
+	}
+
+	query := `
+		SELECT ca.id, ca.domain_id, d.domain, ca.extension_id, COALESCE(ca.extension, ''),
+			ca.month, ca.threshold, ca.spent, ca.monthly_limit, ca.created_at
+		FROM voip.credit_alerts ca
+		INNER JOIN voip.domains d ON d.id = ca.domain_id
+		WHERE ca.domain_id = $1
+		ORDER BY ca.created_at DESC, ca.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := db.QueryContext(ctx, query, domainID, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, fmt.Errorf("query credit alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []*models.CreditAlert{}
+	for rows.Next() {
+		var alert models.CreditAlert
+		var month time.Time
+		if err := rows.Scan(
+			&alert.ID, &alert.DomainID, &alert.Domain, &alert.ExtensionID, &alert.Extension,
+			&month, &alert.Threshold, &alert.Spent, &alert.MonthlyLimit, &alert.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan credit alert: %w", err)
+		}
+		alert.Month = month.Format("2006-01")
+		alerts = append(alerts, &alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &models.CreditAlertListResponse{
+		Alerts:  alerts,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
@@ -0,0 +1,190 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// didColumns are the columns scanned by scanDID; the query must alias
+// voip.dids as did and join voip.domains as d
+const didColumns = `
+	did.id, did.domain_id, did.number, did.destination_type,
+	COALESCE(did.destination, ''), did.time_condition_id,
+	COALESCE(did.caller_id_name_prefix, ''), COALESCE(did.description, ''),
+	did.active, did.created_at, did.updated_at, d.domain
+`
+
+// scanDID scans a row of didColumns
+func scanDID(row interface{ Scan(...interface{}) error }) (*models.DID, error) {
+	var did models.DID
+	err := row.Scan(
+		&did.ID, &did.DomainID, &did.Number, &did.DestinationType,
+		&did.Destination, &did.TimeConditionID,
+		&did.CallerIDNamePrefix, &did.Description,
+		&did.Active, &did.CreatedAt, &did.UpdatedAt, &did.Domain,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &did, nil
+}
+
+// GetDID retrieves a DID by ID
+func (db *DB) GetDID(ctx context.Context, id int64) (*models.DID, error) {
+	query := `
+		SELECT ` + didColumns + `
+		FROM voip.dids did
+		INNER JOIN voip.domains d ON did.domain_id = d.id
+		WHERE did.id = $1
+	`
+
+	did, err := scanDID(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("DID not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query DID: %w", err)
+	}
+
+	return did, nil
+}
+
+// GetDIDByNumber retrieves a DID by the number a carrier delivered, with or
+// without the leading +
+func (db *DB) GetDIDByNumber(ctx context.Context, number string) (*models.DID, error) {
+	query := `
+		SELECT ` + didColumns + `
+		FROM voip.dids did
+		INNER JOIN voip.domains d ON did.domain_id = d.id
+		WHERE LTRIM(did.number, '+') = LTRIM($1, '+')
+	`
+
+	did, err := scanDID(db.QueryRowContext(ctx, query, number))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("DID not found: %s", number)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query DID: %w", err)
+	}
+
+	return did, nil
+}
+
+// ListDIDs retrieves all DIDs, optionally of one domain
+func (db *DB) ListDIDs(ctx context.Context, domainID *int64) ([]*models.DID, error) {
+	query := `
+		SELECT ` + didColumns + `
+		FROM voip.dids did
+		INNER JOIN voip.domains d ON did.domain_id = d.id
+		WHERE ($1::bigint IS NULL OR did.domain_id = $1)
+		ORDER BY d.domain, did.number
+	`
+
+	rows, err := db.QueryContext(ctx, query, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("query DIDs: %w", err)
+	}
+	defer rows.Close()
+
+	dids := []*models.DID{}
+	for rows.Next() {
+		did, err := scanDID(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan DID: %w", err)
+		}
+		dids = append(dids, did)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return dids, nil
+}
+
+// CountTimeConditionDIDs counts the DIDs routed to a time condition
+func (db *DB) CountTimeConditionDIDs(ctx context.Context, timeConditionID int64) (int64, error) {
+	var count int64
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM voip.dids WHERE time_condition_id = $1`, timeConditionID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count time condition DIDs: %w", err)
+	}
+	return count, nil
+}
+
+// CreateDID creates a new DID
+func (db *DB) CreateDID(ctx context.Context, req *models.DIDCreateRequest) (*models.DID, error) {
+	query := `
+		INSERT INTO voip.dids (
+			domain_id, number, destination_type, destination, time_condition_id,
+			caller_id_name_prefix, description, active
+		) VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), $8)
+		RETURNING id
+	`
+
+	var id int64
+	err := db.QueryRowContext(ctx, query,
+		req.DomainID, req.Number, req.DestinationType, req.Destination, req.TimeConditionID,
+		req.CallerIDNamePrefix, req.Description, req.Active,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("insert DID: %w", err)
+	}
+
+	return db.GetDID(ctx, id)
+}
+
+// UpdateDID stores the mutable fields of a DID; the caller merges the
+// update request into the current DID and validates the result
+func (db *DB) UpdateDID(ctx context.Context, did *models.DID) (*models.DID, error) {
+	query := `
+		UPDATE voip.dids
+		SET destination_type = $1, destination = NULLIF($2, ''), time_condition_id = $3,
+			caller_id_name_prefix = NULLIF($4, ''), description = NULLIF($5, ''),
+			active = $6, updated_at = NOW()
+		WHERE id = $7
+	`
+
+	result, err := db.ExecContext(ctx, query,
+		did.DestinationType, did.Destination, did.TimeConditionID,
+		did.CallerIDNamePrefix, did.Description, did.Active, did.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("update DID: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("DID not found: %d", did.ID)
+	}
+
+	return db.GetDID(ctx, did.ID)
+}
+
+// DeleteDID deletes a DID; calls to its number are no longer routed
+func (db *DB) DeleteDID(ctx context.Context, id int64) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM voip.dids WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete DID: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("DID not found: %d", id)
+	}
+
+	return nil
+}
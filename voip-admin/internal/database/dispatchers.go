@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// dispatcherColumns are the kamailio.dispatcher columns scanned by
+// scanDispatcher
+const dispatcherColumns = `id, setid, destination, flags, priority, attrs, description`
+
+// scanDispatcher scans a row of dispatcherColumns
+func scanDispatcher(row interface{ Scan(...interface{}) error }) (*models.Dispatcher, error) {
+	var d models.Dispatcher
+	var attrs string
+	err := row.Scan(&d.ID, &d.SetID, &d.Destination, &d.Flags, &d.Priority, &attrs, &d.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Weight, d.Attrs = splitDispatcherAttrs(attrs)
+	d.Drained = d.Flags&models.DispatcherFlagDisabled != 0
+
+	return &d, nil
+}
+
+// splitDispatcherAttrs separates the weight from the other attributes of a
+// destination ("weight=50;duid=fs1")
+func splitDispatcherAttrs(attrs string) (int, string) {
+	weight := 0
+	var rest []string
+	for _, attr := range strings.Split(attrs, ";") {
+		attr = strings.TrimSpace(attr)
+		if attr == "" {
+			continue
+		}
+		if value, ok := strings.CutPrefix(attr, "weight="); ok {
+			if w, err := strconv.Atoi(value); err == nil {
+				weight = w
+				continue
+			}
+		}
+		rest = append(rest, attr)
+	}
+	return weight, strings.Join(rest, ";")
+}
+
+// joinDispatcherAttrs builds the attrs column of a destination
+func joinDispatcherAttrs(d *models.Dispatcher) string {
+	var attrs []string
+	if d.Weight > 0 {
+		attrs = append(attrs, "weight="+strconv.Itoa(d.Weight))
+	}
+	if d.Attrs != "" {
+		attrs = append(attrs, d.Attrs)
+	}
+	return strings.Join(attrs, ";")
+}
+
+// GetDispatcher retrieves a dispatcher destination by ID
+func (db *DB) GetDispatcher(ctx context.Context, id int64) (*models.Dispatcher, error) {
+	query := `SELECT ` + dispatcherColumns + ` FROM kamailio.dispatcher WHERE id = $1`
+
+	d, err := scanDispatcher(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("dispatcher destination not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query dispatcher destination: %w", err)
+	}
+
+	return d, nil
+}
+
+// GetDispatcherByDestination retrieves a destination of a set by its URI
+func (db *DB) GetDispatcherByDestination(ctx context.Context, setID int, destination string) (*models.Dispatcher, error) {
+	query := `SELECT ` + dispatcherColumns + ` FROM kamailio.dispatcher WHERE setid = $1 AND destination = $2`
+
+	d, err := scanDispatcher(db.QueryRowContext(ctx, query, setID, destination))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("dispatcher destination not found: %s", destination)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query dispatcher destination: %w", err)
+	}
+
+	return d, nil
+}
+
+// ListDispatchers retrieves all dispatcher destinations
+func (db *DB) ListDispatchers(ctx context.Context) ([]*models.Dispatcher, error) {
+	query := `SELECT ` + dispatcherColumns + ` FROM kamailio.dispatcher ORDER BY setid, priority DESC, destination`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query dispatcher destinations: %w", err)
+	}
+	defer rows.Close()
+
+	dispatchers := []*models.Dispatcher{}
+	for rows.Next() {
+		d, err := scanDispatcher(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan dispatcher destination: %w", err)
+		}
+		dispatchers = append(dispatchers, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return dispatchers, nil
+}
+
+// CreateDispatcher adds a dispatcher destination
+func (db *DB) CreateDispatcher(ctx context.Context, d *models.Dispatcher) (*models.Dispatcher, error) {
+	query := `
+		INSERT INTO kamailio.dispatcher (setid, destination, flags, priority, attrs, description)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + dispatcherColumns
+
+	created, err := scanDispatcher(db.QueryRowContext(ctx, query,
+		d.SetID, d.Destination, d.Flags, d.Priority, joinDispatcherAttrs(d), d.Description,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("insert dispatcher destination: %w", err)
+	}
+
+	return created, nil
+}
+
+// UpdateDispatcher stores the flags, priority, attributes and description
+// of a destination; the caller merges the update request into the current
+// destination and validates the result
+func (db *DB) UpdateDispatcher(ctx context.Context, d *models.Dispatcher) (*models.Dispatcher, error) {
+	query := `
+		UPDATE kamailio.dispatcher
+		SET flags = $1, priority = $2, attrs = $3, description = $4
+		WHERE id = $5
+		RETURNING ` + dispatcherColumns
+
+	updated, err := scanDispatcher(db.QueryRowContext(ctx, query,
+		d.Flags, d.Priority, joinDispatcherAttrs(d), d.Description, d.ID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("dispatcher destination not found: %d", d.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("update dispatcher destination: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteDispatcher removes a dispatcher destination
+func (db *DB) DeleteDispatcher(ctx context.Context, id int64) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM kamailio.dispatcher WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete dispatcher destination: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("dispatcher destination not found: %d", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// GetDomain retrieves a domain by ID
+func (db *DB) GetDomain(ctx context.Context, id int64) (*models.Domain, error) {
+	query := `
+		SELECT id, domain, COALESCE(tenant_name, ''), active, created_at, updated_at,
+			COALESCE(voicemail_main_number, ''), rate_deck_id
+		FROM voip.domains
+		WHERE id = $1
+	`
+
+	var domain models.Domain
+	err := db.QueryRowContext(ctx, query, id).Scan(
+		&domain.ID, &domain.Domain, &domain.TenantName, &domain.Active,
+		&domain.CreatedAt, &domain.UpdatedAt, &domain.VoicemailMainNumber, &domain.RateDeckID,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("domain not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query domain: %w", err)
+	}
+
+	return &domain, nil
+}
+
+// GetDomainByName retrieves a domain by name
+func (db *DB) GetDomainByName(ctx context.Context, name string) (*models.Domain, error) {
+	query := `
+		SELECT id, domain, COALESCE(tenant_name, ''), active, created_at, updated_at,
+			COALESCE(voicemail_main_number, ''), rate_deck_id
+		FROM voip.domains
+		WHERE domain = $1
+	`
+
+	var domain models.Domain
+	err := db.QueryRowContext(ctx, query, name).Scan(
+		&domain.ID, &domain.Domain, &domain.TenantName, &domain.Active,
+		&domain.CreatedAt, &domain.UpdatedAt, &domain.VoicemailMainNumber, &domain.RateDeckID,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("domain not found: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query domain: %w", err)
+	}
+
+	return &domain, nil
+}
+
+// ListDomains retrieves domains with pagination and filtering
+func (db *DB) ListDomains(ctx context.Context, active *bool, page, perPage int) (*models.DomainListResponse, error) {
+	var conditions []string
+	var args []interface{}
+	argPos := 1
+
+	if active != nil {
+		conditions = append(conditions, fmt.Sprintf("active = $%d", argPos))
+		args = append(args, *active)
+		argPos++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Count total
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM voip.domains
+		%s
+	`, whereClause)
+
+	var total int64
+	if err := db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count domains: %w", err)
+	}
+
+	// Fetch domains
+	offset := (page - 1) * perPage
+	args = append(args, perPage, offset)
+
+	query := fmt.Sprintf(`
+		SELECT id, domain, COALESCE(tenant_name, ''), active, created_at, updated_at,
+			COALESCE(voicemail_main_number, ''), rate_deck_id
+		FROM voip.domains
+		%s
+		ORDER BY domain
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argPos, argPos+1)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query domains: %w", err)
+	}
+	defer rows.Close()
+
+	var domains []*models.Domain
+	for rows.Next() {
+		var domain models.Domain
+		if err := rows.Scan(
+			&domain.ID, &domain.Domain, &domain.TenantName, &domain.Active,
+			&domain.CreatedAt, &domain.UpdatedAt, &domain.VoicemailMainNumber, &domain.RateDeckID,
+		); err != nil {
+			return nil, fmt.Errorf("scan domain: %w", err)
+		}
+		domains = append(domains, &domain)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &models.DomainListResponse{
+		Domains: domains,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+// CreateDomain creates a new domain
+func (db *DB) CreateDomain(ctx context.Context, req *models.DomainCreateRequest) (*models.Domain, error) {
+	query := `
+		INSERT INTO voip.domains (domain, tenant_name, active)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+
+	var id int64
+	if err := db.QueryRowContext(ctx, query, req.Domain, req.TenantName, req.Active).Scan(&id); err != nil {
+		return nil, fmt.Errorf("insert domain: %w", err)
+	}
+
+	return db.GetDomain(ctx, id)
+}
+
+// UpdateDomain updates an existing domain
+func (db *DB) UpdateDomain(ctx context.Context, id int64, req *models.DomainUpdateRequest) (*models.Domain, error) {
+	var setClauses []string
+	var args []interface{}
+	argPos := 1
+
+	if req.TenantName != nil {
+		setClauses = append(setClauses, fmt.Sprintf("tenant_name = $%d", argPos))
+		args = append(args, *req.TenantName)
+		argPos++
+	}
+
+	if req.Active != nil {
+		setClauses = append(setClauses, fmt.Sprintf("active = $%d", argPos))
+		args = append(args, *req.Active)
+		argPos++
+	}
+
+	if req.VoicemailMainNumber != nil {
+		setClauses = append(setClauses, fmt.Sprintf("voicemail_main_number = NULLIF($%d, '')", argPos))
+		args = append(args, *req.VoicemailMainNumber)
+		argPos++
+	}
+
+	if req.RateDeckID != nil {
+		setClauses = append(setClauses, fmt.Sprintf("rate_deck_id = NULLIF($%d, 0)", argPos))
+		args = append(args, *req.RateDeckID)
+		argPos++
+	}
+
+	if len(setClauses) == 0 {
+		return db.GetDomain(ctx, id)
+	}
+
+	// Add updated_at
+	setClauses = append(setClauses, fmt.Sprintf("updated_at = $%d", argPos))
+	args = append(args, time.Now())
+	argPos++
+
+	// Add ID for WHERE clause
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+		UPDATE voip.domains
+		SET %s
+		WHERE id = $%d
+	`, strings.Join(setClauses, ", "), argPos)
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("update domain: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("domain not found: %d", id)
+	}
+
+	return db.GetDomain(ctx, id)
+}
+
+// GetDomainUsage counts the objects that belong to a domain
+func (db *DB) GetDomainUsage(ctx context.Context, id int64) (*models.DomainUsage, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM voip.extensions WHERE domain_id = $1),
+			(SELECT COUNT(*) FROM voip.queues WHERE domain_id = $1),
+			(SELECT COUNT(*) FROM voip.users WHERE domain_id = $1),
+			(SELECT COUNT(*) FROM voip.trunks WHERE domain_id = $1)
+	`
+
+	var usage models.DomainUsage
+	if err := db.QueryRowContext(ctx, query, id).Scan(
+		&usage.Extensions, &usage.Queues, &usage.Users, &usage.Trunks,
+	); err != nil {
+		return nil, fmt.Errorf("query domain usage: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// DeleteDomain permanently deletes a domain. Extensions, queues, users and
+// trunks of the domain are removed by ON DELETE CASCADE.
+func (db *DB) DeleteDomain(ctx context.Context, id int64) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM voip.domains WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete domain: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("domain not found: %d", id)
+	}
+
+	return nil
+}
@@ -8,28 +8,30 @@ import (
 	"time"
 
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/sipauth"
 )
 
-// GetExtension retrieves a single extension by extension number and domain
+// GetExtension retrieves a single extension by extension number and domain.
+// Extensions of inactive domains are reported as not found.
 func (db *DB) GetExtension(ctx context.Context, extension, domain string) (*models.Extension, error) {
 	query := `
 		SELECT
 			e.id, e.domain_id, e.extension, e.type, e.display_name,
-			e.email, e.sip_password, e.sip_ha1, e.sip_ha1b,
-			e.vm_password, e.vm_email, e.active, e.max_concurrent,
-			e.call_timeout, e.created_at, e.updated_at,
+			e.email, e.sip_ha1, e.sip_ha1b,
+			e.vm_password, e.vm_email, e.vm_auto_login, e.active, e.max_concurrent,
+			e.call_timeout, e.created_at, e.updated_at, e.user_id,
 			d.domain
 		FROM voip.extensions e
 		INNER JOIN voip.domains d ON e.domain_id = d.id
-		WHERE e.extension = $1 AND d.domain = $2
+		WHERE e.extension = $1 AND d.domain = $2 AND d.active = true
 	`
 
 	var ext models.Extension
 	err := db.QueryRowContext(ctx, query, extension, domain).Scan(
 		&ext.ID, &ext.DomainID, &ext.Extension, &ext.Type, &ext.DisplayName,
-		&ext.Email, &ext.SIPPassword, &ext.SIPHA1, &ext.SIPHA1B,
-		&ext.VMPassword, &ext.VMEmail, &ext.Active, &ext.MaxConcurrent,
-		&ext.CallTimeout, &ext.CreatedAt, &ext.UpdatedAt,
+		&ext.Email, &ext.SIPHA1, &ext.SIPHA1B,
+		&ext.VMPassword, &ext.VMEmail, &ext.VMAutoLogin, &ext.Active, &ext.MaxConcurrent,
+		&ext.CallTimeout, &ext.CreatedAt, &ext.UpdatedAt, &ext.UserID,
 		&ext.Domain,
 	)
 
@@ -48,9 +50,9 @@ func (db *DB) GetExtensionByID(ctx context.Context, id int64) (*models.Extension
 	query := `
 		SELECT
 			e.id, e.domain_id, e.extension, e.type, e.display_name,
-			e.email, e.sip_password, e.sip_ha1, e.sip_ha1b,
-			e.vm_password, e.vm_email, e.active, e.max_concurrent,
-			e.call_timeout, e.created_at, e.updated_at,
+			e.email, e.sip_ha1, e.sip_ha1b,
+			e.vm_password, e.vm_email, e.vm_auto_login, e.active, e.max_concurrent,
+			e.call_timeout, e.created_at, e.updated_at, e.user_id,
 			d.domain
 		FROM voip.extensions e
 		INNER JOIN voip.domains d ON e.domain_id = d.id
@@ -60,9 +62,9 @@ func (db *DB) GetExtensionByID(ctx context.Context, id int64) (*models.Extension
 	var ext models.Extension
 	err := db.QueryRowContext(ctx, query, id).Scan(
 		&ext.ID, &ext.DomainID, &ext.Extension, &ext.Type, &ext.DisplayName,
-		&ext.Email, &ext.SIPPassword, &ext.SIPHA1, &ext.SIPHA1B,
-		&ext.VMPassword, &ext.VMEmail, &ext.Active, &ext.MaxConcurrent,
-		&ext.CallTimeout, &ext.CreatedAt, &ext.UpdatedAt,
+		&ext.Email, &ext.SIPHA1, &ext.SIPHA1B,
+		&ext.VMPassword, &ext.VMEmail, &ext.VMAutoLogin, &ext.Active, &ext.MaxConcurrent,
+		&ext.CallTimeout, &ext.CreatedAt, &ext.UpdatedAt, &ext.UserID,
 		&ext.Domain,
 	)
 
@@ -125,9 +127,9 @@ func (db *DB) ListExtensions(ctx context.Context, domainID *int64, extType *stri
 	query := fmt.Sprintf(`
 		SELECT
 			e.id, e.domain_id, e.extension, e.type, e.display_name,
-			e.email, e.sip_password, e.sip_ha1, e.sip_ha1b,
-			e.vm_password, e.vm_email, e.active, e.max_concurrent,
-			e.call_timeout, e.created_at, e.updated_at,
+			e.email, e.sip_ha1, e.sip_ha1b,
+			e.vm_password, e.vm_email, e.vm_auto_login, e.active, e.max_concurrent,
+			e.call_timeout, e.created_at, e.updated_at, e.user_id,
 			d.domain
 		FROM voip.extensions e
 		INNER JOIN voip.domains d ON e.domain_id = d.id
@@ -147,9 +149,9 @@ func (db *DB) ListExtensions(ctx context.Context, domainID *int64, extType *stri
 		var ext models.Extension
 		if err := rows.Scan(
 			&ext.ID, &ext.DomainID, &ext.Extension, &ext.Type, &ext.DisplayName,
-			&ext.Email, &ext.SIPPassword, &ext.SIPHA1, &ext.SIPHA1B,
-			&ext.VMPassword, &ext.VMEmail, &ext.Active, &ext.MaxConcurrent,
-			&ext.CallTimeout, &ext.CreatedAt, &ext.UpdatedAt,
+			&ext.Email, &ext.SIPHA1, &ext.SIPHA1B,
+			&ext.VMPassword, &ext.VMEmail, &ext.VMAutoLogin, &ext.Active, &ext.MaxConcurrent,
+			&ext.CallTimeout, &ext.CreatedAt, &ext.UpdatedAt, &ext.UserID,
 			&ext.Domain,
 		); err != nil {
 			return nil, fmt.Errorf("scan extension: %w", err)
@@ -169,35 +171,85 @@ func (db *DB) ListExtensions(ctx context.Context, domainID *int64, extType *stri
 	}, nil
 }
 
-// CreateExtension creates a new extension
-func (db *DB) CreateExtension(ctx context.Context, req *models.ExtensionCreateRequest) (*models.Extension, error) {
-	// Note: HA1 hashes will be calculated by database trigger
+// ListUserExtensions retrieves the active extensions owned by a user
+func (db *DB) ListUserExtensions(ctx context.Context, userID int64) ([]*models.Extension, error) {
 	query := `
-		INSERT INTO voip.extensions (
-			domain_id, extension, type, display_name, email,
-			sip_password, vm_password, vm_email, active,
-			max_concurrent, call_timeout
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		RETURNING id, created_at, updated_at
+		SELECT
+			e.id, e.domain_id, e.extension, e.type, e.display_name,
+			e.email, e.sip_ha1, e.sip_ha1b,
+			e.vm_password, e.vm_email, e.vm_auto_login, e.active, e.max_concurrent,
+			e.call_timeout, e.created_at, e.updated_at, e.user_id,
+			d.domain
+		FROM voip.extensions e
+		INNER JOIN voip.domains d ON e.domain_id = d.id
+		WHERE e.user_id = $1 AND e.active = true
+		ORDER BY e.extension
 	`
 
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query user extensions: %w", err)
+	}
+	defer rows.Close()
+
+	var extensions []*models.Extension
+	for rows.Next() {
+		var ext models.Extension
+		if err := rows.Scan(
+			&ext.ID, &ext.DomainID, &ext.Extension, &ext.Type, &ext.DisplayName,
+			&ext.Email, &ext.SIPHA1, &ext.SIPHA1B,
+			&ext.VMPassword, &ext.VMEmail, &ext.VMAutoLogin, &ext.Active, &ext.MaxConcurrent,
+			&ext.CallTimeout, &ext.CreatedAt, &ext.UpdatedAt, &ext.UserID,
+			&ext.Domain,
+		); err != nil {
+			return nil, fmt.Errorf("scan extension: %w", err)
+		}
+		extensions = append(extensions, &ext)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return extensions, nil
+}
+
+// insertExtensionQuery inserts an extension; HA1 digests are computed by the caller
+const insertExtensionQuery = `
+	INSERT INTO voip.extensions (
+		domain_id, extension, type, display_name, email,
+		sip_ha1, sip_ha1b, vm_password, vm_email, active,
+		max_concurrent, call_timeout, user_id, vm_auto_login
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	RETURNING id, created_at, updated_at
+`
+
+// CreateExtension creates a new extension
+func (db *DB) CreateExtension(ctx context.Context, req *models.ExtensionCreateRequest) (*models.Extension, error) {
+	// The realm of the digest is the domain name
+	domain, err := db.GetDomain(ctx, req.DomainID)
+	if err != nil {
+		return nil, err
+	}
+	ha1, ha1b := sipauth.Digest(req.Extension, domain.Domain, req.SIPPassword)
+
 	var ext models.Extension
 	ext.DomainID = req.DomainID
 	ext.Extension = req.Extension
 	ext.Type = req.Type
 	ext.DisplayName = req.DisplayName
 	ext.Email = req.Email
-	ext.SIPPassword = req.SIPPassword
 	ext.VMPassword = req.VMPassword
 	ext.VMEmail = req.VMEmail
+	ext.VMAutoLogin = req.VMAutoLogin
 	ext.Active = req.Active
 	ext.MaxConcurrent = req.MaxConcurrent
 	ext.CallTimeout = req.CallTimeout
 
-	err := db.QueryRowContext(ctx, query,
+	err = db.QueryRowContext(ctx, insertExtensionQuery,
 		req.DomainID, req.Extension, req.Type, req.DisplayName, req.Email,
-		req.SIPPassword, req.VMPassword, req.VMEmail, req.Active,
-		req.MaxConcurrent, req.CallTimeout,
+		ha1, ha1b, req.VMPassword, req.VMEmail, req.Active,
+		req.MaxConcurrent, req.CallTimeout, req.UserID, req.VMAutoLogin,
 	).Scan(&ext.ID, &ext.CreatedAt, &ext.UpdatedAt)
 
 	if err != nil {
@@ -208,6 +260,96 @@ func (db *DB) CreateExtension(ctx context.Context, req *models.ExtensionCreateRe
 	return db.GetExtensionByID(ctx, ext.ID)
 }
 
+// CreateExtensions creates many extensions in one transaction; either all
+// rows are created or none. Returns the IDs in request order.
+func (db *DB) CreateExtensions(ctx context.Context, reqs []*models.ExtensionCreateRequest) ([]int64, error) {
+	ids := make([]int64, 0, len(reqs))
+
+	err := db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		domains := make(map[int64]string)
+
+		for _, req := range reqs {
+			domain, ok := domains[req.DomainID]
+			if !ok {
+				err := tx.QueryRowContext(ctx,
+					`SELECT domain FROM voip.domains WHERE id = $1`, req.DomainID,
+				).Scan(&domain)
+				if err == sql.ErrNoRows {
+					return fmt.Errorf("domain not found: %d", req.DomainID)
+				}
+				if err != nil {
+					return fmt.Errorf("query domain: %w", err)
+				}
+				domains[req.DomainID] = domain
+			}
+
+			ha1, ha1b := sipauth.Digest(req.Extension, domain, req.SIPPassword)
+
+			var ext models.Extension
+			if err := tx.QueryRowContext(ctx, insertExtensionQuery,
+				req.DomainID, req.Extension, req.Type, req.DisplayName, req.Email,
+				ha1, ha1b, req.VMPassword, req.VMEmail, req.Active,
+				req.MaxConcurrent, req.CallTimeout, req.UserID, req.VMAutoLogin,
+			).Scan(&ext.ID, &ext.CreatedAt, &ext.UpdatedAt); err != nil {
+				return fmt.Errorf("insert extension %s@%s: %w", req.Extension, domain, err)
+			}
+
+			ids = append(ids, ext.ID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// ExportExtensions retrieves all extensions, optionally of one domain,
+// ordered by domain and extension number
+func (db *DB) ExportExtensions(ctx context.Context, domainID *int64) ([]*models.Extension, error) {
+	query := `
+		SELECT
+			e.id, e.domain_id, e.extension, e.type, e.display_name,
+			e.email, e.sip_ha1, e.sip_ha1b,
+			e.vm_password, e.vm_email, e.vm_auto_login, e.active, e.max_concurrent,
+			e.call_timeout, e.created_at, e.updated_at, e.user_id,
+			d.domain
+		FROM voip.extensions e
+		INNER JOIN voip.domains d ON e.domain_id = d.id
+		WHERE ($1::bigint IS NULL OR e.domain_id = $1)
+		ORDER BY d.domain, e.extension
+	`
+
+	rows, err := db.QueryContext(ctx, query, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("query extensions: %w", err)
+	}
+	defer rows.Close()
+
+	extensions := []*models.Extension{}
+	for rows.Next() {
+		var ext models.Extension
+		if err := rows.Scan(
+			&ext.ID, &ext.DomainID, &ext.Extension, &ext.Type, &ext.DisplayName,
+			&ext.Email, &ext.SIPHA1, &ext.SIPHA1B,
+			&ext.VMPassword, &ext.VMEmail, &ext.VMAutoLogin, &ext.Active, &ext.MaxConcurrent,
+			&ext.CallTimeout, &ext.CreatedAt, &ext.UpdatedAt, &ext.UserID,
+			&ext.Domain,
+		); err != nil {
+			return nil, fmt.Errorf("scan extension: %w", err)
+		}
+		extensions = append(extensions, &ext)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return extensions, nil
+}
+
 // UpdateExtension updates an existing extension
 func (db *DB) UpdateExtension(ctx context.Context, id int64, req *models.ExtensionUpdateRequest) (*models.Extension, error) {
 	var setClauses []string
@@ -238,12 +380,24 @@ func (db *DB) UpdateExtension(ctx context.Context, id int64, req *models.Extensi
 		argPos++
 	}
 
+	if req.VMAutoLogin != nil {
+		setClauses = append(setClauses, fmt.Sprintf("vm_auto_login = $%d", argPos))
+		args = append(args, *req.VMAutoLogin)
+		argPos++
+	}
+
 	if req.Active != nil {
 		setClauses = append(setClauses, fmt.Sprintf("active = $%d", argPos))
 		args = append(args, *req.Active)
 		argPos++
 	}
 
+	if req.UserID != nil {
+		setClauses = append(setClauses, fmt.Sprintf("user_id = $%d", argPos))
+		args = append(args, *req.UserID)
+		argPos++
+	}
+
 	if req.MaxConcurrent != nil {
 		setClauses = append(setClauses, fmt.Sprintf("max_concurrent = $%d", argPos))
 		args = append(args, *req.MaxConcurrent)
@@ -291,16 +445,22 @@ func (db *DB) UpdateExtension(ctx context.Context, id int64, req *models.Extensi
 	return db.GetExtensionByID(ctx, id)
 }
 
-// UpdateExtensionPassword updates extension's SIP password
+// UpdateExtensionPassword updates extension's SIP password.
+// Only the HA1 digests are stored; the plaintext is discarded.
 func (db *DB) UpdateExtensionPassword(ctx context.Context, id int64, newPassword string) error {
-	// HA1 hashes will be recalculated by database trigger
+	ext, err := db.GetExtensionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	ha1, ha1b := sipauth.Digest(ext.Extension, ext.Domain, newPassword)
+
 	query := `
 		UPDATE voip.extensions
-		SET sip_password = $1, updated_at = $2
-		WHERE id = $3
+		SET sip_ha1 = $1, sip_ha1b = $2, updated_at = $3
+		WHERE id = $4
 	`
 
-	result, err := db.ExecContext(ctx, query, newPassword, time.Now(), id)
+	result, err := db.ExecContext(ctx, query, ha1, ha1b, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("update password: %w", err)
 	}
@@ -317,17 +477,17 @@ func (db *DB) UpdateExtensionPassword(ctx context.Context, id int64, newPassword
 	return nil
 }
 
-// DeleteExtension soft-deletes an extension by marking it as inactive
-func (db *DB) DeleteExtension(ctx context.Context, id int64) error {
+// UpdateExtensionVoicemailPIN updates extension's voicemail PIN
+func (db *DB) UpdateExtensionVoicemailPIN(ctx context.Context, id int64, pin string) error {
 	query := `
 		UPDATE voip.extensions
-		SET active = false, updated_at = $1
-		WHERE id = $2
+		SET vm_password = $1, updated_at = $2
+		WHERE id = $3
 	`
 
-	result, err := db.ExecContext(ctx, query, time.Now(), id)
+	result, err := db.ExecContext(ctx, query, pin, time.Now(), id)
 	if err != nil {
-		return fmt.Errorf("delete extension: %w", err)
+		return fmt.Errorf("update voicemail pin: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -342,50 +502,27 @@ func (db *DB) DeleteExtension(ctx context.Context, id int64) error {
 	return nil
 }
 
-// GetDomain retrieves a domain by ID
-func (db *DB) GetDomain(ctx context.Context, id int64) (*models.Domain, error) {
+// DeleteExtension soft-deletes an extension by marking it as inactive
+func (db *DB) DeleteExtension(ctx context.Context, id int64) error {
 	query := `
-		SELECT id, domain, active, created_at, updated_at
-		FROM voip.domains
-		WHERE id = $1
+		UPDATE voip.extensions
+		SET active = false, updated_at = $1
+		WHERE id = $2
 	`
 
-	var domain models.Domain
-	err := db.QueryRowContext(ctx, query, id).Scan(
-		&domain.ID, &domain.Domain, &domain.Active,
-		&domain.CreatedAt, &domain.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("domain not found: %d", id)
-	}
+	result, err := db.ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
-		return nil, fmt.Errorf("query domain: %w", err)
+		return fmt.Errorf("delete extension: %w", err)
 	}
 
-	return &domain, nil
-}
-
-// GetDomainByName retrieves a domain by name
-func (db *DB) GetDomainByName(ctx context.Context, name string) (*models.Domain, error) {
-	query := `
-		SELECT id, domain, active, created_at, updated_at
-		FROM voip.domains
-		WHERE domain = $1
-	`
-
-	var domain models.Domain
-	err := db.QueryRowContext(ctx, query, name).Scan(
-		&domain.ID, &domain.Domain, &domain.Active,
-		&domain.CreatedAt, &domain.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("domain not found: %s", name)
-	}
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return nil, fmt.Errorf("query domain: %w", err)
+		return fmt.Errorf("get rows affected: %w", err)
 	}
 
-	return &domain, nil
+	if rowsAffected == 0 {
+		return fmt.Errorf("extension not found: %d", id)
+	}
+
+	return nil
 }
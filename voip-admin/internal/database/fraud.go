@@ -0,0 +1,347 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// fraudAlertColumns are the columns scanned by scanFraudAlert; the query
+// must alias voip.fraud_alerts as fa and join voip.domains as d
+const fraudAlertColumns = `
+	fa.id, fa.domain_id, d.domain, fa.extension_id, fa.extension, fa.rule,
+	fa.message, fa.details, fa.suspended, fa.status, fa.acknowledged_at,
+	COALESCE(fa.acknowledged_by, ''), COALESCE(fa.note, ''), fa.created_at
+`
+
+// scanFraudAlert scans a row of fraudAlertColumns
+func scanFraudAlert(row interface{ Scan(...interface{}) error }) (*models.FraudAlert, error) {
+	var alert models.FraudAlert
+	var details []byte
+	err := row.Scan(
+		&alert.ID, &alert.DomainID, &alert.Domain, &alert.ExtensionID, &alert.Extension, &alert.Rule,
+		&alert.Message, &details, &alert.Suspended, &alert.Status, &alert.AcknowledgedAt,
+		&alert.AcknowledgedBy, &alert.Note, &alert.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	alert.Details = details
+	return &alert, nil
+}
+
+// placedCallColumns are the columns scanned by scanPlacedCall. The
+// calling user is the extension that forwarded the call, else the SIP
+// From user, which survives caller ID rewrites on the way out;
+// idx_cdr_charged_user indexes the same expression.
+const placedCallColumns = `
+	id, domain, COALESCE(NULLIF(forwarded_by, ''), NULLIF(sip_from_user, ''), caller_id_number, ''),
+	destination_number, start_stamp, COALESCE(end_stamp, start_stamp)
+`
+
+// scanPlacedCall scans a row of placedCallColumns
+func scanPlacedCall(row interface{ Scan(...interface{}) error }) (*models.PlacedCall, error) {
+	var call models.PlacedCall
+	if err := row.Scan(
+		&call.CDRID, &call.Domain, &call.Extension,
+		&call.Destination, &call.Start, &call.End,
+	); err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// queryPlacedCalls runs a query of placedCallColumns
+func (db *DB) queryPlacedCalls(ctx context.Context, query string, args ...interface{}) ([]*models.PlacedCall, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query calls: %w", err)
+	}
+	defer rows.Close()
+
+	calls := []*models.PlacedCall{}
+	for rows.Next() {
+		call, err := scanPlacedCall(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan call: %w", err)
+		}
+		calls = append(calls, call)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return calls, nil
+}
+
+// ListPlacedCallsAfter retrieves up to limit CDRs with an id above lastID,
+// oldest first. The caller decides which of them are outbound calls of an
+// extension.
+func (db *DB) ListPlacedCallsAfter(ctx context.Context, lastID int64, limit int) ([]*models.PlacedCall, error) {
+	query := `
+		SELECT ` + placedCallColumns + `
+		FROM voip.cdr
+		WHERE id > $1 AND domain <> ''
+		ORDER BY id
+		LIMIT $2
+	`
+
+	return db.queryPlacedCalls(ctx, query, lastID, limit)
+}
+
+// ListExtensionCalls retrieves the calls placed by an extension that
+// started at or after since, oldest first
+func (db *DB) ListExtensionCalls(ctx context.Context, domain, extension string, since time.Time) ([]*models.PlacedCall, error) {
+	query := `
+		SELECT ` + placedCallColumns + `
+		FROM voip.cdr
+		WHERE domain = $1
+		  AND COALESCE(NULLIF(forwarded_by, ''), NULLIF(sip_from_user, ''), caller_id_number) = $2
+		  AND start_stamp >= $3
+		ORDER BY start_stamp
+	`
+
+	return db.queryPlacedCalls(ctx, query, domain, extension, since)
+}
+
+// GetFraudCheckpoint retrieves the highest CDR id scanned by the fraud
+// detector
+func (db *DB) GetFraudCheckpoint(ctx context.Context) (int64, error) {
+	var lastID int64
+	err := db.QueryRowContext(ctx, `SELECT last_cdr_id FROM voip.fraud_checkpoint WHERE id = 1`).Scan(&lastID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("query fraud checkpoint: %w", err)
+	}
+
+	return lastID, nil
+}
+
+// AdvanceFraudCheckpoint moves the checkpoint from lastID to newID. Returns
+// false if another node moved it first; that node scans the CDRs instead.
+func (db *DB) AdvanceFraudCheckpoint(ctx context.Context, lastID, newID int64) (bool, error) {
+	query := `
+		INSERT INTO voip.fraud_checkpoint (id, last_cdr_id, updated_at)
+		VALUES (1, $2, NOW())
+		ON CONFLICT (id) DO UPDATE
+		SET last_cdr_id = EXCLUDED.last_cdr_id, updated_at = EXCLUDED.updated_at
+		WHERE voip.fraud_checkpoint.last_cdr_id = $1
+	`
+
+	result, err := db.ExecContext(ctx, query, lastID, newID)
+	if err != nil {
+		return false, fmt.Errorf("advance fraud checkpoint: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// CreateFraudAlert raises an alert unless the extension already has an open
+// alert of the same rule created after since. Returns false for such
+// duplicates.
+func (db *DB) CreateFraudAlert(ctx context.Context, alert *models.FraudAlert, since time.Time) (bool, error) {
+	query := `
+		INSERT INTO voip.fraud_alerts (domain_id, extension_id, extension, rule, message, details)
+		SELECT $1, $2, $3, $4, $5, $6
+		WHERE NOT EXISTS (
+			SELECT 1 FROM voip.fraud_alerts
+			WHERE domain_id = $1 AND extension = $3 AND rule = $4
+			  AND status = 'open' AND created_at >= $7
+		)
+		RETURNING id, status, created_at
+	`
+
+	details := []byte(alert.Details)
+	if len(details) == 0 {
+		details = []byte("{}")
+	}
+
+	err := db.QueryRowContext(ctx, query,
+		alert.DomainID, alert.ExtensionID, alert.Extension, alert.Rule, alert.Message, details, since,
+	).Scan(&alert.ID, &alert.Status, &alert.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("insert fraud alert: %w", err)
+	}
+
+	return true, nil
+}
+
+// MarkFraudAlertSuspended records that an alert deactivated its extension
+func (db *DB) MarkFraudAlertSuspended(ctx context.Context, id int64) error {
+	if _, err := db.ExecContext(ctx, `UPDATE voip.fraud_alerts SET suspended = true WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("update fraud alert: %w", err)
+	}
+	return nil
+}
+
+// GetFraudAlert retrieves a fraud alert by ID
+func (db *DB) GetFraudAlert(ctx context.Context, id int64) (*models.FraudAlert, error) {
+	query := `
+		SELECT ` + fraudAlertColumns + `
+		FROM voip.fraud_alerts fa
+		INNER JOIN voip.domains d ON fa.domain_id = d.id
+		WHERE fa.id = $1
+	`
+
+	alert, err := scanFraudAlert(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("fraud alert not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query fraud alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+// ListFraudAlerts retrieves fraud alerts with pagination and filtering,
+// newest first
+func (db *DB) ListFraudAlerts(ctx context.Context, req *models.FraudAlertListRequest) (*models.FraudAlertListResponse, error) {
+	var conditions []string
+	var args []interface{}
+	argPos := 1
+
+	if req.DomainID != nil {
+		conditions = append(conditions, fmt.Sprintf("fa.domain_id = $%d", argPos))
+		args = append(args, *req.DomainID)
+		argPos++
+	}
+
+	if req.ExtensionID != nil {
+		conditions = append(conditions, fmt.Sprintf("fa.extension_id = $%d", argPos))
+		args = append(args, *req.ExtensionID)
+		argPos++
+	}
+
+	if req.Rule != nil {
+		conditions = append(conditions, fmt.Sprintf("fa.rule = $%d", argPos))
+		args = append(args, *req.Rule)
+		argPos++
+	}
+
+	if req.Status != nil {
+		conditions = append(conditions, fmt.Sprintf("fa.status = $%d", argPos))
+		args = append(args, *req.Status)
+		argPos++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Count total
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM voip.fraud_alerts fa
+		%s
+	`, whereClause)
+
+	var total int64
+	if err := db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count fraud alerts: %w", err)
+	}
+
+	// Fetch alerts
+	offset := (req.Page - 1) * req.PerPage
+	args = append(args, req.PerPage, offset)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM voip.fraud_alerts fa
+		INNER JOIN voip.domains d ON fa.domain_id = d.id
+		%s
+		ORDER BY fa.created_at DESC, fa.id DESC
+		LIMIT $%d OFFSET $%d
+	`, fraudAlertColumns, whereClause, argPos, argPos+1)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query fraud alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []*models.FraudAlert{}
+	for rows.Next() {
+		alert, err := scanFraudAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan fraud alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &models.FraudAlertListResponse{
+		Alerts:  alerts,
+		Total:   total,
+		Page:    req.Page,
+		PerPage: req.PerPage,
+	}, nil
+}
+
+// AcknowledgeFraudAlert closes an open fraud alert. Acknowledging does not
+// reactivate a suspended extension; that is a separate extension update.
+func (db *DB) AcknowledgeFraudAlert(ctx context.Context, id int64, by, note string) (*models.FraudAlert, error) {
+	query := `
+		UPDATE voip.fraud_alerts
+		SET status = 'acknowledged', acknowledged_at = NOW(),
+			acknowledged_by = $2, note = NULLIF($3, '')
+		WHERE id = $1 AND status = 'open'
+	`
+
+	result, err := db.ExecContext(ctx, query, id, by, note)
+	if err != nil {
+		return nil, fmt.Errorf("acknowledge fraud alert: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("open fraud alert not found: %d", id)
+	}
+
+	return db.GetFraudAlert(ctx, id)
+}
+
+// SuspendExtension deactivates an extension. Returns false if it was
+// already inactive.
+func (db *DB) SuspendExtension(ctx context.Context, id int64) (bool, error) {
+	query := `
+		UPDATE voip.extensions
+		SET active = false, updated_at = $1
+		WHERE id = $2 AND active = true
+	`
+
+	result, err := db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return false, fmt.Errorf("suspend extension: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// numberRangeColumns are the columns scanned by scanNumberRange
+const numberRangeColumns = `
+	r.id, r.domain_id, r.type, r.range_start, r.range_end,
+	COALESCE(r.description, ''), r.created_at
+`
+
+// scanNumberRange scans a row selected with numberRangeColumns
+func scanNumberRange(rows *sql.Rows) (*models.NumberRange, error) {
+	var nr models.NumberRange
+	if err := rows.Scan(
+		&nr.ID, &nr.DomainID, &nr.Type, &nr.Start, &nr.End,
+		&nr.Description, &nr.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("scan number range: %w", err)
+	}
+	return &nr, nil
+}
+
+// GetNumberingPlan retrieves the numbering plan of a domain, falling back to
+// the default ranges and outbound pattern where the domain has none
+func (db *DB) GetNumberingPlan(ctx context.Context, domainID int64) (*models.NumberingPlan, error) {
+	plan := &models.NumberingPlan{DomainID: domainID, Ranges: []*models.NumberRange{}}
+
+	err := db.QueryRowContext(ctx, `
+		SELECT d.domain, COALESCE(p.outbound_pattern, '')
+		FROM voip.domains d
+		LEFT JOIN voip.numbering_plans p ON p.domain_id = d.id
+		WHERE d.id = $1
+	`, domainID).Scan(&plan.Domain, &plan.OutboundPattern)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("domain not found: %d", domainID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query numbering plan: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM voip.number_ranges r
+		WHERE r.domain_id = $1
+		ORDER BY LENGTH(r.range_start), r.range_start
+	`, numberRangeColumns)
+
+	rows, err := db.QueryContext(ctx, query, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("query number ranges: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		nr, err := scanNumberRange(rows)
+		if err != nil {
+			return nil, err
+		}
+		plan.Ranges = append(plan.Ranges, nr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	applyPlanDefaults(plan)
+
+	return plan, nil
+}
+
+// ListNumberingPlans retrieves the numbering plans of all domains that
+// have ranges or an outbound pattern of their own
+func (db *DB) ListNumberingPlans(ctx context.Context) ([]*models.NumberingPlan, error) {
+	plans := make(map[int64]*models.NumberingPlan)
+	var order []int64
+
+	planFor := func(domainID int64, domain string) *models.NumberingPlan {
+		plan, ok := plans[domainID]
+		if !ok {
+			plan = &models.NumberingPlan{DomainID: domainID, Domain: domain, Ranges: []*models.NumberRange{}}
+			plans[domainID] = plan
+			order = append(order, domainID)
+		}
+		return plan
+	}
+
+	patternRows, err := db.QueryContext(ctx, `
+		SELECT p.domain_id, d.domain, p.outbound_pattern
+		FROM voip.numbering_plans p
+		INNER JOIN voip.domains d ON p.domain_id = d.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query numbering plans: %w", err)
+	}
+	defer patternRows.Close()
+
+	for patternRows.Next() {
+		var domainID int64
+		var domain, pattern string
+		if err := patternRows.Scan(&domainID, &domain, &pattern); err != nil {
+			return nil, fmt.Errorf("scan numbering plan: %w", err)
+		}
+		planFor(domainID, domain).OutboundPattern = pattern
+	}
+
+	if err := patternRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, d.domain
+		FROM voip.number_ranges r
+		INNER JOIN voip.domains d ON r.domain_id = d.id
+		ORDER BY r.domain_id, LENGTH(r.range_start), r.range_start
+	`, numberRangeColumns)
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query number ranges: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var nr models.NumberRange
+		var domain string
+		if err := rows.Scan(
+			&nr.ID, &nr.DomainID, &nr.Type, &nr.Start, &nr.End,
+			&nr.Description, &nr.CreatedAt, &domain,
+		); err != nil {
+			return nil, fmt.Errorf("scan number range: %w", err)
+		}
+		plan := planFor(nr.DomainID, domain)
+		plan.Ranges = append(plan.Ranges, &nr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	result := make([]*models.NumberingPlan, 0, len(order))
+	for _, domainID := range order {
+		plan := plans[domainID]
+		applyPlanDefaults(plan)
+		result = append(result, plan)
+	}
+
+	return result, nil
+}
+
+// applyPlanDefaults fills in the default ranges and outbound pattern
+func applyPlanDefaults(plan *models.NumberingPlan) {
+	if len(plan.Ranges) == 0 {
+		plan.Default = true
+		plan.Ranges = models.DefaultNumberRanges()
+	}
+	if plan.OutboundPattern == "" {
+		plan.OutboundPattern = models.DefaultOutboundPattern
+	}
+}
+
+// SetNumberingPlan replaces the number ranges and outbound pattern of a
+// domain. An empty list or pattern reverts to the default.
+func (db *DB) SetNumberingPlan(ctx context.Context, domainID int64, ranges []*models.NumberRange, outboundPattern string) error {
+	return db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM voip.number_ranges WHERE domain_id = $1`, domainID,
+		); err != nil {
+			return fmt.Errorf("delete number ranges: %w", err)
+		}
+
+		query := `
+			INSERT INTO voip.number_ranges (domain_id, type, range_start, range_end, description)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		`
+		for _, nr := range ranges {
+			if _, err := tx.ExecContext(ctx, query,
+				domainID, nr.Type, nr.Start, nr.End, nr.Description,
+			); err != nil {
+				return fmt.Errorf("insert number range %s-%s: %w", nr.Start, nr.End, err)
+			}
+		}
+
+		if outboundPattern == "" {
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM voip.numbering_plans WHERE domain_id = $1`, domainID,
+			); err != nil {
+				return fmt.Errorf("delete numbering plan: %w", err)
+			}
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO voip.numbering_plans (domain_id, outbound_pattern, updated_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (domain_id) DO UPDATE
+			SET outbound_pattern = EXCLUDED.outbound_pattern, updated_at = NOW()
+		`, domainID, outboundPattern); err != nil {
+			return fmt.Errorf("upsert numbering plan: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// numbersInUseQuery lists every internal number of a domain: extensions,
+// queues, IVR menus, conference rooms and the voicemail main number
+const numbersInUseQuery = `
+	SELECT extension, type FROM voip.extensions WHERE domain_id = $1
+	UNION ALL
+	SELECT extension, 'queue' FROM voip.queues WHERE domain_id = $1 AND extension IS NOT NULL
+	UNION ALL
+	SELECT extension, 'ivr' FROM voip.ivr_menus WHERE domain_id = $1 AND extension IS NOT NULL
+	UNION ALL
+	SELECT extension, 'conference' FROM voip.conference_rooms WHERE domain_id = $1
+	UNION ALL
+	SELECT voicemail_main_number, 'voicemail' FROM voip.domains WHERE id = $1 AND voicemail_main_number IS NOT NULL
+`
+
+// ListNumbersInUse retrieves all internal numbers taken in a domain
+func (db *DB) ListNumbersInUse(ctx context.Context, domainID int64) ([]*models.NumberInUse, error) {
+	rows, err := db.QueryContext(ctx, numbersInUseQuery, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("query numbers in use: %w", err)
+	}
+	defer rows.Close()
+
+	numbers := []*models.NumberInUse{}
+	for rows.Next() {
+		var n models.NumberInUse
+		if err := rows.Scan(&n.Number, &n.Kind); err != nil {
+			return nil, fmt.Errorf("scan number in use: %w", err)
+		}
+		numbers = append(numbers, &n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return numbers, nil
+}
+
+// FindNumberInUse returns what uses a number in a domain, or nil if the
+// number is free
+func (db *DB) FindNumberInUse(ctx context.Context, domainID int64, number string) (*models.NumberInUse, error) {
+	query := fmt.Sprintf(`
+		SELECT extension, type FROM (%s) AS numbers (extension, type)
+		WHERE extension = $2
+		LIMIT 1
+	`, numbersInUseQuery)
+
+	var n models.NumberInUse
+	err := db.QueryRowContext(ctx, query, domainID, number).Scan(&n.Number, &n.Kind)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query number in use: %w", err)
+	}
+
+	return &n, nil
+}
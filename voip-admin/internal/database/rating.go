@@ -0,0 +1,444 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// rateDeckColumns are the columns scanned by scanRateDeck; the query must
+// alias voip.rate_decks as rd
+const rateDeckColumns = `
+	rd.id, rd.name, rd.type, rd.currency, COALESCE(rd.description, ''),
+	(SELECT COUNT(*) FROM voip.rates r WHERE r.rate_deck_id = rd.id),
+	(SELECT COUNT(*) FROM voip.trunks t WHERE t.rate_deck_id = rd.id),
+	(SELECT COUNT(*) FROM voip.domains d WHERE d.rate_deck_id = rd.id),
+	rd.created_at, rd.updated_at
+`
+
+// scanRateDeck scans a row of rateDeckColumns
+func scanRateDeck(row interface{ Scan(...interface{}) error }) (*models.RateDeck, error) {
+	var deck models.RateDeck
+	err := row.Scan(
+		&deck.ID, &deck.Name, &deck.Type, &deck.Currency, &deck.Description,
+		&deck.Rates, &deck.Trunks, &deck.Domains,
+		&deck.CreatedAt, &deck.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &deck, nil
+}
+
+// rateColumns are the voip.rates columns scanned by scanRate
+const rateColumns = `
+	id, rate_deck_id, prefix, COALESCE(description, ''), rate, connection_fee,
+	initial_increment, increment
+`
+
+// scanRate scans a row of rateColumns
+func scanRate(row interface{ Scan(...interface{}) error }) (*models.Rate, error) {
+	var rate models.Rate
+	err := row.Scan(
+		&rate.ID, &rate.RateDeckID, &rate.Prefix, &rate.Description, &rate.Rate, &rate.ConnectionFee,
+		&rate.InitialIncrement, &rate.Increment,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// GetRateDeck retrieves a rate deck by ID
+func (db *DB) GetRateDeck(ctx context.Context, id int64) (*models.RateDeck, error) {
+	query := `SELECT ` + rateDeckColumns + ` FROM voip.rate_decks rd WHERE rd.id = $1`
+
+	deck, err := scanRateDeck(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("rate deck not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query rate deck: %w", err)
+	}
+
+	return deck, nil
+}
+
+// GetRateDeckByName retrieves a rate deck by name
+func (db *DB) GetRateDeckByName(ctx context.Context, name string) (*models.RateDeck, error) {
+	query := `SELECT ` + rateDeckColumns + ` FROM voip.rate_decks rd WHERE rd.name = $1`
+
+	deck, err := scanRateDeck(db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("rate deck not found: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query rate deck: %w", err)
+	}
+
+	return deck, nil
+}
+
+// ListRateDecks retrieves all rate decks, optionally of one type
+func (db *DB) ListRateDecks(ctx context.Context, deckType *string) ([]*models.RateDeck, error) {
+	query := `
+		SELECT ` + rateDeckColumns + `
+		FROM voip.rate_decks rd
+		WHERE ($1::text IS NULL OR rd.type = $1)
+		ORDER BY rd.name
+	`
+
+	rows, err := db.QueryContext(ctx, query, deckType)
+	if err != nil {
+		return nil, fmt.Errorf("query rate decks: %w", err)
+	}
+	defer rows.Close()
+
+	decks := []*models.RateDeck{}
+	for rows.Next() {
+		deck, err := scanRateDeck(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan rate deck: %w", err)
+		}
+		decks = append(decks, deck)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return decks, nil
+}
+
+// CreateRateDeck creates a new rate deck
+func (db *DB) CreateRateDeck(ctx context.Context, deck *models.RateDeck) (*models.RateDeck, error) {
+	query := `
+		INSERT INTO voip.rate_decks (name, type, currency, description)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id
+	`
+
+	var id int64
+	if err := db.QueryRowContext(ctx, query, deck.Name, deck.Type, deck.Currency, deck.Description).Scan(&id); err != nil {
+		return nil, fmt.Errorf("insert rate deck: %w", err)
+	}
+
+	return db.GetRateDeck(ctx, id)
+}
+
+// UpdateRateDeck stores the mutable fields of a rate deck
+func (db *DB) UpdateRateDeck(ctx context.Context, deck *models.RateDeck) (*models.RateDeck, error) {
+	query := `
+		UPDATE voip.rate_decks
+		SET name = $1, currency = $2, description = NULLIF($3, ''), updated_at = NOW()
+		WHERE id = $4
+	`
+
+	result, err := db.ExecContext(ctx, query, deck.Name, deck.Currency, deck.Description, deck.ID)
+	if err != nil {
+		return nil, fmt.Errorf("update rate deck: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("rate deck not found: %d", deck.ID)
+	}
+
+	return db.GetRateDeck(ctx, deck.ID)
+}
+
+// DeleteRateDeck deletes a rate deck and its rates. Trunks and domains
+// using it are left without a deck.
+func (db *DB) DeleteRateDeck(ctx context.Context, id int64) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM voip.rate_decks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete rate deck: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("rate deck not found: %d", id)
+	}
+
+	return nil
+}
+
+// ListRates retrieves the rates of a deck ordered by prefix, optionally only
+// the prefixes starting with prefix. perPage 0 returns all rates.
+func (db *DB) ListRates(ctx context.Context, deckID int64, prefix string, page, perPage int) (*models.RateListResponse, error) {
+	var total int64
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM voip.rates
+		WHERE rate_deck_id = $1 AND prefix LIKE $2 || '%'
+	`, deckID, prefix).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("count rates: %w", err)
+	}
+
+	query := `
+		SELECT ` + rateColumns + `
+		FROM voip.rates
+		WHERE rate_deck_id = $1 AND prefix LIKE $2 || '%'
+		ORDER BY prefix
+		LIMIT $3 OFFSET $4
+	`
+
+	var limit interface{}
+	offset := 0
+	if perPage > 0 {
+		limit = perPage
+		offset = (page - 1) * perPage
+	}
+
+	rows, err := db.QueryContext(ctx, query, deckID, prefix, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []*models.Rate{}
+	for rows.Next() {
+		rate, err := scanRate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &models.RateListResponse{
+		Rates:   rates,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+// ImportRates writes rates into a deck in one transaction, adding new
+// prefixes and overwriting existing ones. With replace, prefixes missing
+// from rates are deleted, so the deck matches the file.
+func (db *DB) ImportRates(ctx context.Context, deckID int64, rates []*models.Rate, replace bool) error {
+	return db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if replace {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM voip.rates WHERE rate_deck_id = $1`, deckID); err != nil {
+				return fmt.Errorf("delete rates: %w", err)
+			}
+		}
+
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO voip.rates (
+				rate_deck_id, prefix, description, rate, connection_fee, initial_increment, increment
+			) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+			ON CONFLICT (rate_deck_id, prefix) DO UPDATE
+			SET description = EXCLUDED.description, rate = EXCLUDED.rate,
+				connection_fee = EXCLUDED.connection_fee,
+				initial_increment = EXCLUDED.initial_increment, increment = EXCLUDED.increment
+		`)
+		if err != nil {
+			return fmt.Errorf("prepare rate insert: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, rate := range rates {
+			if _, err := stmt.ExecContext(ctx,
+				deckID, rate.Prefix, rate.Description, rate.Rate, rate.ConnectionFee,
+				rate.InitialIncrement, rate.Increment,
+			); err != nil {
+				return fmt.Errorf("insert rate %s: %w", rate.Prefix, err)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE voip.rate_decks SET updated_at = NOW() WHERE id = $1`, deckID); err != nil {
+			return fmt.Errorf("update rate deck: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetSellRate retrieves the rate of the longest of prefixes (the candidate
+// prefixes of a number, longest first) in the sell deck of a domain. It
+// returns nil if the domain has no deck or the deck no matching rate.
+func (db *DB) GetSellRate(ctx context.Context, domainID int64, prefixes []string) (*models.Rate, error) {
+	query := `
+		SELECT ` + rateColumns + `
+		FROM voip.rates
+		WHERE rate_deck_id = (SELECT rate_deck_id FROM voip.domains WHERE id = $1)
+		  AND prefix = ANY($2)
+		ORDER BY length(prefix) DESC
+		LIMIT 1
+	`
+
+	rate, err := scanRate(db.QueryRowContext(ctx, query, domainID, pq.Array(prefixes)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query sell rate: %w", err)
+	}
+
+	return rate, nil
+}
+
+// RateCDR prices a stored outbound call with the longest prefix of
+// prefixes (the candidate prefixes of its destination, longest first) in
+// the sell deck of its domain and the buy deck of its trunk. Costs without
+// a matching rate stay NULL.
+func (db *DB) RateCDR(ctx context.Context, cdr *models.CDR, prefixes []string) error {
+	query := `
+		WITH sell AS (
+			SELECT r.*
+			FROM voip.domains d
+			INNER JOIN voip.rates r ON r.rate_deck_id = d.rate_deck_id
+			WHERE d.domain = $2 AND r.prefix = ANY($3)
+			ORDER BY length(r.prefix) DESC
+			LIMIT 1
+		), buy AS (
+			SELECT r.*
+			FROM voip.trunks t
+			INNER JOIN voip.rates r ON r.rate_deck_id = t.rate_deck_id
+			WHERE t.name = $4 AND r.prefix = ANY($3)
+			ORDER BY length(r.prefix) DESC
+			LIMIT 1
+		)
+		UPDATE voip.cdr c
+		SET rate_prefix = (SELECT prefix FROM sell),
+			rate_description = (SELECT description FROM sell),
+			billed_seconds = (SELECT voip.billed_seconds(c.billsec, initial_increment, increment) FROM sell),
+			sell_cost = (SELECT voip.call_cost(c.billsec, rate, connection_fee, initial_increment, increment) FROM sell),
+			buy_cost = (SELECT voip.call_cost(c.billsec, rate, connection_fee, initial_increment, increment) FROM buy),
+			rated_at = NOW()
+		WHERE c.id = $1
+		RETURNING rate_prefix, rate_description, billed_seconds, sell_cost, buy_cost
+	`
+
+	err := db.QueryRowContext(ctx, query, cdr.ID, cdr.Domain, pq.Array(prefixes), cdr.Trunk).Scan(
+		&cdr.RatePrefix, &cdr.RateDescription, &cdr.BilledSeconds, &cdr.SellCost, &cdr.BuyCost,
+	)
+	if err != nil {
+		return fmt.Errorf("rate cdr: %w", err)
+	}
+
+	return nil
+}
+
+// invoiceQuery sums the rated calls of the domains in a month, per domain
+// ($1: domain ID or NULL) or per domain and prefix
+const invoiceQuery = `
+	SELECT
+		d.id, d.domain, COALESCE(d.tenant_name, ''), COALESCE(rd.currency, ''), %s
+		COUNT(c.sell_cost), COALESCE(SUM(c.billed_seconds), 0), COALESCE(SUM(c.sell_cost), 0),
+		COALESCE(SUM(c.buy_cost) FILTER (WHERE c.sell_cost IS NOT NULL), 0),
+		COUNT(*) FILTER (WHERE c.sell_cost IS NULL)
+	FROM voip.cdr c
+	INNER JOIN voip.domains d ON d.domain = c.domain
+	LEFT JOIN voip.rate_decks rd ON rd.id = d.rate_deck_id
+	WHERE ($1::bigint IS NULL OR d.id = $1)
+	  AND c.rated_at IS NOT NULL
+	  AND c.start_stamp >= $2 AND c.start_stamp < $3
+	GROUP BY d.id, d.domain, d.tenant_name, rd.currency %s
+	ORDER BY d.domain %s
+`
+
+// ListInvoices sums the rated outbound calls of a month per domain, for one
+// domain or all of them. Domains without rated calls are left out.
+func (db *DB) ListInvoices(ctx context.Context, domainID *int64, month time.Time) ([]*models.Invoice, error) {
+	query := fmt.Sprintf(invoiceQuery, "", "", "")
+
+	rows, err := db.QueryContext(ctx, query, domainID, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("query invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []*models.Invoice{}
+	for rows.Next() {
+		inv := &models.Invoice{Month: month.Format("2006-01")}
+		var cost float64
+		if err := rows.Scan(
+			&inv.DomainID, &inv.Domain, &inv.TenantName, &inv.Currency,
+			&inv.Calls, &inv.BilledSeconds, &inv.Amount, &cost, &inv.Unrated,
+		); err != nil {
+			return nil, fmt.Errorf("scan invoice: %w", err)
+		}
+		inv.Cost = &cost
+		invoices = append(invoices, inv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// GetInvoice sums the rated outbound calls of a domain in a month with one
+// line per destination prefix. A month without calls gives an empty
+// invoice.
+func (db *DB) GetInvoice(ctx context.Context, domain *models.Domain, month time.Time) (*models.Invoice, error) {
+	query := fmt.Sprintf(invoiceQuery,
+		"COALESCE(c.rate_prefix, ''), COALESCE(c.rate_description, ''),",
+		", c.rate_prefix, c.rate_description", ", c.rate_prefix NULLS LAST")
+
+	rows, err := db.QueryContext(ctx, query, domain.ID, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("query invoice: %w", err)
+	}
+	defer rows.Close()
+
+	var cost float64
+	inv := &models.Invoice{
+		DomainID:   domain.ID,
+		Domain:     domain.Domain,
+		TenantName: domain.TenantName,
+		Month:      month.Format("2006-01"),
+		Cost:       &cost,
+		Lines:      []*models.InvoiceLine{},
+	}
+
+	for rows.Next() {
+		var line models.InvoiceLine
+		var lineCost float64
+		var unrated int64
+		if err := rows.Scan(
+			&inv.DomainID, &inv.Domain, &inv.TenantName, &inv.Currency, &line.Prefix, &line.Description,
+			&line.Calls, &line.BilledSeconds, &line.Amount, &lineCost, &unrated,
+		); err != nil {
+			return nil, fmt.Errorf("scan invoice line: %w", err)
+		}
+
+		// Calls without a sell rate are counted, not invoiced
+		inv.Unrated += unrated
+		if line.Calls == 0 {
+			continue
+		}
+		line.Cost = &lineCost
+
+		inv.Calls += line.Calls
+		inv.BilledSeconds += line.BilledSeconds
+		inv.Amount += line.Amount
+		cost += lineCost
+		inv.Lines = append(inv.Lines, &line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return inv, nil
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// recordingColumns are the columns scanned by scanRecording; the query must
+// alias voip.recordings as rec and left join voip.domains as d and voip.cdr
+// as c
+const recordingColumns = `
+	rec.id, rec.call_uuid, rec.cdr_id, rec.domain_id, rec.recording_policy_id,
+	COALESCE(rec.file_path, ''), rec.file_size, rec.duration, COALESCE(rec.format, ''),
+	rec.start_time, rec.end_time, rec.legal_hold, COALESCE(rec.legal_hold_reason, ''),
+	COALESCE(rec.legal_hold_by, ''), rec.legal_hold_at, rec.created_at,
+	COALESCE(d.domain, ''), COALESCE(c.caller_id_number, ''),
+	COALESCE(c.destination_number, ''), COALESCE(c.direction, '')
+`
+
+// recordingTables are the tables of recordingColumns
+const recordingTables = `
+	voip.recordings rec
+	LEFT JOIN voip.domains d ON rec.domain_id = d.id
+	LEFT JOIN voip.cdr c ON rec.cdr_id = c.id
+`
+
+// scanRecording scans a row of recordingColumns
+func scanRecording(row interface{ Scan(...interface{}) error }) (*models.Recording, error) {
+	var rec models.Recording
+	err := row.Scan(
+		&rec.ID, &rec.CallUUID, &rec.CDRID, &rec.DomainID, &rec.RecordingPolicyID,
+		&rec.FilePath, &rec.FileSize, &rec.Duration, &rec.Format,
+		&rec.StartTime, &rec.EndTime, &rec.LegalHold, &rec.LegalHoldReason,
+		&rec.LegalHoldBy, &rec.LegalHoldAt, &rec.CreatedAt,
+		&rec.Domain, &rec.CallerIDNumber,
+		&rec.DestinationNumber, &rec.Direction,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// GetExtensionRecordingPolicy retrieves the active recording policy of an
+// extension number. Returns nil if the extension has none.
+func (db *DB) GetExtensionRecordingPolicy(ctx context.Context, extension, domain string) (*models.RecordingPolicy, error) {
+	query := `
+		SELECT
+			rp.id, rp.name, COALESCE(rp.record_inbound, false),
+			COALESCE(rp.record_outbound, false), COALESCE(rp.record_internal, false),
+			COALESCE(rp.record_queue, false), COALESCE(rp.retention_days, 0),
+			COALESCE(rp.storage_path, ''), rp.active, rp.created_at
+		FROM voip.extensions e
+		INNER JOIN voip.domains d ON e.domain_id = d.id
+		INNER JOIN voip.recording_policies rp ON e.recording_policy_id = rp.id
+		WHERE e.extension = $1 AND d.domain = $2 AND rp.active = true
+	`
+
+	var p models.RecordingPolicy
+	err := db.QueryRowContext(ctx, query, extension, domain).Scan(
+		&p.ID, &p.Name, &p.RecordInbound,
+		&p.RecordOutbound, &p.RecordInternal,
+		&p.RecordQueue, &p.RetentionDays,
+		&p.StoragePath, &p.Active, &p.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query recording policy: %w", err)
+	}
+
+	return &p, nil
+}
+
+// InsertRecording registers a recorded file of a call in the domain with
+// the given name. Files already registered are left unchanged.
+func (db *DB) InsertRecording(ctx context.Context, rec *models.Recording, domain string) error {
+	query := `
+		INSERT INTO voip.recordings (
+			call_uuid, cdr_id, domain_id, recording_policy_id,
+			file_path, file_size, duration, format, start_time, end_time
+		) VALUES (
+			$1, $2, (SELECT id FROM voip.domains WHERE domain = $3), $4,
+			$5, $6, $7, $8, $9, $10
+		)
+		ON CONFLICT (file_path) DO NOTHING
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		rec.CallUUID, rec.CDRID, domain, rec.RecordingPolicyID,
+		rec.FilePath, rec.FileSize, rec.Duration, rec.Format, rec.StartTime, rec.EndTime,
+	)
+	if err != nil {
+		return fmt.Errorf("insert recording: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecording retrieves a recording by ID
+func (db *DB) GetRecording(ctx context.Context, id int64) (*models.Recording, error) {
+	query := `SELECT ` + recordingColumns + ` FROM ` + recordingTables + ` WHERE rec.id = $1`
+
+	rec, err := scanRecording(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("recording not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query recording: %w", err)
+	}
+
+	return rec, nil
+}
+
+// ListRecordings searches recordings by the CDR of their call, newest first
+func (db *DB) ListRecordings(ctx context.Context, req *models.RecordingListRequest) (*models.RecordingListResponse, error) {
+	conditions, args := cdrConditions(&req.CDRListRequest, "c.")
+	argPos := len(args) + 1
+
+	if req.Domain != nil {
+		conditions = append(conditions, fmt.Sprintf("d.domain = $%d", argPos))
+		args = append(args, *req.Domain)
+		argPos++
+	}
+
+	if req.LegalHold != nil {
+		conditions = append(conditions, fmt.Sprintf("rec.legal_hold = $%d", argPos))
+		args = append(args, *req.LegalHold)
+		argPos++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM ` + recordingTables + whereClause
+	if err := db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count recordings: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.PerPage
+	args = append(args, req.PerPage, offset)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		%s
+		ORDER BY COALESCE(rec.start_time, rec.created_at) DESC, rec.id DESC
+		LIMIT $%d OFFSET $%d
+	`, recordingColumns, recordingTables, whereClause, argPos, argPos+1)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query recordings: %w", err)
+	}
+	defer rows.Close()
+
+	recordings := []*models.Recording{}
+	for rows.Next() {
+		rec, err := scanRecording(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan recording: %w", err)
+		}
+		recordings = append(recordings, rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &models.RecordingListResponse{
+		Recordings: recordings,
+		Total:      total,
+		Page:       req.Page,
+		PerPage:    req.PerPage,
+	}, nil
+}
+
+// SetRecordingLegalHold places (hold) or releases a legal hold on a
+// recording; by names who placed it
+func (db *DB) SetRecordingLegalHold(ctx context.Context, id int64, hold bool, reason, by string) (*models.Recording, error) {
+	query := `
+		UPDATE voip.recordings
+		SET legal_hold = $1,
+			legal_hold_reason = CASE WHEN $1 THEN NULLIF($2, '') END,
+			legal_hold_by = CASE WHEN $1 THEN NULLIF($3, '') END,
+			legal_hold_at = CASE WHEN $1 THEN NOW() END
+		WHERE id = $4
+	`
+
+	result, err := db.ExecContext(ctx, query, hold, reason, by, id)
+	if err != nil {
+		return nil, fmt.Errorf("update recording legal hold: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("recording not found: %d", id)
+	}
+
+	return db.GetRecording(ctx, id)
+}
+
+// ListExpiredRecordings retrieves up to limit recordings older than the
+// retention of their policy, or defaultRetentionDays without a policy.
+// Recordings on legal hold and policies keeping recordings forever (0 days)
+// are skipped.
+func (db *DB) ListExpiredRecordings(ctx context.Context, defaultRetentionDays, limit int) ([]*models.Recording, error) {
+	query := `
+		SELECT ` + recordingColumns + `
+		FROM ` + recordingTables + `
+		LEFT JOIN voip.recording_policies rp ON rec.recording_policy_id = rp.id
+		WHERE rec.legal_hold = false
+			AND COALESCE(rp.retention_days, $1) > 0
+			AND COALESCE(rec.start_time, rec.created_at) < NOW() - make_interval(days => COALESCE(rp.retention_days, $1))
+		ORDER BY COALESCE(rec.start_time, rec.created_at)
+		LIMIT $2
+	`
+
+	rows, err := db.QueryContext(ctx, query, defaultRetentionDays, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query expired recordings: %w", err)
+	}
+	defer rows.Close()
+
+	var recordings []*models.Recording
+	for rows.Next() {
+		rec, err := scanRecording(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan recording: %w", err)
+		}
+		recordings = append(recordings, rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return recordings, nil
+}
+
+// DeleteExpiredRecording deletes a recording row unless a legal hold was
+// placed on it meanwhile. Reports whether the row was deleted.
+func (db *DB) DeleteExpiredRecording(ctx context.Context, id int64) (bool, error) {
+	result, err := db.ExecContext(ctx,
+		`DELETE FROM voip.recordings WHERE id = $1 AND legal_hold = false`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete recording: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// ListRegistrations retrieves the contacts of the extensions of a domain
+// from kamailio.location, newest first per extension. Contacts saved
+// without a domain (usrloc use_domain off) match by username alone.
+func (db *DB) ListRegistrations(ctx context.Context, domainID int64, extensionID *int64) ([]*models.Registration, error) {
+	query := `
+		SELECT
+			l.id, l.ruid, e.id, l.username, l.domain, d.domain, l.contact,
+			COALESCE(l.received, ''), COALESCE(l.path, ''), COALESCE(l.socket, ''),
+			l.user_agent, l.callid, l.cseq, l.q, COALESCE(l.instance, ''),
+			l.expires, l.last_modified, l.cflags,
+			CASE WHEN l.expires <= TIMESTAMP '1970-01-02' THEN 0
+				ELSE EXTRACT(EPOCH FROM (l.expires - LOCALTIMESTAMP))::int END
+		FROM kamailio.location l
+		INNER JOIN voip.extensions e ON e.extension = l.username
+		INNER JOIN voip.domains d ON d.id = e.domain_id
+		WHERE e.domain_id = $1
+		  AND (l.domain = d.domain OR l.domain = '')
+		  AND ($2::bigint IS NULL OR e.id = $2)
+		ORDER BY l.username, l.last_modified DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, domainID, extensionID)
+	if err != nil {
+		return nil, fmt.Errorf("query registrations: %w", err)
+	}
+	defer rows.Close()
+
+	registrations := []*models.Registration{}
+	for rows.Next() {
+		var reg models.Registration
+		var savedDomain string
+		err := rows.Scan(
+			&reg.ID, &reg.RUID, &reg.ExtensionID, &reg.Username, &savedDomain, &reg.Domain, &reg.Contact,
+			&reg.Received, &reg.Path, &reg.Socket,
+			&reg.UserAgent, &reg.CallID, &reg.CSeq, &reg.Q, &reg.Instance,
+			&reg.Expires, &reg.LastModified, &reg.CFlags,
+			&reg.ExpiresIn,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan registration: %w", err)
+		}
+
+		reg.AOR = reg.Username
+		if savedDomain != "" {
+			reg.AOR += "@" + savedDomain
+		}
+
+		registrations = append(registrations, &reg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return registrations, nil
+}
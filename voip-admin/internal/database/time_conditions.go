@@ -0,0 +1,369 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// timeConditionColumns are the columns scanned by scanTimeCondition
+const timeConditionColumns = `
+	id, domain_id, name, timezone, COALESCE(override, ''),
+	closed_action, COALESCE(closed_target, ''), created_at, updated_at
+`
+
+// scanTimeCondition scans a row of timeConditionColumns
+func scanTimeCondition(row interface{ Scan(...interface{}) error }) (*models.TimeCondition, error) {
+	var tc models.TimeCondition
+	err := row.Scan(
+		&tc.ID, &tc.DomainID, &tc.Name, &tc.Timezone, &tc.Override,
+		&tc.ClosedAction, &tc.ClosedTarget, &tc.CreatedAt, &tc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &tc, nil
+}
+
+// GetTimeCondition retrieves a time condition with its schedule, holidays
+// and destinations
+func (db *DB) GetTimeCondition(ctx context.Context, id int64) (*models.TimeCondition, error) {
+	query := `SELECT ` + timeConditionColumns + ` FROM voip.time_conditions WHERE id = $1`
+
+	tc, err := scanTimeCondition(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("time condition not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query time condition: %w", err)
+	}
+
+	if err := db.loadTimeConditionDetails(ctx, tc); err != nil {
+		return nil, err
+	}
+
+	return tc, nil
+}
+
+// ListTimeConditions retrieves all time conditions, optionally of one domain
+func (db *DB) ListTimeConditions(ctx context.Context, domainID *int64) ([]*models.TimeCondition, error) {
+	query := `
+		SELECT ` + timeConditionColumns + `
+		FROM voip.time_conditions
+		WHERE ($1::bigint IS NULL OR domain_id = $1)
+		ORDER BY domain_id, name
+	`
+
+	rows, err := db.QueryContext(ctx, query, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("query time conditions: %w", err)
+	}
+	defer rows.Close()
+
+	conditions := []*models.TimeCondition{}
+	for rows.Next() {
+		tc, err := scanTimeCondition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan time condition: %w", err)
+		}
+		conditions = append(conditions, tc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	for _, tc := range conditions {
+		if err := db.loadTimeConditionDetails(ctx, tc); err != nil {
+			return nil, err
+		}
+	}
+
+	return conditions, nil
+}
+
+// GetTimeConditionForNumber retrieves the time condition guarding an
+// extension, queue or IVR menu number of a domain, or nil if there is none
+func (db *DB) GetTimeConditionForNumber(ctx context.Context, domain, number string) (*models.TimeCondition, error) {
+	query := `
+		SELECT tcd.time_condition_id
+		FROM voip.time_condition_destinations tcd
+		INNER JOIN voip.time_conditions tc ON tc.id = tcd.time_condition_id
+		INNER JOIN voip.domains d ON d.id = tc.domain_id
+		LEFT JOIN voip.extensions e ON e.id = tcd.extension_id
+		LEFT JOIN voip.queues q ON q.id = tcd.queue_id
+		LEFT JOIN voip.ivr_menus i ON i.id = tcd.ivr_id
+		WHERE d.domain = $1 AND COALESCE(e.extension, q.extension, i.extension) = $2
+		LIMIT 1
+	`
+
+	var id int64
+	err := db.QueryRowContext(ctx, query, domain, number).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query time condition destination: %w", err)
+	}
+
+	return db.GetTimeCondition(ctx, id)
+}
+
+// CreateTimeCondition creates a time condition with its schedule, holidays
+// and destinations
+func (db *DB) CreateTimeCondition(ctx context.Context, req *models.TimeConditionRequest) (*models.TimeCondition, error) {
+	var id int64
+
+	err := db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO voip.time_conditions (
+				domain_id, name, timezone, override, closed_action, closed_target
+			) VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''))
+			RETURNING id
+		`
+		if err := tx.QueryRowContext(ctx, query,
+			req.DomainID, req.Name, req.Timezone, req.Override, req.ClosedAction, req.ClosedTarget,
+		).Scan(&id); err != nil {
+			return fmt.Errorf("insert time condition: %w", err)
+		}
+
+		return insertTimeConditionDetails(ctx, tx, id, req.DomainID, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetTimeCondition(ctx, id)
+}
+
+// UpdateTimeCondition replaces a time condition, including its schedule,
+// holidays and destinations
+func (db *DB) UpdateTimeCondition(ctx context.Context, id int64, req *models.TimeConditionRequest) (*models.TimeCondition, error) {
+	err := db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE voip.time_conditions
+			SET name = $1, timezone = $2, override = NULLIF($3, ''),
+				closed_action = $4, closed_target = NULLIF($5, ''), updated_at = NOW()
+			WHERE id = $6
+			RETURNING domain_id
+		`
+		var domainID int64
+		err := tx.QueryRowContext(ctx, query,
+			req.Name, req.Timezone, req.Override, req.ClosedAction, req.ClosedTarget, id,
+		).Scan(&domainID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("time condition not found: %d", id)
+		}
+		if err != nil {
+			return fmt.Errorf("update time condition: %w", err)
+		}
+
+		for _, table := range []string{"time_condition_rules", "time_condition_holidays", "time_condition_destinations"} {
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM voip.`+table+` WHERE time_condition_id = $1`, id,
+			); err != nil {
+				return fmt.Errorf("delete %s: %w", table, err)
+			}
+		}
+
+		return insertTimeConditionDetails(ctx, tx, id, domainID, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetTimeCondition(ctx, id)
+}
+
+// SetTimeConditionOverride forces a time condition open or closed, or
+// makes it follow its schedule again when override is empty
+func (db *DB) SetTimeConditionOverride(ctx context.Context, id int64, override string) error {
+	query := `
+		UPDATE voip.time_conditions
+		SET override = NULLIF($1, ''), updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := db.ExecContext(ctx, query, override, id)
+	if err != nil {
+		return fmt.Errorf("update time condition override: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("time condition not found: %d", id)
+	}
+
+	return nil
+}
+
+// DeleteTimeCondition deletes a time condition; its destinations route
+// normally again
+func (db *DB) DeleteTimeCondition(ctx context.Context, id int64) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM voip.time_conditions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete time condition: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("time condition not found: %d", id)
+	}
+
+	return nil
+}
+
+// insertTimeConditionDetails inserts the schedule, holidays and
+// destinations of a time condition. Destinations are looked up by number
+// in the condition's domain.
+func insertTimeConditionDetails(ctx context.Context, tx *sql.Tx, id, domainID int64, req *models.TimeConditionRequest) error {
+	for _, rule := range req.Schedule {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO voip.time_condition_rules (time_condition_id, weekday, start_time, end_time)
+			VALUES ($1, $2, $3, $4)
+		`, id, rule.Weekday, rule.Start, rule.End); err != nil {
+			return fmt.Errorf("insert time condition rule: %w", err)
+		}
+	}
+
+	for _, h := range req.Holidays {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO voip.time_condition_holidays (time_condition_id, name, start_date, end_date)
+			VALUES ($1, $2, $3, $4)
+		`, id, h.Name, h.StartDate, h.EndDate); err != nil {
+			return fmt.Errorf("insert time condition holiday: %w", err)
+		}
+	}
+
+	for _, dest := range req.Destinations {
+		var query string
+		switch dest.Type {
+		case models.NumberTypeUser:
+			query = `
+				INSERT INTO voip.time_condition_destinations (time_condition_id, extension_id)
+				SELECT $1, id FROM voip.extensions
+				WHERE domain_id = $2 AND extension = $3 AND type = 'user'
+			`
+		case models.NumberTypeQueue:
+			query = `
+				INSERT INTO voip.time_condition_destinations (time_condition_id, queue_id)
+				SELECT $1, id FROM voip.queues WHERE domain_id = $2 AND extension = $3
+			`
+		case models.NumberTypeIVR:
+			query = `
+				INSERT INTO voip.time_condition_destinations (time_condition_id, ivr_id)
+				SELECT $1, id FROM voip.ivr_menus WHERE domain_id = $2 AND extension = $3
+			`
+		default:
+			return fmt.Errorf("invalid destination type: %s", dest.Type)
+		}
+
+		result, err := tx.ExecContext(ctx, query, id, domainID, dest.Number)
+		if err != nil {
+			return fmt.Errorf("insert time condition destination %s: %w", dest.Number, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("%s not found: %s", dest.Type, dest.Number)
+		}
+	}
+
+	return nil
+}
+
+// loadTimeConditionDetails loads the schedule, holidays and destinations
+// of a time condition
+func (db *DB) loadTimeConditionDetails(ctx context.Context, tc *models.TimeCondition) error {
+	tc.Schedule = []*models.TimeRule{}
+	tc.Holidays = []*models.Holiday{}
+	tc.Destinations = []*models.TimeConditionDestination{}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT weekday, TO_CHAR(start_time, 'HH24:MI'), TO_CHAR(end_time, 'HH24:MI')
+		FROM voip.time_condition_rules
+		WHERE time_condition_id = $1
+		ORDER BY weekday, start_time
+	`, tc.ID)
+	if err != nil {
+		return fmt.Errorf("query time condition rules: %w", err)
+	}
+	for rows.Next() {
+		var rule models.TimeRule
+		if err := rows.Scan(&rule.Weekday, &rule.Start, &rule.End); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan time condition rule: %w", err)
+		}
+		tc.Schedule = append(tc.Schedule, &rule)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT name, TO_CHAR(start_date, 'YYYY-MM-DD'), TO_CHAR(end_date, 'YYYY-MM-DD')
+		FROM voip.time_condition_holidays
+		WHERE time_condition_id = $1
+		ORDER BY start_date
+	`, tc.ID)
+	if err != nil {
+		return fmt.Errorf("query time condition holidays: %w", err)
+	}
+	for rows.Next() {
+		var h models.Holiday
+		if err := rows.Scan(&h.Name, &h.StartDate, &h.EndDate); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan time condition holiday: %w", err)
+		}
+		tc.Holidays = append(tc.Holidays, &h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT
+			CASE
+				WHEN tcd.extension_id IS NOT NULL THEN 'user'
+				WHEN tcd.queue_id IS NOT NULL THEN 'queue'
+				ELSE 'ivr'
+			END,
+			COALESCE(e.extension, q.extension, i.extension, '')
+		FROM voip.time_condition_destinations tcd
+		LEFT JOIN voip.extensions e ON e.id = tcd.extension_id
+		LEFT JOIN voip.queues q ON q.id = tcd.queue_id
+		LEFT JOIN voip.ivr_menus i ON i.id = tcd.ivr_id
+		WHERE tcd.time_condition_id = $1
+		ORDER BY tcd.id
+	`, tc.ID)
+	if err != nil {
+		return fmt.Errorf("query time condition destinations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var dest models.TimeConditionDestination
+		if err := rows.Scan(&dest.Type, &dest.Number); err != nil {
+			return fmt.Errorf("scan time condition destination: %w", err)
+		}
+		tc.Destinations = append(tc.Destinations, &dest)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,295 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// trunkColumns are the voip.trunks columns scanned by scanTrunk
+const trunkColumns = `
+	t.id, t.domain_id, t.name, COALESCE(t.type, ''), t.sip_profile,
+	COALESCE(t.host, ''), COALESCE(t.port, 5060), t.transport,
+	COALESCE(t.username, ''), COALESCE(t.password, ''), COALESCE(t.realm, ''),
+	t.register, t.expire_seconds, COALESCE(t.codec_prefs, ''), t.caller_id_in_from,
+	COALESCE(t.prefix, ''), COALESCE(t.description, ''), t.rate_deck_id, COALESCE(t.active, true),
+	t.created_at, t.updated_at
+`
+
+// scanTrunk scans a row of trunkColumns
+func scanTrunk(row interface{ Scan(...interface{}) error }) (*models.Trunk, error) {
+	var t models.Trunk
+	var domainID, rateDeckID sql.NullInt64
+	err := row.Scan(
+		&t.ID, &domainID, &t.Name, &t.Type, &t.SIPProfile,
+		&t.Host, &t.Port, &t.Transport,
+		&t.Username, &t.Password, &t.Realm,
+		&t.Register, &t.ExpireSeconds, &t.CodecPrefs, &t.CallerIDInFrom,
+		&t.Prefix, &t.Description, &rateDeckID, &t.Active,
+		&t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if domainID.Valid {
+		t.DomainID = &domainID.Int64
+	}
+	if rateDeckID.Valid {
+		t.RateDeckID = &rateDeckID.Int64
+	}
+	t.HasPassword = t.Password != ""
+
+	return &t, nil
+}
+
+// GetTrunk retrieves a trunk by ID
+func (db *DB) GetTrunk(ctx context.Context, id int64) (*models.Trunk, error) {
+	query := `SELECT ` + trunkColumns + ` FROM voip.trunks t WHERE t.id = $1`
+
+	trunk, err := scanTrunk(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("trunk not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query trunk: %w", err)
+	}
+
+	return trunk, nil
+}
+
+// GetTrunkByName retrieves a trunk by its gateway name
+func (db *DB) GetTrunkByName(ctx context.Context, name string) (*models.Trunk, error) {
+	query := `SELECT ` + trunkColumns + ` FROM voip.trunks t WHERE t.name = $1`
+
+	trunk, err := scanTrunk(db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("trunk not found: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query trunk: %w", err)
+	}
+
+	return trunk, nil
+}
+
+// ListTrunks retrieves all trunks, optionally of one domain
+func (db *DB) ListTrunks(ctx context.Context, domainID *int64) ([]*models.Trunk, error) {
+	query := `
+		SELECT ` + trunkColumns + `
+		FROM voip.trunks t
+		WHERE ($1::bigint IS NULL OR t.domain_id = $1)
+		ORDER BY t.name
+	`
+
+	return db.queryTrunks(ctx, query, domainID)
+}
+
+// ListGatewayTrunks retrieves the active trunks of active SIP profiles, the
+// gateways rendered into sofia.conf
+func (db *DB) ListGatewayTrunks(ctx context.Context) ([]*models.Trunk, error) {
+	query := `
+		SELECT ` + trunkColumns + `
+		FROM voip.trunks t
+		INNER JOIN voip.sip_profiles p ON p.name = t.sip_profile
+		WHERE COALESCE(t.active, true) AND p.active
+		ORDER BY t.sip_profile, t.name
+	`
+
+	return db.queryTrunks(ctx, query)
+}
+
+// ListOutboundTrunks retrieves the gateway trunks a domain can call out
+// through: its own first, then the shared ones
+func (db *DB) ListOutboundTrunks(ctx context.Context, domainID int64) ([]*models.Trunk, error) {
+	query := `
+		SELECT ` + trunkColumns + `
+		FROM voip.trunks t
+		INNER JOIN voip.sip_profiles p ON p.name = t.sip_profile
+		WHERE COALESCE(t.active, true) AND p.active
+		  AND (t.domain_id = $1 OR t.domain_id IS NULL)
+		ORDER BY t.domain_id NULLS LAST, t.name
+	`
+
+	return db.queryTrunks(ctx, query, domainID)
+}
+
+// queryTrunks runs a query selecting trunkColumns
+func (db *DB) queryTrunks(ctx context.Context, query string, args ...interface{}) ([]*models.Trunk, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query trunks: %w", err)
+	}
+	defer rows.Close()
+
+	trunks := []*models.Trunk{}
+	for rows.Next() {
+		trunk, err := scanTrunk(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan trunk: %w", err)
+		}
+		trunks = append(trunks, trunk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return trunks, nil
+}
+
+// CreateTrunk creates a new trunk
+func (db *DB) CreateTrunk(ctx context.Context, trunk *models.Trunk) (*models.Trunk, error) {
+	query := `
+		INSERT INTO voip.trunks (
+			domain_id, name, type, sip_profile, host, port, transport,
+			username, password, realm, register, expire_seconds, codec_prefs,
+			caller_id_in_from, prefix, description, active, rate_deck_id
+		) VALUES (
+			$1, $2, NULLIF($3, ''), $4, $5, $6, $7,
+			NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11, $12, NULLIF($13, ''),
+			$14, NULLIF($15, ''), NULLIF($16, ''), $17, $18
+		)
+		RETURNING id
+	`
+
+	var id int64
+	err := db.QueryRowContext(ctx, query,
+		trunk.DomainID, trunk.Name, trunk.Type, trunk.SIPProfile, trunk.Host, trunk.Port, trunk.Transport,
+		trunk.Username, trunk.Password, trunk.Realm, trunk.Register, trunk.ExpireSeconds, trunk.CodecPrefs,
+		trunk.CallerIDInFrom, trunk.Prefix, trunk.Description, trunk.Active, trunk.RateDeckID,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("insert trunk: %w", err)
+	}
+
+	return db.GetTrunk(ctx, id)
+}
+
+// UpdateTrunk stores the mutable fields of a trunk; the caller merges the
+// update request into the current trunk and validates the result
+func (db *DB) UpdateTrunk(ctx context.Context, trunk *models.Trunk) (*models.Trunk, error) {
+	query := `
+		UPDATE voip.trunks
+		SET type = NULLIF($1, ''), sip_profile = $2, host = $3, port = $4, transport = $5,
+			username = NULLIF($6, ''), password = NULLIF($7, ''), realm = NULLIF($8, ''),
+			register = $9, expire_seconds = $10, codec_prefs = NULLIF($11, ''),
+			caller_id_in_from = $12, prefix = NULLIF($13, ''), description = NULLIF($14, ''),
+			active = $15, rate_deck_id = $16, updated_at = NOW()
+		WHERE id = $17
+	`
+
+	result, err := db.ExecContext(ctx, query,
+		trunk.Type, trunk.SIPProfile, trunk.Host, trunk.Port, trunk.Transport,
+		trunk.Username, trunk.Password, trunk.Realm,
+		trunk.Register, trunk.ExpireSeconds, trunk.CodecPrefs,
+		trunk.CallerIDInFrom, trunk.Prefix, trunk.Description,
+		trunk.Active, trunk.RateDeckID, trunk.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("update trunk: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("trunk not found: %d", trunk.ID)
+	}
+
+	return db.GetTrunk(ctx, trunk.ID)
+}
+
+// DeleteTrunk deletes a trunk; its gateway stays up on FreeSWITCH until the
+// gateways are reloaded
+func (db *DB) DeleteTrunk(ctx context.Context, id int64) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM voip.trunks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete trunk: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("trunk not found: %d", id)
+	}
+
+	return nil
+}
+
+// sipProfileColumns are the voip.sip_profiles columns scanned by
+// scanSIPProfile
+const sipProfileColumns = `
+	p.id, p.name, COALESCE(p.sip_ip, ''), p.sip_port, COALESCE(p.rtp_ip, ''),
+	COALESCE(p.ext_sip_ip, ''), COALESCE(p.ext_rtp_ip, ''), p.context,
+	p.inbound_codec_prefs, p.outbound_codec_prefs, p.auth_calls, COALESCE(p.inbound_acl, ''),
+	p.active, p.created_at, p.updated_at
+`
+
+// scanSIPProfile scans a row of sipProfileColumns
+func scanSIPProfile(row interface{ Scan(...interface{}) error }) (*models.SIPProfile, error) {
+	var p models.SIPProfile
+	err := row.Scan(
+		&p.ID, &p.Name, &p.SIPIP, &p.SIPPort, &p.RTPIP,
+		&p.ExtSIPIP, &p.ExtRTPIP, &p.Context,
+		&p.InboundCodecPrefs, &p.OutboundCodecPrefs, &p.AuthCalls, &p.InboundACL,
+		&p.Active, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetSIPProfile retrieves a SIP profile by name
+func (db *DB) GetSIPProfile(ctx context.Context, name string) (*models.SIPProfile, error) {
+	query := `SELECT ` + sipProfileColumns + ` FROM voip.sip_profiles p WHERE p.name = $1`
+
+	profile, err := scanSIPProfile(db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("SIP profile not found: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query SIP profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// ListSIPProfiles retrieves the active SIP profiles
+func (db *DB) ListSIPProfiles(ctx context.Context) ([]*models.SIPProfile, error) {
+	query := `
+		SELECT ` + sipProfileColumns + `
+		FROM voip.sip_profiles p
+		WHERE p.active
+		ORDER BY p.name
+	`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query SIP profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []*models.SIPProfile{}
+	for rows.Next() {
+		profile, err := scanSIPProfile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan SIP profile: %w", err)
+		}
+		profiles = append(profiles, profile)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return profiles, nil
+}
@@ -0,0 +1,493 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// userColumns is the column list shared by user queries (joined with domains)
+const userColumns = `
+	u.id, u.domain_id, u.username, COALESCE(u.email, ''), COALESCE(u.full_name, ''),
+	u.role, COALESCE(u.password_hash, ''), u.active, u.last_login_at,
+	u.created_at, u.updated_at, COALESCE(d.domain, '')
+`
+
+// scanUser scans one user row selected with userColumns
+func scanUser(row interface{ Scan(...interface{}) error }) (*models.User, error) {
+	var user models.User
+	if err := row.Scan(
+		&user.ID, &user.DomainID, &user.Username, &user.Email, &user.FullName,
+		&user.Role, &user.PasswordHash, &user.Active, &user.LastLoginAt,
+		&user.CreatedAt, &user.UpdatedAt, &user.Domain,
+	); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUser retrieves a user by ID
+func (db *DB) GetUser(ctx context.Context, id int64) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM voip.users u
+		LEFT JOIN voip.domains d ON u.domain_id = d.id
+		WHERE u.id = $1
+	`
+
+	user, err := scanUser(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserForLogin retrieves a user by domain name and username. An empty
+// domain selects global users (domain_id IS NULL).
+func (db *DB) GetUserForLogin(ctx context.Context, domain, username string) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM voip.users u
+		LEFT JOIN voip.domains d ON u.domain_id = d.id
+		WHERE u.username = $1
+		  AND (($2 = '' AND u.domain_id IS NULL) OR (d.domain = $2 AND d.active = true))
+	`
+
+	user, err := scanUser(db.QueryRowContext(ctx, query, username, domain))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found: %s@%s", username, domain)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query user: %w", err)
+	}
+
+	return user, nil
+}
+
+// ListUsers retrieves users with pagination and filtering
+func (db *DB) ListUsers(ctx context.Context, domainID *int64, role *string, active *bool, page, perPage int) (*models.UserListResponse, error) {
+	var conditions []string
+	var args []interface{}
+	argPos := 1
+
+	if domainID != nil {
+		conditions = append(conditions, fmt.Sprintf("u.domain_id = $%d", argPos))
+		args = append(args, *domainID)
+		argPos++
+	}
+
+	if role != nil {
+		conditions = append(conditions, fmt.Sprintf("u.role = $%d", argPos))
+		args = append(args, *role)
+		argPos++
+	}
+
+	if active != nil {
+		conditions = append(conditions, fmt.Sprintf("u.active = $%d", argPos))
+		args = append(args, *active)
+		argPos++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Count total
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM voip.users u
+		%s
+	`, whereClause)
+
+	var total int64
+	if err := db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count users: %w", err)
+	}
+
+	// Fetch users
+	offset := (page - 1) * perPage
+	args = append(args, perPage, offset)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM voip.users u
+		LEFT JOIN voip.domains d ON u.domain_id = d.id
+		%s
+		ORDER BY d.domain NULLS FIRST, u.username
+		LIMIT $%d OFFSET $%d
+	`, userColumns, whereClause, argPos, argPos+1)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &models.UserListResponse{
+		Users:   users,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+// CreateUser creates a new user with an already hashed password
+func (db *DB) CreateUser(ctx context.Context, req *models.UserCreateRequest, passwordHash string) (*models.User, error) {
+	query := `
+		INSERT INTO voip.users (
+			domain_id, username, email, full_name, role, password_hash, active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var id int64
+	if err := db.QueryRowContext(ctx, query,
+		req.DomainID, req.Username, req.Email, req.FullName, req.Role, passwordHash, req.Active,
+	).Scan(&id); err != nil {
+		return nil, fmt.Errorf("insert user: %w", err)
+	}
+
+	return db.GetUser(ctx, id)
+}
+
+// UpdateUser updates an existing user. Deactivating a user ends all of its
+// sessions.
+func (db *DB) UpdateUser(ctx context.Context, id int64, req *models.UserUpdateRequest) (*models.User, error) {
+	var setClauses []string
+	var args []interface{}
+	argPos := 1
+
+	if req.Email != nil {
+		setClauses = append(setClauses, fmt.Sprintf("email = $%d", argPos))
+		args = append(args, *req.Email)
+		argPos++
+	}
+
+	if req.FullName != nil {
+		setClauses = append(setClauses, fmt.Sprintf("full_name = $%d", argPos))
+		args = append(args, *req.FullName)
+		argPos++
+	}
+
+	if req.Role != nil {
+		setClauses = append(setClauses, fmt.Sprintf("role = $%d", argPos))
+		args = append(args, *req.Role)
+		argPos++
+	}
+
+	if req.Active != nil {
+		setClauses = append(setClauses, fmt.Sprintf("active = $%d", argPos))
+		args = append(args, *req.Active)
+		argPos++
+	}
+
+	if len(setClauses) == 0 {
+		return db.GetUser(ctx, id)
+	}
+
+	// Add updated_at
+	setClauses = append(setClauses, fmt.Sprintf("updated_at = $%d", argPos))
+	args = append(args, time.Now())
+	argPos++
+
+	// Add ID for WHERE clause
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+		UPDATE voip.users
+		SET %s
+		WHERE id = $%d
+	`, strings.Join(setClauses, ", "), argPos)
+
+	err := db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("update user: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("user not found: %d", id)
+		}
+
+		if req.Active != nil && !*req.Active {
+			return revokeUserSessions(ctx, tx, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetUser(ctx, id)
+}
+
+// SetUserPassword replaces a user's password hash and ends all of its
+// sessions
+func (db *DB) SetUserPassword(ctx context.Context, id int64, passwordHash string) error {
+	return db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE voip.users
+			SET password_hash = $1, updated_at = $2
+			WHERE id = $3
+		`, passwordHash, time.Now(), id)
+		if err != nil {
+			return fmt.Errorf("update user password: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("user not found: %d", id)
+		}
+
+		return revokeUserSessions(ctx, tx, id)
+	})
+}
+
+// DeleteUser soft-deletes a user by marking it as inactive
+func (db *DB) DeleteUser(ctx context.Context, id int64) error {
+	active := false
+	_, err := db.UpdateUser(ctx, id, &models.UserUpdateRequest{Active: &active})
+	return err
+}
+
+// CreateUserSession starts a login session and records the login time
+func (db *DB) CreateUserSession(ctx context.Context, userID int64, ipAddress, userAgent string, expiresAt time.Time) (*models.UserSession, error) {
+	session := &models.UserSession{
+		UserID:    userID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		ExpiresAt: expiresAt,
+	}
+
+	err := db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, `
+			INSERT INTO voip.user_sessions (user_id, ip_address, user_agent, expires_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		`, userID, ipAddress, userAgent, expiresAt).Scan(&session.ID, &session.CreatedAt); err != nil {
+			return fmt.Errorf("insert user session: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE voip.users SET last_login_at = $1 WHERE id = $2
+		`, session.CreatedAt, userID); err != nil {
+			return fmt.Errorf("update last login: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetSessionUser retrieves the user of a session that is still valid: not
+// revoked, not expired, and the user and its domain still active
+func (db *DB) GetSessionUser(ctx context.Context, sessionID int64) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM voip.user_sessions s
+		INNER JOIN voip.users u ON s.user_id = u.id
+		LEFT JOIN voip.domains d ON u.domain_id = d.id
+		WHERE s.id = $1
+		  AND s.revoked_at IS NULL
+		  AND s.expires_at > NOW()
+		  AND u.active = true
+		  AND (u.domain_id IS NULL OR d.active = true)
+	`
+
+	user, err := scanUser(db.QueryRowContext(ctx, query, sessionID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session not found or expired: %d", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query session: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserSession retrieves a session by ID
+func (db *DB) GetUserSession(ctx context.Context, sessionID int64) (*models.UserSession, error) {
+	query := `
+		SELECT id, user_id, COALESCE(ip_address, ''), COALESCE(user_agent, ''),
+			created_at, expires_at, revoked_at
+		FROM voip.user_sessions
+		WHERE id = $1
+	`
+
+	var session models.UserSession
+	err := db.QueryRowContext(ctx, query, sessionID).Scan(
+		&session.ID, &session.UserID, &session.IPAddress, &session.UserAgent,
+		&session.CreatedAt, &session.ExpiresAt, &session.RevokedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session not found: %d", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// RevokeUserSession ends a single session (logout)
+func (db *DB) RevokeUserSession(ctx context.Context, sessionID int64) error {
+	query := `
+		UPDATE voip.user_sessions
+		SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+
+	if _, err := db.ExecContext(ctx, query, sessionID); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// revokeUserSessions ends all open sessions of a user
+func revokeUserSessions(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `
+		UPDATE voip.user_sessions
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("revoke user sessions: %w", err)
+	}
+
+	return nil
+}
+
+// CleanupExpiredSessions deletes sessions that expired more than a day ago
+func (db *DB) CleanupExpiredSessions(ctx context.Context) (int64, error) {
+	result, err := db.ExecContext(ctx, `
+		DELETE FROM voip.user_sessions
+		WHERE expires_at < NOW() - INTERVAL '1 day'
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("cleanup sessions: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// ListSupervisedQueueIDs returns the IDs of the queues a user supervises
+func (db *DB) ListSupervisedQueueIDs(ctx context.Context, userID int64) ([]int64, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT queue_id
+		FROM voip.queue_supervisors
+		WHERE user_id = $1
+		ORDER BY queue_id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query supervised queues: %w", err)
+	}
+	defer rows.Close()
+
+	queueIDs := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan queue id: %w", err)
+		}
+		queueIDs = append(queueIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return queueIDs, nil
+}
+
+// SetSupervisedQueues replaces the set of queues a user supervises. Queues
+// outside the user's domain are rejected.
+func (db *DB) SetSupervisedQueues(ctx context.Context, userID int64, queueIDs []int64) error {
+	return db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM voip.queue_supervisors WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("clear supervised queues: %w", err)
+		}
+
+		for _, queueID := range queueIDs {
+			result, err := tx.ExecContext(ctx, `
+				INSERT INTO voip.queue_supervisors (queue_id, user_id)
+				SELECT q.id, u.id
+				FROM voip.queues q
+				INNER JOIN voip.users u ON u.domain_id = q.domain_id
+				WHERE q.id = $1 AND u.id = $2
+				ON CONFLICT DO NOTHING
+			`, queueID, userID)
+			if err != nil {
+				return fmt.Errorf("insert supervised queue: %w", err)
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("get rows affected: %w", err)
+			}
+
+			if rowsAffected == 0 {
+				return fmt.Errorf("queue not found in user's domain: %d", queueID)
+			}
+		}
+
+		return nil
+	})
+}
+
+// IsSupervisedAgent reports whether an extension is an agent of any queue
+// the user supervises
+func (db *DB) IsSupervisedAgent(ctx context.Context, userID, extensionID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM voip.queue_agents qa
+			INNER JOIN voip.queue_supervisors qs ON qa.queue_id = qs.queue_id
+			WHERE qs.user_id = $1 AND qa.extension_id = $2
+		)
+	`
+
+	var supervised bool
+	if err := db.QueryRowContext(ctx, query, userID, extensionID).Scan(&supervised); err != nil {
+		return false, fmt.Errorf("query supervised agent: %w", err)
+	}
+
+	return supervised, nil
+}
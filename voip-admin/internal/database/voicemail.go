@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// voicemailColumns are the voip.voicemail_msgs columns scanned by
+// scanVoicemailMessage
+const voicemailColumns = `
+	COALESCE(m.uuid, ''), COALESCE(m.username, ''), COALESCE(m.domain, ''),
+	COALESCE(m.in_folder, ''), COALESCE(m.cid_name, ''), COALESCE(m.cid_number, ''),
+	COALESCE(m.message_len, 0), COALESCE(m.read_flags, ''), COALESCE(m.created_epoch, 0),
+	COALESCE(m.read_epoch, 0), COALESCE(m.file_path, '')
+`
+
+// voicemailVisible excludes messages mod_voicemail has flagged for deletion
+const voicemailVisible = `COALESCE(m.flags, '') <> 'delete'`
+
+// scanVoicemailMessage scans a row of voicemailColumns, followed by dest
+func scanVoicemailMessage(row interface{ Scan(...interface{}) error }, dest ...interface{}) (*models.VoicemailMessage, error) {
+	var msg models.VoicemailMessage
+	var readFlags string
+	var createdEpoch, readEpoch int64
+
+	err := row.Scan(append([]interface{}{
+		&msg.UUID, &msg.Extension, &msg.Domain,
+		&msg.Folder, &msg.CallerIDName, &msg.CallerIDNumber,
+		&msg.Duration, &readFlags, &createdEpoch,
+		&readEpoch, &msg.FilePath,
+	}, dest...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.Urgent = readFlags == "A_URGENT"
+	msg.ReceivedAt = time.Unix(createdEpoch, 0)
+	if readEpoch > 0 {
+		readAt := time.Unix(readEpoch, 0)
+		msg.ReadAt = &readAt
+		msg.Read = true
+	}
+
+	return &msg, nil
+}
+
+// ListVoicemailMessages retrieves the messages of a mailbox, newest first
+func (db *DB) ListVoicemailMessages(ctx context.Context, extension, domain string) ([]*models.VoicemailMessage, error) {
+	query := `
+		SELECT ` + voicemailColumns + `
+		FROM voip.voicemail_msgs m
+		WHERE m.username = $1 AND m.domain = $2 AND ` + voicemailVisible + `
+		ORDER BY m.created_epoch DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, extension, domain)
+	if err != nil {
+		return nil, fmt.Errorf("query voicemail messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []*models.VoicemailMessage{}
+	for rows.Next() {
+		msg, err := scanVoicemailMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan voicemail message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return messages, nil
+}
+
+// GetVoicemailMessage retrieves a message of a mailbox by UUID
+func (db *DB) GetVoicemailMessage(ctx context.Context, extension, domain, uuid string) (*models.VoicemailMessage, error) {
+	query := `
+		SELECT ` + voicemailColumns + `
+		FROM voip.voicemail_msgs m
+		WHERE m.username = $1 AND m.domain = $2 AND m.uuid = $3 AND ` + voicemailVisible
+
+	msg, err := scanVoicemailMessage(db.QueryRowContext(ctx, query, extension, domain, uuid))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("voicemail message not found: %s", uuid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query voicemail message: %w", err)
+	}
+
+	return msg, nil
+}
+
+// GetVoicemailMWI counts the inbox messages of a mailbox the way
+// mod_voicemail does for message waiting indication
+func (db *DB) GetVoicemailMWI(ctx context.Context, extension, domain string) (*models.VoicemailMWI, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE COALESCE(m.read_epoch, 0) = 0),
+			COUNT(*) FILTER (WHERE COALESCE(m.read_epoch, 0) > 0),
+			COUNT(*) FILTER (WHERE COALESCE(m.read_epoch, 0) = 0 AND m.read_flags = 'A_URGENT'),
+			COUNT(*) FILTER (WHERE COALESCE(m.read_epoch, 0) > 0 AND m.read_flags = 'A_URGENT')
+		FROM voip.voicemail_msgs m
+		WHERE m.username = $1 AND m.domain = $2 AND m.in_folder = $3 AND ` + voicemailVisible
+
+	var mwi models.VoicemailMWI
+	err := db.QueryRowContext(ctx, query, extension, domain, models.VoicemailFolderInbox).Scan(
+		&mwi.New, &mwi.Saved, &mwi.NewUrgent, &mwi.SavedUrgent,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("count voicemail messages: %w", err)
+	}
+	mwi.MessagesWaiting = mwi.New > 0
+
+	return &mwi, nil
+}
+
+// ClaimVoicemailNotifications claims up to limit new inbox messages
+// received after since whose extension has a voicemail email address.
+// Each message is claimed by one voipadmind node only.
+func (db *DB) ClaimVoicemailNotifications(ctx context.Context, since time.Time, limit int) ([]*models.VoicemailNotification, error) {
+	query := `
+		WITH pending AS (
+			SELECT m.uuid AS claim_uuid, e.vm_email, e.display_name
+			FROM voip.voicemail_msgs m
+			INNER JOIN voip.domains d ON d.domain = m.domain
+			INNER JOIN voip.extensions e ON e.domain_id = d.id AND e.extension = m.username
+			WHERE m.in_folder = $1 AND m.created_epoch > $2 AND ` + voicemailVisible + `
+				AND COALESCE(e.vm_email, '') <> ''
+				AND NOT EXISTS (
+					SELECT 1 FROM voip.voicemail_notifications n WHERE n.message_uuid = m.uuid
+				)
+			ORDER BY m.created_epoch
+			LIMIT $3
+		), claimed AS (
+			INSERT INTO voip.voicemail_notifications (message_uuid)
+			SELECT claim_uuid FROM pending
+			ON CONFLICT (message_uuid) DO NOTHING
+			RETURNING message_uuid
+		)
+		SELECT ` + voicemailColumns + `, p.vm_email, COALESCE(p.display_name, '')
+		FROM claimed c
+		INNER JOIN pending p ON p.claim_uuid = c.message_uuid
+		INNER JOIN voip.voicemail_msgs m ON m.uuid = c.message_uuid
+	`
+
+	rows, err := db.QueryContext(ctx, query, models.VoicemailFolderInbox, since.Unix(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim voicemail notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*models.VoicemailNotification
+	for rows.Next() {
+		n := &models.VoicemailNotification{}
+		msg, err := scanVoicemailMessage(rows, &n.Email, &n.DisplayName)
+		if err != nil {
+			return nil, fmt.Errorf("scan voicemail notification: %w", err)
+		}
+		n.Message = msg
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// CompleteVoicemailNotification records that a message was emailed
+func (db *DB) CompleteVoicemailNotification(ctx context.Context, uuid string) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE voip.voicemail_notifications SET sent_at = NOW() WHERE message_uuid = $1`, uuid)
+	if err != nil {
+		return fmt.Errorf("complete voicemail notification: %w", err)
+	}
+	return nil
+}
+
+// ReleaseVoicemailNotification drops the claim of a message that could not
+// be emailed, so it is retried
+func (db *DB) ReleaseVoicemailNotification(ctx context.Context, uuid string) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM voip.voicemail_notifications WHERE message_uuid = $1`, uuid)
+	if err != nil {
+		return fmt.Errorf("release voicemail notification: %w", err)
+	}
+	return nil
+}
+
+// CleanupVoicemailNotifications deletes notification claims made before
+// before; their messages are no longer considered for email
+func (db *DB) CleanupVoicemailNotifications(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx,
+		`DELETE FROM voip.voicemail_notifications WHERE claimed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("cleanup voicemail notifications: %w", err)
+	}
+
+	return result.RowsAffected()
+}
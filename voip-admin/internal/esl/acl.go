@@ -0,0 +1,10 @@
+package esl
+
+import "context"
+
+// ReloadACL re-reads acl.conf, so changed network lists apply to new
+// requests
+func (c *Client) ReloadACL(ctx context.Context) error {
+	_, err := c.API(ctx, "reloadacl")
+	return err
+}
@@ -0,0 +1,101 @@
+package esl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrAgentNotFound is returned when mod_callcenter does not know the agent
+var ErrAgentNotFound = errors.New("agent not found in mod_callcenter")
+
+// invalidAgentReply is mod_callcenter's reply to commands on unknown agents
+const invalidAgentReply = "-ERR Invalid Agent!"
+
+// CallCenterAgent is an agent as reported by mod_callcenter.
+//
+// Note the naming: mod_callcenter's "status" (Available, On Break,
+// Logged Out) is what voip.queue_agents stores in its state column, and
+// mod_callcenter's "state" (Waiting, Receiving, In a queue call) is stored
+// in the status column.
+type CallCenterAgent struct {
+	Name    string
+	Contact string
+	Status  string
+	State   string
+}
+
+// SetAgentStatus sets an agent's availability (Available, On Break, Logged Out)
+func (c *Client) SetAgentStatus(ctx context.Context, agent, status string) error {
+	return agentCommand(c.API(ctx, fmt.Sprintf("callcenter_config agent set status %s '%s'", agent, status)))
+}
+
+// SetAgentState sets an agent's call state (Waiting, Receiving, In a queue call)
+func (c *Client) SetAgentState(ctx context.Context, agent, state string) error {
+	return agentCommand(c.API(ctx, fmt.Sprintf("callcenter_config agent set state %s '%s'", agent, state)))
+}
+
+// agentCommand maps the reply of an agent command on an unknown agent to
+// ErrAgentNotFound
+func agentCommand(_ string, err error) error {
+	if err != nil && strings.Contains(err.Error(), invalidAgentReply) {
+		return fmt.Errorf("%w: %v", ErrAgentNotFound, err)
+	}
+	return err
+}
+
+// ListAgents returns all agents currently known to mod_callcenter
+func (c *Client) ListAgents(ctx context.Context) ([]*CallCenterAgent, error) {
+	out, err := c.API(ctx, "callcenter_config agent list")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseAgentList(out)
+}
+
+// parseAgentList parses the pipe-delimited table printed by
+// "callcenter_config agent list". The first line is a header row and the
+// output is terminated by "+OK".
+func parseAgentList(out string) ([]*CallCenterAgent, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return nil, fmt.Errorf("empty agent list")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range strings.Split(strings.TrimSpace(lines[0]), "|") {
+		columns[name] = i
+	}
+	for _, required := range []string{"name", "status", "state"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("agent list missing column %q", required)
+		}
+	}
+
+	var agents []*CallCenterAgent
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "+OK" {
+			continue
+		}
+
+		fields := strings.Split(line, "|")
+		if len(fields) < len(columns) {
+			continue
+		}
+
+		agent := &CallCenterAgent{
+			Name:   fields[columns["name"]],
+			Status: fields[columns["status"]],
+			State:  fields[columns["state"]],
+		}
+		if i, ok := columns["contact"]; ok {
+			agent.Contact = fields[i]
+		}
+		agents = append(agents, agent)
+	}
+
+	return agents, nil
+}
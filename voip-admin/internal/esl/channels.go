@@ -0,0 +1,43 @@
+package esl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Channel is a live call leg, as reported by "show channels as json"
+type Channel struct {
+	UUID         string `json:"uuid"`
+	Direction    string `json:"direction"` // inbound for legs from phones and trunks
+	CreatedEpoch string `json:"created_epoch"`
+	CallerID     string `json:"cid_num"`
+	Destination  string `json:"dest"`
+	PresenceID   string `json:"presence_id"` // user@domain of the phone's leg
+	CallState    string `json:"callstate"`
+}
+
+// ListChannels returns the live call legs on this node
+func (c *Client) ListChannels(ctx context.Context) ([]*Channel, error) {
+	out, err := c.API(ctx, "show channels as json")
+	if err != nil {
+		return nil, err
+	}
+
+	// Without channels FreeSWITCH omits rows altogether
+	var list struct {
+		RowCount int        `json:"row_count"`
+		Rows     []*Channel `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("parse channel list: %w", err)
+	}
+
+	return list.Rows, nil
+}
+
+// HangupUser hangs up every call leg of a user (presence_id user@domain)
+func (c *Client) HangupUser(ctx context.Context, user, domain, cause string) error {
+	_, err := c.API(ctx, fmt.Sprintf("hupall %s presence_id %s@%s", cause, user, domain))
+	return err
+}
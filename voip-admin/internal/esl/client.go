@@ -0,0 +1,143 @@
+package esl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client sends API commands to FreeSWITCH over the inbound Event Socket
+type Client struct {
+	addr     string
+	password string
+	timeout  time.Duration
+}
+
+// Config holds Event Socket connection configuration
+type Config struct {
+	Host     string
+	Port     int
+	Password string
+	Timeout  time.Duration
+}
+
+// NewClient creates a new Event Socket client
+func NewClient(cfg *Config) *Client {
+	if cfg.Host == "" {
+		cfg.Host = "127.0.0.1"
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 8021
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	return &Client{
+		addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		password: cfg.Password,
+		timeout:  cfg.Timeout,
+	}
+}
+
+// API runs a FreeSWITCH API command and returns its response body.
+// Each call uses its own short-lived connection so the client is safe for
+// concurrent use and survives FreeSWITCH restarts without reconnect logic.
+func (c *Client) API(ctx context.Context, command string) (string, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return "", fmt.Errorf("connect event socket: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return "", fmt.Errorf("set deadline: %w", err)
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(conn))
+
+	// FreeSWITCH greets every new connection with an auth request
+	header, _, err := readMessage(reader)
+	if err != nil {
+		return "", fmt.Errorf("read auth request: %w", err)
+	}
+	if header.Get("Content-Type") != "auth/request" {
+		return "", fmt.Errorf("unexpected greeting: %s", header.Get("Content-Type"))
+	}
+
+	if err := send(conn, "auth "+c.password); err != nil {
+		return "", err
+	}
+	header, _, err = readMessage(reader)
+	if err != nil {
+		return "", fmt.Errorf("read auth reply: %w", err)
+	}
+	if reply := header.Get("Reply-Text"); !strings.HasPrefix(reply, "+OK") {
+		return "", fmt.Errorf("event socket auth failed: %s", reply)
+	}
+
+	if err := send(conn, "api "+command); err != nil {
+		return "", err
+	}
+	header, body, err := readMessage(reader)
+	if err != nil {
+		return "", fmt.Errorf("read api response: %w", err)
+	}
+	if header.Get("Content-Type") != "api/response" {
+		return "", fmt.Errorf("unexpected api reply: %s", header.Get("Content-Type"))
+	}
+
+	// Best effort: let FreeSWITCH close its side cleanly
+	_ = send(conn, "exit")
+
+	result := strings.TrimSpace(string(body))
+	if strings.HasPrefix(result, "-ERR") || strings.HasPrefix(result, "-USAGE") {
+		return "", fmt.Errorf("api %q: %s", command, result)
+	}
+
+	return result, nil
+}
+
+// send writes a single Event Socket command
+func send(conn net.Conn, command string) error {
+	if _, err := io.WriteString(conn, command+"\n\n"); err != nil {
+		return fmt.Errorf("write command: %w", err)
+	}
+	return nil
+}
+
+// readMessage reads one Event Socket message (headers plus optional body)
+func readMessage(reader *textproto.Reader) (textproto.MIMEHeader, []byte, error) {
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lengthStr := header.Get("Content-Length")
+	if lengthStr == "" {
+		return header, nil, nil
+	}
+
+	length, err := strconv.Atoi(lengthStr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid content length %q: %w", lengthStr, err)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader.R, body); err != nil {
+		return nil, nil, fmt.Errorf("read body: %w", err)
+	}
+
+	return header, body, nil
+}
@@ -0,0 +1,77 @@
+package esl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Conference is a running mod_conference conference, as reported by
+// "conference json_list"
+type Conference struct {
+	Name      string              `json:"conference_name"`
+	RunTime   int                 `json:"run_time"`
+	Locked    bool                `json:"locked"`
+	Recording bool                `json:"recording"`
+	Members   []*ConferenceMember `json:"members"`
+}
+
+// ConferenceMember is a member of a running conference
+type ConferenceMember struct {
+	Type           string `json:"type"` // caller, recording_node
+	ID             int    `json:"id"`
+	UUID           string `json:"uuid"`
+	CallerIDName   string `json:"caller_id_name"`
+	CallerIDNumber string `json:"caller_id_number"`
+	JoinTime       int    `json:"join_time"`
+	Flags          struct {
+		CanSpeak    bool `json:"can_speak"`
+		Talking     bool `json:"talking"`
+		IsModerator bool `json:"is_moderator"`
+	} `json:"flags"`
+}
+
+// GetConference returns a running conference, or nil if no conference of
+// that name runs on this node
+func (c *Client) GetConference(ctx context.Context, name string) (*Conference, error) {
+	out, err := c.API(ctx, fmt.Sprintf("conference json_list %s", name))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var conferences []*Conference
+	if err := json.Unmarshal([]byte(out), &conferences); err != nil {
+		return nil, fmt.Errorf("parse conference list: %w", err)
+	}
+
+	for _, conf := range conferences {
+		if conf.Name == name {
+			return conf, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ConferenceMemberAction applies a member control (mute, unmute, kick) to a
+// member of a running conference
+func (c *Client) ConferenceMemberAction(ctx context.Context, name, action string, memberID int) error {
+	_, err := c.API(ctx, fmt.Sprintf("conference %s %s %d", name, action, memberID))
+	return err
+}
+
+// LockConference locks or unlocks a running conference; locked
+// conferences admit no new members
+func (c *Client) LockConference(ctx context.Context, name string, locked bool) error {
+	action := "unlock"
+	if locked {
+		action = "lock"
+	}
+
+	_, err := c.API(ctx, fmt.Sprintf("conference %s %s", name, action))
+	return err
+}
@@ -0,0 +1,74 @@
+package esl
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Gateway is a sofia gateway as reported by "sofia xmlstatus gateway"
+type Gateway struct {
+	Name    string `xml:"name"`
+	Profile string `xml:"profile"`
+	State   string `xml:"state"`  // REGED, NOREG, FAILED, ...
+	Status  string `xml:"status"` // UP, DOWN
+}
+
+// ListGateways returns the gateways of all sofia profiles on this node
+func (c *Client) ListGateways(ctx context.Context) ([]*Gateway, error) {
+	out, err := c.API(ctx, "sofia xmlstatus gateway")
+	if err != nil {
+		return nil, err
+	}
+
+	var status struct {
+		Gateways []*Gateway `xml:"gateway"`
+	}
+	if err := xml.Unmarshal([]byte(out), &status); err != nil {
+		return nil, fmt.Errorf("parse gateway status: %w", err)
+	}
+
+	return status.Gateways, nil
+}
+
+// ReloadGateways re-reads the gateways of the given profiles from
+// sofia.conf. Gateways are only added by a rescan, so the existing gateways
+// of the profiles are killed first; calls in progress are not affected.
+func (c *Client) ReloadGateways(ctx context.Context, profiles []string) error {
+	gateways, err := c.ListGateways(ctx)
+	if err != nil {
+		return err
+	}
+
+	reload := make(map[string]bool, len(profiles))
+	for _, profile := range profiles {
+		reload[profile] = true
+	}
+
+	for _, gw := range gateways {
+		if !reload[gw.Profile] {
+			continue
+		}
+		_, err := c.API(ctx, fmt.Sprintf("sofia profile %s killgw %s", gw.Profile, gw.Name))
+		if err != nil && !strings.Contains(err.Error(), "Invalid gateway") {
+			return err
+		}
+	}
+
+	// Profiles added to the database since FreeSWITCH started are not
+	// running yet and have to be started instead
+	for _, profile := range profiles {
+		out, err := c.API(ctx, fmt.Sprintf("sofia profile %s rescan", profile))
+		if err != nil {
+			return err
+		}
+		if strings.Contains(out, "Invalid Profile") {
+			if _, err := c.API(ctx, fmt.Sprintf("sofia profile %s start", profile)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
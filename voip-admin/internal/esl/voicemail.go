@@ -0,0 +1,37 @@
+package esl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// voicemailUUIDPattern matches mod_voicemail message UUIDs
+var voicemailUUIDPattern = regexp.MustCompile(`^[0-9a-fA-F-]{36}$`)
+
+// MarkVoicemailRead marks a message of the user@domain mailbox read or
+// unread. mod_voicemail updates its storage and sends the new MWI state.
+func (c *Client) MarkVoicemailRead(ctx context.Context, user, domain, uuid string, read bool) error {
+	if !voicemailUUIDPattern.MatchString(uuid) {
+		return fmt.Errorf("invalid voicemail message uuid: %q", uuid)
+	}
+
+	state := "unread"
+	if read {
+		state = "read"
+	}
+
+	_, err := c.API(ctx, fmt.Sprintf("vm_read %s@%s %s %s", user, domain, state, uuid))
+	return err
+}
+
+// DeleteVoicemail deletes a message of the user@domain mailbox, file
+// included, and sends the new MWI state
+func (c *Client) DeleteVoicemail(ctx context.Context, user, domain, uuid string) error {
+	if !voicemailUUIDPattern.MatchString(uuid) {
+		return fmt.Errorf("invalid voicemail message uuid: %q", uuid)
+	}
+
+	_, err := c.API(ctx, fmt.Sprintf("vm_delete %s@%s %s", user, domain, uuid))
+	return err
+}
@@ -0,0 +1,135 @@
+// Package kamailio sends management commands to Kamailio over JSON-RPC
+// (the jsonrpcs module, served over HTTP by xhttp)
+package kamailio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoNodes is returned when no Kamailio RPC endpoint is configured
+var ErrNoNodes = errors.New("no Kamailio RPC endpoints configured")
+
+// Client sends JSON-RPC commands to every Kamailio node. Each node keeps
+// its own in-memory state (dispatcher sets, address cache), so reloads
+// must reach all of them.
+type Client struct {
+	urls   []string
+	http   *http.Client
+	nextID atomic.Int64
+}
+
+// Config holds Kamailio JSON-RPC configuration
+type Config struct {
+	URLs    []string // e.g. http://172.16.91.101:5071/RPC
+	Timeout time.Duration
+}
+
+// NewClient creates a new JSON-RPC client
+func NewClient(cfg *Config) *Client {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	return &Client{
+		urls: cfg.URLs,
+		http: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Nodes returns the RPC endpoints of the configured nodes
+func (c *Client) Nodes() []string {
+	return c.urls
+}
+
+// rpcRequest is a JSON-RPC 2.0 request
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params,omitempty"`
+	ID      int64         `json:"id"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// RPCError is an error reply of a Kamailio RPC command, e.g. 404 when
+// ul.rm finds no such AOR
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// Call runs an RPC command on one node and returns its result
+func (c *Client) Call(ctx context.Context, url, method string, params ...interface{}) (json.RawMessage, error) {
+	body, err := json.Marshal(&rpcRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      c.nextID.Add(1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read %s response: %w", method, err)
+	}
+
+	var reply rpcResponse
+	if err := json.Unmarshal(data, &reply); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: HTTP %d", method, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("parse %s response: %w", method, err)
+	}
+	if reply.Error != nil {
+		return nil, fmt.Errorf("%s: %w", method, reply.Error)
+	}
+
+	return reply.Result, nil
+}
+
+// CallAll runs an RPC command on every node. All nodes are tried; the
+// error names each node that failed.
+func (c *Client) CallAll(ctx context.Context, method string, params ...interface{}) error {
+	if len(c.urls) == 0 {
+		return ErrNoNodes
+	}
+
+	var errs []error
+	for _, url := range c.urls {
+		if _, err := c.Call(ctx, url, method, params...); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,260 @@
+package kamailio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// stubCall is a JSON-RPC request received by a stub node
+type stubCall struct {
+	JSONRPC string            `json:"jsonrpc"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+	ID      int64             `json:"id"`
+}
+
+// stubNode is a Kamailio node answering JSON-RPC requests with reply
+type stubNode struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	calls []*stubCall
+}
+
+// newStubNode starts a stub node. reply returns the result of a call, or
+// an RPC error reply.
+func newStubNode(t *testing.T, reply func(call *stubCall) (interface{}, *RPCError)) *stubNode {
+	t.Helper()
+
+	node := &stubNode{}
+	node.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		var call stubCall
+		if err := json.NewDecoder(r.Body).Decode(&call); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		node.mu.Lock()
+		node.calls = append(node.calls, &call)
+		node.mu.Unlock()
+
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": call.ID}
+		result, rpcErr := reply(&call)
+		if rpcErr != nil {
+			response["error"] = rpcErr
+		} else {
+			response["result"] = result
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(node.Close)
+
+	return node
+}
+
+// okNode starts a stub node accepting every call
+func okNode(t *testing.T) *stubNode {
+	return newStubNode(t, func(*stubCall) (interface{}, *RPCError) {
+		return "ok", nil
+	})
+}
+
+// errorNode starts a stub node failing every call with an RPC error
+func errorNode(t *testing.T, code int, message string) *stubNode {
+	return newStubNode(t, func(*stubCall) (interface{}, *RPCError) {
+		return nil, &RPCError{Code: code, Message: message}
+	})
+}
+
+// Calls returns the requests received so far
+func (n *stubNode) Calls() []*stubCall {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]*stubCall(nil), n.calls...)
+}
+
+// params decodes the params of a call as generic JSON values
+func (c *stubCall) params(t *testing.T) []interface{} {
+	t.Helper()
+
+	params := make([]interface{}, len(c.Params))
+	for i, raw := range c.Params {
+		if err := json.Unmarshal(raw, &params[i]); err != nil {
+			t.Fatalf("decode param %d: %v", i, err)
+		}
+	}
+	return params
+}
+
+// newTestClient returns a client for nodes
+func newTestClient(nodes ...*stubNode) *Client {
+	urls := make([]string, len(nodes))
+	for i, node := range nodes {
+		urls[i] = node.URL
+	}
+	return NewClient(&Config{URLs: urls})
+}
+
+func TestCallReturnsResult(t *testing.T) {
+	node := newStubNode(t, func(call *stubCall) (interface{}, *RPCError) {
+		return map[string]int{"answer": 42}, nil
+	})
+	client := newTestClient(node)
+
+	result, err := client.Call(context.Background(), node.URL, "core.echo", "a", 1)
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	var got map[string]int
+	if err := json.Unmarshal(result, &got); err != nil || got["answer"] != 42 {
+		t.Errorf("result = %s, want {\"answer\":42}", result)
+	}
+
+	calls := node.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("node received %d calls, want 1", len(calls))
+	}
+	call := calls[0]
+	if call.JSONRPC != "2.0" || call.Method != "core.echo" {
+		t.Errorf("request = %+v, want jsonrpc 2.0 core.echo", call)
+	}
+	params := call.params(t)
+	if len(params) != 2 || params[0] != "a" || params[1] != float64(1) {
+		t.Errorf("params = %v, want [a 1]", params)
+	}
+}
+
+func TestCallUsesNewIDs(t *testing.T) {
+	node := okNode(t)
+	client := newTestClient(node)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Call(context.Background(), node.URL, "core.version"); err != nil {
+			t.Fatalf("Call: %v", err)
+		}
+	}
+
+	seen := make(map[int64]bool)
+	for _, call := range node.Calls() {
+		if seen[call.ID] {
+			t.Errorf("id %d used twice", call.ID)
+		}
+		seen[call.ID] = true
+	}
+}
+
+func TestCallRPCError(t *testing.T) {
+	node := errorNode(t, 500, "Reload failed")
+	client := newTestClient(node)
+
+	_, err := client.Call(context.Background(), node.URL, "dispatcher.reload")
+	if err == nil {
+		t.Fatal("Call succeeded, want an RPC error")
+	}
+
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("error %v is not an *RPCError", err)
+	}
+	if rpcErr.Code != 500 || rpcErr.Message != "Reload failed" {
+		t.Errorf("RPC error = %+v, want 500 Reload failed", rpcErr)
+	}
+	if !strings.Contains(err.Error(), "dispatcher.reload") {
+		t.Errorf("error %q does not name the method", err)
+	}
+}
+
+func TestCallHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{URLs: []string{server.URL}})
+
+	_, err := client.Call(context.Background(), server.URL, "core.version")
+	if err == nil || !strings.Contains(err.Error(), "HTTP 403") {
+		t.Errorf("error = %v, want HTTP 403", err)
+	}
+}
+
+func TestCallUnreachable(t *testing.T) {
+	node := okNode(t)
+	url := node.URL
+	node.Close()
+
+	client := NewClient(&Config{URLs: []string{url}})
+	if _, err := client.Call(context.Background(), url, "core.version"); err == nil {
+		t.Error("Call to a closed node succeeded")
+	}
+}
+
+func TestCallAllNoNodes(t *testing.T) {
+	client := NewClient(&Config{})
+
+	if err := client.CallAll(context.Background(), "dispatcher.reload"); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("error = %v, want ErrNoNodes", err)
+	}
+}
+
+func TestCallAllReachesEveryNode(t *testing.T) {
+	first, second := okNode(t), okNode(t)
+	client := newTestClient(first, second)
+
+	if err := client.CallAll(context.Background(), "permissions.addressReload"); err != nil {
+		t.Fatalf("CallAll: %v", err)
+	}
+
+	for i, node := range []*stubNode{first, second} {
+		calls := node.Calls()
+		if len(calls) != 1 || calls[0].Method != "permissions.addressReload" {
+			t.Errorf("node %d calls = %v, want one permissions.addressReload", i, calls)
+		}
+	}
+}
+
+func TestCallAllNamesFailedNodes(t *testing.T) {
+	failing := errorNode(t, 500, "Reload failed")
+	healthy := okNode(t)
+	down := okNode(t)
+	downURL := down.URL
+	down.Close()
+
+	client := NewClient(&Config{URLs: []string{failing.URL, downURL, healthy.URL}})
+
+	err := client.CallAll(context.Background(), "dispatcher.reload")
+	if err == nil {
+		t.Fatal("CallAll succeeded, want per-node errors")
+	}
+
+	// A failing node does not stop the others
+	if n := len(healthy.Calls()); n != 1 {
+		t.Errorf("healthy node received %d calls, want 1", n)
+	}
+
+	msg := err.Error()
+	if !strings.Contains(msg, failing.URL) || !strings.Contains(msg, downURL) {
+		t.Errorf("error %q does not name both failed nodes", msg)
+	}
+	if strings.Contains(msg, healthy.URL) {
+		t.Errorf("error %q names the healthy node", msg)
+	}
+
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != 500 {
+		t.Errorf("error %v does not wrap the RPC error", err)
+	}
+}
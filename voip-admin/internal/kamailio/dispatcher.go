@@ -0,0 +1,73 @@
+package kamailio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Dispatcher destination states for SetDispatcherState
+const (
+	DispatcherActive   = "a"
+	DispatcherInactive = "i"
+	DispatcherDisabled = "d" // Not selected and not probed
+)
+
+// DispatcherTarget is a destination as reported by "dispatcher.list"
+type DispatcherTarget struct {
+	SetID    int    `json:"set_id"`
+	URI      string `json:"uri"`
+	Flags    string `json:"flags"` // AP = active and probing, DX = disabled, IP = inactive, ...
+	Priority int    `json:"priority"`
+}
+
+// ReloadDispatcher re-reads the dispatcher table on every node. States
+// set at runtime are replaced by the flags stored in the table.
+func (c *Client) ReloadDispatcher(ctx context.Context) error {
+	return c.CallAll(ctx, "dispatcher.reload")
+}
+
+// SetDispatcherState changes the state of a destination on every node
+func (c *Client) SetDispatcherState(ctx context.Context, state string, setID int, uri string) error {
+	return c.CallAll(ctx, "dispatcher.set_state", state, setID, uri)
+}
+
+// ListDispatcher returns the destinations loaded on one node
+func (c *Client) ListDispatcher(ctx context.Context, url string) ([]*DispatcherTarget, error) {
+	result, err := c.Call(ctx, url, "dispatcher.list")
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Records []struct {
+			Set struct {
+				ID      int `json:"ID"`
+				Targets []struct {
+					Dest struct {
+						URI      string `json:"URI"`
+						Flags    string `json:"FLAGS"`
+						Priority int    `json:"PRIORITY"`
+					} `json:"DEST"`
+				} `json:"TARGETS"`
+			} `json:"SET"`
+		} `json:"RECORDS"`
+	}
+	if err := json.Unmarshal(result, &list); err != nil {
+		return nil, fmt.Errorf("parse dispatcher list: %w", err)
+	}
+
+	targets := []*DispatcherTarget{}
+	for _, record := range list.Records {
+		for _, t := range record.Set.Targets {
+			targets = append(targets, &DispatcherTarget{
+				SetID:    record.Set.ID,
+				URI:      t.Dest.URI,
+				Flags:    t.Dest.Flags,
+				Priority: t.Dest.Priority,
+			})
+		}
+	}
+
+	return targets, nil
+}
@@ -0,0 +1,139 @@
+package kamailio
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// dispatcherListReply is a "dispatcher.list" result as returned by Kamailio
+const dispatcherListReply = `{
+	"NRSETS": 2,
+	"RECORDS": [
+		{"SET": {"ID": 1, "TARGETS": [
+			{"DEST": {"URI": "sip:172.16.91.111:5080", "FLAGS": "AP", "PRIORITY": 10,
+				"ATTRS": {"BODY": "weight=50", "DUID": "", "MAXLOAD": 0, "WEIGHT": 50}}},
+			{"DEST": {"URI": "sip:172.16.91.112:5080", "FLAGS": "IP", "PRIORITY": 5}}
+		]}},
+		{"SET": {"ID": 2, "TARGETS": [
+			{"DEST": {"URI": "sip:172.16.91.113:5080", "FLAGS": "DX", "PRIORITY": 0}}
+		]}}
+	]
+}`
+
+func TestReloadDispatcher(t *testing.T) {
+	first, second := okNode(t), okNode(t)
+	client := newTestClient(first, second)
+
+	if err := client.ReloadDispatcher(context.Background()); err != nil {
+		t.Fatalf("ReloadDispatcher: %v", err)
+	}
+
+	for i, node := range []*stubNode{first, second} {
+		calls := node.Calls()
+		if len(calls) != 1 || calls[0].Method != "dispatcher.reload" || len(calls[0].Params) != 0 {
+			t.Errorf("node %d calls = %+v, want one dispatcher.reload without params", i, calls)
+		}
+	}
+}
+
+func TestReloadDispatcherNodeError(t *testing.T) {
+	client := newTestClient(okNode(t), errorNode(t, 500, "Dispatcher reload failed"))
+
+	if err := client.ReloadDispatcher(context.Background()); err == nil {
+		t.Error("ReloadDispatcher succeeded although a node failed")
+	}
+}
+
+func TestSetDispatcherState(t *testing.T) {
+	first, second := okNode(t), okNode(t)
+	client := newTestClient(first, second)
+
+	err := client.SetDispatcherState(context.Background(), DispatcherInactive, 1, "sip:172.16.91.111:5080")
+	if err != nil {
+		t.Fatalf("SetDispatcherState: %v", err)
+	}
+
+	want := []interface{}{"i", float64(1), "sip:172.16.91.111:5080"}
+	for i, node := range []*stubNode{first, second} {
+		calls := node.Calls()
+		if len(calls) != 1 || calls[0].Method != "dispatcher.set_state" {
+			t.Fatalf("node %d calls = %+v, want one dispatcher.set_state", i, calls)
+		}
+		if got := calls[0].params(t); !reflect.DeepEqual(got, want) {
+			t.Errorf("node %d params = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestSetDispatcherStateUnknownDestination(t *testing.T) {
+	client := newTestClient(errorNode(t, 404, "destination not found"))
+
+	err := client.SetDispatcherState(context.Background(), DispatcherActive, 9, "sip:10.0.0.1")
+	if err == nil {
+		t.Error("SetDispatcherState succeeded for an unknown destination")
+	}
+}
+
+func TestListDispatcher(t *testing.T) {
+	node := newStubNode(t, func(call *stubCall) (interface{}, *RPCError) {
+		if call.Method != "dispatcher.list" {
+			return nil, &RPCError{Code: 500, Message: "unexpected method " + call.Method}
+		}
+		return json.RawMessage(dispatcherListReply), nil
+	})
+	client := newTestClient(node)
+
+	targets, err := client.ListDispatcher(context.Background(), node.URL)
+	if err != nil {
+		t.Fatalf("ListDispatcher: %v", err)
+	}
+
+	want := []*DispatcherTarget{
+		{SetID: 1, URI: "sip:172.16.91.111:5080", Flags: "AP", Priority: 10},
+		{SetID: 1, URI: "sip:172.16.91.112:5080", Flags: "IP", Priority: 5},
+		{SetID: 2, URI: "sip:172.16.91.113:5080", Flags: "DX", Priority: 0},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		for _, target := range targets {
+			t.Logf("got %+v", target)
+		}
+		t.Errorf("ListDispatcher returned %d targets, want %d as above", len(targets), len(want))
+	}
+}
+
+func TestListDispatcherEmpty(t *testing.T) {
+	node := newStubNode(t, func(*stubCall) (interface{}, *RPCError) {
+		return json.RawMessage(`{"NRSETS": 0, "RECORDS": []}`), nil
+	})
+	client := newTestClient(node)
+
+	targets, err := client.ListDispatcher(context.Background(), node.URL)
+	if err != nil {
+		t.Fatalf("ListDispatcher: %v", err)
+	}
+	if targets == nil || len(targets) != 0 {
+		t.Errorf("targets = %v, want an empty list", targets)
+	}
+}
+
+func TestListDispatcherMalformed(t *testing.T) {
+	node := newStubNode(t, func(*stubCall) (interface{}, *RPCError) {
+		return json.RawMessage(`{"RECORDS": "none"}`), nil
+	})
+	client := newTestClient(node)
+
+	if _, err := client.ListDispatcher(context.Background(), node.URL); err == nil {
+		t.Error("ListDispatcher accepted a malformed reply")
+	}
+}
+
+func TestListDispatcherRPCError(t *testing.T) {
+	node := errorNode(t, 500, "dispatcher not loaded")
+	client := newTestClient(node)
+
+	if _, err := client.ListDispatcher(context.Background(), node.URL); err == nil {
+		t.Error("ListDispatcher succeeded on an RPC error")
+	}
+}
@@ -0,0 +1,9 @@
+package kamailio
+
+import "context"
+
+// ReloadAddresses re-reads the address table (kamailio.address) into the
+// permissions cache on every node
+func (c *Client) ReloadAddresses(ctx context.Context) error {
+	return c.CallAll(ctx, "permissions.addressReload")
+}
@@ -0,0 +1,56 @@
+package kamailio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrNotRegistered is returned when no node has the AOR or contact
+var ErrNotRegistered = errors.New("not registered")
+
+// locationTable is the usrloc domain of registered contacts
+const locationTable = "location"
+
+// RemoveAOR removes all contacts of an AOR (user@domain) from every node,
+// forcing the devices to register again
+func (c *Client) RemoveAOR(ctx context.Context, aor string) error {
+	return c.callAllFound(ctx, "ul.rm", locationTable, aor)
+}
+
+// RemoveContact removes one contact of an AOR from every node
+func (c *Client) RemoveContact(ctx context.Context, aor, contact string) error {
+	return c.callAllFound(ctx, "ul.rm_contact", locationTable, aor, contact)
+}
+
+// callAllFound runs a usrloc command on every node. Nodes that do not know
+// the AOR or contact are skipped; ErrNotRegistered is returned if none does.
+func (c *Client) callAllFound(ctx context.Context, method string, params ...interface{}) error {
+	if len(c.urls) == 0 {
+		return ErrNoNodes
+	}
+
+	found := false
+	var errs []error
+	for _, url := range c.urls {
+		_, err := c.Call(ctx, url, method, params...)
+		var rpcErr *RPCError
+		switch {
+		case err == nil:
+			found = true
+		case errors.As(err, &rpcErr) && rpcErr.Code == http.StatusNotFound:
+			// Not registered through this node
+		default:
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if !found {
+		return ErrNotRegistered
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package kamailio
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// notFoundNode starts a stub node that does not know any AOR or contact
+func notFoundNode(t *testing.T) *stubNode {
+	return errorNode(t, 404, "AOR not found")
+}
+
+func TestRemoveAOR(t *testing.T) {
+	first, second := okNode(t), okNode(t)
+	client := newTestClient(first, second)
+
+	if err := client.RemoveAOR(context.Background(), "1001@pbx.example.com"); err != nil {
+		t.Fatalf("RemoveAOR: %v", err)
+	}
+
+	want := []interface{}{"location", "1001@pbx.example.com"}
+	for i, node := range []*stubNode{first, second} {
+		calls := node.Calls()
+		if len(calls) != 1 || calls[0].Method != "ul.rm" {
+			t.Fatalf("node %d calls = %+v, want one ul.rm", i, calls)
+		}
+		if got := calls[0].params(t); !reflect.DeepEqual(got, want) {
+			t.Errorf("node %d params = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestRemoveContact(t *testing.T) {
+	node := okNode(t)
+	client := newTestClient(node)
+
+	contact := "sip:1001@192.168.1.20:5060;transport=udp"
+	if err := client.RemoveContact(context.Background(), "1001@pbx.example.com", contact); err != nil {
+		t.Fatalf("RemoveContact: %v", err)
+	}
+
+	calls := node.Calls()
+	if len(calls) != 1 || calls[0].Method != "ul.rm_contact" {
+		t.Fatalf("calls = %+v, want one ul.rm_contact", calls)
+	}
+	want := []interface{}{"location", "1001@pbx.example.com", contact}
+	if got := calls[0].params(t); !reflect.DeepEqual(got, want) {
+		t.Errorf("params = %v, want %v", got, want)
+	}
+}
+
+func TestRemoveAORRegisteredOnOneNode(t *testing.T) {
+	// Phones register through one node; the others answer 404
+	missing, registered := notFoundNode(t), okNode(t)
+	client := newTestClient(missing, registered)
+
+	if err := client.RemoveAOR(context.Background(), "1001@pbx.example.com"); err != nil {
+		t.Errorf("RemoveAOR: %v, want nil when one node has the AOR", err)
+	}
+	if n := len(registered.Calls()); n != 1 {
+		t.Errorf("registered node received %d calls, want 1", n)
+	}
+}
+
+func TestRemoveAORNotRegistered(t *testing.T) {
+	client := newTestClient(notFoundNode(t), notFoundNode(t))
+
+	err := client.RemoveAOR(context.Background(), "1001@pbx.example.com")
+	if !errors.Is(err, ErrNotRegistered) {
+		t.Errorf("error = %v, want ErrNotRegistered", err)
+	}
+}
+
+func TestRemoveAORNodeError(t *testing.T) {
+	// A failing node may hold the AOR, so it is not reported as missing
+	failing := errorNode(t, 500, "internal error")
+	client := newTestClient(notFoundNode(t), failing)
+
+	err := client.RemoveAOR(context.Background(), "1001@pbx.example.com")
+	if err == nil {
+		t.Fatal("RemoveAOR succeeded although a node failed")
+	}
+	if errors.Is(err, ErrNotRegistered) {
+		t.Errorf("error = %v, want the node error rather than ErrNotRegistered", err)
+	}
+
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != 500 {
+		t.Errorf("error %v does not wrap the 500 reply", err)
+	}
+}
+
+func TestRemoveAORNoNodes(t *testing.T) {
+	client := NewClient(&Config{})
+
+	if err := client.RemoveAOR(context.Background(), "1001@pbx.example.com"); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("error = %v, want ErrNoNodes", err)
+	}
+}
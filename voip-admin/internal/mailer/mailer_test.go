@@ -0,0 +1,198 @@
+package mailer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/mailer"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/mailer/mailertest"
+)
+
+// newMailer returns a mailer sending to server
+func newMailer(server *mailertest.Server) *mailer.Mailer {
+	return mailer.New(&mailer.Config{
+		Host: server.Host(),
+		Port: server.Port(),
+		From: "PBX <pbx@example.com>",
+	})
+}
+
+// receive parses the only message accepted by server
+func receive(t *testing.T, server *mailertest.Server) (*mailertest.Delivery, *mail.Message) {
+	t.Helper()
+
+	received := server.Received()
+	if len(received) != 1 {
+		t.Fatalf("received %d messages, want 1", len(received))
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(received[0].Data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+
+	return received[0], msg
+}
+
+// decodeBase64 decodes a base64 part body split in lines
+func decodeBase64(t *testing.T, r io.Reader) []byte {
+	t.Helper()
+
+	data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, r))
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+	return data
+}
+
+func TestSendPlainText(t *testing.T) {
+	server := mailertest.NewServer(t)
+
+	err := newMailer(server).Send(context.Background(), &mailer.Message{
+		To:      "Alice <alice@example.com>",
+		Subject: "Hello",
+		Body:    "Line one\nLine two\n",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	delivery, msg := receive(t, server)
+	if delivery.From != "pbx@example.com" {
+		t.Errorf("MAIL FROM = %q, want pbx@example.com", delivery.From)
+	}
+	if len(delivery.To) != 1 || delivery.To[0] != "alice@example.com" {
+		t.Errorf("RCPT TO = %q, want [alice@example.com]", delivery.To)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "text/plain" || params["charset"] != "utf-8" {
+		t.Errorf("Content-Type = %q, want text/plain; charset=utf-8", msg.Header.Get("Content-Type"))
+	}
+	if got := msg.Header.Get("MIME-Version"); got != "1.0" {
+		t.Errorf("MIME-Version = %q, want 1.0", got)
+	}
+	if got := msg.Header.Get("Content-Transfer-Encoding"); got != "base64" {
+		t.Errorf("Content-Transfer-Encoding = %q, want base64", got)
+	}
+	if got := string(decodeBase64(t, msg.Body)); got != "Line one\nLine two\n" {
+		t.Errorf("body = %q, want %q", got, "Line one\nLine two\n")
+	}
+}
+
+func TestSendEncodesSubject(t *testing.T) {
+	server := mailertest.NewServer(t)
+
+	subject := "Tin nhắn thoại từ 0901234567"
+	err := newMailer(server).Send(context.Background(), &mailer.Message{
+		To:      "alice@example.com",
+		Subject: subject,
+		Body:    "Body",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	_, msg := receive(t, server)
+
+	raw := msg.Header["Subject"]
+	if len(raw) != 1 || !strings.HasPrefix(raw[0], "=?utf-8?q?") {
+		t.Fatalf("Subject header = %q, want a Q-encoded word", raw)
+	}
+
+	decoded, err := new(mime.WordDecoder).DecodeHeader(raw[0])
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+	if decoded != subject {
+		t.Errorf("Subject = %q, want %q", decoded, subject)
+	}
+}
+
+func TestSendWithAttachment(t *testing.T) {
+	server := mailertest.NewServer(t)
+
+	// Longer than one base64 line, and not valid UTF-8
+	audio := bytes.Repeat([]byte{0x52, 0x49, 0x46, 0x46, 0x00, 0xff, 0x80}, 40)
+
+	err := newMailer(server).Send(context.Background(), &mailer.Message{
+		To:      "alice@example.com",
+		Subject: "Voicemail",
+		Body:    "The message is attached.",
+		Attachments: []mailer.Attachment{
+			{Name: `voice"mail.wav`, ContentType: "audio/wav", Data: audio},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	delivery, msg := receive(t, server)
+	for _, line := range strings.Split(string(delivery.Data), "\n") {
+		if len(strings.TrimRight(line, "\r")) > 998 {
+			t.Fatalf("line longer than 998 characters")
+		}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" || params["boundary"] == "" {
+		t.Fatalf("Content-Type = %q, want multipart/mixed with a boundary", msg.Header.Get("Content-Type"))
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+
+	text, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("read text part: %v", err)
+	}
+	if got := text.Header.Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("first part Content-Type = %q, want text/plain", got)
+	}
+	if got := string(decodeBase64(t, text)); got != "The message is attached." {
+		t.Errorf("text = %q, want %q", got, "The message is attached.")
+	}
+
+	attachment, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("read attachment part: %v", err)
+	}
+	if got := attachment.FileName(); got != "voicemail.wav" {
+		t.Errorf("attachment filename = %q, want voicemail.wav", got)
+	}
+	if got, _, _ := mime.ParseMediaType(attachment.Header.Get("Content-Type")); got != "audio/wav" {
+		t.Errorf("attachment Content-Type = %q, want audio/wav", got)
+	}
+	if got := attachment.Header.Get("Content-Transfer-Encoding"); got != "base64" {
+		t.Errorf("attachment Content-Transfer-Encoding = %q, want base64", got)
+	}
+	if got := decodeBase64(t, attachment); !bytes.Equal(got, audio) {
+		t.Errorf("attachment data differs: got %d bytes, want %d", len(got), len(audio))
+	}
+
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("expected 2 parts, next part error = %v", err)
+	}
+}
+
+func TestSendInvalidRecipient(t *testing.T) {
+	server := mailertest.NewServer(t)
+
+	err := newMailer(server).Send(context.Background(), &mailer.Message{
+		To:      "not an address",
+		Subject: "Hello",
+		Body:    "Body",
+	})
+	if err == nil {
+		t.Fatal("Send succeeded, want an invalid recipient error")
+	}
+	if n := len(server.Received()); n != 0 {
+		t.Errorf("received %d messages, want 0", n)
+	}
+}
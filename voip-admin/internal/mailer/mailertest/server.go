@@ -0,0 +1,157 @@
+// Package mailertest provides a local SMTP server for tests
+package mailertest
+
+import (
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Delivery is a message accepted by the server
+type Delivery struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Server accepts mail on a local port without authentication or TLS
+type Server struct {
+	Addr string
+
+	listener net.Listener
+	mu       sync.Mutex
+	received []*Delivery
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server on a random local port, closed when the test
+// ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	s := &Server{
+		Addr:     listener.Addr().String(),
+		listener: listener,
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Host returns the host of the server address
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.Addr)
+	return host
+}
+
+// Port returns the port of the server address
+func (s *Server) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Received returns the messages accepted so far
+func (s *Server) Received() []*Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Delivery(nil), s.received...)
+}
+
+// Close stops the server and waits for open sessions to end
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// serve accepts connections until the listener is closed
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.session(conn)
+		}()
+	}
+}
+
+// session runs one SMTP session
+func (s *Server) session(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 mailertest ESMTP")
+
+	delivery := &Delivery{}
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 mailertest")
+
+		case "MAIL":
+			delivery = &Delivery{From: address(arg)}
+			tp.PrintfLine("250 OK")
+
+		case "RCPT":
+			delivery.To = append(delivery.To, address(arg))
+			tp.PrintfLine("250 OK")
+
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			delivery.Data = data
+
+			s.mu.Lock()
+			s.received = append(s.received, delivery)
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK")
+
+		case "RSET", "NOOP":
+			tp.PrintfLine("250 OK")
+
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// address extracts the address of a MAIL FROM or RCPT TO argument
+func address(arg string) string {
+	start := strings.Index(arg, "<")
+	end := strings.LastIndex(arg, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return arg[start+1 : end]
+}
@@ -1,9 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"crypto/subtle"
+	"log"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
 )
 
 // AuthConfig holds authentication configuration
@@ -12,10 +17,33 @@ type AuthConfig struct {
 	FreeSwitchUser     string
 	FreeSwitchPassword string
 
-	// API Key for admin API
+	// Static API keys for admin API. They have full access to all domains and
+	// are meant for bootstrapping; issue scoped keys through /api/v1/api-keys.
 	APIKeys []string
+
+	// KeyStore validates database-managed API keys (optional)
+	KeyStore KeyStore
+
+	// TokenSecret signs user access tokens; Sessions validates the session
+	// behind each token. User login is disabled unless both are set.
+	TokenSecret []byte
+	Sessions    SessionStore
+}
+
+// KeyStore looks up database-managed API keys
+type KeyStore interface {
+	GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int64) error
 }
 
+// SessionStore looks up the user behind a login session
+type SessionStore interface {
+	GetSessionUser(ctx context.Context, sessionID int64) (*models.User, error)
+}
+
+// selfServiceResource is open to every authenticated caller (logout, whoami)
+const selfServiceResource = "auth"
+
 // BasicAuth middleware for FreeSWITCH XML_CURL endpoints
 func BasicAuth(config *AuthConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -43,7 +71,8 @@ func BasicAuth(config *AuthConfig) func(http.Handler) http.Handler {
 	}
 }
 
-// APIKeyAuth middleware for admin REST API
+// APIKeyAuth middleware for admin REST API. It accepts API keys as well as
+// user access tokens issued by /api/v1/auth/login.
 func APIKeyAuth(config *AuthConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -63,25 +92,118 @@ func APIKeyAuth(config *AuthConfig) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Validate API key (constant-time comparison)
-			valid := false
-			for _, validKey := range config.APIKeys {
-				if subtle.ConstantTimeCompare([]byte(apiKey), []byte(validKey)) == 1 {
-					valid = true
-					break
+			var principal *Principal
+			if isToken(apiKey) {
+				principal = config.authenticateToken(r, apiKey)
+				if principal == nil {
+					http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+					return
+				}
+			} else {
+				principal = config.authenticateKey(r, apiKey)
+				if principal == nil {
+					http.Error(w, "Invalid API key", http.StatusUnauthorized)
+					return
 				}
 			}
 
-			if !valid {
-				http.Error(w, "Invalid API key", http.StatusUnauthorized)
+			resource, access := requestPermission(r)
+			if resource != selfServiceResource && !principal.Permissions.Allows(resource, access) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
 		})
 	}
 }
 
+// authenticateKey resolves an API key to a principal, or nil if the key is
+// unknown, revoked or expired
+func (config *AuthConfig) authenticateKey(r *http.Request, apiKey string) *Principal {
+	// Static keys from the config file (constant-time comparison)
+	for _, validKey := range config.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(apiKey), []byte(validKey)) == 1 {
+			return &Principal{
+				Type:        PrincipalStaticKey,
+				Name:        "config",
+				Permissions: models.Permissions{"*": models.AccessWrite},
+			}
+		}
+	}
+
+	if config.KeyStore == nil {
+		return nil
+	}
+
+	// Keys are looked up by hash, so the comparison does not leak timing
+	key, err := config.KeyStore.GetAPIKeyByHash(r.Context(), HashAPIKey(apiKey))
+	if err != nil {
+		return nil
+	}
+
+	if !key.Usable(time.Now()) {
+		return nil
+	}
+
+	// Usage tracking is best effort; it fails on a read-only standby
+	if err := config.KeyStore.TouchAPIKey(r.Context(), key.ID); err != nil {
+		log.Printf("[Auth] Failed to record use of API key %d: %v", key.ID, err)
+	}
+
+	return &Principal{
+		Type:        PrincipalAPIKey,
+		KeyID:       key.ID,
+		Name:        key.Name,
+		DomainID:    key.DomainID,
+		Permissions: key.Permissions,
+	}
+}
+
+// authenticateToken resolves a user access token to a principal, or nil if
+// the token is invalid or its session has ended
+func (config *AuthConfig) authenticateToken(r *http.Request, token string) *Principal {
+	if config.Sessions == nil || len(config.TokenSecret) == 0 {
+		return nil
+	}
+
+	claims, err := ParseToken(config.TokenSecret, token)
+	if err != nil {
+		return nil
+	}
+
+	// Role, domain and active flag come from the database, so changes apply
+	// without waiting for the token to expire
+	user, err := config.Sessions.GetSessionUser(r.Context(), claims.SessionID)
+	if err != nil || user.ID != claims.UserID {
+		return nil
+	}
+
+	return &Principal{
+		Type:        PrincipalUser,
+		UserID:      user.ID,
+		SessionID:   claims.SessionID,
+		Name:        user.Username,
+		Role:        user.Role,
+		DomainID:    user.DomainID,
+		Permissions: models.RolePermissions(user.Role),
+	}
+}
+
+// requestPermission maps a request to the resource and access level it needs.
+// The resource is the first path segment after /api/v1/.
+func requestPermission(r *http.Request) (string, string) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	resource, _, _ := strings.Cut(path, "/")
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return resource, models.AccessRead
+	default:
+		return resource, models.AccessWrite
+	}
+}
+
 // AllowPublic middleware that allows public access (for health checks)
 func AllowPublic(next http.Handler) http.Handler {
 	return next
@@ -47,12 +47,13 @@ func Logging(next http.Handler) http.Handler {
 
 		// Log request
 		duration := time.Since(start)
-		log.Printf("[HTTP] %s %s - %d (%s) %d bytes",
+		log.Printf("[HTTP] %s %s - %d (%s) %d bytes [%s]",
 			r.Method,
 			r.RequestURI,
 			wrapped.statusCode,
 			duration,
 			wrapped.written,
+			wrapped.Header().Get(RequestIDHeader),
 		)
 	})
 }
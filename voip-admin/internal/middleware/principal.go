@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// Principal types
+const (
+	PrincipalStaticKey = "static_key" // Key from config.yaml
+	PrincipalAPIKey    = "api_key"    // Key from voip.api_keys
+	PrincipalUser      = "user"       // Logged-in user from voip.users
+)
+
+// apiKeyPrefix marks keys issued by voip-admin so they are easy to spot in
+// logs and secret scanners
+const apiKeyPrefix = "vak_"
+
+// apiKeyDisplayLength is how much of a key is stored in clear for listings
+const apiKeyDisplayLength = 12
+
+// Principal is the authenticated caller of an admin API request
+type Principal struct {
+	Type        string
+	KeyID       int64 // Set for database API keys
+	UserID      int64 // Set for users
+	SessionID   int64 // Set for users
+	Name        string
+	Role        string // Set for users: agent, supervisor, admin
+	DomainID    *int64 // nil = all domains
+	Permissions models.Permissions
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated principal, or nil
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// HashAPIKey returns the hex SHA-256 of an API key as stored in voip.api_keys
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey creates a new random API key and returns it together with
+// its display prefix and hash
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("generate api key: %w", err)
+	}
+
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:apiKeyDisplayLength], HashAPIKey(key), nil
+}
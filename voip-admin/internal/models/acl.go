@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// ACL actions of lists and entries
+const (
+	ACLAllow = "allow"
+	ACLDeny  = "deny"
+)
+
+// ACLList is a network list rendered into FreeSWITCH's acl.conf. With a
+// Kamailio group, its allowed networks are also trusted by Kamailio.
+type ACLList struct {
+	ID            int64       `json:"id" db:"id"`
+	Name          string      `json:"name" db:"name"`
+	DefaultAction string      `json:"default_action" db:"default_action"`
+	KamailioGroup *int        `json:"kamailio_group,omitempty" db:"kamailio_group"` // Nil: FreeSWITCH only
+	Description   string      `json:"description,omitempty" db:"description"`
+	Entries       []*ACLEntry `json:"entries"`
+	CreatedAt     time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// ACLEntry is a network of an ACL list
+type ACLEntry struct {
+	ID          int64  `json:"id,omitempty" db:"id"`
+	CIDR        string `json:"cidr" db:"cidr"` // A bare address is a single host
+	Action      string `json:"action" db:"action"`
+	Port        int    `json:"port,omitempty" db:"port"` // Kamailio only; 0 = any
+	Description string `json:"description,omitempty" db:"description"`
+}
+
+// ACLListCreateRequest represents a request to create an ACL list
+type ACLListCreateRequest struct {
+	Name          string      `json:"name" validate:"required,max=50"`
+	DefaultAction string      `json:"default_action,omitempty"` // Default: deny
+	KamailioGroup *int        `json:"kamailio_group,omitempty" validate:"omitempty,gt=0"`
+	Description   string      `json:"description,omitempty" validate:"omitempty,max=255"`
+	Entries       []*ACLEntry `json:"entries"`
+}
+
+// ACLListUpdateRequest represents a request to update an ACL list. The
+// name cannot change, since SIP profiles refer to it. Entries, if given,
+// replace all entries of the list; a Kamailio group of 0 removes it.
+type ACLListUpdateRequest struct {
+	DefaultAction *string     `json:"default_action,omitempty"`
+	KamailioGroup *int        `json:"kamailio_group,omitempty" validate:"omitempty,gte=0"`
+	Description   *string     `json:"description,omitempty" validate:"omitempty,max=255"`
+	Entries       []*ACLEntry `json:"entries,omitempty"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Agent availability states (stored in voip.queue_agents.state)
+const (
+	AgentStateAvailable = "Available"
+	AgentStateOnBreak   = "On Break"
+	AgentStateLoggedOut = "Logged Out"
+)
+
+// AgentState represents the current state of an agent across all its queues
+type AgentState struct {
+	ExtensionID      int64         `json:"extension_id"`
+	Extension        string        `json:"extension"`
+	Domain           string        `json:"domain"`
+	Agent            string        `json:"agent"` // mod_callcenter agent name (extension@domain)
+	State            string        `json:"state"`
+	Status           string        `json:"status"`
+	Queues           []*QueueAgent `json:"queues"`
+	FreeSwitchSynced bool          `json:"freeswitch_synced"`
+}
+
+// AgentStateRequest represents a login/logout/break request
+type AgentStateRequest struct {
+	Reason string `json:"reason,omitempty" validate:"omitempty,max=255"`
+}
+
+// AgentStateChange represents a row in the agent state history
+type AgentStateChange struct {
+	ID            int64     `json:"id" db:"id"`
+	ExtensionID   int64     `json:"extension_id" db:"extension_id"`
+	PreviousState *string   `json:"previous_state,omitempty" db:"previous_state"`
+	State         string    `json:"state" db:"state"`
+	Reason        *string   `json:"reason,omitempty" db:"reason"`
+	Source        string    `json:"source" db:"source"` // who made the change, e.g. "api"
+	ChangedAt     time.Time `json:"changed_at" db:"changed_at"`
+}
+
+// AgentStateSummary represents time spent in one state over a period
+type AgentStateSummary struct {
+	State        string `json:"state"`
+	Occurrences  int64  `json:"occurrences"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// AgentSyncState is the minimal agent view used for FreeSWITCH reconciliation
+type AgentSyncState struct {
+	ExtensionID int64
+	Extension   string
+	Domain      string
+	State       string
+	Status      string
+}
+
+// AgentName returns the mod_callcenter agent name for an extension
+func AgentName(extension, domain string) string {
+	return extension + "@" + domain
+}
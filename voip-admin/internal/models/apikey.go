@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+// Access levels for API permissions. Write implies read.
+const (
+	AccessRead  = "read"
+	AccessWrite = "write"
+)
+
+// APIResources lists the resources that can appear in a permission set.
+// A resource is the first path segment after /api/v1/; "*" matches all.
+var APIResources = []string{
+	"*",
+	"acls",
+	"agents",
+	"api-keys",
+	"audit",
+	"cdr",
+	"conferences",
+	"dids",
+	"dispatchers",
+	"domains",
+	"extensions",
+	"fraud",
+	"invoices",
+	"queues",
+	"rate-decks",
+	"recordings",
+	"time-conditions",
+	"trunks",
+	"users",
+	"voicemail",
+}
+
+// Permissions maps an API resource to its access level,
+// e.g. {"cdr": "read", "extensions": "write"}
+type Permissions map[string]string
+
+// Allows reports whether the permissions grant access to a resource
+func (p Permissions) Allows(resource, access string) bool {
+	granted, ok := p[resource]
+	if !ok {
+		granted, ok = p["*"]
+	}
+	if !ok {
+		return false
+	}
+
+	if granted == AccessWrite {
+		return true
+	}
+	return granted == AccessRead && access == AccessRead
+}
+
+// Covers reports whether p grants at least everything other grants
+func (p Permissions) Covers(other Permissions) bool {
+	for resource, access := range other {
+		if resource == "*" {
+			if p["*"] != AccessWrite && !(p["*"] == AccessRead && access == AccessRead) {
+				return false
+			}
+			continue
+		}
+		if !p.Allows(resource, access) {
+			return false
+		}
+	}
+	return true
+}
+
+// APIKey represents a database-managed API key. Only a SHA-256 hash of the
+// key is stored; the plaintext is returned once when the key is issued.
+type APIKey struct {
+	ID          int64       `json:"id" db:"id"`
+	Name        string      `json:"name" db:"key_name"`
+	Prefix      string      `json:"prefix" db:"key_prefix"` // First characters of the key, for identification
+	KeyHash     string      `json:"-" db:"key_hash"`
+	DomainID    *int64      `json:"domain_id,omitempty" db:"domain_id"` // nil = all domains
+	Permissions Permissions `json:"permissions" db:"permissions"`
+	Active      bool        `json:"active" db:"active"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	ExpiresAt   *time.Time  `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt  *time.Time  `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt   *time.Time  `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// Usable reports whether the key is active and not expired
+func (k *APIKey) Usable(now time.Time) bool {
+	if !k.Active || k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// APIKeyCreateRequest represents a request to issue a new API key
+type APIKeyCreateRequest struct {
+	Name        string      `json:"name" validate:"required,min=1,max=100"`
+	DomainID    *int64      `json:"domain_id,omitempty"`
+	Permissions Permissions `json:"permissions" validate:"required"`
+	ExpiresAt   *time.Time  `json:"expires_at,omitempty"`
+}
+
+// APIKeyIssued is returned when a key is issued or rotated. It is the only
+// response that ever contains the plaintext key.
+type APIKeyIssued struct {
+	*APIKey
+	Key string `json:"key"`
+}
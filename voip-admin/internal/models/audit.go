@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+
+	AuditChangePassword = "change_password"
+	AuditRotate         = "rotate"
+	AuditRevoke         = "revoke"
+	AuditImport         = "import"
+	AuditExport         = "export"
+	AuditDownload       = "download"
+	AuditSuspend        = "suspend"
+	AuditAcknowledge    = "acknowledge"
+)
+
+// AuditEntry represents one administrative change
+type AuditEntry struct {
+	ID         int64           `json:"id" db:"id"`
+	OccurredAt time.Time       `json:"occurred_at" db:"occurred_at"`
+	ActorType  string          `json:"actor_type" db:"actor_type"` // static_key, api_key, user, system
+	ActorID    *int64          `json:"actor_id,omitempty" db:"actor_id"`
+	ActorName  string          `json:"actor_name,omitempty" db:"actor_name"`
+	Action     string          `json:"action" db:"action"`
+	Resource   string          `json:"resource" db:"resource"`
+	ResourceID string          `json:"resource_id,omitempty" db:"resource_id"`
+	DomainID   *int64          `json:"domain_id,omitempty" db:"domain_id"`
+	Changes    json.RawMessage `json:"changes,omitempty" db:"changes"` // {"before": {...}, "after": {...}}
+	SourceIP   string          `json:"source_ip,omitempty" db:"source_ip"`
+	RequestID  string          `json:"request_id,omitempty" db:"request_id"`
+}
+
+// AuditListRequest represents parameters for querying the audit log
+type AuditListRequest struct {
+	StartDate  *time.Time `json:"start_date,omitempty"`
+	EndDate    *time.Time `json:"end_date,omitempty"`
+	ActorType  *string    `json:"actor_type,omitempty"`
+	ActorID    *int64     `json:"actor_id,omitempty"`
+	Action     *string    `json:"action,omitempty"`
+	Resource   *string    `json:"resource,omitempty"`
+	ResourceID *string    `json:"resource_id,omitempty"`
+	DomainID   *int64     `json:"domain_id,omitempty"`
+	RequestID  *string    `json:"request_id,omitempty"`
+	Page       int        `json:"page" validate:"min=1"`
+	PerPage    int        `json:"per_page" validate:"min=1,max=1000"`
+}
+
+// AuditListResponse represents paginated audit log entries
+type AuditListResponse struct {
+	Entries []*AuditEntry `json:"entries"`
+	Total   int64         `json:"total"`
+	Page    int           `json:"page"`
+	PerPage int           `json:"per_page"`
+}
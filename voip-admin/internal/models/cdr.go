@@ -53,6 +53,7 @@ type CDR struct {
 	// Recording
 	RecordFile         *string    `json:"record_file,omitempty" db:"record_file"`
 	RecordDuration     *int       `json:"record_duration,omitempty" db:"record_duration"`
+	RecordingPolicyID  *int64     `json:"-" db:"-"` // Not stored; the recording is registered under it
 
 	// SIP Information
 	SIPFromUser        *string    `json:"sip_from_user,omitempty" db:"sip_from_user"`
@@ -65,6 +66,15 @@ type CDR struct {
 	WriteCodec         *string    `json:"write_codec,omitempty" db:"write_codec"`
 	RemoteMediaIP      *string    `json:"remote_media_ip,omitempty" db:"remote_media_ip"`
 
+	// Rating (outbound calls; nil when no rate matched)
+	Trunk              *string    `json:"trunk,omitempty" db:"trunk"` // Gateway the call left through
+	ForwardedBy        *string    `json:"forwarded_by,omitempty" db:"forwarded_by"` // Extension that forwarded the call, charged for it
+	RatePrefix         *string    `json:"rate_prefix,omitempty" db:"rate_prefix"`
+	RateDescription    *string    `json:"rate_description,omitempty" db:"rate_description"`
+	BilledSeconds      *int       `json:"billed_seconds,omitempty" db:"billed_seconds"`
+	SellCost           *float64   `json:"sell_cost,omitempty" db:"sell_cost"`
+	BuyCost            *float64   `json:"buy_cost,omitempty" db:"buy_cost"`
+
 	// Network Quality
 	RTPAudioInMOS      *float64   `json:"rtp_audio_in_mos,omitempty" db:"rtp_audio_in_mos"`
 	RTPAudioInPacketCount *int    `json:"rtp_audio_in_packet_count,omitempty" db:"rtp_audio_in_packet_count"`
@@ -76,6 +86,18 @@ type CDR struct {
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 }
 
+// ChargedUser returns the user an outbound call is charged to: the
+// extension that forwarded it, else the SIP user or caller ID that placed it
+func (c *CDR) ChargedUser() string {
+	if c.ForwardedBy != nil && *c.ForwardedBy != "" {
+		return *c.ForwardedBy
+	}
+	if c.SIPFromUser != nil && *c.SIPFromUser != "" {
+		return *c.SIPFromUser
+	}
+	return c.CallerIDNumber
+}
+
 // CDRIngest represents the initial CDR data received from FreeSWITCH
 type CDRIngest struct {
 	UUID    string `json:"uuid" validate:"required"`
@@ -92,6 +114,7 @@ type CDRListRequest struct {
 	HangupCause     *string    `json:"hangup_cause,omitempty"`
 	QueueID         *int64     `json:"queue_id,omitempty"`
 	MinDuration     *int       `json:"min_duration,omitempty"`
+	Domain          *string    `json:"domain,omitempty"`
 	Page            int        `json:"page" validate:"min=1"`
 	PerPage         int        `json:"per_page" validate:"min=1,max=1000"`
 }
@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+// ConferenceProfiles lists the mod_conference profiles of
+// configs/freeswitch/autoload_configs/conference.conf.xml
+var ConferenceProfiles = []string{"default", "wideband", "ultrawideband", "cdquality"}
+
+// Member controls of a live conference
+const (
+	ConferenceActionMute   = "mute"
+	ConferenceActionUnmute = "unmute"
+	ConferenceActionKick   = "kick"
+)
+
+// ConferenceRoom is a conference room of a domain, reached on a number of
+// the conference range
+type ConferenceRoom struct {
+	ID               int64     `json:"id" db:"id"`
+	DomainID         int64     `json:"domain_id" db:"domain_id"`
+	Extension        string    `json:"extension" db:"extension"`
+	Name             string    `json:"name" db:"name"`
+	ParticipantPIN   string    `json:"participant_pin,omitempty" db:"participant_pin"`
+	ModeratorPIN     string    `json:"moderator_pin,omitempty" db:"moderator_pin"`
+	MaxMembers       int       `json:"max_members" db:"max_members"` // 0 = unlimited
+	Record           bool      `json:"record" db:"record"`
+	Profile          string    `json:"profile" db:"profile"`
+	MuteOnJoin       bool      `json:"mute_on_join" db:"mute_on_join"`
+	WaitForModerator bool      `json:"wait_for_moderator" db:"wait_for_moderator"`
+	Description      string    `json:"description,omitempty" db:"description"`
+	Active           bool      `json:"active" db:"active"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+
+	// Joined fields from domains table
+	Domain string `json:"domain,omitempty" db:"domain"`
+}
+
+// ConferenceName returns the mod_conference name of a room. Rooms of
+// different domains may share a number, so the name includes the domain.
+func ConferenceName(extension, domain string) string {
+	return extension + "-" + domain
+}
+
+// HasPIN reports whether callers are asked for a PIN
+func (c *ConferenceRoom) HasPIN() bool {
+	return c.ParticipantPIN != "" || c.ModeratorPIN != ""
+}
+
+// ConferenceRoomCreateRequest represents a request to create a conference
+// room
+type ConferenceRoomCreateRequest struct {
+	DomainID         int64  `json:"domain_id" validate:"required,gt=0"`
+	Extension        string `json:"extension" validate:"required"`
+	Name             string `json:"name" validate:"required,max=100"`
+	ParticipantPIN   string `json:"participant_pin,omitempty"`
+	ModeratorPIN     string `json:"moderator_pin,omitempty"`
+	MaxMembers       int    `json:"max_members" validate:"gte=0"`
+	Record           bool   `json:"record"`
+	Profile          string `json:"profile,omitempty"` // Default: default
+	MuteOnJoin       bool   `json:"mute_on_join"`
+	WaitForModerator bool   `json:"wait_for_moderator"`
+	Description      string `json:"description,omitempty" validate:"omitempty,max=255"`
+	Active           bool   `json:"active"`
+}
+
+// ConferenceRoomUpdateRequest represents a request to update a conference
+// room. An empty PIN removes it.
+type ConferenceRoomUpdateRequest struct {
+	Name             *string `json:"name,omitempty" validate:"omitempty,max=100"`
+	ParticipantPIN   *string `json:"participant_pin,omitempty"`
+	ModeratorPIN     *string `json:"moderator_pin,omitempty"`
+	MaxMembers       *int    `json:"max_members,omitempty" validate:"omitempty,gte=0"`
+	Record           *bool   `json:"record,omitempty"`
+	Profile          *string `json:"profile,omitempty"`
+	MuteOnJoin       *bool   `json:"mute_on_join,omitempty"`
+	WaitForModerator *bool   `json:"wait_for_moderator,omitempty"`
+	Description      *string `json:"description,omitempty" validate:"omitempty,max=255"`
+	Active           *bool   `json:"active,omitempty"`
+}
+
+// ConferenceLive is the live state of a conference room
+type ConferenceLive struct {
+	RoomID    int64               `json:"room_id"`
+	Name      string              `json:"name"` // mod_conference name
+	Running   bool                `json:"running"`
+	Locked    bool                `json:"locked"`
+	Recording bool                `json:"recording"`
+	RunTime   int                 `json:"run_time"` // seconds
+	Members   []*ConferenceMember `json:"members"`
+}
+
+// ConferenceMember is a participant of a running conference
+type ConferenceMember struct {
+	ID             int    `json:"id"` // mod_conference member ID
+	UUID           string `json:"uuid"`
+	CallerIDName   string `json:"caller_id_name"`
+	CallerIDNumber string `json:"caller_id_number"`
+	Moderator      bool   `json:"moderator"`
+	Muted          bool   `json:"muted"`
+	Talking        bool   `json:"talking"`
+	JoinTime       int    `json:"join_time"` // seconds in the conference
+}
+
+// ConferenceLockRequest represents a request to lock or unlock a running
+// conference; locked conferences admit no new members
+type ConferenceLockRequest struct {
+	Locked bool `json:"locked"`
+}
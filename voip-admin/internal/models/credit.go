@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// DefaultCreditAlertPercent is the share of a monthly limit that raises the
+// first credit alert; reaching the limit raises another
+const DefaultCreditAlertPercent = 80
+
+// Outbound call rejection reasons, set as outbound_rejected on the channel
+const (
+	CreditRejectSpendingLimit = "spending_limit"
+	CreditRejectOutboundCalls = "outbound_calls"
+)
+
+// CreditLimit caps the outbound calling of a domain, or of one extension
+// when ExtensionID is set, with the spend of the current month
+type CreditLimit struct {
+	DomainID         int64      `json:"domain_id"`
+	Domain           string     `json:"domain,omitempty"`
+	ExtensionID      *int64     `json:"extension_id,omitempty"`
+	Extension        string     `json:"extension,omitempty"`
+	MonthlyLimit     *float64   `json:"monthly_limit"`      // nil: no cap
+	MaxOutboundCalls *int       `json:"max_outbound_calls"` // nil: no limit; counted per FreeSWITCH node
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+
+	Month     string   `json:"month"`              // YYYY-MM
+	Currency  string   `json:"currency,omitempty"` // Of the domain's sell deck
+	Spent     float64  `json:"spent"`              // Sell cost of the month's rated calls
+	Remaining *float64 `json:"remaining,omitempty"`
+}
+
+// Exhausted reports whether the monthly limit is used up
+func (l *CreditLimit) Exhausted() bool {
+	return l.MonthlyLimit != nil && l.Spent >= *l.MonthlyLimit
+}
+
+// CreditLimitUpdate replaces the limits of a domain or extension. Omitted
+// (null) fields remove that limit.
+type CreditLimitUpdate struct {
+	MonthlyLimit     *float64 `json:"monthly_limit"`
+	MaxOutboundCalls *int     `json:"max_outbound_calls"`
+}
+
+// CreditAlert reports a monthly limit used up to a threshold
+type CreditAlert struct {
+	ID           int64     `json:"id"`
+	DomainID     int64     `json:"domain_id"`
+	Domain       string    `json:"domain"`
+	ExtensionID  *int64    `json:"extension_id,omitempty"`
+	Extension    string    `json:"extension,omitempty"` // Empty for the domain limit
+	Month        string    `json:"month"`               // YYYY-MM
+	Threshold    int       `json:"threshold"`           // Percent of the limit
+	Spent        float64   `json:"spent"`
+	MonthlyLimit float64   `json:"monthly_limit"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CreditAlertListResponse represents a paginated list of credit alerts
+type CreditAlertListResponse struct {
+	Alerts  []*CreditAlert `json:"alerts"`
+	Total   int64          `json:"total"`
+	Page    int            `json:"page"`
+	PerPage int            `json:"per_page"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// DIDDestinationTimeCondition routes a DID to the first destination of a
+// time condition while it is open, and to its closed action otherwise
+const DIDDestinationTimeCondition = "time_condition"
+
+// DIDDestinationTypes lists the valid DID destination types
+var DIDDestinationTypes = []string{NumberTypeUser, NumberTypeQueue, NumberTypeIVR, DIDDestinationTimeCondition}
+
+// DID is an external number delivered by a carrier into the public
+// context, mapped to an internal destination of a domain
+type DID struct {
+	ID                 int64     `json:"id" db:"id"`
+	DomainID           int64     `json:"domain_id" db:"domain_id"`
+	Number             string    `json:"number" db:"number"`
+	DestinationType    string    `json:"destination_type" db:"destination_type"`             // user, queue, ivr, time_condition
+	Destination        string    `json:"destination,omitempty" db:"destination"`             // Number, unless time_condition
+	TimeConditionID    *int64    `json:"time_condition_id,omitempty" db:"time_condition_id"` // Only for time_condition
+	CallerIDNamePrefix string    `json:"caller_id_name_prefix,omitempty" db:"caller_id_name_prefix"`
+	Description        string    `json:"description,omitempty" db:"description"`
+	Active             bool      `json:"active" db:"active"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+
+	// Joined fields from domains table
+	Domain string `json:"domain,omitempty" db:"domain"`
+}
+
+// DIDCreateRequest represents a request to create a DID
+type DIDCreateRequest struct {
+	DomainID           int64  `json:"domain_id" validate:"required,gt=0"`
+	Number             string `json:"number" validate:"required"`
+	DestinationType    string `json:"destination_type" validate:"required,oneof=user queue ivr time_condition"`
+	Destination        string `json:"destination,omitempty"`
+	TimeConditionID    *int64 `json:"time_condition_id,omitempty"`
+	CallerIDNamePrefix string `json:"caller_id_name_prefix,omitempty" validate:"omitempty,max=20"`
+	Description        string `json:"description,omitempty" validate:"omitempty,max=255"`
+	Active             bool   `json:"active"`
+}
+
+// DIDUpdateRequest represents a request to update a DID. Changing the
+// destination type requires the matching destination or time_condition_id.
+type DIDUpdateRequest struct {
+	DestinationType    *string `json:"destination_type,omitempty" validate:"omitempty,oneof=user queue ivr time_condition"`
+	Destination        *string `json:"destination,omitempty"`
+	TimeConditionID    *int64  `json:"time_condition_id,omitempty"`
+	CallerIDNamePrefix *string `json:"caller_id_name_prefix,omitempty" validate:"omitempty,max=20"`
+	Description        *string `json:"description,omitempty" validate:"omitempty,max=255"`
+	Active             *bool   `json:"active,omitempty"`
+}
@@ -0,0 +1,56 @@
+package models
+
+// DefaultDispatcherSet is the dispatcher set Kamailio routes calls to
+// FreeSWITCH with (DS_SETID in kamailio.cfg)
+const DefaultDispatcherSet = 1
+
+// Dispatcher destination flags (kamailio.dispatcher.flags)
+const (
+	DispatcherFlagInactive = 1
+	DispatcherFlagProbing  = 2
+	DispatcherFlagDisabled = 4
+)
+
+// Dispatcher is a FreeSWITCH node in a Kamailio dispatcher set
+type Dispatcher struct {
+	ID          int64  `json:"id" db:"id"`
+	SetID       int    `json:"set_id" db:"setid"`
+	Destination string `json:"destination" db:"destination"` // SIP URI, e.g. sip:172.16.91.101:5080
+	Flags       int    `json:"flags" db:"flags"`
+	Priority    int    `json:"priority" db:"priority"`
+	Weight      int    `json:"weight"`          // weight attribute; 0 = unset
+	Attrs       string `json:"attrs,omitempty"` // Other attributes, without weight
+	Description string `json:"description,omitempty" db:"description"`
+
+	// Drained destinations get no new calls; calls in progress continue
+	Drained bool `json:"drained"`
+
+	// State is the live state per Kamailio node, when requested
+	State []*DispatcherNodeState `json:"state,omitempty"`
+}
+
+// DispatcherNodeState is the state of a destination on one Kamailio node
+type DispatcherNodeState struct {
+	Node  string `json:"node"`
+	Flags string `json:"flags,omitempty"` // AP = active and probing, DX = disabled, ...
+	Error string `json:"error,omitempty"` // Node unreachable or destination not loaded
+}
+
+// DispatcherCreateRequest represents a request to add a destination
+type DispatcherCreateRequest struct {
+	SetID       int    `json:"set_id,omitempty"` // Default: 1
+	Destination string `json:"destination" validate:"required,max=192"`
+	Priority    int    `json:"priority,omitempty"`
+	Weight      int    `json:"weight,omitempty" validate:"omitempty,min=1,max=100"`
+	Attrs       string `json:"attrs,omitempty"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=64"`
+}
+
+// DispatcherUpdateRequest represents a request to update a destination.
+// The set and destination cannot change; remove and add it instead.
+type DispatcherUpdateRequest struct {
+	Priority    *int    `json:"priority,omitempty"`
+	Weight      *int    `json:"weight,omitempty" validate:"omitempty,min=0,max=100"` // 0 removes
+	Attrs       *string `json:"attrs,omitempty"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=64"`
+}
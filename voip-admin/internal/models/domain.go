@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Domain represents a SIP domain (one tenant)
+type Domain struct {
+	ID         int64     `json:"id" db:"id"`
+	Domain     string    `json:"domain" db:"domain"`
+	TenantName string    `json:"tenant_name" db:"tenant_name"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+
+	// Internal number that asks for a mailbox and its password
+	VoicemailMainNumber string `json:"voicemail_main_number,omitempty" db:"voicemail_main_number"`
+
+	// Sell deck the tenant's outbound calls are invoiced with
+	RateDeckID *int64 `json:"rate_deck_id,omitempty" db:"rate_deck_id"`
+}
+
+// DomainCreateRequest represents a request to create a domain
+type DomainCreateRequest struct {
+	Domain     string `json:"domain" validate:"required,fqdn"`
+	TenantName string `json:"tenant_name" validate:"required,min=1,max=255"`
+	Active     bool   `json:"active"`
+}
+
+// DomainUpdateRequest represents a request to update a domain.
+// The domain name itself is immutable because it is the SIP realm that
+// every extension's HA1 hash was computed with.
+type DomainUpdateRequest struct {
+	TenantName *string `json:"tenant_name,omitempty" validate:"omitempty,min=1,max=255"`
+	Active     *bool   `json:"active,omitempty"`
+
+	// Empty removes the voicemail main number
+	VoicemailMainNumber *string `json:"voicemail_main_number,omitempty"`
+
+	// Sell deck; 0 removes it
+	RateDeckID *int64 `json:"rate_deck_id,omitempty"`
+}
+
+// DomainUsage counts the objects that a domain deletion would cascade to
+type DomainUsage struct {
+	Extensions int64 `json:"extensions"`
+	Queues     int64 `json:"queues"`
+	Users      int64 `json:"users"`
+	Trunks     int64 `json:"trunks"`
+}
+
+// DomainListResponse represents paginated domain list
+type DomainListResponse struct {
+	Domains []*Domain `json:"domains"`
+	Total   int64     `json:"total"`
+	Page    int       `json:"page"`
+	PerPage int       `json:"per_page"`
+}
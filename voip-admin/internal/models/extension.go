@@ -10,19 +10,23 @@ type Extension struct {
 	Type             string    `json:"type" db:"type"` // user, queue, ivr, conference
 	DisplayName      string    `json:"display_name" db:"display_name"`
 	Email            string    `json:"email,omitempty" db:"email"`
-	SIPPassword      string    `json:"-" db:"sip_password"`       // Never expose in JSON
 	SIPHA1           string    `json:"-" db:"sip_ha1"`            // MD5 hash for auth
 	SIPHA1B          string    `json:"-" db:"sip_ha1b"`           // MD5 hash variant
 	VMPassword       string    `json:"vm_password,omitempty" db:"vm_password"`
 	VMEmail          string    `json:"vm_email,omitempty" db:"vm_email"`
+	VMAutoLogin      bool      `json:"vm_auto_login" db:"vm_auto_login"` // *97 skips the password from the extension itself
 	Active           bool      `json:"active" db:"active"`
 	MaxConcurrent    int       `json:"max_concurrent" db:"max_concurrent"`
 	CallTimeout      int       `json:"call_timeout" db:"call_timeout"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+	UserID           *int64    `json:"user_id,omitempty" db:"user_id"` // Owning user of a user extension
 
 	// Joined fields from domains table
 	Domain           string    `json:"domain,omitempty" db:"domain"`
+
+	// Forwarding, DND and follow-me; only loaded for single extensions
+	CallHandling     *CallHandling `json:"call_handling,omitempty"`
 }
 
 // ExtensionCreateRequest represents a request to create a new extension
@@ -35,9 +39,11 @@ type ExtensionCreateRequest struct {
 	SIPPassword   string `json:"sip_password" validate:"required,min=8,max=128"`
 	VMPassword    string `json:"vm_password,omitempty" validate:"omitempty,len=4"`
 	VMEmail       string `json:"vm_email,omitempty" validate:"omitempty,email"`
+	VMAutoLogin   bool   `json:"vm_auto_login"`
 	Active        bool   `json:"active"`
 	MaxConcurrent int    `json:"max_concurrent" validate:"min=1,max=100"`
 	CallTimeout   int    `json:"call_timeout" validate:"min=10,max=300"`
+	UserID        *int64 `json:"user_id,omitempty"`
 }
 
 // ExtensionUpdateRequest represents a request to update an extension
@@ -46,24 +52,81 @@ type ExtensionUpdateRequest struct {
 	Email         *string `json:"email,omitempty" validate:"omitempty,email"`
 	VMPassword    *string `json:"vm_password,omitempty" validate:"omitempty,len=4"`
 	VMEmail       *string `json:"vm_email,omitempty" validate:"omitempty,email"`
+	VMAutoLogin   *bool   `json:"vm_auto_login,omitempty"`
 	Active        *bool   `json:"active,omitempty"`
 	MaxConcurrent *int    `json:"max_concurrent,omitempty" validate:"omitempty,min=1,max=100"`
 	CallTimeout   *int    `json:"call_timeout,omitempty" validate:"omitempty,min=10,max=300"`
+	UserID        *int64  `json:"user_id,omitempty"`
 }
 
-// ExtensionPasswordUpdate represents a request to change extension password
+// ExtensionPasswordUpdate represents a request to change extension password.
+// OldPassword is only required when users change their own extension.
 type ExtensionPasswordUpdate struct {
-	OldPassword string `json:"old_password" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=8,max=128"`
+	OldPassword string `json:"old_password,omitempty"`
+	NewPassword string `json:"new_password" validate:"required,max=128"`
+}
+
+// ExtensionImportResult reports the outcome of a bulk extension import
+type ExtensionImportResult struct {
+	DryRun    bool                       `json:"dry_run"`
+	Total     int                        `json:"total"`
+	Created   int                        `json:"created"`
+	Errors    []*ExtensionImportError    `json:"errors,omitempty"`
+	Passwords []*ExtensionImportPassword `json:"passwords,omitempty"` // Generated for rows without sip_password
+}
+
+// ExtensionImportError is a validation error of one import row.
+// Row is 1-based and does not count the CSV header.
+type ExtensionImportError struct {
+	Row       int    `json:"row"`
+	Extension string `json:"extension,omitempty"`
+	Error     string `json:"error"`
+}
+
+// ExtensionImportPassword is a SIP password generated during import.
+// It is only returned once.
+type ExtensionImportPassword struct {
+	Row       int    `json:"row"`
+	DomainID  int64  `json:"domain_id"`
+	Extension string `json:"extension"`
+	Password  string `json:"password"`
+}
+
+// GeneratedPassword is a random SIP password suggested by the server
+type GeneratedPassword struct {
+	Password    string  `json:"password"`
+	EntropyBits float64 `json:"entropy_bits"`
+}
+
+// MaxFollowMeNumbers limits the numbers rung together with an extension
+const MaxFollowMeNumbers = 5
+
+// CallHandling holds the call forwarding, do-not-disturb and follow-me
+// settings of an extension. Empty forward destinations are disabled.
+// Extensions without settings ring normally and fall back to voicemail.
+type CallHandling struct {
+	ExtensionID     int64      `json:"extension_id" db:"extension_id"`
+	DND             bool       `json:"dnd" db:"dnd"`                             // Don't ring; busy treatment
+	ForwardAlways   string     `json:"forward_always" db:"forward_always"`       // Forward without ringing
+	ForwardBusy     string     `json:"forward_busy" db:"forward_busy"`           // Busy, rejected or DND
+	ForwardNoAnswer string     `json:"forward_no_answer" db:"forward_no_answer"` // No answer or not registered
+	FollowMe        []string   `json:"follow_me" db:"follow_me"`                 // Rung together with the extension
+	UpdatedAt       *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// CallHandlingUpdate represents a request to replace the call handling
+// settings of an extension
+type CallHandlingUpdate struct {
+	DND             bool     `json:"dnd"`
+	ForwardAlways   string   `json:"forward_always"`
+	ForwardBusy     string   `json:"forward_busy"`
+	ForwardNoAnswer string   `json:"forward_no_answer"`
+	FollowMe        []string `json:"follow_me"`
 }
 
-// Domain represents a SIP domain
-type Domain struct {
-	ID        int64     `json:"id" db:"id"`
-	Domain    string    `json:"domain" db:"domain"`
-	Active    bool      `json:"active" db:"active"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+// VoicemailPINUpdate represents a request to change an extension's voicemail PIN
+type VoicemailPINUpdate struct {
+	PIN string `json:"pin" validate:"required,numeric,min=4,max=10"`
 }
 
 // ExtensionListResponse represents paginated extension list
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Fraud detection rules
+const (
+	FraudRuleInternationalSpike = "international_spike" // Many international calls in the window
+	FraudRuleHighCostPrefix     = "high_cost_prefix"    // A call to a configured high-cost prefix
+	FraudRuleOffHoursBurst      = "off_hours_burst"     // Many outbound calls outside business hours
+	FraudRuleConcurrentCalls    = "concurrent_calls"    // More concurrent outbound calls than max_concurrent
+)
+
+// FraudRules lists the fraud detection rules
+var FraudRules = []string{
+	FraudRuleInternationalSpike, FraudRuleHighCostPrefix, FraudRuleOffHoursBurst, FraudRuleConcurrentCalls,
+}
+
+// Fraud alert statuses
+const (
+	FraudAlertOpen         = "open"
+	FraudAlertAcknowledged = "acknowledged"
+)
+
+// FraudAlert is an outbound calling anomaly of an extension
+type FraudAlert struct {
+	ID             int64           `json:"id" db:"id"`
+	DomainID       int64           `json:"domain_id" db:"domain_id"`
+	Domain         string          `json:"domain,omitempty"`
+	ExtensionID    *int64          `json:"extension_id,omitempty" db:"extension_id"` // Nil once the extension is deleted
+	Extension      string          `json:"extension" db:"extension"`
+	Rule           string          `json:"rule" db:"rule"`
+	Message        string          `json:"message" db:"message"`
+	Details        json.RawMessage `json:"details" db:"details"`
+	Suspended      bool            `json:"suspended" db:"suspended"` // The extension was deactivated
+	Status         string          `json:"status" db:"status"`
+	AcknowledgedAt *time.Time      `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	AcknowledgedBy string          `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+	Note           string          `json:"note,omitempty" db:"note"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
+
+// FraudAlertListRequest represents parameters for listing fraud alerts
+type FraudAlertListRequest struct {
+	DomainID    *int64  `json:"domain_id,omitempty"`
+	ExtensionID *int64  `json:"extension_id,omitempty"`
+	Rule        *string `json:"rule,omitempty"`
+	Status      *string `json:"status,omitempty"`
+	Page        int     `json:"page" validate:"min=1"`
+	PerPage     int     `json:"per_page" validate:"min=1,max=1000"`
+}
+
+// FraudAlertListResponse represents paginated fraud alerts
+type FraudAlertListResponse struct {
+	Alerts  []*FraudAlert `json:"alerts"`
+	Total   int64         `json:"total"`
+	Page    int           `json:"page"`
+	PerPage int           `json:"per_page"`
+}
+
+// FraudAlertAcknowledgeRequest represents a request to acknowledge an alert
+type FraudAlertAcknowledgeRequest struct {
+	Note string `json:"note,omitempty" validate:"omitempty,max=255"`
+}
+
+// PlacedCall is a call placed by a user, as scanned by the fraud detector
+type PlacedCall struct {
+	CDRID       int64     `json:"cdr_id"`
+	Domain      string    `json:"domain"`
+	Extension   string    `json:"extension"` // Charged user (forwarded_by, else sip_from_user, else caller ID)
+	Destination string    `json:"destination"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
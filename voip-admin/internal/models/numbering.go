@@ -0,0 +1,103 @@
+package models
+
+import "time"
+
+// Number types of a numbering plan; they match the extension types
+const (
+	NumberTypeUser       = "user"
+	NumberTypeQueue      = "queue"
+	NumberTypeIVR        = "ivr"
+	NumberTypeConference = "conference"
+)
+
+// NumberTypeOutbound classifies numbers matching a domain's outbound
+// pattern; it is not a range type
+const NumberTypeOutbound = "outbound"
+
+// DefaultOutboundPattern matches PSTN numbers: 10-11 digits, or + and 8-15 digits
+const DefaultOutboundPattern = `^(\+?\d{10,11}|\+\d{8,15})$`
+
+// NumberTypes lists the valid number range types
+var NumberTypes = []string{NumberTypeUser, NumberTypeQueue, NumberTypeIVR, NumberTypeConference}
+
+// NumberRange is an inclusive range of internal numbers reserved for one
+// type. Start and End are digit strings of the same length, so "0100" and
+// "100" are different numbers.
+type NumberRange struct {
+	ID          int64     `json:"id,omitempty" db:"id"`
+	DomainID    int64     `json:"domain_id,omitempty" db:"domain_id"`
+	Type        string    `json:"type" db:"type"`
+	Start       string    `json:"start" db:"range_start"`
+	End         string    `json:"end" db:"range_end"`
+	Description string    `json:"description,omitempty" db:"description"`
+	CreatedAt   time.Time `json:"created_at,omitempty" db:"created_at"`
+}
+
+// Contains reports whether number lies within the range
+func (nr *NumberRange) Contains(number string) bool {
+	if len(number) != len(nr.Start) {
+		return false
+	}
+	return number >= nr.Start && number <= nr.End
+}
+
+// Overlaps reports whether two ranges share a number
+func (nr *NumberRange) Overlaps(other *NumberRange) bool {
+	return len(nr.Start) == len(other.Start) && nr.Start <= other.End && other.Start <= nr.End
+}
+
+// NumberingPlan is the set of number ranges of a domain and the pattern of
+// its outbound numbers. Domains without ranges of their own use
+// DefaultNumberRanges, and without a pattern DefaultOutboundPattern.
+type NumberingPlan struct {
+	DomainID        int64          `json:"domain_id"`
+	Domain          string         `json:"domain,omitempty"`
+	Default         bool           `json:"default"` // Ranges are the default ranges
+	Ranges          []*NumberRange `json:"ranges"`
+	OutboundPattern string         `json:"outbound_pattern"`
+}
+
+// DefaultNumberRanges is the numbering plan the dialplan was built around:
+// 3xxx conferences, 8xxx queues, 9xxx IVRs and the other 4-digit numbers
+// for users
+func DefaultNumberRanges() []*NumberRange {
+	return []*NumberRange{
+		{Type: NumberTypeUser, Start: "1000", End: "2999", Description: "Users"},
+		{Type: NumberTypeConference, Start: "3000", End: "3999", Description: "Conferences"},
+		{Type: NumberTypeUser, Start: "4000", End: "7999", Description: "Users"},
+		{Type: NumberTypeQueue, Start: "8000", End: "8999", Description: "Queues"},
+		{Type: NumberTypeIVR, Start: "9000", End: "9999", Description: "IVR menus"},
+	}
+}
+
+// RangeFor returns the range that number falls in, or nil
+func (p *NumberingPlan) RangeFor(number string) *NumberRange {
+	for _, nr := range p.Ranges {
+		if nr.Contains(number) {
+			return nr
+		}
+	}
+	return nil
+}
+
+// NumberingPlanUpdate replaces a domain's plan. An empty list of ranges
+// reverts to the default ranges, an empty pattern to the default pattern.
+type NumberingPlanUpdate struct {
+	Ranges          []*NumberRange `json:"ranges"`
+	OutboundPattern string         `json:"outbound_pattern,omitempty"`
+}
+
+// NumberInUse is an internal number taken by an extension, queue, IVR menu,
+// conference room or voicemail main number
+type NumberInUse struct {
+	Number string `json:"number"`
+	Kind   string `json:"kind"` // extension type, or "queue" / "ivr" / "conference" / "voicemail"
+}
+
+// NextNumberResponse is the next free number of a type in a domain
+type NextNumberResponse struct {
+	DomainID int64        `json:"domain_id"`
+	Type     string       `json:"type"`
+	Number   string       `json:"number"`
+	Range    *NumberRange `json:"range"`
+}
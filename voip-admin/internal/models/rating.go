@@ -0,0 +1,165 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Rate deck types
+const (
+	RateDeckBuy  = "buy"  // Carrier cost, assigned to trunks
+	RateDeckSell = "sell" // Tenant price, assigned to domains
+)
+
+// DefaultCurrency is the currency of rate decks created without one
+const DefaultCurrency = "USD"
+
+// RateDeck is a price list of destination prefixes
+type RateDeck struct {
+	ID          int64     `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Type        string    `json:"type" db:"type"`
+	Currency    string    `json:"currency" db:"currency"`
+	Description string    `json:"description,omitempty" db:"description"`
+	Rates       int64     `json:"rates"`   // Number of prefixes
+	Trunks      int64     `json:"trunks"`  // Trunks buying with this deck
+	Domains     int64     `json:"domains"` // Domains invoiced with this deck
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RateDeckCreateRequest represents a request to create a rate deck
+type RateDeckCreateRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Type        string `json:"type" validate:"required,oneof=buy sell"`
+	Currency    string `json:"currency,omitempty"` // Default: USD
+	Description string `json:"description,omitempty" validate:"omitempty,max=255"`
+}
+
+// RateDeckUpdateRequest represents a request to update a rate deck. The
+// type is fixed once created.
+type RateDeckUpdateRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,max=100"`
+	Currency    *string `json:"currency,omitempty"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=255"`
+}
+
+// Rate is the price of the destinations starting with a prefix. Calls are
+// billed the initial increment once answered, then whole increments
+// (60/6: the first minute, then every 6 seconds).
+type Rate struct {
+	ID               int64   `json:"id" db:"id"`
+	RateDeckID       int64   `json:"rate_deck_id" db:"rate_deck_id"`
+	Prefix           string  `json:"prefix" db:"prefix"` // Digits without a leading + or 00
+	Description      string  `json:"description,omitempty" db:"description"`
+	Rate             float64 `json:"rate" db:"rate"` // Per minute
+	ConnectionFee    float64 `json:"connection_fee" db:"connection_fee"`
+	InitialIncrement int     `json:"initial_increment" db:"initial_increment"` // Seconds
+	Increment        int     `json:"increment" db:"increment"`                 // Seconds
+}
+
+// MaxDuration returns the longest billsec whose cost stays within credit,
+// on the increment grid of the rate (0: not even the initial increment is
+// covered). It mirrors voip.call_cost.
+func (r *Rate) MaxDuration(credit float64) int {
+	if r.Rate <= 0 {
+		if credit >= r.ConnectionFee {
+			return math.MaxInt32
+		}
+		return 0
+	}
+
+	// A small epsilon keeps credit that exactly covers an increment from
+	// rounding down
+	affordable := (credit-r.ConnectionFee)*60/r.Rate + 1e-6
+	if affordable < float64(r.InitialIncrement) {
+		return 0
+	}
+	if affordable >= math.MaxInt32 {
+		return math.MaxInt32
+	}
+
+	steps := int(affordable-float64(r.InitialIncrement)) / r.Increment
+	return r.InitialIncrement + steps*r.Increment
+}
+
+// RatePrefixes returns the prefixes of a dialed number a rate can match,
+// longest first. Rates are keyed by the digits after a leading + or 00.
+func RatePrefixes(number string) []string {
+	number = strings.TrimPrefix(number, "+")
+	number = strings.TrimPrefix(number, "00")
+
+	var digits strings.Builder
+	for _, c := range number {
+		if c >= '0' && c <= '9' {
+			digits.WriteRune(c)
+		}
+	}
+
+	// voip.rates.prefix holds at most 20 digits
+	normalized := digits.String()
+	if len(normalized) > 20 {
+		normalized = normalized[:20]
+	}
+
+	prefixes := make([]string, 0, len(normalized))
+	for i := len(normalized); i > 0; i-- {
+		prefixes = append(prefixes, normalized[:i])
+	}
+
+	return prefixes
+}
+
+// RateListResponse represents a paginated list of the rates of a deck
+type RateListResponse struct {
+	Rates   []*Rate `json:"rates"`
+	Total   int64   `json:"total"`
+	Page    int     `json:"page"`
+	PerPage int     `json:"per_page"`
+}
+
+// RateImportResult reports the outcome of a rate deck import
+type RateImportResult struct {
+	DryRun   bool               `json:"dry_run"`
+	Replace  bool               `json:"replace"` // Rates missing from the file were deleted
+	Total    int                `json:"total"`
+	Imported int                `json:"imported"`
+	Errors   []*RateImportError `json:"errors,omitempty"`
+}
+
+// RateImportError is a validation error of one import row.
+// Row is 1-based and does not count the CSV header.
+type RateImportError struct {
+	Row    int    `json:"row"`
+	Prefix string `json:"prefix,omitempty"`
+	Error  string `json:"error"`
+}
+
+// Invoice sums the rated outbound calls of a domain in a month. Costs are
+// in the currency of the domain's sell deck; buy costs are only shown to
+// unscoped callers.
+type Invoice struct {
+	DomainID      int64          `json:"domain_id"`
+	Domain        string         `json:"domain"`
+	TenantName    string         `json:"tenant_name"`
+	Month         string         `json:"month"` // YYYY-MM
+	Currency      string         `json:"currency,omitempty"`
+	Calls         int64          `json:"calls"`
+	BilledSeconds int64          `json:"billed_seconds"`
+	Amount        float64        `json:"amount"`
+	Cost          *float64       `json:"cost,omitempty"`   // Carrier cost of the same calls
+	Margin        *float64       `json:"margin,omitempty"` // Amount minus cost
+	Unrated       int64          `json:"unrated"`          // Calls without a sell rate
+	Lines         []*InvoiceLine `json:"lines,omitempty"`
+}
+
+// InvoiceLine sums the calls of an invoice by destination prefix
+type InvoiceLine struct {
+	Prefix        string   `json:"prefix"`
+	Description   string   `json:"description,omitempty"`
+	Calls         int64    `json:"calls"`
+	BilledSeconds int64    `json:"billed_seconds"`
+	Amount        float64  `json:"amount"`
+	Cost          *float64 `json:"cost,omitempty"`
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultRecordingStoragePath is where recordings are stored when the
+// policy does not name a path
+const DefaultRecordingStoragePath = "/storage/recordings"
+
+// AudioContentTypes maps the formats of recordings and voicemail messages
+// to their MIME type
+var AudioContentTypes = map[string]string{
+	"wav": "audio/wav",
+	"mp3": "audio/mpeg",
+	"ogg": "audio/ogg",
+}
+
+// RecordingPolicy decides which calls of an extension are recorded and for
+// how long the recordings are kept
+type RecordingPolicy struct {
+	ID             int64     `json:"id" db:"id"`
+	Name           string    `json:"name" db:"name"`
+	RecordInbound  bool      `json:"record_inbound" db:"record_inbound"`
+	RecordOutbound bool      `json:"record_outbound" db:"record_outbound"`
+	RecordInternal bool      `json:"record_internal" db:"record_internal"`
+	RecordQueue    bool      `json:"record_queue" db:"record_queue"`
+	RetentionDays  int       `json:"retention_days" db:"retention_days"`
+	StoragePath    string    `json:"storage_path" db:"storage_path"`
+	Active         bool      `json:"active" db:"active"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// Records reports whether the policy records calls of a kind: a call
+// direction (inbound, outbound, internal) or "queue"
+func (p *RecordingPolicy) Records(kind string) bool {
+	switch kind {
+	case "inbound":
+		return p.RecordInbound
+	case "outbound":
+		return p.RecordOutbound
+	case "internal":
+		return p.RecordInternal
+	case NumberTypeQueue:
+		return p.RecordQueue
+	default:
+		return false
+	}
+}
+
+// Storage returns the directory recordings of the policy are stored in
+func (p *RecordingPolicy) Storage() string {
+	if p == nil || p.StoragePath == "" {
+		return DefaultRecordingStoragePath
+	}
+	return p.StoragePath
+}
+
+// Recording is an audio file recorded during a call
+type Recording struct {
+	ID                int64      `json:"id" db:"id"`
+	CallUUID          string     `json:"call_uuid" db:"call_uuid"`
+	CDRID             *int64     `json:"cdr_id,omitempty" db:"cdr_id"`
+	DomainID          *int64     `json:"domain_id,omitempty" db:"domain_id"`
+	RecordingPolicyID *int64     `json:"recording_policy_id,omitempty" db:"recording_policy_id"`
+	FilePath          string     `json:"file_path" db:"file_path"`
+	FileSize          *int64     `json:"file_size,omitempty" db:"file_size"`
+	Duration          *int       `json:"duration,omitempty" db:"duration"` // seconds
+	Format            string     `json:"format" db:"format"`
+	StartTime         *time.Time `json:"start_time,omitempty" db:"start_time"`
+	EndTime           *time.Time `json:"end_time,omitempty" db:"end_time"`
+	LegalHold         bool       `json:"legal_hold" db:"legal_hold"` // Kept past its retention
+	LegalHoldReason   string     `json:"legal_hold_reason,omitempty" db:"legal_hold_reason"`
+	LegalHoldBy       string     `json:"legal_hold_by,omitempty" db:"legal_hold_by"`
+	LegalHoldAt       *time.Time `json:"legal_hold_at,omitempty" db:"legal_hold_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+
+	// Joined fields from the domain and CDR
+	Domain            string `json:"domain,omitempty" db:"domain"`
+	CallerIDNumber    string `json:"caller_id_number,omitempty" db:"caller_id_number"`
+	DestinationNumber string `json:"destination_number,omitempty" db:"destination_number"`
+	Direction         string `json:"direction,omitempty" db:"direction"`
+}
+
+// RecordingListRequest represents parameters for searching recordings by
+// the CDR of their call
+type RecordingListRequest struct {
+	CDRListRequest
+	LegalHold *bool `json:"legal_hold,omitempty"`
+}
+
+// RecordingListResponse represents a paginated recording list
+type RecordingListResponse struct {
+	Recordings []*Recording `json:"recordings"`
+	Total      int64        `json:"total"`
+	Page       int          `json:"page"`
+	PerPage    int          `json:"per_page"`
+}
+
+// RecordingLegalHoldRequest represents a request to place or release a
+// legal hold
+type RecordingLegalHoldRequest struct {
+	LegalHold bool   `json:"legal_hold"`
+	Reason    string `json:"reason,omitempty" validate:"max=255"`
+}
+
+// LocalPath returns the file of the recording if it is inside storage, the
+// recordings root as mounted on this host
+func (r *Recording) LocalPath(storage string) (string, bool) {
+	root := filepath.Clean(storage)
+	file := filepath.Clean(r.FilePath)
+	if !filepath.IsAbs(file) || !strings.HasPrefix(file, root+string(filepath.Separator)) {
+		return "", false
+	}
+	return file, true
+}
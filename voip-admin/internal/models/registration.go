@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Registration is a contact of an extension in Kamailio's location table.
+// usrloc writes the table back periodically, so it may lag Kamailio's
+// memory by up to its timer interval.
+type Registration struct {
+	ID           int64     `json:"id" db:"id"`
+	RUID         string    `json:"ruid" db:"ruid"` // Identifies the contact for force-unregister
+	ExtensionID  int64     `json:"extension_id"`
+	Username     string    `json:"username" db:"username"`
+	Domain       string    `json:"domain" db:"domain"`
+	AOR          string    `json:"aor"` // user@domain, or user for contacts saved without domain
+	Contact      string    `json:"contact" db:"contact"`
+	Received     string    `json:"received,omitempty" db:"received"` // Source address when behind NAT
+	Source       string    `json:"source"`                           // host:port the device registers from
+	Path         string    `json:"path,omitempty" db:"path"`
+	Socket       string    `json:"socket,omitempty" db:"socket"` // Kamailio listen socket
+	UserAgent    string    `json:"user_agent" db:"user_agent"`
+	CallID       string    `json:"call_id" db:"callid"`
+	CSeq         int       `json:"cseq" db:"cseq"`
+	Q            float64   `json:"q" db:"q"`
+	Instance     string    `json:"instance,omitempty" db:"instance"` // +sip.instance
+	Expires      time.Time `json:"expires" db:"expires"`
+	ExpiresIn    int       `json:"expires_in"` // Seconds; negative once expired
+	LastModified time.Time `json:"last_modified" db:"last_modified"`
+	CFlags       int       `json:"-" db:"cflags"`
+
+	// Expired contacts have not been refreshed; usrloc removes them on
+	// its next timer run
+	Expired bool `json:"expired"`
+	// NATed contacts register from a different address than their Contact
+	NATed bool `json:"nated"`
+	// Duplicate contacts are older bindings of the same device (same
+	// instance, or same source host and user agent)
+	Duplicate bool `json:"duplicate"`
+}
+
+// RegistrationList is the result of a registrations query
+type RegistrationList struct {
+	Registrations []*Registration `json:"registrations"`
+	Total         int             `json:"total"`
+	Registered    int             `json:"registered"` // Extensions with an unexpired contact
+	Expired       int             `json:"expired"`
+	NATed         int             `json:"nated"`
+	Duplicate     int             `json:"duplicate"`
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Time condition overrides; empty follows the schedule
+const (
+	OverrideOpen   = "open"
+	OverrideClosed = "closed"
+)
+
+// What happens to calls while a time condition is closed
+const (
+	ClosedActionVoicemail    = "voicemail"    // Leave a message in the target mailbox
+	ClosedActionAnnouncement = "announcement" // Play the target sound file and hang up
+	ClosedActionDestination  = "destination"  // Transfer to the target number
+)
+
+// Reasons a time condition is open or closed
+const (
+	TimeReasonOverride = "override"
+	TimeReasonHoliday  = "holiday"
+	TimeReasonSchedule = "schedule"
+)
+
+// TimeConditionDestinationTypes lists the number types a time condition
+// can guard
+var TimeConditionDestinationTypes = []string{NumberTypeUser, NumberTypeQueue, NumberTypeIVR}
+
+// TimeCondition holds the business hours of extensions, queues and IVR
+// menus. It is open during its weekly schedule, evaluated in its timezone,
+// except on holidays; Override forces it open or closed.
+type TimeCondition struct {
+	ID           int64                       `json:"id" db:"id"`
+	DomainID     int64                       `json:"domain_id" db:"domain_id"`
+	Name         string                      `json:"name" db:"name"`
+	Timezone     string                      `json:"timezone" db:"timezone"`
+	Override     string                      `json:"override,omitempty" db:"override"`
+	ClosedAction string                      `json:"closed_action" db:"closed_action"`
+	ClosedTarget string                      `json:"closed_target,omitempty" db:"closed_target"`
+	Schedule     []*TimeRule                 `json:"schedule"` // None = open all week
+	Holidays     []*Holiday                  `json:"holidays"`
+	Destinations []*TimeConditionDestination `json:"destinations"`
+	CreatedAt    time.Time                   `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time                   `json:"updated_at" db:"updated_at"`
+}
+
+// TimeRule is an open period on one weekday
+type TimeRule struct {
+	Weekday int    `json:"weekday" db:"weekday"`  // 0 = Sunday
+	Start   string `json:"start" db:"start_time"` // HH:MM
+	End     string `json:"end" db:"end_time"`     // HH:MM, exclusive; 24:00 = midnight
+}
+
+// Holiday is an inclusive range of closed dates (YYYY-MM-DD)
+type Holiday struct {
+	Name      string `json:"name" db:"name"`
+	StartDate string `json:"start_date" db:"start_date"`
+	EndDate   string `json:"end_date" db:"end_date"`
+}
+
+// TimeConditionDestination is an extension, queue or IVR menu guarded by a
+// time condition
+type TimeConditionDestination struct {
+	Type   string `json:"type"` // user, queue, ivr
+	Number string `json:"number"`
+}
+
+// TimeConditionRequest represents a request to create or replace a time
+// condition. DomainID is only used on create.
+type TimeConditionRequest struct {
+	DomainID     int64                       `json:"domain_id,omitempty"`
+	Name         string                      `json:"name" validate:"required,min=1,max=100"`
+	Timezone     string                      `json:"timezone"`
+	Override     string                      `json:"override,omitempty" validate:"omitempty,oneof=open closed"`
+	ClosedAction string                      `json:"closed_action" validate:"required,oneof=voicemail announcement destination"`
+	ClosedTarget string                      `json:"closed_target,omitempty"`
+	Schedule     []*TimeRule                 `json:"schedule"`
+	Holidays     []*Holiday                  `json:"holidays"`
+	Destinations []*TimeConditionDestination `json:"destinations"`
+}
+
+// TimeConditionOverride represents a request to force a time condition
+// open or closed, or to follow its schedule again (empty)
+type TimeConditionOverride struct {
+	Override string `json:"override" validate:"omitempty,oneof=open closed"`
+}
+
+// TimeConditionState is the result of evaluating a time condition
+type TimeConditionState struct {
+	Open      bool   `json:"open"`
+	Reason    string `json:"reason"`            // override, holiday, schedule
+	Holiday   string `json:"holiday,omitempty"` // Name of the holiday
+	LocalTime string `json:"local_time"`        // Evaluated time in the condition's timezone
+}
+
+// TimeConditionEvaluation describes what happens to a call to a number at
+// a given time
+type TimeConditionEvaluation struct {
+	DomainID      int64               `json:"domain_id"`
+	Number        string              `json:"number"`
+	Type          string              `json:"type"`
+	At            time.Time           `json:"at"`
+	TimeCondition *TimeCondition      `json:"time_condition,omitempty"`
+	State         *TimeConditionState `json:"state,omitempty"`
+	Action        string              `json:"action"` // route, or the closed action
+	Target        string              `json:"target"`
+}
+
+// TimeActionRoute is the evaluation action of calls routed normally
+const TimeActionRoute = "route"
+
+// Evaluate reports whether the time condition is open at t
+func (tc *TimeCondition) Evaluate(t time.Time) (*TimeConditionState, error) {
+	loc, err := time.LoadLocation(tc.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("load timezone %s: %w", tc.Timezone, err)
+	}
+
+	local := t.In(loc)
+	state := &TimeConditionState{LocalTime: local.Format(time.RFC3339)}
+
+	switch tc.Override {
+	case OverrideOpen:
+		state.Open = true
+		state.Reason = TimeReasonOverride
+		return state, nil
+	case OverrideClosed:
+		state.Reason = TimeReasonOverride
+		return state, nil
+	}
+
+	date := local.Format("2006-01-02")
+	for _, h := range tc.Holidays {
+		if date >= h.StartDate && date <= h.EndDate {
+			state.Reason = TimeReasonHoliday
+			state.Holiday = h.Name
+			return state, nil
+		}
+	}
+
+	state.Reason = TimeReasonSchedule
+	if len(tc.Schedule) == 0 {
+		state.Open = true
+		return state, nil
+	}
+
+	clock := local.Format("15:04")
+	weekday := int(local.Weekday())
+	for _, rule := range tc.Schedule {
+		if rule.Weekday == weekday && clock >= rule.Start && clock < rule.End {
+			state.Open = true
+			break
+		}
+	}
+
+	return state, nil
+}
+
+// ClosedTargetFor returns the closed target for calls to number. Voicemail
+// without a target goes to the mailbox of the number itself.
+func (tc *TimeCondition) ClosedTargetFor(number string) string {
+	if tc.ClosedTarget == "" && tc.ClosedAction == ClosedActionVoicemail {
+		return number
+	}
+	return tc.ClosedTarget
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// TrunkTransports lists the SIP transports a gateway can use
+var TrunkTransports = []string{"udp", "tcp", "tls"}
+
+// DefaultTrunkProfile is the sofia profile trunks are created in unless
+// the request names one
+const DefaultTrunkProfile = "external"
+
+// SIPProfile is a sofia SIP profile rendered into sofia.conf
+type SIPProfile struct {
+	ID                 int64     `json:"id" db:"id"`
+	Name               string    `json:"name" db:"name"`
+	SIPIP              string    `json:"sip_ip,omitempty" db:"sip_ip"` // Empty: $${local_ip_v4}
+	SIPPort            int       `json:"sip_port" db:"sip_port"`
+	RTPIP              string    `json:"rtp_ip,omitempty" db:"rtp_ip"`         // Empty: $${local_ip_v4}
+	ExtSIPIP           string    `json:"ext_sip_ip,omitempty" db:"ext_sip_ip"` // Empty: auto-nat
+	ExtRTPIP           string    `json:"ext_rtp_ip,omitempty" db:"ext_rtp_ip"` // Empty: auto-nat
+	Context            string    `json:"context" db:"context"`
+	InboundCodecPrefs  string    `json:"inbound_codec_prefs" db:"inbound_codec_prefs"`
+	OutboundCodecPrefs string    `json:"outbound_codec_prefs" db:"outbound_codec_prefs"`
+	AuthCalls          bool      `json:"auth_calls" db:"auth_calls"`
+	InboundACL         string    `json:"inbound_acl,omitempty" db:"inbound_acl"` // ACL list name
+	Active             bool      `json:"active" db:"active"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Trunk is a carrier connection, served to FreeSWITCH as a gateway of a
+// sofia profile. The password is never returned by the API.
+type Trunk struct {
+	ID             int64     `json:"id" db:"id"`
+	DomainID       *int64    `json:"domain_id,omitempty" db:"domain_id"` // Nil: shared by all domains
+	Name           string    `json:"name" db:"name"`                     // Gateway name
+	Type           string    `json:"type,omitempty" db:"type"`
+	SIPProfile     string    `json:"sip_profile" db:"sip_profile"`
+	Host           string    `json:"host" db:"host"`
+	Port           int       `json:"port" db:"port"`
+	Transport      string    `json:"transport" db:"transport"`
+	Username       string    `json:"username,omitempty" db:"username"`
+	Password       string    `json:"-" db:"password"`
+	Realm          string    `json:"realm,omitempty" db:"realm"` // Empty: host
+	Register       bool      `json:"register" db:"register"`
+	ExpireSeconds  int       `json:"expire_seconds" db:"expire_seconds"`
+	CodecPrefs     string    `json:"codec_prefs,omitempty" db:"codec_prefs"`
+	CallerIDInFrom bool      `json:"caller_id_in_from" db:"caller_id_in_from"`
+	Prefix         string    `json:"prefix,omitempty" db:"prefix"`
+	Description    string    `json:"description,omitempty" db:"description"`
+	RateDeckID     *int64    `json:"rate_deck_id,omitempty" db:"rate_deck_id"` // Buy deck
+	Active         bool      `json:"active" db:"active"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+
+	// HasPassword reports whether a password is stored
+	HasPassword bool `json:"has_password"`
+}
+
+// CarrierNumber returns the number sent to the carrier for a dialed
+// number. A trunk with a prefix only takes numbers dialed with it, and
+// sends them without the prefix.
+func (t *Trunk) CarrierNumber(dialed string) (string, bool) {
+	if t.Prefix == "" {
+		return dialed, true
+	}
+	if !strings.HasPrefix(dialed, t.Prefix) || len(dialed) == len(t.Prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(dialed, t.Prefix), true
+}
+
+// TrunkCreateRequest represents a request to create a trunk
+type TrunkCreateRequest struct {
+	DomainID       *int64 `json:"domain_id,omitempty"`
+	Name           string `json:"name" validate:"required,max=100"`
+	Type           string `json:"type,omitempty"`
+	SIPProfile     string `json:"sip_profile,omitempty"` // Default: external
+	Host           string `json:"host" validate:"required,max=255"`
+	Port           int    `json:"port,omitempty"`      // Default: 5060, 5061 for tls
+	Transport      string `json:"transport,omitempty"` // Default: udp
+	Username       string `json:"username,omitempty" validate:"omitempty,max=100"`
+	Password       string `json:"password,omitempty" validate:"omitempty,max=255"`
+	Realm          string `json:"realm,omitempty" validate:"omitempty,max=255"`
+	Register       bool   `json:"register"`
+	ExpireSeconds  int    `json:"expire_seconds,omitempty"` // Default: 3600
+	CodecPrefs     string `json:"codec_prefs,omitempty"`
+	CallerIDInFrom bool   `json:"caller_id_in_from"`
+	Prefix         string `json:"prefix,omitempty" validate:"omitempty,max=20"`
+	Description    string `json:"description,omitempty" validate:"omitempty,max=255"`
+	RateDeckID     *int64 `json:"rate_deck_id,omitempty"` // Buy deck
+	Active         bool   `json:"active"`
+}
+
+// TrunkUpdateRequest represents a request to update a trunk. The name of a
+// trunk cannot change, since dial strings refer to it.
+type TrunkUpdateRequest struct {
+	Type           *string `json:"type,omitempty"`
+	SIPProfile     *string `json:"sip_profile,omitempty"`
+	Host           *string `json:"host,omitempty" validate:"omitempty,max=255"`
+	Port           *int    `json:"port,omitempty"`
+	Transport      *string `json:"transport,omitempty"`
+	Username       *string `json:"username,omitempty" validate:"omitempty,max=100"`
+	Password       *string `json:"password,omitempty" validate:"omitempty,max=255"`
+	Realm          *string `json:"realm,omitempty" validate:"omitempty,max=255"`
+	Register       *bool   `json:"register,omitempty"`
+	ExpireSeconds  *int    `json:"expire_seconds,omitempty"`
+	CodecPrefs     *string `json:"codec_prefs,omitempty"`
+	CallerIDInFrom *bool   `json:"caller_id_in_from,omitempty"`
+	Prefix         *string `json:"prefix,omitempty" validate:"omitempty,max=20"`
+	Description    *string `json:"description,omitempty" validate:"omitempty,max=255"`
+	RateDeckID     *int64  `json:"rate_deck_id,omitempty"` // 0 removes the deck
+	Active         *bool   `json:"active,omitempty"`
+}
+
+// GatewayStatus is the registration state of a trunk's gateway on a
+// FreeSWITCH node
+type GatewayStatus struct {
+	Name    string `json:"name"`
+	Profile string `json:"profile"`
+	State   string `json:"state"`  // REGED, NOREG, FAILED, ...
+	Status  string `json:"status"` // UP, DOWN
+}
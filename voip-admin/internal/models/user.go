@@ -0,0 +1,114 @@
+package models
+
+import "time"
+
+// User roles, from least to most privileged
+const (
+	RoleAgent      = "agent"
+	RoleSupervisor = "supervisor"
+	RoleAdmin      = "admin"
+)
+
+// RolePermissions returns the API permissions granted to a role.
+// Agents are further limited to their own extensions, and supervisors to
+// agents of the queues they supervise, by the handlers.
+func RolePermissions(role string) Permissions {
+	switch role {
+	case RoleAdmin:
+		return Permissions{"*": AccessWrite}
+	case RoleSupervisor:
+		return Permissions{
+			"agents":          AccessWrite,
+			"cdr":             AccessRead,
+			"extensions":      AccessRead,
+			"queues":          AccessRead,
+			"recordings":      AccessRead,
+			"time-conditions": AccessRead,
+		}
+	case RoleAgent:
+		return Permissions{"agents": AccessWrite}
+	default:
+		return Permissions{}
+	}
+}
+
+// User represents an admin API user (agent, supervisor or admin)
+type User struct {
+	ID           int64      `json:"id" db:"id"`
+	DomainID     *int64     `json:"domain_id,omitempty" db:"domain_id"` // nil = global admin
+	Username     string     `json:"username" db:"username"`
+	Email        string     `json:"email,omitempty" db:"email"`
+	FullName     string     `json:"full_name,omitempty" db:"full_name"`
+	Role         string     `json:"role" db:"role"` // agent, supervisor, admin
+	PasswordHash string     `json:"-" db:"password_hash"`
+	Active       bool       `json:"active" db:"active"`
+	LastLoginAt  *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Joined fields from domains table
+	Domain string `json:"domain,omitempty" db:"domain"`
+}
+
+// UserCreateRequest represents a request to create a user
+type UserCreateRequest struct {
+	DomainID *int64 `json:"domain_id,omitempty"`
+	Username string `json:"username" validate:"required,min=1,max=100"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email"`
+	FullName string `json:"full_name,omitempty" validate:"omitempty,max=255"`
+	Role     string `json:"role" validate:"required,oneof=agent supervisor admin"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Active   bool   `json:"active"`
+}
+
+// UserUpdateRequest represents a request to update a user
+type UserUpdateRequest struct {
+	Email    *string `json:"email,omitempty" validate:"omitempty,email"`
+	FullName *string `json:"full_name,omitempty" validate:"omitempty,max=255"`
+	Role     *string `json:"role,omitempty" validate:"omitempty,oneof=agent supervisor admin"`
+	Active   *bool   `json:"active,omitempty"`
+}
+
+// UserPasswordUpdate represents a request to set a user's login password
+type UserPasswordUpdate struct {
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// UserQueuesUpdate replaces the set of queues a supervisor supervises
+type UserQueuesUpdate struct {
+	QueueIDs []int64 `json:"queue_ids"`
+}
+
+// UserListResponse represents paginated user list
+type UserListResponse struct {
+	Users   []*User `json:"users"`
+	Total   int64   `json:"total"`
+	Page    int     `json:"page"`
+	PerPage int     `json:"per_page"`
+}
+
+// UserSession represents a login session backing access tokens
+type UserSession struct {
+	ID        int64      `json:"id" db:"id"`
+	UserID    int64      `json:"user_id" db:"user_id"`
+	IPAddress string     `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent string     `json:"user_agent,omitempty" db:"user_agent"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// LoginRequest represents a password login. Domain is empty for global admins.
+type LoginRequest struct {
+	Domain   string `json:"domain,omitempty"`
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// LoginResponse carries a signed access token
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"` // Always "Bearer"
+	ExpiresAt time.Time `json:"expires_at"`
+	User      *User     `json:"user"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// VoicemailFolderInbox is the mod_voicemail folder of received messages
+const VoicemailFolderInbox = "inbox"
+
+// DefaultVoicemailStoragePath is the mod_voicemail storage-dir of
+// configs/freeswitch/autoload_configs/voicemail.conf.xml
+const DefaultVoicemailStoragePath = "/storage/voicemail"
+
+// VoicemailMessage is a message in a mod_voicemail mailbox
+type VoicemailMessage struct {
+	UUID           string     `json:"uuid" db:"uuid"`
+	Extension      string     `json:"extension" db:"username"`
+	Domain         string     `json:"domain" db:"domain"`
+	Folder         string     `json:"folder" db:"in_folder"`
+	CallerIDName   string     `json:"caller_id_name" db:"cid_name"`
+	CallerIDNumber string     `json:"caller_id_number" db:"cid_number"`
+	Duration       int        `json:"duration" db:"message_len"` // seconds
+	Urgent         bool       `json:"urgent"`
+	Read           bool       `json:"read"`
+	ReceivedAt     time.Time  `json:"received_at" db:"created_epoch"`
+	ReadAt         *time.Time `json:"read_at,omitempty" db:"read_epoch"`
+	FilePath       string     `json:"-" db:"file_path"`
+}
+
+// LocalPath returns the audio file of the message if it is inside storage,
+// the voicemail root as mounted on this host
+func (m *VoicemailMessage) LocalPath(storage string) (string, bool) {
+	root := filepath.Clean(storage)
+	file := filepath.Clean(m.FilePath)
+	if !filepath.IsAbs(file) || !strings.HasPrefix(file, root+string(filepath.Separator)) {
+		return "", false
+	}
+	return file, true
+}
+
+// VoicemailMWI is the message waiting state of a mailbox, as shown on the
+// phone's message lamp
+type VoicemailMWI struct {
+	MessagesWaiting bool `json:"messages_waiting"`
+	New             int  `json:"new"`
+	Saved           int  `json:"saved"`
+	NewUrgent       int  `json:"new_urgent"`
+	SavedUrgent     int  `json:"saved_urgent"`
+}
+
+// VoicemailBox is the mailbox of an extension
+type VoicemailBox struct {
+	ExtensionID int64               `json:"extension_id"`
+	Extension   string              `json:"extension"`
+	Domain      string              `json:"domain"`
+	MWI         *VoicemailMWI       `json:"mwi"`
+	Messages    []*VoicemailMessage `json:"messages"`
+}
+
+// VoicemailMessageUpdate represents a request to mark a message read or
+// unread
+type VoicemailMessageUpdate struct {
+	Read bool `json:"read"`
+}
+
+// VoicemailNotification is a new message to be emailed to the mailbox
+// owner
+type VoicemailNotification struct {
+	Message     *VoicemailMessage
+	Email       string
+	DisplayName string
+}
@@ -0,0 +1,165 @@
+// Package numbering classifies dialed numbers by the per-domain numbering
+// plans stored in the database. The plans are compiled once and shared by
+// dialplan routing and CDR classification.
+package numbering
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// compiledPlan is a numbering plan ready for matching
+type compiledPlan struct {
+	ranges   []*models.NumberRange
+	outbound *regexp.Regexp
+}
+
+// Matcher classifies numbers using the numbering plan of their domain.
+// Domains without a plan of their own use the default plan. The plans are
+// reloaded periodically, so changes made on the other node are picked up
+// too, and immediately via Reload after a change through the API.
+type Matcher struct {
+	db       *database.DB
+	interval time.Duration
+	done     chan struct{}
+
+	mu       sync.RWMutex
+	plans    map[string]*compiledPlan // By domain name
+	fallback *compiledPlan
+}
+
+// NewMatcher creates a matcher that knows only the default plan until the
+// first Reload
+func NewMatcher(db *database.DB, interval time.Duration) *Matcher {
+	if interval == 0 {
+		interval = 60 * time.Second
+	}
+
+	fallback, err := compile(&models.NumberingPlan{
+		Ranges:          models.DefaultNumberRanges(),
+		OutboundPattern: models.DefaultOutboundPattern,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("compile default numbering plan: %v", err))
+	}
+
+	return &Matcher{
+		db:       db,
+		interval: interval,
+		done:     make(chan struct{}),
+		plans:    make(map[string]*compiledPlan),
+		fallback: fallback,
+	}
+}
+
+// Start reloads the plans periodically until ctx is cancelled
+func (m *Matcher) Start(ctx context.Context) {
+	log.Printf("[Numbering] Starting with reload_interval=%v", m.interval)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[Numbering] Shutting down...")
+			close(m.done)
+			return
+
+		case <-ticker.C:
+			if err := m.Reload(ctx); err != nil {
+				log.Printf("[Numbering] Error reloading numbering plans: %v", err)
+			}
+		}
+	}
+}
+
+// Stop waits for the reload loop to finish
+func (m *Matcher) Stop() {
+	<-m.done
+}
+
+// Reload loads and compiles the numbering plans of all domains. A plan
+// that fails to compile is logged and its domain falls back to the default
+// plan; the other plans are still replaced.
+func (m *Matcher) Reload(ctx context.Context) error {
+	plans, err := m.db.ListNumberingPlans(ctx)
+	if err != nil {
+		return fmt.Errorf("list numbering plans: %w", err)
+	}
+
+	compiled := make(map[string]*compiledPlan, len(plans))
+	for _, plan := range plans {
+		cp, err := compile(plan)
+		if err != nil {
+			log.Printf("[Numbering] Invalid numbering plan for %s, using default: %v", plan.Domain, err)
+			continue
+		}
+		compiled[plan.Domain] = cp
+	}
+
+	m.mu.Lock()
+	m.plans = compiled
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Classify returns the type of a number dialed in a domain: one of the
+// models.NumberType* constants, or "" if the plan does not cover it
+func (m *Matcher) Classify(domain, number string) string {
+	plan := m.planFor(domain)
+
+	for _, nr := range plan.ranges {
+		if nr.Contains(number) {
+			return nr.Type
+		}
+	}
+
+	if plan.outbound.MatchString(number) {
+		return models.NumberTypeOutbound
+	}
+
+	return ""
+}
+
+// IsUser reports whether number is a user extension number in domain
+func (m *Matcher) IsUser(domain, number string) bool {
+	return m.Classify(domain, number) == models.NumberTypeUser
+}
+
+// IsOutbound reports whether number is an outbound number in domain
+func (m *Matcher) IsOutbound(domain, number string) bool {
+	return m.Classify(domain, number) == models.NumberTypeOutbound
+}
+
+// planFor returns the compiled plan of a domain
+func (m *Matcher) planFor(domain string) *compiledPlan {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if plan, ok := m.plans[domain]; ok {
+		return plan
+	}
+	return m.fallback
+}
+
+// compile prepares a plan for matching
+func compile(plan *models.NumberingPlan) (*compiledPlan, error) {
+	outbound, err := regexp.Compile(plan.OutboundPattern)
+	if err != nil {
+		return nil, fmt.Errorf("compile outbound pattern: %w", err)
+	}
+
+	return &compiledPlan{
+		ranges:   plan.Ranges,
+		outbound: outbound,
+	}, nil
+}
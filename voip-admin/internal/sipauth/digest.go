@@ -0,0 +1,27 @@
+// Package sipauth handles SIP digest credentials: HA1 computation, the
+// password policy for extensions and password generation.
+package sipauth
+
+import (
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// Digest computes the RFC 2617 HA1 digests used by Kamailio and the
+// FreeSWITCH directory, with the domain as realm:
+// ha1 = MD5(extension:domain:password), ha1b = MD5(extension@domain:domain:password)
+func Digest(extension, domain, password string) (ha1, ha1b string) {
+	sum := md5.Sum([]byte(extension + ":" + domain + ":" + password))
+	sumB := md5.Sum([]byte(extension + "@" + domain + ":" + domain + ":" + password))
+	return hex.EncodeToString(sum[:]), hex.EncodeToString(sumB[:])
+}
+
+// Verify reports whether password matches the stored HA1 of an extension
+func Verify(extension, domain, password, storedHA1 string) bool {
+	if storedHA1 == "" {
+		return false
+	}
+	ha1, _ := Digest(extension, domain, password)
+	return subtle.ConstantTimeCompare([]byte(ha1), []byte(storedHA1)) == 1
+}
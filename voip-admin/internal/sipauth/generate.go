@@ -0,0 +1,35 @@
+package sipauth
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// generateAlphabet avoids look-alike characters (0/O, 1/l/I) and characters
+// that need escaping in SIP headers, XML or shell commands
+const generateAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789-_.!~*+"
+
+// Generate returns a random password of the configured length that
+// passes the policy for the given extension and domain
+func (p *Policy) Generate(extension, domain string) (string, error) {
+	max := big.NewInt(int64(len(generateAlphabet)))
+
+	for attempt := 0; attempt < 10; attempt++ {
+		buf := make([]byte, p.GenerateLength)
+		for i := range buf {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", fmt.Errorf("generate password: %w", err)
+			}
+			buf[i] = generateAlphabet[n.Int64()]
+		}
+
+		password := string(buf)
+		if p.Check(password, extension, domain) == nil {
+			return password, nil
+		}
+	}
+
+	return "", fmt.Errorf("generate password: no candidate passed the policy")
+}
@@ -0,0 +1,167 @@
+package sipauth
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// MaxPasswordLength is the longest SIP password accepted
+const MaxPasswordLength = 128
+
+//go:embed common-passwords.txt
+var bundledCommonPasswords []byte
+
+// Policy is the password policy for extension SIP passwords
+type Policy struct {
+	MinLength           int     `yaml:"min_length"`
+	MinEntropyBits      float64 `yaml:"min_entropy_bits"`
+	GenerateLength      int     `yaml:"generate_length"`
+	CommonPasswordsFile string  `yaml:"common_passwords_file"` // Extra list, one password per line
+
+	common map[string]bool
+}
+
+// DefaultPolicy returns the policy used when none is configured
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MinLength:      10,
+		MinEntropyBits: 50,
+		GenerateLength: 20,
+	}
+}
+
+// Load fills unset fields with defaults and loads the common-password lists
+func (p *Policy) Load() error {
+	defaults := DefaultPolicy()
+	if p.MinLength == 0 {
+		p.MinLength = defaults.MinLength
+	}
+	if p.MinEntropyBits == 0 {
+		p.MinEntropyBits = defaults.MinEntropyBits
+	}
+	if p.GenerateLength == 0 {
+		p.GenerateLength = defaults.GenerateLength
+	}
+	if p.MinLength > MaxPasswordLength || p.GenerateLength > MaxPasswordLength {
+		return fmt.Errorf("password lengths must be at most %d", MaxPasswordLength)
+	}
+	if p.GenerateLength < p.MinLength {
+		return fmt.Errorf("generate_length (%d) must be at least min_length (%d)", p.GenerateLength, p.MinLength)
+	}
+
+	p.common = make(map[string]bool)
+	addCommonPasswords(p.common, bundledCommonPasswords)
+
+	if p.CommonPasswordsFile != "" {
+		data, err := os.ReadFile(p.CommonPasswordsFile)
+		if err != nil {
+			return fmt.Errorf("read common passwords file: %w", err)
+		}
+		addCommonPasswords(p.common, data)
+	}
+
+	return nil
+}
+
+// Check validates a SIP password for an extension. The error message is
+// safe to return to the client.
+func (p *Policy) Check(password, extension, domain string) error {
+	if len(password) < p.MinLength || len(password) > MaxPasswordLength {
+		return fmt.Errorf("password must be %d-%d characters", p.MinLength, MaxPasswordLength)
+	}
+
+	for _, r := range password {
+		if r < 0x21 || r > 0x7e {
+			return errors.New("password may only contain printable ASCII characters without spaces")
+		}
+	}
+
+	lower := strings.ToLower(password)
+	if extension != "" && strings.Contains(lower, strings.ToLower(extension)) {
+		return errors.New("password must not contain the extension number")
+	}
+	if domain != "" {
+		if strings.Contains(lower, strings.ToLower(domain)) {
+			return errors.New("password must not contain the domain")
+		}
+		// Also reject the first label, e.g. "acme" of acme.example.com
+		if label := strings.ToLower(strings.SplitN(domain, ".", 2)[0]); len(label) >= 4 && strings.Contains(lower, label) {
+			return errors.New("password must not contain the domain")
+		}
+	}
+
+	if p.common[lower] {
+		return errors.New("password is too common")
+	}
+
+	if bits := Entropy(password); bits < p.MinEntropyBits {
+		return fmt.Errorf("password is too weak (%.0f bits of entropy, need %.0f); use a longer password or more character types", bits, p.MinEntropyBits)
+	}
+
+	return nil
+}
+
+// Entropy estimates the strength of a password in bits as its length times
+// log2 of the size of the character classes it uses. Repeated characters
+// only count once, so "aaaaaaaaaa" scores as a single character.
+func Entropy(password string) float64 {
+	var lower, upper, digit, symbol bool
+	seen := make(map[rune]bool)
+	unique := 0
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+		if !seen[r] {
+			seen[r] = true
+			unique++
+		}
+	}
+
+	pool := 0
+	if lower {
+		pool += 26
+	}
+	if upper {
+		pool += 26
+	}
+	if digit {
+		pool += 10
+	}
+	if symbol {
+		pool += 32
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	return float64(unique) * math.Log2(float64(pool))
+}
+
+// addCommonPasswords adds one lower-cased password per line; blank lines
+// and lines starting with # are skipped
+func addCommonPasswords(common map[string]bool, data []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		common[strings.ToLower(line)] = true
+	}
+}
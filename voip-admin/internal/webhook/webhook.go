@@ -0,0 +1,83 @@
+// Package webhook posts JSON notifications to an HTTP endpoint
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the body, keyed with the
+// configured secret, so receivers can verify the sender
+const SignatureHeader = "X-Signature-SHA256"
+
+// Config holds webhook configuration
+type Config struct {
+	URL     string
+	Secret  string // Signs the body when set
+	Timeout time.Duration
+}
+
+// Notifier posts events to one webhook URL
+type Notifier struct {
+	config *Config
+	http   *http.Client
+}
+
+// New creates a new webhook notifier
+func New(cfg *Config) *Notifier {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	return &Notifier{
+		config: cfg,
+		http:   &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Event is the body of a webhook request
+type Event struct {
+	Type       string      `json:"type"` // e.g. fraud.alert
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Send posts an event. Any status other than 2xx is an error.
+func (n *Notifier) Send(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.config.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.config.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("post %s: %w", event.Type, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("post %s: HTTP %d", event.Type, resp.StatusCode)
+	}
+
+	return nil
+}
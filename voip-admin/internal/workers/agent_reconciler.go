@@ -0,0 +1,131 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/esl"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// AgentReconciler periodically compares agent states in the database with
+// mod_callcenter and repairs drift. The database is authoritative for the
+// availability state (Available, On Break, Logged Out); FreeSWITCH is
+// authoritative for the call status (Waiting, Receiving, In a queue call).
+type AgentReconciler struct {
+	db       *database.DB
+	esl      *esl.Client
+	interval time.Duration
+	done     chan struct{}
+}
+
+// NewAgentReconciler creates a new agent reconciler
+func NewAgentReconciler(db *database.DB, eslClient *esl.Client, interval time.Duration) *AgentReconciler {
+	if interval == 0 {
+		interval = 60 * time.Second
+	}
+
+	return &AgentReconciler{
+		db:       db,
+		esl:      eslClient,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins the reconciliation loop
+func (r *AgentReconciler) Start(ctx context.Context) {
+	log.Printf("[AgentReconciler] Starting with interval=%v", r.interval)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[AgentReconciler] Shutting down...")
+			close(r.done)
+			return
+
+		case <-ticker.C:
+			if err := r.reconcile(ctx); err != nil {
+				log.Printf("[AgentReconciler] Error during reconciliation: %v", err)
+			}
+		}
+	}
+}
+
+// reconcile runs a single reconciliation pass
+func (r *AgentReconciler) reconcile(ctx context.Context) error {
+	states, err := r.db.ListAgentSyncStates(ctx)
+	if err != nil {
+		return fmt.Errorf("list agent states: %w", err)
+	}
+
+	if len(states) == 0 {
+		return nil
+	}
+
+	fsAgents, err := r.esl.ListAgents(ctx)
+	if err != nil {
+		return fmt.Errorf("list callcenter agents: %w", err)
+	}
+
+	byName := make(map[string]*esl.CallCenterAgent, len(fsAgents))
+	for _, agent := range fsAgents {
+		byName[agent.Name] = agent
+	}
+
+	pushed := 0
+	for _, s := range states {
+		name := models.AgentName(s.Extension, s.Domain)
+
+		fsAgent, ok := byName[name]
+		if !ok {
+			log.Printf("[AgentReconciler] Agent %s not loaded in mod_callcenter", name)
+			continue
+		}
+
+		if fsAgent.Status != s.State {
+			log.Printf("[AgentReconciler] Drift for %s: freeswitch=%q database=%q, pushing database state",
+				name, fsAgent.Status, s.State)
+
+			if err := r.esl.SetAgentStatus(ctx, name, s.State); err != nil {
+				log.Printf("[AgentReconciler] Failed to push status for %s: %v", name, err)
+				continue
+			}
+			pushed++
+		}
+
+		if fsAgent.State != s.Status && isTrackedAgentStatus(fsAgent.State) {
+			if err := r.db.UpdateAgentStatus(ctx, s.ExtensionID, fsAgent.State); err != nil {
+				log.Printf("[AgentReconciler] Failed to update status for %s: %v", name, err)
+			}
+		}
+	}
+
+	if pushed > 0 {
+		log.Printf("[AgentReconciler] Reconciled %d agents", pushed)
+	}
+
+	return nil
+}
+
+// isTrackedAgentStatus reports whether a mod_callcenter agent state can be
+// stored in voip.queue_agents.status (see chk_queue_agents_status)
+func isTrackedAgentStatus(status string) bool {
+	switch status {
+	case "Waiting", "Receiving", "In a queue call":
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop signals the reconciler to stop
+func (r *AgentReconciler) Stop() {
+	<-r.done
+}
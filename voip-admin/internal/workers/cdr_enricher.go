@@ -4,21 +4,23 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"regexp"
 
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/numbering"
 )
 
 // CDREnricher enriches CDR records with business logic
 type CDREnricher struct {
-	db *database.DB
+	db      *database.DB
+	numbers *numbering.Matcher
 }
 
 // NewCDREnricher creates a new CDR enricher
-func NewCDREnricher(db *database.DB) *CDREnricher {
+func NewCDREnricher(db *database.DB, numbers *numbering.Matcher) *CDREnricher {
 	return &CDREnricher{
-		db: db,
+		db:      db,
+		numbers: numbers,
 	}
 }
 
@@ -26,7 +28,7 @@ func NewCDREnricher(db *database.DB) *CDREnricher {
 func (e *CDREnricher) Enrich(ctx context.Context, cdr *models.CDR) error {
 	// 1. Determine call type if not already set
 	if cdr.CallType == "" {
-		cdr.CallType = e.determineCallType(cdr.DestinationNumber)
+		cdr.CallType = e.determineCallType(cdr.Domain, cdr.DestinationNumber)
 	}
 
 	// 2. Map queue if call type is queue
@@ -39,7 +41,7 @@ func (e *CDREnricher) Enrich(ctx context.Context, cdr *models.CDR) error {
 
 	// 3. Validate and normalize direction
 	if cdr.Direction == "" {
-		cdr.Direction = e.determineDirection(cdr.CallerIDNumber, cdr.DestinationNumber)
+		cdr.Direction = e.determineDirection(cdr.Domain, cdr.CallerIDNumber, cdr.DestinationNumber)
 	}
 
 	// 4. Enrich with extension information
@@ -51,16 +53,16 @@ func (e *CDREnricher) Enrich(ctx context.Context, cdr *models.CDR) error {
 	return nil
 }
 
-// determineCallType determines the call type based on destination number pattern
-func (e *CDREnricher) determineCallType(destNumber string) string {
-	switch {
-	case isQueuePattern(destNumber):
+// determineCallType determines the call type from the domain's numbering plan
+func (e *CDREnricher) determineCallType(domain, destNumber string) string {
+	switch e.numbers.Classify(domain, destNumber) {
+	case models.NumberTypeQueue:
 		return "queue"
-	case isIVRPattern(destNumber):
+	case models.NumberTypeIVR:
 		return "ivr"
-	case isConferencePattern(destNumber):
+	case models.NumberTypeConference:
 		return "conference"
-	case isExtensionPattern(destNumber):
+	case models.NumberTypeUser:
 		return "direct"
 	default:
 		return "other"
@@ -68,19 +70,22 @@ func (e *CDREnricher) determineCallType(destNumber string) string {
 }
 
 // determineDirection determines call direction
-func (e *CDREnricher) determineDirection(callerNumber, destNumber string) string {
-	// If both are extensions (4 digits), it's internal
-	if isExtensionPattern(callerNumber) && isExtensionPattern(destNumber) {
+func (e *CDREnricher) determineDirection(domain, callerNumber, destNumber string) string {
+	callerIsUser := e.numbers.IsUser(domain, callerNumber)
+	destIsUser := e.numbers.IsUser(domain, destNumber)
+
+	// If both are extensions, it's internal
+	if callerIsUser && destIsUser {
 		return "internal"
 	}
 
-	// If destination is 10+ digits or starts with +, it's outbound
-	if len(destNumber) >= 10 || regexp.MustCompile(`^\+`).MatchString(destNumber) {
+	// If destination matches the outbound pattern, it's outbound
+	if e.numbers.IsOutbound(domain, destNumber) {
 		return "outbound"
 	}
 
-	// If caller is external (not 4 digits) and dest is extension, it's inbound
-	if !isExtensionPattern(callerNumber) && isExtensionPattern(destNumber) {
+	// If caller is external and dest is extension, it's inbound
+	if !callerIsUser && destIsUser {
 		return "inbound"
 	}
 
@@ -108,7 +113,7 @@ func (e *CDREnricher) enrichQueueInfo(ctx context.Context, cdr *models.CDR) erro
 // enrichExtensionInfo enriches extension information
 func (e *CDREnricher) enrichExtensionInfo(ctx context.Context, cdr *models.CDR) error {
 	// Try to get destination extension info
-	if isExtensionPattern(cdr.DestinationNumber) && cdr.Domain != "" {
+	if cdr.Domain != "" && e.numbers.IsUser(cdr.Domain, cdr.DestinationNumber) {
 		ext, err := e.db.GetExtension(ctx, cdr.DestinationNumber, cdr.Domain)
 		if err == nil {
 			// Store additional extension info if needed
@@ -119,24 +124,3 @@ func (e *CDREnricher) enrichExtensionInfo(ctx context.Context, cdr *models.CDR)
 
 	return nil
 }
-
-// Pattern matching helpers
-func isQueuePattern(number string) bool {
-	match, _ := regexp.MatchString(`^8\d{3}$`, number)
-	return match
-}
-
-func isIVRPattern(number string) bool {
-	match, _ := regexp.MatchString(`^9\d{3}$`, number)
-	return match
-}
-
-func isConferencePattern(number string) bool {
-	match, _ := regexp.MatchString(`^3\d{3}$`, number)
-	return match
-}
-
-func isExtensionPattern(number string) bool {
-	match, _ := regexp.MatchString(`^[1-79]\d{3}$`, number)
-	return match
-}
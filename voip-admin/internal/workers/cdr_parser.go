@@ -73,6 +73,9 @@ type Variables struct {
 	SIPToUser             string `xml:"sip_to_user"`
 	SIPCallID             string `xml:"sip_call_id"`
 	SIPUserAgent          string `xml:"sip_user_agent"`
+	SIPGatewayName        string `xml:"sip_gateway_name"`
+	BridgeChannel         string `xml:"bridge_channel"` // sofia/gateway/<name>/<number> for trunk calls
+	ForwardedBy           string `xml:"forwarded_by"`   // Set by the outbound dialplan
 
 	// Media codec
 	ReadCodec             string `xml:"read_codec"`
@@ -96,6 +99,7 @@ type Variables struct {
 	// Recording
 	RecordingFile         string `xml:"recording_file"`
 	RecordSeconds         string `xml:"record_seconds"`
+	RecordingPolicyID     string `xml:"recording_policy_id"`
 
 	// Queue specific (if present)
 	CCQueue               string `xml:"cc_queue"`
@@ -192,6 +196,12 @@ func ParseCDRXML(xmlData string) (*models.CDR, error) {
 	if userAgent := fsCDR.Variables.SIPUserAgent; userAgent != "" {
 		cdr.UserAgent = &userAgent
 	}
+	if trunk := gatewayName(fsCDR.Variables); trunk != "" {
+		cdr.Trunk = &trunk
+	}
+	if forwardedBy := fsCDR.Variables.ForwardedBy; forwardedBy != "" {
+		cdr.ForwardedBy = &forwardedBy
+	}
 
 	// Media codec
 	if readCodec := fsCDR.Variables.ReadCodec; readCodec != "" {
@@ -215,6 +225,11 @@ func ParseCDRXML(xmlData string) (*models.CDR, error) {
 				cdr.RecordDuration = &val
 			}
 		}
+		if policyID := fsCDR.Variables.RecordingPolicyID; policyID != "" {
+			if val, err := strconv.ParseInt(policyID, 10, 64); err == nil {
+				cdr.RecordingPolicyID = &val
+			}
+		}
 	}
 
 	// Queue information
@@ -339,6 +354,18 @@ func parseQueueInfo(cdr *models.CDR, vars *Variables) {
 	}
 }
 
+// gatewayName returns the name of the trunk gateway a call was bridged to
+func gatewayName(vars Variables) string {
+	if vars.SIPGatewayName != "" {
+		return vars.SIPGatewayName
+	}
+	if rest, ok := strings.CutPrefix(vars.BridgeChannel, "sofia/gateway/"); ok {
+		name, _, _ := strings.Cut(rest, "/")
+		return name
+	}
+	return ""
+}
+
 // determineDirection determines call direction
 func determineDirection(fsDirection, destNumber string) string {
 	// Check FreeSWITCH direction variable first
@@ -4,9 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/numbering"
 )
 
 // CDRProcessor processes CDRs from the queue asynchronously
@@ -15,13 +20,15 @@ type CDRProcessor struct {
 	batchSize       int
 	processingInterval time.Duration
 	enricher        *CDREnricher
+	rater           *CDRRater
 	done            chan struct{}
 }
 
 // CDRProcessorConfig holds configuration for the CDR processor
 type CDRProcessorConfig struct {
-	BatchSize          int           // Number of CDRs to process per batch
-	ProcessingInterval time.Duration // How often to process batches
+	BatchSize          int                // Number of CDRs to process per batch
+	ProcessingInterval time.Duration      // How often to process batches
+	Numbers            *numbering.Matcher // Classifies numbers for call types
 }
 
 // NewCDRProcessor creates a new CDR processor
@@ -37,7 +44,8 @@ func NewCDRProcessor(db *database.DB, cfg *CDRProcessorConfig) *CDRProcessor {
 		db:              db,
 		batchSize:       cfg.BatchSize,
 		processingInterval: cfg.ProcessingInterval,
-		enricher:        NewCDREnricher(db),
+		enricher:        NewCDREnricher(db, cfg.Numbers),
+		rater:           NewCDRRater(db, cfg.Numbers),
 		done:            make(chan struct{}),
 	}
 }
@@ -129,12 +137,53 @@ func (p *CDRProcessor) processOne(ctx context.Context, queueID int64, uuid, xmlD
 		return fmt.Errorf("insert CDR: %w", err)
 	}
 
+	// The CDR is kept unrated if rating fails
+	if err := p.rater.Rate(ctx, cdr); err != nil {
+		log.Printf("[CDRProcessor] Failed to rate CDR %s: %v", uuid, err)
+	}
+
+	// The CDR is kept even if its recording cannot be registered
+	if cdr.RecordFile != nil {
+		if err := p.registerRecording(ctx, cdr); err != nil {
+			log.Printf("[CDRProcessor] Failed to register recording of %s: %v", uuid, err)
+		}
+	}
+
 	log.Printf("[CDRProcessor] Successfully processed CDR %s (caller=%s, dest=%s, duration=%ds)",
 		uuid, cdr.CallerIDNumber, cdr.DestinationNumber, cdr.Duration)
 
 	return nil
 }
 
+// registerRecording registers the recorded file of a CDR in voip.recordings
+func (p *CDRProcessor) registerRecording(ctx context.Context, cdr *models.CDR) error {
+	rec := &models.Recording{
+		CallUUID:          cdr.UUID,
+		CDRID:             &cdr.ID,
+		RecordingPolicyID: cdr.RecordingPolicyID,
+		FilePath:          *cdr.RecordFile,
+		Duration:          cdr.RecordDuration,
+		Format:            strings.TrimPrefix(path.Ext(*cdr.RecordFile), "."),
+		StartTime:         cdr.AnswerStamp,
+		EndTime:           &cdr.EndStamp,
+	}
+	if rec.Format == "" {
+		rec.Format = "wav"
+	}
+	if rec.StartTime == nil {
+		rec.StartTime = &cdr.StartStamp
+	}
+
+	// The size is only known when the recordings storage is shared with
+	// FreeSWITCH
+	if info, err := os.Stat(rec.FilePath); err == nil {
+		size := info.Size()
+		rec.FileSize = &size
+	}
+
+	return p.db.InsertRecording(ctx, rec, cdr.Domain)
+}
+
 // Stop signals the processor to stop
 func (p *CDRProcessor) Stop() {
 	<-p.done
@@ -188,7 +237,7 @@ func (w *CleanupWorker) Start(ctx context.Context) {
 	}
 }
 
-// cleanup removes old processed CDR queue entries
+// cleanup removes old processed CDR queue entries and expired user sessions
 func (w *CleanupWorker) cleanup(ctx context.Context) error {
 	deleted, err := w.db.CleanupOldCDRQueue(ctx, w.retentionDays)
 	if err != nil {
@@ -199,6 +248,15 @@ func (w *CleanupWorker) cleanup(ctx context.Context) error {
 		log.Printf("[CleanupWorker] Cleaned up %d old CDR queue entries", deleted)
 	}
 
+	sessions, err := w.db.CleanupExpiredSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("cleanup expired sessions: %w", err)
+	}
+
+	if sessions > 0 {
+		log.Printf("[CleanupWorker] Cleaned up %d expired user sessions", sessions)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,66 @@
+package workers
+
+import (
+	"context"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/numbering"
+)
+
+// CDRRater prices outbound calls with the rate decks of their domain (sell)
+// and trunk (buy)
+type CDRRater struct {
+	db      *database.DB
+	numbers *numbering.Matcher
+}
+
+// NewCDRRater creates a new CDR rater
+func NewCDRRater(db *database.DB, numbers *numbering.Matcher) *CDRRater {
+	return &CDRRater{
+		db:      db,
+		numbers: numbers,
+	}
+}
+
+// Rate stores the costs of a stored CDR. Calls that were not placed by an
+// extension to an outside number are left unrated.
+func (r *CDRRater) Rate(ctx context.Context, cdr *models.CDR) error {
+	if !r.isOutbound(cdr) {
+		return nil
+	}
+
+	prefixes := models.RatePrefixes(r.carrierNumber(ctx, cdr))
+	if len(prefixes) == 0 {
+		return nil
+	}
+
+	return r.db.RateCDR(ctx, cdr, prefixes)
+}
+
+// carrierNumber returns the number the call was sent to the carrier with:
+// the dialed number without the prefix of its trunk. Calls whose trunk is
+// unknown are rated as dialed.
+func (r *CDRRater) carrierNumber(ctx context.Context, cdr *models.CDR) string {
+	if cdr.Trunk == nil || *cdr.Trunk == "" {
+		return cdr.DestinationNumber
+	}
+
+	trunk, err := r.db.GetTrunkByName(ctx, *cdr.Trunk)
+	if err != nil {
+		return cdr.DestinationNumber
+	}
+
+	if number, ok := trunk.CarrierNumber(cdr.DestinationNumber); ok {
+		return number
+	}
+	return cdr.DestinationNumber
+}
+
+// isOutbound reports whether a call was placed by an extension to an
+// outside number. FreeSWITCH records the A-leg of a phone as inbound, so
+// the direction of the CDR cannot be used. Forwarded calls count as
+// placed by the forwarding extension.
+func (r *CDRRater) isOutbound(cdr *models.CDR) bool {
+	return r.numbers.IsUser(cdr.Domain, cdr.ChargedUser()) && r.numbers.IsOutbound(cdr.Domain, cdr.DestinationNumber)
+}
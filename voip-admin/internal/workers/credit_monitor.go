@@ -0,0 +1,141 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/webhook"
+)
+
+// CreditMonitorConfig holds credit monitor configuration
+type CreditMonitorConfig struct {
+	Interval     time.Duration     // How often spend is checked against the monthly limits
+	AlertPercent int               // Share of a limit that raises the first alert
+	Webhook      *webhook.Notifier // nil disables notifications
+}
+
+// CreditMonitor alerts when the monthly spending limit of a domain or
+// extension is AlertPercent used, and again when it is reached. Each
+// threshold alerts once per month; both nodes may run the monitor.
+type CreditMonitor struct {
+	db     *database.DB
+	config *CreditMonitorConfig
+	done   chan struct{}
+}
+
+// NewCreditMonitor creates a new credit monitor
+func NewCreditMonitor(db *database.DB, cfg *CreditMonitorConfig) (*CreditMonitor, error) {
+	if cfg.Interval == 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.AlertPercent == 0 {
+		cfg.AlertPercent = models.DefaultCreditAlertPercent
+	}
+	if cfg.AlertPercent < 1 || cfg.AlertPercent > 100 {
+		return nil, fmt.Errorf("alert_percent must be between 1 and 100")
+	}
+
+	return &CreditMonitor{
+		db:     db,
+		config: cfg,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Start begins the monitoring loop
+func (m *CreditMonitor) Start(ctx context.Context) {
+	log.Printf("[CreditMonitor] Starting with interval=%v, alert_percent=%d",
+		m.config.Interval, m.config.AlertPercent)
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[CreditMonitor] Shutting down...")
+			close(m.done)
+			return
+
+		case <-ticker.C:
+			if err := m.check(ctx); err != nil {
+				log.Printf("[CreditMonitor] Error checking credit: %v", err)
+			}
+		}
+	}
+}
+
+// Stop waits for the monitoring loop to stop
+func (m *CreditMonitor) Stop() {
+	<-m.done
+}
+
+// check raises an alert for each limit past a threshold this month
+func (m *CreditMonitor) check(ctx context.Context) error {
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	limits, err := m.db.ListCreditUsage(ctx, month)
+	if err != nil {
+		return fmt.Errorf("list credit usage: %w", err)
+	}
+
+	for _, limit := range limits {
+		// Only the highest threshold reached alerts
+		for _, threshold := range []int{100, m.config.AlertPercent} {
+			if limit.Spent < *limit.MonthlyLimit*float64(threshold)/100 {
+				continue
+			}
+			m.raise(ctx, limit, threshold)
+			break
+		}
+	}
+
+	return nil
+}
+
+// raise stores an alert and sends the webhook, unless the limit already
+// alerted at the threshold this month
+func (m *CreditMonitor) raise(ctx context.Context, limit *models.CreditLimit, threshold int) {
+	alert := &models.CreditAlert{
+		DomainID:     limit.DomainID,
+		Domain:       limit.Domain,
+		ExtensionID:  limit.ExtensionID,
+		Extension:    limit.Extension,
+		Month:        limit.Month,
+		Threshold:    threshold,
+		Spent:        limit.Spent,
+		MonthlyLimit: *limit.MonthlyLimit,
+	}
+
+	who := limit.Domain
+	if limit.Extension != "" {
+		who = limit.Extension + "@" + limit.Domain
+	}
+
+	created, err := m.db.CreateCreditAlert(ctx, alert)
+	if err != nil {
+		log.Printf("[CreditMonitor] Failed to store %d%% alert for %s: %v", threshold, who, err)
+		return
+	}
+	if !created {
+		return
+	}
+	log.Printf("[CreditMonitor] Alert %d: %s used %d%% of its monthly limit (%.2f of %.2f %s)",
+		alert.ID, who, threshold, alert.Spent, alert.MonthlyLimit, limit.Currency)
+
+	if m.config.Webhook != nil {
+		err := m.config.Webhook.Send(ctx, &webhook.Event{
+			Type:       "credit.alert",
+			OccurredAt: alert.CreatedAt,
+			Data:       alert,
+		})
+		if err != nil {
+			log.Printf("[CreditMonitor] Failed to send webhook for alert %d: %v", alert.ID, err)
+		}
+	}
+}
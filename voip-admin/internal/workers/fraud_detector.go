@@ -0,0 +1,473 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/esl"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/kamailio"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/numbering"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/webhook"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/xmlcurl"
+)
+
+// FraudDetectorConfig holds fraud detection configuration
+type FraudDetectorConfig struct {
+	Interval  time.Duration // How often CDRs and live channels are checked
+	Window    time.Duration // Period the spike and burst thresholds count over
+	BatchSize int           // CDRs scanned per query
+
+	// Rules to run (models.FraudRule*); empty runs all of them
+	Rules []string
+	// Rules whose alerts deactivate the extension
+	AutoSuspend []string
+
+	InternationalPrefixes  []string // Access prefixes of international calls, e.g. 00 and +
+	InternationalThreshold int      // International calls per extension in the window
+	HighCostPrefixes       []string // Any call to these raises an alert
+
+	OffHoursStart     string // HH:MM
+	OffHoursEnd       string // HH:MM
+	OffHoursWeekends  bool   // Saturdays and Sundays are off-hours all day
+	OffHoursTimezone  string
+	OffHoursThreshold int // Outbound calls per extension in off-hours in the window
+
+	Numbers  *numbering.Matcher
+	Cache    xmlcurl.Cache     // Directory cache to drop suspended extensions from
+	ESL      *esl.Client       // Live channels and hangup of suspended extensions
+	Kamailio *kamailio.Client  // Unregisters suspended extensions
+	Webhook  *webhook.Notifier // nil disables notifications
+}
+
+// FraudDetector watches outbound calls for toll fraud. Processed CDRs are
+// scanned once across nodes through a shared checkpoint; live channels of
+// the local FreeSWITCH are checked against max_concurrent on every run.
+type FraudDetector struct {
+	db          *database.DB
+	config      *FraudDetectorConfig
+	offHours    *offHours
+	rules       map[string]bool
+	autoSuspend map[string]bool
+	done        chan struct{}
+}
+
+// NewFraudDetector creates a new fraud detector
+func NewFraudDetector(db *database.DB, cfg *FraudDetectorConfig) (*FraudDetector, error) {
+	if cfg.Interval == 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.Window == 0 {
+		cfg.Window = time.Hour
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 1000
+	}
+	if len(cfg.Rules) == 0 {
+		cfg.Rules = models.FraudRules
+	}
+	if len(cfg.InternationalPrefixes) == 0 {
+		cfg.InternationalPrefixes = []string{"+", "00"}
+	}
+	if cfg.InternationalThreshold == 0 {
+		cfg.InternationalThreshold = 10
+	}
+	if cfg.OffHoursStart == "" {
+		cfg.OffHoursStart = "20:00"
+	}
+	if cfg.OffHoursEnd == "" {
+		cfg.OffHoursEnd = "07:00"
+	}
+	if cfg.OffHoursTimezone == "" {
+		cfg.OffHoursTimezone = "UTC"
+	}
+	if cfg.OffHoursThreshold == 0 {
+		cfg.OffHoursThreshold = 5
+	}
+
+	hours, err := parseOffHours(cfg.OffHoursStart, cfg.OffHoursEnd, cfg.OffHoursWeekends, cfg.OffHoursTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := fraudRuleSet(cfg.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("rules: %w", err)
+	}
+	autoSuspend, err := fraudRuleSet(cfg.AutoSuspend)
+	if err != nil {
+		return nil, fmt.Errorf("auto_suspend: %w", err)
+	}
+
+	return &FraudDetector{
+		db:          db,
+		config:      cfg,
+		offHours:    hours,
+		rules:       rules,
+		autoSuspend: autoSuspend,
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// fraudRuleSet checks rule names and returns them as a set
+func fraudRuleSet(names []string) (map[string]bool, error) {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		valid := false
+		for _, rule := range models.FraudRules {
+			if name == rule {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown fraud rule %q", name)
+		}
+		set[name] = true
+	}
+	return set, nil
+}
+
+// Start begins the detection loop
+func (d *FraudDetector) Start(ctx context.Context) {
+	log.Printf("[FraudDetector] Starting with interval=%v, window=%v, rules=%s, auto-suspend=%s",
+		d.config.Interval, d.config.Window, strings.Join(d.config.Rules, ","), strings.Join(d.config.AutoSuspend, ","))
+
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[FraudDetector] Shutting down...")
+			close(d.done)
+			return
+
+		case <-ticker.C:
+			if d.rules[models.FraudRuleConcurrentCalls] {
+				if err := d.checkChannels(ctx); err != nil {
+					log.Printf("[FraudDetector] Error checking live channels: %v", err)
+				}
+			}
+			if err := d.scanCDRs(ctx); err != nil {
+				log.Printf("[FraudDetector] Error scanning CDRs: %v", err)
+			}
+		}
+	}
+}
+
+// Stop waits for the detection loop to stop
+func (d *FraudDetector) Stop() {
+	<-d.done
+}
+
+// isOutbound reports whether a call was placed by an extension to an
+// outside number
+func (d *FraudDetector) isOutbound(call *models.PlacedCall) bool {
+	return d.config.Numbers.IsUser(call.Domain, call.Extension) &&
+		d.config.Numbers.IsOutbound(call.Domain, call.Destination)
+}
+
+// checkChannels counts the live outbound calls of each extension on the
+// local FreeSWITCH
+func (d *FraudDetector) checkChannels(ctx context.Context) error {
+	channels, err := d.config.ESL.ListChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The phone's leg is inbound to FreeSWITCH and carries the caller's
+	// presence_id
+	live := make(map[string][]string)
+	for _, ch := range channels {
+		user, domain, ok := strings.Cut(ch.PresenceID, "@")
+		if ch.Direction != "inbound" || !ok {
+			continue
+		}
+		call := &models.PlacedCall{Domain: domain, Extension: user, Destination: ch.Destination}
+		if d.isOutbound(call) {
+			live[ch.PresenceID] = append(live[ch.PresenceID], ch.Destination)
+		}
+	}
+
+	for presenceID, destinations := range live {
+		user, domain, _ := strings.Cut(presenceID, "@")
+		ext, err := d.db.GetExtension(ctx, user, domain)
+		if err != nil {
+			continue
+		}
+		if ext.MaxConcurrent <= 0 || len(destinations) <= ext.MaxConcurrent {
+			continue
+		}
+
+		d.raise(ctx, ext, models.FraudRuleConcurrentCalls,
+			fmt.Sprintf("%d live outbound calls, max_concurrent is %d", len(destinations), ext.MaxConcurrent),
+			map[string]interface{}{
+				"source":         "live",
+				"calls":          len(destinations),
+				"max_concurrent": ext.MaxConcurrent,
+				"destinations":   destinations,
+			})
+	}
+
+	return nil
+}
+
+// scanCDRs checks the CDRs processed since the last run, in batches
+func (d *FraudDetector) scanCDRs(ctx context.Context) error {
+	for {
+		lastID, err := d.db.GetFraudCheckpoint(ctx)
+		if err != nil {
+			return err
+		}
+
+		calls, err := d.db.ListPlacedCallsAfter(ctx, lastID, d.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("list calls: %w", err)
+		}
+		if len(calls) == 0 {
+			return nil
+		}
+
+		// Claim the batch; the node that loses the race skips it
+		claimed, err := d.db.AdvanceFraudCheckpoint(ctx, lastID, calls[len(calls)-1].CDRID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return nil
+		}
+
+		d.checkCalls(ctx, calls)
+
+		if len(calls) < d.config.BatchSize {
+			return nil
+		}
+	}
+}
+
+// checkCalls runs the CDR rules for the extensions that placed outbound
+// calls in a batch
+func (d *FraudDetector) checkCalls(ctx context.Context, calls []*models.PlacedCall) {
+	// Newest call per extension, to evaluate its window from
+	latest := make(map[string]*models.PlacedCall)
+	var order []string
+	for _, call := range calls {
+		if !d.isOutbound(call) {
+			continue
+		}
+		key := call.Extension + "@" + call.Domain
+		if _, ok := latest[key]; !ok {
+			order = append(order, key)
+		}
+		latest[key] = call
+
+		if d.rules[models.FraudRuleHighCostPrefix] {
+			d.checkHighCost(ctx, call)
+		}
+	}
+
+	for _, key := range order {
+		if err := d.checkExtension(ctx, latest[key]); err != nil {
+			log.Printf("[FraudDetector] Error checking %s: %v", key, err)
+		}
+	}
+}
+
+// checkHighCost raises an alert for a call to a high-cost prefix
+func (d *FraudDetector) checkHighCost(ctx context.Context, call *models.PlacedCall) {
+	prefix := highCostPrefix(call.Destination, d.config.InternationalPrefixes, d.config.HighCostPrefixes)
+	if prefix == "" {
+		return
+	}
+
+	ext, err := d.db.GetExtension(ctx, call.Extension, call.Domain)
+	if err != nil {
+		return
+	}
+
+	d.raise(ctx, ext, models.FraudRuleHighCostPrefix,
+		fmt.Sprintf("Call to high-cost prefix %s (%s)", prefix, call.Destination),
+		map[string]interface{}{
+			"cdr_id":      call.CDRID,
+			"destination": call.Destination,
+			"prefix":      prefix,
+			"start":       call.Start,
+		})
+}
+
+// checkExtension runs the window rules over the calls an extension placed
+// up to its newest call
+func (d *FraudDetector) checkExtension(ctx context.Context, newest *models.PlacedCall) error {
+	ext, err := d.db.GetExtension(ctx, newest.Extension, newest.Domain)
+	if err != nil {
+		// Calls of deleted extensions or inactive domains
+		return nil
+	}
+
+	since := newest.Start.Add(-d.config.Window)
+	history, err := d.db.ListExtensionCalls(ctx, newest.Domain, newest.Extension, since)
+	if err != nil {
+		return err
+	}
+
+	var outbound []*models.PlacedCall
+	var international, offHours []string
+	for _, call := range history {
+		if !d.isOutbound(call) {
+			continue
+		}
+		outbound = append(outbound, call)
+		if _, ok := internationalNumber(call.Destination, d.config.InternationalPrefixes); ok {
+			international = append(international, call.Destination)
+		}
+		if d.offHours.contains(call.Start) {
+			offHours = append(offHours, call.Destination)
+		}
+	}
+
+	if d.rules[models.FraudRuleInternationalSpike] && len(international) >= d.config.InternationalThreshold {
+		d.raise(ctx, ext, models.FraudRuleInternationalSpike,
+			fmt.Sprintf("%d international calls in %v", len(international), d.config.Window),
+			map[string]interface{}{
+				"calls":        len(international),
+				"threshold":    d.config.InternationalThreshold,
+				"window":       d.config.Window.String(),
+				"destinations": international,
+			})
+	}
+
+	if d.rules[models.FraudRuleOffHoursBurst] && len(offHours) >= d.config.OffHoursThreshold {
+		d.raise(ctx, ext, models.FraudRuleOffHoursBurst,
+			fmt.Sprintf("%d outbound calls outside business hours in %v", len(offHours), d.config.Window),
+			map[string]interface{}{
+				"calls":        len(offHours),
+				"threshold":    d.config.OffHoursThreshold,
+				"window":       d.config.Window.String(),
+				"destinations": offHours,
+			})
+	}
+
+	if d.rules[models.FraudRuleConcurrentCalls] && ext.MaxConcurrent > 0 {
+		if peak := maxConcurrent(outbound); peak > ext.MaxConcurrent {
+			d.raise(ctx, ext, models.FraudRuleConcurrentCalls,
+				fmt.Sprintf("%d concurrent outbound calls, max_concurrent is %d", peak, ext.MaxConcurrent),
+				map[string]interface{}{
+					"source":         "cdr",
+					"calls":          peak,
+					"max_concurrent": ext.MaxConcurrent,
+					"window":         d.config.Window.String(),
+				})
+		}
+	}
+
+	return nil
+}
+
+// raise stores an alert, suspends the extension if the rule says so and
+// sends the webhook. Repeats of an open alert within the window are
+// dropped.
+func (d *FraudDetector) raise(ctx context.Context, ext *models.Extension, rule, message string, details map[string]interface{}) {
+	data, err := json.Marshal(details)
+	if err != nil {
+		log.Printf("[FraudDetector] Failed to encode alert details: %v", err)
+		return
+	}
+
+	alert := &models.FraudAlert{
+		DomainID:    ext.DomainID,
+		Domain:      ext.Domain,
+		ExtensionID: &ext.ID,
+		Extension:   ext.Extension,
+		Rule:        rule,
+		Message:     message,
+		Details:     data,
+	}
+
+	created, err := d.db.CreateFraudAlert(ctx, alert, time.Now().Add(-d.config.Window))
+	if err != nil {
+		log.Printf("[FraudDetector] Failed to store %s alert for %s@%s: %v", rule, ext.Extension, ext.Domain, err)
+		return
+	}
+	if !created {
+		return
+	}
+	log.Printf("[FraudDetector] Alert %d for %s@%s: %s", alert.ID, ext.Extension, ext.Domain, message)
+
+	if d.autoSuspend[rule] && ext.Active {
+		if err := d.suspend(ctx, ext, alert); err != nil {
+			log.Printf("[FraudDetector] Failed to suspend %s@%s: %v", ext.Extension, ext.Domain, err)
+		}
+	}
+
+	if d.config.Webhook != nil {
+		err := d.config.Webhook.Send(ctx, &webhook.Event{
+			Type:       "fraud.alert",
+			OccurredAt: alert.CreatedAt,
+			Data:       alert,
+		})
+		if err != nil {
+			log.Printf("[FraudDetector] Failed to send webhook for alert %d: %v", alert.ID, err)
+		}
+	}
+}
+
+// suspend deactivates an extension and cuts it off: the cached directory
+// entry is dropped, its registrations removed and its calls hung up
+func (d *FraudDetector) suspend(ctx context.Context, ext *models.Extension, alert *models.FraudAlert) error {
+	changed, err := d.db.SuspendExtension(ctx, ext.ID)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	ext.Active = false
+	alert.Suspended = true
+
+	if err := d.db.MarkFraudAlertSuspended(ctx, alert.ID); err != nil {
+		log.Printf("[FraudDetector] %v", err)
+	}
+
+	if d.config.Cache != nil {
+		d.config.Cache.Delete(xmlcurl.DirectoryCacheKey(ext.Extension, ext.Domain))
+	}
+
+	// Inactive extensions fail authentication from now on; bindings and
+	// calls made before are cut off here
+	err = d.config.Kamailio.RemoveAOR(ctx, ext.Extension+"@"+ext.Domain)
+	if err != nil && !errors.Is(err, kamailio.ErrNotRegistered) && !errors.Is(err, kamailio.ErrNoNodes) {
+		log.Printf("[FraudDetector] Failed to unregister %s@%s: %v", ext.Extension, ext.Domain, err)
+	}
+	if err := d.config.ESL.HangupUser(ctx, ext.Extension, ext.Domain, "CALL_REJECTED"); err != nil {
+		log.Printf("[FraudDetector] Failed to hang up calls of %s@%s: %v", ext.Extension, ext.Domain, err)
+	}
+
+	changes, _ := json.Marshal(map[string]interface{}{
+		"before": map[string]bool{"active": true},
+		"after":  map[string]bool{"active": false},
+	})
+	entry := &models.AuditEntry{
+		ActorType:  "system",
+		ActorName:  "fraud-detector",
+		Action:     models.AuditSuspend,
+		Resource:   "extensions",
+		ResourceID: strconv.FormatInt(ext.ID, 10),
+		DomainID:   &ext.DomainID,
+		Changes:    changes,
+		RequestID:  "fraud-alert-" + strconv.FormatInt(alert.ID, 10),
+	}
+	if err := d.db.InsertAuditEntry(ctx, entry); err != nil {
+		log.Printf("[FraudDetector] Failed to record suspension of %s@%s: %v", ext.Extension, ext.Domain, err)
+	}
+
+	log.Printf("[FraudDetector] Suspended %s@%s after alert %d", ext.Extension, ext.Domain, alert.ID)
+	return nil
+}
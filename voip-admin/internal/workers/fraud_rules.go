@@ -0,0 +1,126 @@
+package workers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// offHours is a recurring period outside business hours
+type offHours struct {
+	start    int // Minutes after midnight the period starts
+	end      int // Minutes after midnight it ends; before start when it spans midnight
+	weekends bool
+	location *time.Location
+}
+
+// parseOffHours parses "HH:MM" start and end times in a time zone
+func parseOffHours(start, end string, weekends bool, timezone string) (*offHours, error) {
+	startMin, err := parseClock(start)
+	if err != nil {
+		return nil, fmt.Errorf("off-hours start: %w", err)
+	}
+	endMin, err := parseClock(end)
+	if err != nil {
+		return nil, fmt.Errorf("off-hours end: %w", err)
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("off-hours timezone: %w", err)
+	}
+
+	return &offHours{start: startMin, end: endMin, weekends: weekends, location: location}, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight
+func parseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(s, ":")
+	h, errH := strconv.Atoi(hh)
+	m, errM := strconv.Atoi(mm)
+	if !ok || errH != nil || errM != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return h*60 + m, nil
+}
+
+// contains reports whether t falls outside business hours
+func (o *offHours) contains(t time.Time) bool {
+	t = t.In(o.location)
+	if o.weekends && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return true
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if o.start <= o.end {
+		return minute >= o.start && minute < o.end
+	}
+	return minute >= o.start || minute < o.end
+}
+
+// internationalNumber returns the number dialed after the international
+// access prefix (e.g. 00 or +), or false for domestic destinations
+func internationalNumber(destination string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(destination, prefix) && len(destination) > len(prefix) {
+			return destination[len(prefix):], true
+		}
+	}
+	return "", false
+}
+
+// highCostPrefix returns the configured prefix a destination matches. The
+// prefixes match the destination as dialed (e.g. a domestic premium range)
+// or, for international calls, the number after the access prefix (e.g.
+// 882 for +882...).
+func highCostPrefix(destination string, international, highCost []string) string {
+	number, isInternational := internationalNumber(destination, international)
+	for _, prefix := range highCost {
+		if prefix == "" {
+			continue
+		}
+		if strings.HasPrefix(destination, prefix) || (isInternational && strings.HasPrefix(number, prefix)) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// maxConcurrent returns the most calls that were up at the same time
+func maxConcurrent(calls []*models.PlacedCall) int {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+
+	edges := make([]edge, 0, 2*len(calls))
+	for _, call := range calls {
+		// Unanswered calls still held a channel for a moment
+		end := call.End
+		if !end.After(call.Start) {
+			end = call.Start.Add(time.Second)
+		}
+		edges = append(edges, edge{call.Start, 1}, edge{end, -1})
+	}
+
+	// A call ending when another starts does not overlap it
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	current, highest := 0, 0
+	for _, e := range edges {
+		current += e.delta
+		if current > highest {
+			highest = current
+		}
+	}
+
+	return highest
+}
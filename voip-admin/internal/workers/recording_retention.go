@@ -0,0 +1,117 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+)
+
+// recordingPurgeBatch is the number of recordings deleted per query
+const recordingPurgeBatch = 500
+
+// RecordingRetention periodically deletes recordings older than the
+// retention of their recording policy, file and row. Recordings on legal
+// hold are kept.
+type RecordingRetention struct {
+	db                   *database.DB
+	storage              string // Recordings root as mounted on this host
+	interval             time.Duration
+	defaultRetentionDays int // For recordings without a policy; 0 keeps them
+	done                 chan struct{}
+}
+
+// NewRecordingRetention creates a new recording retention worker
+func NewRecordingRetention(db *database.DB, storage string, interval time.Duration, defaultRetentionDays int) *RecordingRetention {
+	if interval == 0 {
+		interval = time.Hour
+	}
+
+	return &RecordingRetention{
+		db:                   db,
+		storage:              storage,
+		interval:             interval,
+		defaultRetentionDays: defaultRetentionDays,
+		done:                 make(chan struct{}),
+	}
+}
+
+// Start begins the retention loop
+func (w *RecordingRetention) Start(ctx context.Context) {
+	log.Printf("[RecordingRetention] Starting with interval=%v, storage=%s, default retention=%d days",
+		w.interval, w.storage, w.defaultRetentionDays)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[RecordingRetention] Shutting down...")
+			close(w.done)
+			return
+
+		case <-ticker.C:
+			if err := w.purge(ctx); err != nil {
+				log.Printf("[RecordingRetention] Error during purge: %v", err)
+			}
+		}
+	}
+}
+
+// purge deletes expired recordings in batches until none are left
+func (w *RecordingRetention) purge(ctx context.Context) error {
+	var deleted, failed int
+	defer func() {
+		if deleted > 0 || failed > 0 {
+			log.Printf("[RecordingRetention] Deleted %d expired recordings, %d files left behind", deleted, failed)
+		}
+	}()
+
+	for {
+		expired, err := w.db.ListExpiredRecordings(ctx, w.defaultRetentionDays, recordingPurgeBatch)
+		if err != nil {
+			return fmt.Errorf("list expired recordings: %w", err)
+		}
+
+		progress := false
+		for _, rec := range expired {
+			// The row goes first, so a legal hold placed meanwhile still
+			// keeps the file
+			ok, err := w.db.DeleteExpiredRecording(ctx, rec.ID)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			progress = true
+			deleted++
+
+			file, ok := rec.LocalPath(w.storage)
+			if !ok {
+				log.Printf("[RecordingRetention] Recording %d is outside the storage, file kept: %s", rec.ID, rec.FilePath)
+				failed++
+				continue
+			}
+			if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				log.Printf("[RecordingRetention] Failed to delete file of recording %d: %v", rec.ID, err)
+				failed++
+			}
+		}
+
+		if len(expired) < recordingPurgeBatch || !progress {
+			return nil
+		}
+	}
+}
+
+// Stop waits for the retention loop to stop
+func (w *RecordingRetention) Stop() {
+	<-w.done
+}
@@ -0,0 +1,260 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/mailer"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+const (
+	// voicemailNotifyBatch is the number of messages claimed per query
+	voicemailNotifyBatch = 50
+
+	// voicemailNotifyWindow is how old a message may be and still be
+	// emailed; failed emails are retried until then
+	voicemailNotifyWindow = 24 * time.Hour
+
+	// voicemailMaxAttachment is the largest audio file attached to an email
+	voicemailMaxAttachment = 10 << 20
+)
+
+// DefaultVoicemailSubject is the default voicemail email subject template
+const DefaultVoicemailSubject = `{{if .Urgent}}Urgent voicemail{{else}}Voicemail{{end}} from {{.Caller}} ({{.Duration}}s)`
+
+// DefaultVoicemailBody is the default voicemail email body template
+const DefaultVoicemailBody = `Hello {{.DisplayName}},
+
+You have a new voicemail in mailbox {{.Extension}}@{{.Domain}}.
+
+From:     {{.Caller}}
+Received: {{.ReceivedAt.Format "2006-01-02 15:04:05 MST"}}
+Length:   {{.Duration}} seconds
+{{if .Attached}}
+The message is attached.
+{{else}}
+Call your voicemail to listen to the message.
+{{end}}`
+
+// VoicemailEmail is the data of the voicemail email templates
+type VoicemailEmail struct {
+	UUID           string
+	Extension      string
+	Domain         string
+	DisplayName    string
+	CallerIDName   string
+	CallerIDNumber string
+	Caller         string // "Name <number>", or the number alone
+	Duration       int
+	Urgent         bool
+	ReceivedAt     time.Time
+	Attached       bool
+}
+
+// VoicemailNotifier emails new voicemail messages, with the audio attached,
+// to extensions with a voicemail email address
+type VoicemailNotifier struct {
+	db       *database.DB
+	mailer   *mailer.Mailer
+	storage  string // Voicemail root as mounted on this host
+	interval time.Duration
+	subject  *template.Template
+	body     *template.Template
+	done     chan struct{}
+}
+
+// NewVoicemailNotifier creates a new voicemail notifier. Empty templates
+// fall back to DefaultVoicemailSubject and DefaultVoicemailBody.
+func NewVoicemailNotifier(db *database.DB, m *mailer.Mailer, storage string, interval time.Duration, subjectTemplate, bodyTemplate string) (*VoicemailNotifier, error) {
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+	if subjectTemplate == "" {
+		subjectTemplate = DefaultVoicemailSubject
+	}
+	if bodyTemplate == "" {
+		bodyTemplate = DefaultVoicemailBody
+	}
+
+	subject, err := template.New("subject").Parse(subjectTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parse subject template: %w", err)
+	}
+	body, err := template.New("body").Parse(bodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parse body template: %w", err)
+	}
+
+	return &VoicemailNotifier{
+		db:       db,
+		mailer:   m,
+		storage:  storage,
+		interval: interval,
+		subject:  subject,
+		body:     body,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Start begins the notification loop
+func (n *VoicemailNotifier) Start(ctx context.Context) {
+	log.Printf("[VoicemailNotifier] Starting with interval=%v, storage=%s", n.interval, n.storage)
+
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[VoicemailNotifier] Shutting down...")
+			close(n.done)
+			return
+
+		case <-ticker.C:
+			if err := n.notify(ctx); err != nil {
+				log.Printf("[VoicemailNotifier] Error during notification: %v", err)
+			}
+		}
+	}
+}
+
+// notify emails the messages received since the last pass
+func (n *VoicemailNotifier) notify(ctx context.Context) error {
+	since := time.Now().Add(-voicemailNotifyWindow)
+
+	// Claims older than the window can no longer match a message
+	if _, err := n.db.CleanupVoicemailNotifications(ctx, since); err != nil {
+		return err
+	}
+
+	var sent, failed int
+	defer func() {
+		if sent > 0 || failed > 0 {
+			log.Printf("[VoicemailNotifier] Sent %d voicemail emails, %d failed", sent, failed)
+		}
+	}()
+
+	for {
+		pending, err := n.db.ClaimVoicemailNotifications(ctx, since, voicemailNotifyBatch)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range pending {
+			if err := n.send(ctx, p); err != nil {
+				log.Printf("[VoicemailNotifier] Failed to email message %s to %s: %v", p.Message.UUID, p.Email, err)
+				failed++
+
+				// Retried on the next pass
+				if err := n.db.ReleaseVoicemailNotification(ctx, p.Message.UUID); err != nil {
+					log.Printf("[VoicemailNotifier] %v", err)
+				}
+				continue
+			}
+			sent++
+
+			if err := n.db.CompleteVoicemailNotification(ctx, p.Message.UUID); err != nil {
+				log.Printf("[VoicemailNotifier] %v", err)
+			}
+		}
+
+		// Failed messages are released and would be claimed again
+		if len(pending) < voicemailNotifyBatch || failed > 0 {
+			return nil
+		}
+	}
+}
+
+// send renders and sends the email of one message
+func (n *VoicemailNotifier) send(ctx context.Context, p *models.VoicemailNotification) error {
+	msg := p.Message
+	data := &VoicemailEmail{
+		UUID:           msg.UUID,
+		Extension:      msg.Extension,
+		Domain:         msg.Domain,
+		DisplayName:    p.DisplayName,
+		CallerIDName:   msg.CallerIDName,
+		CallerIDNumber: msg.CallerIDNumber,
+		Caller:         msg.CallerIDNumber,
+		Duration:       msg.Duration,
+		Urgent:         msg.Urgent,
+		ReceivedAt:     msg.ReceivedAt,
+	}
+	if data.DisplayName == "" {
+		data.DisplayName = msg.Extension
+	}
+	if msg.CallerIDName != "" && msg.CallerIDName != msg.CallerIDNumber {
+		data.Caller = fmt.Sprintf("%s <%s>", msg.CallerIDName, msg.CallerIDNumber)
+	}
+
+	var attachments []mailer.Attachment
+	if attachment, ok := n.attachment(msg); ok {
+		attachments = append(attachments, attachment)
+		data.Attached = true
+	}
+
+	var subject, body bytes.Buffer
+	if err := n.subject.Execute(&subject, data); err != nil {
+		return fmt.Errorf("render subject: %w", err)
+	}
+	if err := n.body.Execute(&body, data); err != nil {
+		return fmt.Errorf("render body: %w", err)
+	}
+
+	return n.mailer.Send(ctx, &mailer.Message{
+		To:          p.Email,
+		Subject:     strings.Join(strings.Fields(subject.String()), " "),
+		Body:        body.String(),
+		Attachments: attachments,
+	})
+}
+
+// attachment reads the audio file of a message. Files outside the storage,
+// missing or too large are not attached.
+func (n *VoicemailNotifier) attachment(msg *models.VoicemailMessage) (mailer.Attachment, bool) {
+	file, ok := msg.LocalPath(n.storage)
+	if !ok {
+		log.Printf("[VoicemailNotifier] Message %s is outside the storage, not attached: %s", msg.UUID, msg.FilePath)
+		return mailer.Attachment{}, false
+	}
+
+	info, err := os.Stat(file)
+	if err != nil || info.Size() > voicemailMaxAttachment {
+		if err != nil {
+			log.Printf("[VoicemailNotifier] Message %s not attached: %v", msg.UUID, err)
+		}
+		return mailer.Attachment{}, false
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		log.Printf("[VoicemailNotifier] Message %s not attached: %v", msg.UUID, err)
+		return mailer.Attachment{}, false
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(file), ".")
+	contentType, ok := models.AudioContentTypes[ext]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+
+	return mailer.Attachment{
+		Name:        fmt.Sprintf("voicemail-%s.%s", msg.ReceivedAt.Format("20060102-150405"), ext),
+		ContentType: contentType,
+		Data:        data,
+	}, true
+}
+
+// Stop waits for the notification loop to stop
+func (n *VoicemailNotifier) Stop() {
+	<-n.done
+}
@@ -0,0 +1,203 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/mailer"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/mailer/mailertest"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+)
+
+// newTestNotifier returns a notifier sending to server, reading audio
+// from storage
+func newTestNotifier(t *testing.T, server *mailertest.Server, storage, subject, body string) *VoicemailNotifier {
+	t.Helper()
+
+	m := mailer.New(&mailer.Config{
+		Host: server.Host(),
+		Port: server.Port(),
+		From: "pbx@example.com",
+	})
+
+	n, err := NewVoicemailNotifier(nil, m, storage, 0, subject, body)
+	if err != nil {
+		t.Fatalf("NewVoicemailNotifier: %v", err)
+	}
+	return n
+}
+
+// receiveEmail parses the only message accepted by server, returning its
+// decoded subject, text and attachments by file name
+func receiveEmail(t *testing.T, server *mailertest.Server) (string, string, map[string][]byte) {
+	t.Helper()
+
+	received := server.Received()
+	if len(received) != 1 {
+		t.Fatalf("received %d messages, want 1", len(received))
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(received[0].Data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+
+	decode := func(r io.Reader) []byte {
+		data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, r))
+		if err != nil {
+			t.Fatalf("decode base64: %v", err)
+		}
+		return data
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("parse Content-Type: %v", err)
+	}
+	if mediaType != "multipart/mixed" {
+		return subject, string(decode(msg.Body)), nil
+	}
+
+	var text string
+	attachments := make(map[string][]byte)
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		if name := part.FileName(); name != "" {
+			attachments[name] = decode(part)
+		} else {
+			text = string(decode(part))
+		}
+	}
+
+	return subject, text, attachments
+}
+
+func TestVoicemailNotifierDefaultTemplates(t *testing.T) {
+	server := mailertest.NewServer(t)
+	storage := t.TempDir()
+
+	audio := []byte("RIFF....WAVEfmt ")
+	file := filepath.Join(storage, "default", "pbx.example.com", "1001", "msg_1.wav")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, audio, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	n := newTestNotifier(t, server, storage, "", "")
+	err := n.send(context.Background(), &models.VoicemailNotification{
+		Email:       "alice@example.com",
+		DisplayName: "Alice",
+		Message: &models.VoicemailMessage{
+			UUID:           "4b5c9a1e-7d2f-4c1a-9e3b-2f6d8a0c1b7e",
+			Extension:      "1001",
+			Domain:         "pbx.example.com",
+			CallerIDName:   "Bob",
+			CallerIDNumber: "0901234567",
+			Duration:       12,
+			Urgent:         true,
+			ReceivedAt:     time.Date(2026, 10, 19, 10, 15, 0, 0, time.UTC),
+			FilePath:       file,
+		},
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	subject, text, attachments := receiveEmail(t, server)
+
+	if want := "Urgent voicemail from Bob <0901234567> (12s)"; subject != want {
+		t.Errorf("subject = %q, want %q", subject, want)
+	}
+	for _, want := range []string{
+		"Hello Alice,",
+		"mailbox 1001@pbx.example.com",
+		"From:     Bob <0901234567>",
+		"Received: 2026-10-19 10:15:00 UTC",
+		"Length:   12 seconds",
+		"The message is attached.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("body does not contain %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Call your voicemail") {
+		t.Errorf("body asks to call voicemail although the message is attached:\n%s", text)
+	}
+
+	got, ok := attachments["voicemail-20261019-101500.wav"]
+	if !ok || !bytes.Equal(got, audio) {
+		t.Errorf("attachments = %v, want voicemail-20261019-101500.wav with the audio", attachments)
+	}
+}
+
+func TestVoicemailNotifierCustomTemplates(t *testing.T) {
+	server := mailertest.NewServer(t)
+	storage := t.TempDir()
+
+	// A multi-line subject must not break the header
+	subject := "New message\n  for {{.DisplayName}}{{if .Urgent}} (urgent){{end}}"
+	body := "{{.Caller}} left {{.Duration}}s{{if not .Attached}}, not attached{{end}}"
+
+	n := newTestNotifier(t, server, storage, subject, body)
+	err := n.send(context.Background(), &models.VoicemailNotification{
+		Email: "alice@example.com",
+		Message: &models.VoicemailMessage{
+			UUID:           "4b5c9a1e-7d2f-4c1a-9e3b-2f6d8a0c1b7e",
+			Extension:      "1001",
+			Domain:         "pbx.example.com",
+			CallerIDName:   "0901234567",
+			CallerIDNumber: "0901234567",
+			Duration:       5,
+			ReceivedAt:     time.Date(2026, 10, 19, 10, 15, 0, 0, time.UTC),
+			FilePath:       "/etc/passwd", // Outside the storage, never attached
+		},
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	gotSubject, text, attachments := receiveEmail(t, server)
+
+	if want := "New message for 1001"; gotSubject != want {
+		t.Errorf("subject = %q, want %q", gotSubject, want)
+	}
+	if want := "0901234567 left 5s, not attached"; text != want {
+		t.Errorf("body = %q, want %q", text, want)
+	}
+	if len(attachments) != 0 {
+		t.Errorf("attachments = %v, want none", attachments)
+	}
+}
+
+func TestNewVoicemailNotifierInvalidTemplate(t *testing.T) {
+	if _, err := NewVoicemailNotifier(nil, nil, "", 0, "{{.Caller", ""); err == nil {
+		t.Error("invalid subject template accepted")
+	}
+	if _, err := NewVoicemailNotifier(nil, nil, "", 0, "", "{{if}}"); err == nil {
+		t.Error("invalid body template accepted")
+	}
+}
@@ -1,11 +1,16 @@
 package xmlcurl
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"html/template"
 	"log"
+	"net"
+	"strconv"
 
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
 )
 
 // ConfigurationHandler handles FreeSWITCH configuration XML_CURL requests
@@ -35,13 +40,17 @@ func (h *ConfigurationHandler) Handle(ctx context.Context, req *ConfigurationReq
 	// This tells FreeSWITCH to use static configuration files
 	// In the future, we can implement dynamic configuration for:
 	// - callcenter.conf (queue configuration)
-	// - sofia.conf (SIP profile configuration)
-	// - acl.conf (access control lists)
 
 	switch req.KeyValue {
 	case "callcenter.conf":
 		return h.handleCallCenterConfig(ctx)
 
+	case "sofia.conf":
+		return h.handleSofiaConfig(ctx)
+
+	case "acl.conf":
+		return h.handleACLConfig(ctx)
+
 	default:
 		// Use static configuration files
 		log.Printf("[Configuration] Using static config for: %s", req.KeyValue)
@@ -57,6 +66,89 @@ func (h *ConfigurationHandler) handleCallCenterConfig(ctx context.Context) (stri
 	return h.renderNotFound(), nil
 }
 
+// sofiaProfile is a SIP profile and its gateways as rendered into sofia.conf
+type sofiaProfile struct {
+	*models.SIPProfile
+	Gateways []*sofiaGateway
+}
+
+// sofiaGateway is a trunk as rendered into sofia.conf
+type sofiaGateway struct {
+	*models.Trunk
+	Proxy string // host:port
+}
+
+// handleSofiaConfig generates sofia.conf from the SIP profiles and trunks in
+// the database. Without any active profile the static sofia.conf.xml is
+// used.
+func (h *ConfigurationHandler) handleSofiaConfig(ctx context.Context) (string, error) {
+	profiles, err := h.db.ListSIPProfiles(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list SIP profiles: %w", err)
+	}
+	if len(profiles) == 0 {
+		log.Printf("[Configuration] No SIP profiles in database, using static sofia.conf")
+		return h.renderNotFound(), nil
+	}
+
+	trunks, err := h.db.ListGatewayTrunks(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list trunks: %w", err)
+	}
+
+	data := struct {
+		Profiles []*sofiaProfile
+	}{}
+	byName := make(map[string]*sofiaProfile, len(profiles))
+	for _, profile := range profiles {
+		p := &sofiaProfile{SIPProfile: profile}
+		byName[profile.Name] = p
+		data.Profiles = append(data.Profiles, p)
+	}
+	for _, trunk := range trunks {
+		p, ok := byName[trunk.SIPProfile]
+		if !ok {
+			continue
+		}
+		p.Gateways = append(p.Gateways, &sofiaGateway{
+			Trunk: trunk,
+			Proxy: net.JoinHostPort(trunk.Host, strconv.Itoa(trunk.Port)),
+		})
+	}
+
+	// html/template would escape the XML declaration, so it is written
+	// before the template output
+	var buf bytes.Buffer
+	buf.WriteString(xmlDeclaration)
+	if err := sofiaConfTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("execute sofia.conf template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// handleACLConfig generates acl.conf from the ACL lists in the database.
+// Without any list the static acl.conf.xml is used. FreeSWITCH adds its
+// own .auto lists (rfc1918.auto, localnet.auto, ...) either way.
+func (h *ConfigurationHandler) handleACLConfig(ctx context.Context) (string, error) {
+	lists, err := h.db.ListACLLists(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list ACL lists: %w", err)
+	}
+	if len(lists) == 0 {
+		log.Printf("[Configuration] No ACL lists in database, using static acl.conf")
+		return h.renderNotFound(), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xmlDeclaration)
+	if err := aclConfTemplate.Execute(&buf, lists); err != nil {
+		return "", fmt.Errorf("execute acl.conf template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
 // renderNotFound renders a "not found" XML response
 func (h *ConfigurationHandler) renderNotFound() string {
 	return `<?xml version="1.0" encoding="UTF-8"?>
@@ -66,3 +158,107 @@ func (h *ConfigurationHandler) renderNotFound() string {
   </section>
 </document>`
 }
+
+// xmlDeclaration starts every configuration document
+const xmlDeclaration = `<?xml version="1.0" encoding="UTF-8"?>
+`
+
+// sofiaConfTemplate renders sofia.conf. The profile settings follow the
+// static configs/freeswitch/autoload_configs/sofia.conf.xml; empty IPs bind
+// to the node's own address, so one configuration serves every node.
+var sofiaConfTemplate = template.Must(template.New("sofia.conf").Parse(`<document type="freeswitch/xml">
+  <section name="configuration">
+    <configuration name="sofia.conf" description="Sofia SIP Configuration">
+      <global_settings>
+        <param name="log-level" value="0"/>
+        <param name="auto-restart" value="true"/>
+        <param name="debug-presence" value="0"/>
+        <param name="rtp-enable-zrtp" value="false"/>
+        <param name="sip-trace" value="no"/>
+      </global_settings>
+
+      <profiles>
+{{- range .Profiles}}
+        <profile name="{{.Name}}">
+          <gateways>
+{{- range .Gateways}}
+            <gateway name="{{.Name}}">
+              <param name="proxy" value="{{.Proxy}}"/>
+              <param name="realm" value="{{if .Realm}}{{.Realm}}{{else}}{{.Host}}{{end}}"/>
+{{- if .Username}}
+              <param name="username" value="{{.Username}}"/>
+{{- end}}
+{{- if .Password}}
+              <param name="password" value="{{.Password}}"/>
+{{- end}}
+              <param name="register" value="{{.Register}}"/>
+              <param name="register-transport" value="{{.Transport}}"/>
+              <param name="expire-seconds" value="{{.ExpireSeconds}}"/>
+              <param name="retry-seconds" value="30"/>
+              <param name="caller-id-in-from" value="{{.CallerIDInFrom}}"/>
+{{- if .CodecPrefs}}
+              <variables>
+                <variable name="absolute_codec_string" value="{{.CodecPrefs}}" direction="outbound"/>
+              </variables>
+{{- end}}
+            </gateway>
+{{- end}}
+          </gateways>
+
+          <settings>
+            <param name="sip-ip" value="{{if .SIPIP}}{{.SIPIP}}{{else}}$${local_ip_v4}{{end}}"/>
+            <param name="sip-port" value="{{.SIPPort}}"/>
+            <param name="rtp-ip" value="{{if .RTPIP}}{{.RTPIP}}{{else}}$${local_ip_v4}{{end}}"/>
+            <param name="context" value="{{.Context}}"/>
+            <param name="dialplan" value="XML"/>
+
+            <param name="max-proceeding" value="3000"/>
+            <param name="session-timeout" value="1800"/>
+
+            <param name="rtp-timeout-sec" value="300"/>
+            <param name="rtp-hold-timeout-sec" value="1800"/>
+
+            <param name="inbound-codec-prefs" value="{{.InboundCodecPrefs}}"/>
+            <param name="outbound-codec-prefs" value="{{.OutboundCodecPrefs}}"/>
+            <param name="inbound-codec-negotiation" value="generous"/>
+
+            <param name="auth-calls" value="{{.AuthCalls}}"/>
+{{- if not .AuthCalls}}
+            <param name="accept-blind-auth" value="true"/>
+{{- end}}
+{{- if .InboundACL}}
+            <param name="apply-inbound-acl" value="{{.InboundACL}}"/>
+{{- end}}
+            <param name="manage-presence" value="false"/>
+
+            <param name="NDLB-received-in-nat-reg-contact" value="true"/>
+            <param name="NDLB-force-rport" value="true"/>
+            <param name="ext-rtp-ip" value="{{if .ExtRTPIP}}{{.ExtRTPIP}}{{else}}auto-nat{{end}}"/>
+            <param name="ext-sip-ip" value="{{if .ExtSIPIP}}{{.ExtSIPIP}}{{else}}auto-nat{{end}}"/>
+
+            <param name="record-path" value="/var/lib/freeswitch/recordings"/>
+            <param name="record-waste-resources" value="false"/>
+          </settings>
+        </profile>
+{{- end}}
+      </profiles>
+    </configuration>
+  </section>
+</document>`))
+
+// aclConfTemplate renders acl.conf from ACL lists
+var aclConfTemplate = template.Must(template.New("acl.conf").Parse(`<document type="freeswitch/xml">
+  <section name="configuration">
+    <configuration name="acl.conf" description="Network Lists">
+      <network-lists>
+{{- range .}}
+        <list name="{{.Name}}" default="{{.DefaultAction}}">
+{{- range .Entries}}
+          <node type="{{.Action}}" cidr="{{.CIDR}}"/>
+{{- end}}
+        </list>
+{{- end}}
+      </network-lists>
+    </configuration>
+  </section>
+</document>`))
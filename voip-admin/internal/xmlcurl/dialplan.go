@@ -3,18 +3,63 @@ package xmlcurl
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"html/template"
 	"log"
+	"math"
+	"path"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/models"
+	"github.com/yourusername/high-cc-pbx/voip-admin/internal/numbering"
+)
+
+// featureCodePattern matches feature codes (*xx, *xxx)
+var featureCodePattern = regexp.MustCompile(`^\*\d{2,3}$`)
+
+// directVoicemailPattern matches *99<ext>, which leaves a message in the
+// mailbox of ext without ringing it
+var directVoicemailPattern = regexp.MustCompile(`^\*99([0-9]{2,20})$`)
+
+// publicContext is the context of calls arriving from carriers
+const publicContext = "public"
+
+// DefaultCreditAnnouncement is played to outbound callers stopped by a
+// spending or outbound call limit
+const DefaultCreditAnnouncement = "ivr/ivr-call_cannot_be_completed_as_dialed.wav"
+
+// outboundLimitDestination is where the limit application transfers
+// outbound calls above max_outbound_calls
+const outboundLimitDestination = "outbound_limit_exceeded"
+
+// outboundNumberPattern matches the outside numbers a dial string can carry
+var outboundNumberPattern = regexp.MustCompile(`^\+?[0-9*#]{1,32}$`)
+
+var (
+	// callUUIDPattern matches the call UUIDs recordings are named after
+	callUUIDPattern = regexp.MustCompile(`^[0-9a-fA-F-]{36}$`)
+
+	// recordingPathPattern matches recording paths the dialplan can carry
+	// unescaped
+	recordingPathPattern = regexp.MustCompile(`^/[A-Za-z0-9._/@+-]+$`)
+
+	// recordTemplateVarPattern matches channel variables in queue record
+	// templates, e.g. ${uuid} or $${recordings_dir}
+	recordTemplateVarPattern = regexp.MustCompile(`\$\$?\{([a-z_]+)\}`)
 )
 
 // DialplanHandler handles FreeSWITCH dialplan XML_CURL requests
 type DialplanHandler struct {
 	db        *database.DB
+	numbers   *numbering.Matcher
 	templates map[string]*template.Template
+
+	// Played when a credit limit stops an outbound call
+	creditAnnouncement string
 }
 
 // DialplanRequest represents a FreeSWITCH dialplan request
@@ -28,10 +73,21 @@ type DialplanRequest struct {
 	NetworkAddr     string // Source IP
 	ChannelName     string // Channel name
 	UUID            string // Call UUID
+	AuthUser        string // Extension the caller authenticated as, if any
+	ForwardedBy     string // Extension the call reached before, which forwards it
+
+	// Set by the conference PIN prompt, which transfers back to the room
+	ConferencePIN     string // PIN the caller entered
+	ConferencePINRoom string // Room number the PIN was entered for
 }
 
-// NewDialplanHandler creates a new dialplan handler
-func NewDialplanHandler(db *database.DB) (*DialplanHandler, error) {
+// NewDialplanHandler creates a new dialplan handler. creditAnnouncement
+// defaults to DefaultCreditAnnouncement.
+func NewDialplanHandler(db *database.DB, numbers *numbering.Matcher, creditAnnouncement string) (*DialplanHandler, error) {
+	if creditAnnouncement == "" {
+		creditAnnouncement = DefaultCreditAnnouncement
+	}
+
 	templates := make(map[string]*template.Template)
 
 	// Parse templates
@@ -44,8 +100,10 @@ func NewDialplanHandler(db *database.DB) (*DialplanHandler, error) {
 	}
 
 	return &DialplanHandler{
-		db:        db,
-		templates: templates,
+		db:                 db,
+		numbers:            numbers,
+		templates:          templates,
+		creditAnnouncement: creditAnnouncement,
 	}, nil
 }
 
@@ -54,39 +112,68 @@ func (h *DialplanHandler) Handle(ctx context.Context, req *DialplanRequest) (str
 	log.Printf("[Dialplan] Request: context=%s, caller=%s, destination=%s, domain=%s",
 		req.Context, req.CallerIDNumber, req.DestinationNumber, req.Domain)
 
-	// Route based on destination number pattern
-	switch {
-	case req.DestinationNumber == "":
-		log.Printf("[Dialplan] Empty destination number")
-		return h.renderNotFound(), nil
-
-	case isExtension(req.DestinationNumber):
-		// Local extension (4 digits: 1000-9999)
-		return h.handleExtensionCall(ctx, req)
-
-	case isQueue(req.DestinationNumber):
-		// Queue (starts with 8: 8000-8999)
-		return h.handleQueueCall(ctx, req)
+	// Carrier calls carry no domain; the DID decides where they go
+	if req.Context == publicContext {
+		return h.handlePublicCall(ctx, req)
+	}
 
-	case isIVR(req.DestinationNumber):
-		// IVR menu (starts with 9: 9000-9999)
-		return h.handleIVRCall(ctx, req)
+	// Calls in deactivated domains are not routed at all
+	var domain *models.Domain
+	if req.Domain != "" {
+		var err error
+		domain, err = h.db.GetDomainByName(ctx, req.Domain)
+		if err != nil || !domain.Active {
+			log.Printf("[Dialplan] Unknown or inactive domain: %s", req.Domain)
+			return h.renderNotFound(), nil
+		}
+	}
 
-	case isConference(req.DestinationNumber):
-		// Conference (starts with 3: 3000-3999)
-		return h.handleConferenceCall(ctx, req)
+	if req.DestinationNumber == "" {
+		log.Printf("[Dialplan] Empty destination number")
+		return h.renderNotFound(), nil
+	}
 
+	// Voicemail and feature codes are the same in every domain
+	switch {
 	case isVoicemail(req.DestinationNumber):
 		// Voicemail (*97, *98)
 		return h.handleVoicemailCall(ctx, req)
 
+	case isDirectVoicemail(req.DestinationNumber):
+		// Leave a message without ringing (*99<ext>)
+		return h.handleDirectVoicemail(ctx, req)
+
 	case isFeatureCode(req.DestinationNumber):
 		// Feature codes (*xx)
 		return h.handleFeatureCode(ctx, req)
+	}
+
+	// Outbound calls above max_outbound_calls are transferred here
+	if req.DestinationNumber == outboundLimitDestination {
+		return h.renderOutboundRejected(req, models.CreditRejectOutboundCalls)
+	}
+
+	// The voicemail main number asks for a mailbox, like *98
+	if domain != nil && domain.VoicemailMainNumber != "" && req.DestinationNumber == domain.VoicemailMainNumber {
+		return h.renderVoicemail(req, "", false, false)
+	}
 
-	case isOutbound(req.DestinationNumber):
-		// Outbound call (10-11 digits, or starts with +)
-		return h.handleOutboundCall(ctx, req)
+	// Route based on the domain's numbering plan
+	switch h.numbers.Classify(req.Domain, req.DestinationNumber) {
+	case models.NumberTypeUser:
+		return h.handleExtensionCall(ctx, req)
+
+	case models.NumberTypeQueue:
+		return h.handleQueueCall(ctx, req)
+
+	case models.NumberTypeIVR:
+		return h.handleIVRCall(ctx, req)
+
+	case models.NumberTypeConference:
+		return h.handleConferenceCall(ctx, req)
+
+	case models.NumberTypeOutbound:
+		return h.handleOutboundCall(ctx, req, domain)
 
 	default:
 		log.Printf("[Dialplan] No matching pattern for destination: %s", req.DestinationNumber)
@@ -94,6 +181,72 @@ func (h *DialplanHandler) Handle(ctx context.Context, req *DialplanRequest) (str
 	}
 }
 
+// handlePublicCall routes a carrier call to the destination of its DID
+func (h *DialplanHandler) handlePublicCall(ctx context.Context, req *DialplanRequest) (string, error) {
+	did, err := h.db.GetDIDByNumber(ctx, req.DestinationNumber)
+	if err != nil || !did.Active {
+		log.Printf("[Dialplan] Unknown or inactive DID: %s", req.DestinationNumber)
+		return h.renderNotFound(), nil
+	}
+
+	domain, err := h.db.GetDomain(ctx, did.DomainID)
+	if err != nil || !domain.Active {
+		log.Printf("[Dialplan] DID %s belongs to an inactive domain: %s", did.Number, did.Domain)
+		return h.renderNotFound(), nil
+	}
+
+	data := struct {
+		Digits             string
+		DIDNumber          string
+		Domain             string
+		CallerIDNamePrefix string
+		TimeConditionID    int64
+		Action             string
+		Target             string
+	}{
+		Digits:             strings.TrimPrefix(req.DestinationNumber, "+"),
+		DIDNumber:          did.Number,
+		Domain:             did.Domain,
+		CallerIDNamePrefix: did.CallerIDNamePrefix,
+		Action:             models.TimeActionRoute,
+		Target:             did.Destination,
+	}
+
+	if did.DestinationType == models.DIDDestinationTimeCondition {
+		tc, err := h.db.GetTimeCondition(ctx, *did.TimeConditionID)
+		if err != nil {
+			return "", fmt.Errorf("get time condition: %w", err)
+		}
+		data.TimeConditionID = tc.ID
+
+		state, err := tc.Evaluate(time.Now())
+		if err != nil {
+			// Route as open rather than dropping calls on a bad timezone
+			log.Printf("[Dialplan] Failed to evaluate time condition %q: %v", tc.Name, err)
+			state = &models.TimeConditionState{Open: true}
+		}
+
+		// While open, calls go to the first number the condition guards
+		var number string
+		if len(tc.Destinations) > 0 {
+			number = tc.Destinations[0].Number
+		}
+		data.Target = number
+		if !state.Open {
+			data.Action = tc.ClosedAction
+			data.Target = tc.ClosedTargetFor(number)
+		}
+		if data.Target == "" {
+			log.Printf("[Dialplan] DID %s: time condition %q has no destinations", did.Number, tc.Name)
+			return h.renderNotFound(), nil
+		}
+	}
+
+	log.Printf("[Dialplan] DID %s -> %s %s@%s", did.Number, data.Action, data.Target, did.Domain)
+
+	return h.renderTemplate("did", data)
+}
+
 // handleExtensionCall handles calls to local extensions
 func (h *DialplanHandler) handleExtensionCall(ctx context.Context, req *DialplanRequest) (string, error) {
 	// Verify extension exists in database
@@ -115,25 +268,111 @@ func (h *DialplanHandler) handleExtensionCall(ctx context.Context, req *Dialplan
 		return h.renderNotFound(), nil
 	}
 
-	data := struct {
-		Extension       string
-		Domain          string
-		CallerIDNumber  string
-		CallerIDName    string
-		CallTimeout     int
-		MaxConcurrent   int
-	}{
-		Extension:       ext.Extension,
-		Domain:          req.Domain,
-		CallerIDNumber:  req.CallerIDNumber,
-		CallerIDName:    req.CallerIDName,
-		CallTimeout:     ext.CallTimeout,
-		MaxConcurrent:   ext.MaxConcurrent,
+	if xml, closed, err := h.renderClosed(ctx, req); err != nil || closed {
+		return xml, err
+	}
+
+	ch, err := h.db.GetCallHandling(ctx, ext.ID)
+	if err != nil {
+		return "", fmt.Errorf("get call handling: %w", err)
+	}
+
+	data := extensionCallData(ext, ch, req)
+
+	// Calls between users of the domain are internal
+	direction := "inbound"
+	if h.numbers.IsUser(req.Domain, req.CallerIDNumber) {
+		direction = "internal"
+	}
+
+	policy, err := h.resolveRecordingPolicy(ctx, req, direction)
+	if err != nil {
+		return "", fmt.Errorf("resolve recording policy: %w", err)
+	}
+	if policy != nil {
+		data.RecordingFile = recordingFile(req, policy.Storage())
+		data.RecordingPolicyID = policy.ID
 	}
 
 	return h.renderTemplate("extension", data)
 }
 
+// Hangup causes that select the busy and the no-answer forward
+const (
+	busyCauses     = "USER_BUSY,CALL_REJECTED"
+	noAnswerCauses = "NO_ANSWER,NO_USER_RESPONSE,USER_NOT_REGISTERED,SUBSCRIBER_ABSENT," +
+		"ALLOTTED_TIMEOUT,RECOVERY_ON_TIMER_EXPIRE,NORMAL_TEMPORARY_FAILURE,DESTINATION_OUT_OF_ORDER"
+)
+
+// extensionCall is the data of the extension template
+type extensionCall struct {
+	Extension      string
+	Domain         string
+	Context        string
+	CallerIDNumber string
+	CallerIDName   string
+	CallTimeout    int
+	MaxConcurrent  int
+
+	DND           bool
+	ForwardAlways string
+	ForwardBusy   string
+	BridgeTarget  string // Extension plus follow-me numbers, rung together
+
+	// Failed bridges continue with the next action for ContinueOnFail
+	// causes and are transferred to TransferOnFail for TransferOnFailCauses.
+	// The next action is FallbackForward, or voicemail when empty.
+	ContinueOnFail       string
+	TransferOnFailCauses string
+	TransferOnFail       string
+	FallbackForward      string
+
+	// Set when the recording policy records the call
+	RecordingFile     string
+	RecordingPolicyID int64
+}
+
+// extensionCallData builds the extension template data from the call
+// handling settings. Forwards are transfers back into the dialplan, so they
+// route like a dialed number; follow-me numbers are rung over loopback,
+// which hands dialed_extension on so the legs are charged to the extension.
+func extensionCallData(ext *models.Extension, ch *models.CallHandling, req *DialplanRequest) *extensionCall {
+	data := &extensionCall{
+		Extension:      ext.Extension,
+		Domain:         req.Domain,
+		Context:        req.Context,
+		CallerIDNumber: req.CallerIDNumber,
+		CallerIDName:   req.CallerIDName,
+		CallTimeout:    ext.CallTimeout,
+		MaxConcurrent:  ext.MaxConcurrent,
+		DND:            ch.DND,
+		ForwardAlways:  ch.ForwardAlways,
+		ForwardBusy:    ch.ForwardBusy,
+		ContinueOnFail: "true",
+	}
+
+	targets := []string{fmt.Sprintf("user/%s@%s", ext.Extension, req.Domain)}
+	for _, number := range ch.FollowMe {
+		targets = append(targets, fmt.Sprintf("[loopback_export=dialed_extension]loopback/%s/%s", number, req.Context))
+	}
+	data.BridgeTarget = strings.Join(targets, ",")
+
+	switch {
+	case ch.ForwardNoAnswer != "":
+		data.ContinueOnFail = busyCauses
+		data.TransferOnFailCauses = noAnswerCauses
+		data.TransferOnFail = ch.ForwardNoAnswer
+		data.FallbackForward = ch.ForwardBusy
+
+	case ch.ForwardBusy != "":
+		data.ContinueOnFail = noAnswerCauses
+		data.TransferOnFailCauses = busyCauses
+		data.TransferOnFail = ch.ForwardBusy
+	}
+
+	return data
+}
+
 // handleQueueCall handles calls to queues
 func (h *DialplanHandler) handleQueueCall(ctx context.Context, req *DialplanRequest) (string, error) {
 	// Verify queue exists
@@ -148,14 +387,20 @@ func (h *DialplanHandler) handleQueueCall(ctx context.Context, req *DialplanRequ
 		return h.renderNotFound(), nil
 	}
 
+	if xml, closed, err := h.renderClosed(ctx, req); err != nil || closed {
+		return xml, err
+	}
+
 	data := struct {
-		QueueName      string
-		Extension      string
-		Domain         string
-		CallerIDNumber string
-		CallerIDName   string
-		MaxWaitTime    int
-		Moh            string
+		QueueName         string
+		Extension         string
+		Domain            string
+		CallerIDNumber    string
+		CallerIDName      string
+		MaxWaitTime       int
+		Moh               string
+		RecordingFile     string
+		RecordingPolicyID int64
 	}{
 		QueueName:      queue.Name,
 		Extension:      queue.Extension,
@@ -166,44 +411,264 @@ func (h *DialplanHandler) handleQueueCall(ctx context.Context, req *DialplanRequ
 		Moh:            queue.Moh,
 	}
 
+	// Queues with a record template are always recorded
+	policy, err := h.resolveRecordingPolicy(ctx, req, models.NumberTypeQueue)
+	if err != nil {
+		return "", fmt.Errorf("resolve recording policy: %w", err)
+	}
+	if policy != nil || queue.RecordTemplate != "" {
+		data.RecordingFile = recordingFile(req, policy.Storage())
+		if queue.RecordTemplate != "" {
+			if file, ok := expandRecordTemplate(queue.RecordTemplate, req, queue, policy.Storage()); ok {
+				data.RecordingFile = file
+			} else {
+				log.Printf("[Dialplan] Queue %s: unusable record template %q, using %s",
+					queue.Name, queue.RecordTemplate, data.RecordingFile)
+			}
+		}
+		if policy != nil {
+			data.RecordingPolicyID = policy.ID
+		}
+	}
+
 	return h.renderTemplate("queue", data)
 }
 
+// resolveRecordingPolicy returns the recording policy that records a call
+// of a kind (inbound, internal, queue) to the called number, or nil. Internal
+// calls are also recorded under the caller's policy.
+func (h *DialplanHandler) resolveRecordingPolicy(ctx context.Context, req *DialplanRequest, kind string) (*models.RecordingPolicy, error) {
+	if !callUUIDPattern.MatchString(req.UUID) {
+		log.Printf("[Dialplan] Not recording call without a valid UUID: %q", req.UUID)
+		return nil, nil
+	}
+
+	policy, err := h.db.GetExtensionRecordingPolicy(ctx, req.DestinationNumber, req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil && policy.Records(kind) {
+		return policy, nil
+	}
+
+	if kind == "internal" {
+		policy, err = h.db.GetExtensionRecordingPolicy(ctx, req.CallerIDNumber, req.Domain)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil && policy.Records(kind) {
+			return policy, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// recordingFile returns the file a call is recorded to:
+// <storage>/<domain>/<yyyy>/<mm>/<dd>/<uuid>.wav
+func recordingFile(req *DialplanRequest, storage string) string {
+	return path.Join(storage, req.Domain, time.Now().Format("2006/01/02"), req.UUID+".wav")
+}
+
+// expandRecordTemplate expands the channel variables of a queue record
+// template here, as the dialplan would escape them. Templates with other
+// variables, or expanding to a path outside the storage or one the dialplan
+// cannot carry, are unusable.
+func expandRecordTemplate(tmpl string, req *DialplanRequest, queue *models.Queue, storage string) (string, bool) {
+	vars := map[string]string{
+		"recordings_dir":     storage,
+		"uuid":               req.UUID,
+		"domain_name":        req.Domain,
+		"caller_id_number":   req.CallerIDNumber,
+		"destination_number": req.DestinationNumber,
+		"queue_name":         queue.Name,
+	}
+
+	ok := true
+	file := recordTemplateVarPattern.ReplaceAllStringFunc(tmpl, func(v string) string {
+		value, known := vars[recordTemplateVarPattern.FindStringSubmatch(v)[1]]
+		if !known {
+			ok = false
+		}
+		return value
+	})
+
+	// Caller IDs are chosen by the caller; keep them from escaping the storage
+	file = path.Clean(file)
+	if !ok || !recordingPathPattern.MatchString(file) || !strings.HasPrefix(file, path.Clean(storage)+"/") {
+		return "", false
+	}
+	return file, true
+}
+
 // handleIVRCall handles IVR menu calls
 func (h *DialplanHandler) handleIVRCall(ctx context.Context, req *DialplanRequest) (string, error) {
+	if xml, closed, err := h.renderClosed(ctx, req); err != nil || closed {
+		return xml, err
+	}
+
 	// TODO: Implement IVR logic when ivr table is ready
 	log.Printf("[Dialplan] IVR not yet implemented: %s", req.DestinationNumber)
 	return h.renderNotFound(), nil
 }
 
-// handleConferenceCall handles conference calls
+// renderClosed renders the after-hours destination of the called number
+// if a time condition guards it and is closed now
+func (h *DialplanHandler) renderClosed(ctx context.Context, req *DialplanRequest) (string, bool, error) {
+	tc, err := h.db.GetTimeConditionForNumber(ctx, req.Domain, req.DestinationNumber)
+	if err != nil {
+		return "", false, fmt.Errorf("get time condition: %w", err)
+	}
+	if tc == nil {
+		return "", false, nil
+	}
+
+	state, err := tc.Evaluate(time.Now())
+	if err != nil {
+		// Route normally rather than dropping calls on a bad timezone
+		log.Printf("[Dialplan] Failed to evaluate time condition %q: %v", tc.Name, err)
+		return "", false, nil
+	}
+	if state.Open {
+		return "", false, nil
+	}
+
+	log.Printf("[Dialplan] %s@%s closed by time condition %q (%s), %s",
+		req.DestinationNumber, req.Domain, tc.Name, state.Reason, tc.ClosedAction)
+
+	data := struct {
+		Number          string
+		Domain          string
+		Context         string
+		TimeConditionID int64
+		Action          string
+		Target          string
+	}{
+		Number:          req.DestinationNumber,
+		Domain:          req.Domain,
+		Context:         req.Context,
+		TimeConditionID: tc.ID,
+		Action:          tc.ClosedAction,
+		Target:          tc.ClosedTargetFor(req.DestinationNumber),
+	}
+
+	xml, err := h.renderTemplate("closed", data)
+	return xml, true, err
+}
+
+// handleConferenceCall lets callers into a conference room. Rooms with a
+// PIN are entered in two passes: the first asks for the PIN and transfers
+// back to the room, the second checks it and joins.
 func (h *DialplanHandler) handleConferenceCall(ctx context.Context, req *DialplanRequest) (string, error) {
-	// Basic conference implementation
+	room, err := h.db.GetConferenceRoomByExtension(ctx, req.DestinationNumber, req.Domain)
+	if err != nil || !room.Active {
+		log.Printf("[Dialplan] Conference room not found or inactive: %s@%s", req.DestinationNumber, req.Domain)
+		return h.renderNotFound(), nil
+	}
+
 	data := struct {
-		ConferenceNumber string
-		Domain           string
-		CallerIDNumber   string
-		CallerIDName     string
+		Extension        string
+		Context          string
+		Name             string
+		Profile          string
+		ParticipantPIN   string
+		ModeratorPIN     string
+		MaxMembers       int
+		MuteOnJoin       bool
+		WaitForModerator bool
+		RecordingFile    string
+		PromptPIN        bool
+		Moderator        bool
+		Denied           bool
 	}{
-		ConferenceNumber: req.DestinationNumber,
-		Domain:           req.Domain,
-		CallerIDNumber:   req.CallerIDNumber,
-		CallerIDName:     req.CallerIDName,
+		Extension:        room.Extension,
+		Context:          req.Context,
+		Name:             models.ConferenceName(room.Extension, room.Domain),
+		Profile:          room.Profile,
+		ParticipantPIN:   room.ParticipantPIN,
+		ModeratorPIN:     room.ModeratorPIN,
+		MaxMembers:       room.MaxMembers,
+		MuteOnJoin:       room.MuteOnJoin,
+		WaitForModerator: room.WaitForModerator,
+	}
+
+	if room.HasPIN() {
+		switch {
+		case req.ConferencePINRoom != room.Extension:
+			data.PromptPIN = true
+		case room.ModeratorPIN != "" && pinEqual(req.ConferencePIN, room.ModeratorPIN):
+			data.Moderator = true
+		case pinEqual(req.ConferencePIN, room.ParticipantPIN):
+			// An empty participant PIN admits callers that just press #
+		default:
+			log.Printf("[Dialplan] Wrong PIN for conference %s from %s", data.Name, req.CallerIDNumber)
+			data.Denied = true
+		}
+	}
+
+	if room.Record && callUUIDPattern.MatchString(req.UUID) {
+		data.RecordingFile = path.Join(models.DefaultRecordingStoragePath, req.Domain,
+			time.Now().Format("2006/01/02"), fmt.Sprintf("conference-%s-%s.wav", room.Extension, req.UUID))
 	}
 
 	return h.renderTemplate("conference", data)
 }
 
-// handleVoicemailCall handles voicemail access
+// pinEqual compares a PIN in constant time
+func pinEqual(entered, pin string) bool {
+	return subtle.ConstantTimeCompare([]byte(entered), []byte(pin)) == 1
+}
+
+// handleVoicemailCall handles voicemail access. *97 checks the caller's own
+// mailbox, *98 asks for the mailbox number. The voicemail password is
+// skipped on *97 only if the extension has auto-login enabled and the
+// caller authenticated as that extension, since caller ID can be set freely.
 func (h *DialplanHandler) handleVoicemailCall(ctx context.Context, req *DialplanRequest) (string, error) {
+	if req.DestinationNumber != "*97" {
+		return h.renderVoicemail(req, "", false, false)
+	}
+
+	autoLogin := false
+	if req.AuthUser != "" && req.AuthUser == req.CallerIDNumber {
+		ext, err := h.db.GetExtension(ctx, req.CallerIDNumber, req.Domain)
+		if err == nil && ext.Active && ext.VMAutoLogin {
+			autoLogin = true
+		}
+	}
+
+	return h.renderVoicemail(req, req.CallerIDNumber, false, autoLogin)
+}
+
+// handleDirectVoicemail leaves a message in the mailbox of the extension
+// dialed after *99, without ringing it
+func (h *DialplanHandler) handleDirectVoicemail(ctx context.Context, req *DialplanRequest) (string, error) {
+	mailbox := directVoicemailPattern.FindStringSubmatch(req.DestinationNumber)[1]
+
+	ext, err := h.db.GetExtension(ctx, mailbox, req.Domain)
+	if err != nil || !ext.Active || ext.Type != models.NumberTypeUser {
+		log.Printf("[Dialplan] Mailbox not found or inactive: %s@%s", mailbox, req.Domain)
+		return h.renderNotFound(), nil
+	}
+
+	return h.renderVoicemail(req, mailbox, true, false)
+}
+
+// renderVoicemail renders voicemail access for the dialed number. Without
+// a mailbox, mod_voicemail asks for one. With deposit, a message is left
+// in the mailbox instead of checking it.
+func (h *DialplanHandler) renderVoicemail(req *DialplanRequest, mailbox string, deposit, autoLogin bool) (string, error) {
 	data := struct {
-		Domain         string
-		CallerIDNumber string
-		Extension      string
+		Expression string
+		Domain     string
+		Mailbox    string
+		Deposit    bool
+		AutoLogin  bool
 	}{
-		Domain:         req.Domain,
-		CallerIDNumber: req.CallerIDNumber,
-		Extension:      req.CallerIDNumber, // Default to caller's own voicemail
+		Expression: regexp.QuoteMeta(req.DestinationNumber),
+		Domain:     req.Domain,
+		Mailbox:    mailbox,
+		Deposit:    deposit,
+		AutoLogin:  autoLogin,
 	}
 
 	return h.renderTemplate("voicemail", data)
@@ -216,47 +681,179 @@ func (h *DialplanHandler) handleFeatureCode(ctx context.Context, req *DialplanRe
 	return h.renderNotFound(), nil
 }
 
-// handleOutboundCall handles outbound PSTN calls
-func (h *DialplanHandler) handleOutboundCall(ctx context.Context, req *DialplanRequest) (string, error) {
-	// TODO: Implement trunk selection and outbound routing
-	log.Printf("[Dialplan] Outbound call routing not yet implemented: %s", req.DestinationNumber)
-	return h.renderNotFound(), nil
-}
+// handleOutboundCall bridges a call to an outside number over the trunks of
+// the domain, within the credit limits of the domain and of the calling
+// extension. A used-up monthly limit, or remaining credit that does not
+// pay for the first increment, rejects the call with an announcement;
+// otherwise the call is hung up when the remaining credit runs out.
+// Concurrent calls are counted by FreeSWITCH per node.
+func (h *DialplanHandler) handleOutboundCall(ctx context.Context, req *DialplanRequest, domain *models.Domain) (string, error) {
+	if domain == nil || !outboundNumberPattern.MatchString(req.DestinationNumber) {
+		log.Printf("[Dialplan] Invalid outbound call to %s@%s", req.DestinationNumber, req.Domain)
+		return h.renderNotFound(), nil
+	}
 
-// Pattern matching functions
-func isExtension(number string) bool {
-	match, _ := regexp.MatchString(`^[1-79]\d{3}$`, number)
-	return match
-}
+	// The caller ID can be set freely, the authenticated user cannot.
+	// Forwarded calls are placed on behalf of the forwarding extension.
+	caller := req.ForwardedBy
+	if caller == "" {
+		caller = req.AuthUser
+	}
+	if caller == "" {
+		caller = req.CallerIDNumber
+	}
+	var extensionID *int64
+	if ext, err := h.db.GetExtension(ctx, caller, req.Domain); err == nil {
+		extensionID = &ext.ID
+	}
+
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	domainLimit, err := h.db.GetCreditLimit(ctx, domain.ID, nil, month)
+	if err != nil {
+		return "", fmt.Errorf("get domain credit limit: %w", err)
+	}
+	limits := []*models.CreditLimit{domainLimit}
+
+	var extensionLimit *models.CreditLimit
+	if extensionID != nil {
+		if extensionLimit, err = h.db.GetCreditLimit(ctx, domain.ID, extensionID, month); err != nil {
+			return "", fmt.Errorf("get extension credit limit: %w", err)
+		}
+		limits = append(limits, extensionLimit)
+	}
+
+	data := struct {
+		Expression        string
+		Domain            string
+		Context           string
+		Extension         string
+		ForwardedBy       string
+		DomainMaxCalls    int
+		ExtensionMaxCalls int
+		LimitDestination  string
+		MaxDuration       int
+		BridgeTarget      string
+	}{
+		Expression:       regexp.QuoteMeta(req.DestinationNumber),
+		Domain:           req.Domain,
+		Context:          req.Context,
+		Extension:        caller,
+		ForwardedBy:      req.ForwardedBy,
+		LimitDestination: outboundLimitDestination,
+	}
+
+	// The tightest limit decides
+	var remaining *float64
+	for _, limit := range limits {
+		if limit.Exhausted() {
+			log.Printf("[Dialplan] Outbound call from %s@%s to %s rejected: monthly limit %.2f used up (%.2f spent)",
+				caller, req.Domain, req.DestinationNumber, *limit.MonthlyLimit, limit.Spent)
+			return h.renderOutboundRejected(req, models.CreditRejectSpendingLimit)
+		}
+		if limit.MaxOutboundCalls != nil && *limit.MaxOutboundCalls == 0 {
+			log.Printf("[Dialplan] Outbound call from %s@%s to %s rejected: outbound calls disabled",
+				caller, req.Domain, req.DestinationNumber)
+			return h.renderOutboundRejected(req, models.CreditRejectOutboundCalls)
+		}
+		if limit.Remaining != nil && (remaining == nil || *limit.Remaining < *remaining) {
+			remaining = limit.Remaining
+		}
+	}
+	if domainLimit.MaxOutboundCalls != nil {
+		data.DomainMaxCalls = *domainLimit.MaxOutboundCalls
+	}
+	if extensionLimit != nil && extensionLimit.MaxOutboundCalls != nil {
+		data.ExtensionMaxCalls = *extensionLimit.MaxOutboundCalls
+	}
+
+	trunks, err := h.db.ListOutboundTrunks(ctx, domain.ID)
+	if err != nil {
+		return "", fmt.Errorf("list outbound trunks: %w", err)
+	}
+	var carrierNumber string
+	data.BridgeTarget, carrierNumber = outboundBridgeTarget(trunks, req.DestinationNumber)
+	if data.BridgeTarget == "" {
+		log.Printf("[Dialplan] No trunk for outbound call to %s@%s", req.DestinationNumber, req.Domain)
+		return h.renderNotFound(), nil
+	}
+
+	// Destinations without a sell rate are not invoiced, so not capped.
+	// Calls are rated on the number sent to the first trunk, as the CDR
+	// rater does.
+	if remaining != nil {
+		rate, err := h.db.GetSellRate(ctx, domain.ID, models.RatePrefixes(carrierNumber))
+		if err != nil {
+			return "", fmt.Errorf("get sell rate: %w", err)
+		}
+		if rate != nil {
+			maxDuration := rate.MaxDuration(*remaining)
+			if maxDuration == 0 {
+				log.Printf("[Dialplan] Outbound call from %s@%s to %s rejected: %.2f credit left, %s costs %.6f/min",
+					caller, req.Domain, req.DestinationNumber, *remaining, rate.Prefix, rate.Rate)
+				return h.renderOutboundRejected(req, models.CreditRejectSpendingLimit)
+			}
+			if maxDuration < math.MaxInt32 {
+				data.MaxDuration = maxDuration
+			}
+		}
+	}
 
-func isQueue(number string) bool {
-	match, _ := regexp.MatchString(`^8\d{3}$`, number)
-	return match
+	return h.renderTemplate("outbound", data)
 }
 
-func isIVR(number string) bool {
-	match, _ := regexp.MatchString(`^9\d{3}$`, number)
-	return match
+// outboundBridgeTarget returns the dial string trying each trunk in turn,
+// and the number sent to the first one. Trunks with a prefix take the
+// numbers dialed with it, without the prefix; if none matches, the trunks
+// without a prefix are used.
+func outboundBridgeTarget(trunks []*models.Trunk, number string) (string, string) {
+	var prefixed, plain, prefixedNumbers []string
+	for _, t := range trunks {
+		carrierNumber, ok := t.CarrierNumber(number)
+		switch {
+		case !ok:
+		case t.Prefix == "":
+			plain = append(plain, "sofia/gateway/"+t.Name+"/"+carrierNumber)
+		default:
+			prefixed = append(prefixed, "sofia/gateway/"+t.Name+"/"+carrierNumber)
+			prefixedNumbers = append(prefixedNumbers, carrierNumber)
+		}
+	}
+
+	if len(prefixed) > 0 {
+		return strings.Join(prefixed, "|"), prefixedNumbers[0]
+	}
+	return strings.Join(plain, "|"), number
 }
 
-func isConference(number string) bool {
-	match, _ := regexp.MatchString(`^3\d{3}$`, number)
-	return match
+// renderOutboundRejected plays the credit announcement as early media, so
+// the rejected call is never answered or billed
+func (h *DialplanHandler) renderOutboundRejected(req *DialplanRequest, reason string) (string, error) {
+	data := struct {
+		Expression   string
+		Reason       string
+		Announcement string
+	}{
+		Expression:   regexp.QuoteMeta(req.DestinationNumber),
+		Reason:       reason,
+		Announcement: h.creditAnnouncement,
+	}
+
+	return h.renderTemplate("outbound_rejected", data)
 }
 
+// Pattern matching functions
 func isVoicemail(number string) bool {
 	return number == "*97" || number == "*98"
 }
 
-func isFeatureCode(number string) bool {
-	match, _ := regexp.MatchString(`^\*\d{2,3}$`, number)
-	return match
+func isDirectVoicemail(number string) bool {
+	return directVoicemailPattern.MatchString(number)
 }
 
-func isOutbound(number string) bool {
-	// 10-11 digits or starts with +
-	match, _ := regexp.MatchString(`^(\+?\d{10,11}|\+\d{8,15})$`, number)
-	return match
+func isFeatureCode(number string) bool {
+	return featureCodePattern.MatchString(number)
 }
 
 // renderTemplate renders a dialplan template
@@ -294,9 +891,31 @@ var dialplanTemplates = map[string]string{
         <condition field="destination_number" expression="^{{.Extension}}$">
           <action application="set" data="call_timeout={{.CallTimeout}}"/>
           <action application="set" data="hangup_after_bridge=true"/>
-          <action application="set" data="continue_on_fail=true"/>
           <action application="set" data="called_party_callgroup=${user_data({{.Extension}}@{{.Domain}} var callgroup)}"/>
           <action application="export" data="dialed_extension={{.Extension}}"/>
+          <action application="export" data="domain_name={{.Domain}}"/>
+
+          <!-- Stop forwarding loops between extensions -->
+          <action application="set" data="max_session_transfers=10"/>
+{{- if .ForwardAlways}}
+
+          <!-- Unconditional forward -->
+          <action application="transfer" data="{{.ForwardAlways}} XML {{.Context}}"/>
+{{- else if .DND}}
+
+          <!-- Do not disturb: busy treatment without ringing -->
+{{- if .ForwardBusy}}
+          <action application="transfer" data="{{.ForwardBusy}} XML {{.Context}}"/>
+{{- else}}
+          <action application="answer" data=""/>
+          <action application="sleep" data="1000"/>
+          <action application="voicemail" data="default {{.Domain}} {{.Extension}}"/>
+{{- end}}
+{{- else}}
+          <action application="set" data="continue_on_fail={{.ContinueOnFail}}"/>
+{{- if .TransferOnFail}}
+          <action application="set" data="transfer_on_fail={{.TransferOnFailCauses}} {{.TransferOnFail}} XML {{.Context}}"/>
+{{- end}}
 
           <!-- Pre-answer for queue calls -->
           <action application="ring_ready" data=""/>
@@ -306,13 +925,31 @@ var dialplanTemplates = map[string]string{
           <action application="set" data="RECORD_COPYRIGHT=High CC PBX"/>
           <action application="set" data="RECORD_ARTIST={{.CallerIDNumber}}"/>
           <action application="set" data="RECORD_DATE=${strftime(%Y-%m-%d %H:%M:%S)}"/>
+{{- if .RecordingFile}}
+          <action application="set" data="RECORD_ANSWER_REQ=true"/>
+          <action application="set" data="recording_file={{.RecordingFile}}"/>
+{{- if .RecordingPolicyID}}
+          <action application="set" data="recording_policy_id={{.RecordingPolicyID}}"/>
+{{- end}}
+          <action application="record_session" data="{{.RecordingFile}}"/>
+{{- end}}
 
-          <action application="bridge" data="user/{{.Extension}}@{{.Domain}}"/>
+          <action application="bridge" data="{{.BridgeTarget}}"/>
+{{- if .FallbackForward}}
+
+          <!-- Forward on busy -->
+          <action application="transfer" data="{{.FallbackForward}} XML {{.Context}}"/>
+{{- else}}
 
           <!-- Voicemail on no answer or busy -->
+{{- if .RecordingFile}}
+          <action application="stop_record_session" data="{{.RecordingFile}}"/>
+{{- end}}
           <action application="answer" data=""/>
           <action application="sleep" data="1000"/>
           <action application="voicemail" data="default {{.Domain}} {{.Extension}}"/>
+{{- end}}
+{{- end}}
         </condition>
       </extension>
     </context>
@@ -332,6 +969,15 @@ var dialplanTemplates = map[string]string{
           <!-- Set queue variables -->
           <action application="set" data="queue_name={{.QueueName}}"/>
           <action application="set" data="max_wait_time={{.MaxWaitTime}}"/>
+{{- if .RecordingFile}}
+
+          <!-- Record from entering the queue -->
+          <action application="set" data="recording_file={{.RecordingFile}}"/>
+{{- if .RecordingPolicyID}}
+          <action application="set" data="recording_policy_id={{.RecordingPolicyID}}"/>
+{{- end}}
+          <action application="record_session" data="{{.RecordingFile}}"/>
+{{- end}}
 
           <!-- Enter queue with music on hold -->
           <action application="callcenter" data="{{.QueueName}}@{{.Domain}}"/>
@@ -350,12 +996,157 @@ var dialplanTemplates = map[string]string{
   <section name="dialplan" description="Conference Dialplan">
     <context name="default">
       <extension name="conference_call">
-        <condition field="destination_number" expression="^{{.ConferenceNumber}}$">
+        <condition field="destination_number" expression="^{{.Extension}}$">
+{{- if .PromptPIN}}
+          <action application="answer" data=""/>
+          <action application="sleep" data="500"/>
+
+          <!-- Ask for the PIN, then enter the room again to check it -->
+          <action application="set" data="conference_pin_room={{.Extension}}"/>
+          <action application="play_and_get_digits" data="{{if .ParticipantPIN}}3{{else}}0{{end}} 12 3 10000 # conference/conf-pin.wav conference/conf-bad-pin.wav conference_pin ^({{if .ParticipantPIN}}{{.ParticipantPIN}}{{if .ModeratorPIN}}|{{.ModeratorPIN}}{{end}}{{else}}{{.ModeratorPIN}}{{end}}){{if not .ParticipantPIN}}?{{end}}$"/>
+          <action application="transfer" data="{{.Extension}} XML {{.Context}}"/>
+{{- else if .Denied}}
+          <action application="unset" data="conference_pin"/>
           <action application="answer" data=""/>
-          <action application="set" data="conference_name={{.ConferenceNumber}}@{{.Domain}}"/>
+          <action application="playback" data="conference/conf-bad-pin.wav"/>
+          <action application="hangup" data="CALL_REJECTED"/>
+{{- else}}
+          <action application="unset" data="conference_pin"/>
+{{- if .MaxMembers}}
+
+          <!-- Room size, counted per node -->
+          <action application="limit" data="hash conference {{.Name}} {{.MaxMembers}} !USER_BUSY"/>
+{{- end}}
+          <action application="answer" data=""/>
+          <action application="set" data="conference_name={{.Name}}"/>
+{{- if .Moderator}}
+          <action application="set" data="conference_member_flags=moderator"/>
+{{- else if and .MuteOnJoin .WaitForModerator}}
+          <action application="set" data="conference_member_flags=mute|wait-mod"/>
+{{- else if .MuteOnJoin}}
+          <action application="set" data="conference_member_flags=mute"/>
+{{- else if .WaitForModerator}}
+          <action application="set" data="conference_member_flags=wait-mod"/>
+{{- end}}
+{{- if .RecordingFile}}
+
+          <!-- Used by whoever starts the conference -->
+          <action application="set" data="conference_auto_record={{.RecordingFile}}"/>
+{{- end}}
 
           <!-- Join conference -->
-          <action application="conference" data="{{.ConferenceNumber}}@default"/>
+          <action application="conference" data="{{.Name}}@{{.Profile}}"/>
+{{- end}}
+        </condition>
+      </extension>
+    </context>
+  </section>
+</document>`,
+
+	"closed": `<?xml version="1.0" encoding="UTF-8"?>
+<document type="freeswitch/xml">
+  <section name="dialplan" description="After Hours">
+    <context name="default">
+      <extension name="time_condition_closed">
+        <condition field="destination_number" expression="^{{.Number}}$">
+          <action application="set" data="time_condition_id={{.TimeConditionID}}"/>
+          <action application="set" data="hangup_after_bridge=true"/>
+{{- if eq .Action "voicemail"}}
+          <action application="answer" data=""/>
+          <action application="sleep" data="1000"/>
+          <action application="voicemail" data="default {{.Domain}} {{.Target}}"/>
+{{- else if eq .Action "announcement"}}
+          <action application="answer" data=""/>
+          <action application="sleep" data="500"/>
+          <action application="playback" data="{{.Target}}"/>
+          <action application="hangup" data=""/>
+{{- else}}
+          <action application="transfer" data="{{.Target}} XML {{.Context}}"/>
+{{- end}}
+        </condition>
+      </extension>
+    </context>
+  </section>
+</document>`,
+
+	"did": `<?xml version="1.0" encoding="UTF-8"?>
+<document type="freeswitch/xml">
+  <section name="dialplan" description="Inbound DID">
+    <context name="public">
+      <extension name="did_{{.Digits}}">
+        <condition field="destination_number" expression="^\+?{{.Digits}}$">
+          <action application="export" data="domain_name={{.Domain}}"/>
+          <action application="set" data="did_number={{.DIDNumber}}"/>
+{{- if .CallerIDNamePrefix}}
+          <action application="set" data="effective_caller_id_name={{.CallerIDNamePrefix}} ${caller_id_name}"/>
+{{- end}}
+{{- if .TimeConditionID}}
+          <action application="set" data="time_condition_id={{.TimeConditionID}}"/>
+{{- end}}
+{{- if eq .Action "voicemail"}}
+          <action application="answer" data=""/>
+          <action application="sleep" data="1000"/>
+          <action application="voicemail" data="default {{.Domain}} {{.Target}}"/>
+{{- else if eq .Action "announcement"}}
+          <action application="answer" data=""/>
+          <action application="sleep" data="500"/>
+          <action application="playback" data="{{.Target}}"/>
+          <action application="hangup" data=""/>
+{{- else}}
+          <action application="transfer" data="{{.Target}} XML default"/>
+{{- end}}
+        </condition>
+      </extension>
+    </context>
+  </section>
+</document>`,
+
+	"outbound": `<?xml version="1.0" encoding="UTF-8"?>
+<document type="freeswitch/xml">
+  <section name="dialplan" description="Outbound Call">
+    <context name="default">
+      <extension name="outbound_call">
+        <condition field="destination_number" expression="^{{.Expression}}$">
+          <action application="set" data="hangup_after_bridge=true"/>
+          <action application="export" data="domain_name={{.Domain}}"/>
+{{- if .ForwardedBy}}
+
+          <!-- Charged to the extension that forwarded the call -->
+          <action application="set" data="forwarded_by={{.ForwardedBy}}"/>
+{{- end}}
+{{- if .DomainMaxCalls}}
+
+          <!-- Concurrent outbound calls of the domain, counted per node -->
+          <action application="limit" data="hash outbound {{.Domain}} {{.DomainMaxCalls}} {{.LimitDestination}} XML {{.Context}}"/>
+{{- end}}
+{{- if .ExtensionMaxCalls}}
+          <action application="limit" data="hash outbound {{.Extension}}@{{.Domain}} {{.ExtensionMaxCalls}} {{.LimitDestination}} XML {{.Context}}"/>
+{{- end}}
+{{- if .MaxDuration}}
+
+          <!-- Hang up when the remaining credit runs out -->
+          <action application="set" data="credit_max_duration={{.MaxDuration}}"/>
+          <action application="set" data="execute_on_answer=sched_hangup +{{.MaxDuration}} ALLOTTED_TIMEOUT"/>
+{{- end}}
+
+          <action application="bridge" data="{{.BridgeTarget}}"/>
+        </condition>
+      </extension>
+    </context>
+  </section>
+</document>`,
+
+	"outbound_rejected": `<?xml version="1.0" encoding="UTF-8"?>
+<document type="freeswitch/xml">
+  <section name="dialplan" description="Outbound Call Rejected">
+    <context name="default">
+      <extension name="outbound_rejected">
+        <condition field="destination_number" expression="^{{.Expression}}$">
+          <action application="set" data="outbound_rejected={{.Reason}}"/>
+          <action application="pre_answer" data=""/>
+          <action application="sleep" data="500"/>
+          <action application="playback" data="{{.Announcement}}"/>
+          <action application="hangup" data="CALL_REJECTED"/>
         </condition>
       </extension>
     </context>
@@ -366,11 +1157,20 @@ var dialplanTemplates = map[string]string{
 <document type="freeswitch/xml">
   <section name="dialplan" description="Voicemail Access">
     <context name="default">
-      <extension name="voicemail_check">
-        <condition field="destination_number" expression="^(\*97|\*98)$">
+      <extension name="{{if .Deposit}}voicemail_deposit{{else}}voicemail_check{{end}}">
+        <condition field="destination_number" expression="^{{.Expression}}$">
           <action application="answer" data=""/>
           <action application="sleep" data="1000"/>
-          <action application="voicemail" data="check default {{.Domain}} {{.Extension}}"/>
+{{- if .AutoLogin}}
+          <action application="set" data="voicemail_authorized=true"/>
+{{- end}}
+{{- if .Deposit}}
+          <action application="voicemail" data="default {{.Domain}} {{.Mailbox}}"/>
+{{- else if .Mailbox}}
+          <action application="voicemail" data="check default {{.Domain}} {{.Mailbox}}"/>
+{{- else}}
+          <action application="voicemail" data="check default {{.Domain}}"/>
+{{- end}}
         </condition>
       </extension>
     </context>
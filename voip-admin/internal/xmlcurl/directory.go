@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"html/template"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/yourusername/high-cc-pbx/voip-admin/internal/database"
@@ -26,6 +27,11 @@ type Cache interface {
 	Delete(key string)
 }
 
+// DomainCache is a Cache that can drop every entry of a domain at once
+type DomainCache interface {
+	DeleteMatching(match func(key string) bool) int
+}
+
 // DirectoryRequest represents a FreeSWITCH directory request
 type DirectoryRequest struct {
 	Section   string // "directory"
@@ -65,7 +71,7 @@ func (h *DirectoryHandler) Handle(ctx context.Context, req *DirectoryRequest) (s
 	}
 
 	// Try cache first (60s TTL)
-	cacheKey := fmt.Sprintf("dir:%s@%s", req.User, req.Domain)
+	cacheKey := DirectoryCacheKey(req.User, req.Domain)
 	if cached, ok := h.cache.Get(cacheKey); ok {
 		if ext, ok := cached.(*models.Extension); ok {
 			log.Printf("[Directory] Cache hit for %s@%s", req.User, req.Domain)
@@ -144,11 +150,26 @@ func (h *DirectoryHandler) renderNotFound() string {
 
 // InvalidateCache invalidates the cache for a specific user
 func (h *DirectoryHandler) InvalidateCache(user, domain string) {
-	cacheKey := fmt.Sprintf("dir:%s@%s", user, domain)
+	cacheKey := DirectoryCacheKey(user, domain)
 	h.cache.Delete(cacheKey)
 	log.Printf("[Directory] Cache invalidated for %s@%s", user, domain)
 }
 
+// DirectoryCacheKey returns the cache key of a directory entry
+func DirectoryCacheKey(user, domain string) string {
+	return fmt.Sprintf("dir:%s@%s", user, domain)
+}
+
+// InvalidateDomainCache removes all cached directory entries of a domain
+func InvalidateDomainCache(cache DomainCache, domain string) int {
+	suffix := "@" + domain
+	removed := cache.DeleteMatching(func(key string) bool {
+		return strings.HasPrefix(key, "dir:") && strings.HasSuffix(key, suffix)
+	})
+	log.Printf("[Directory] Cache invalidated for domain %s (%d entries)", domain, removed)
+	return removed
+}
+
 // directoryTemplate is the XML template for FreeSWITCH directory responses
 // Uses MD5 digest authentication with HA1/HA1B hashes
 const directoryTemplate = `<?xml version="1.0" encoding="UTF-8"?>
@@ -165,7 +186,7 @@ const directoryTemplate = `<?xml version="1.0" encoding="UTF-8"?>
           <users>
             <user id="{{.Extension}}">
               <params>
-                <!-- MD5 Digest Authentication (HA1 hashes calculated by database) -->
+                <!-- MD5 Digest Authentication (HA1 hashes calculated by voipadmind) -->
                 <param name="a1-hash" value="{{.HA1}}"/>
                 <param name="a1-hash-b" value="{{.HA1B}}"/>
 
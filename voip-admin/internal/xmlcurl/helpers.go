@@ -48,6 +48,10 @@ func ParseDialplanRequest(r *http.Request) (*DialplanRequest, error) {
 		NetworkAddr:       r.FormValue("Caller-Network-Addr"),
 		ChannelName:       r.FormValue("Caller-Channel-Name"),
 		UUID:              r.FormValue("Caller-Unique-ID"),
+		AuthUser:          r.FormValue("variable_user_name"),
+		ForwardedBy:       r.FormValue("variable_dialed_extension"),
+		ConferencePIN:     r.FormValue("variable_conference_pin"),
+		ConferencePINRoom: r.FormValue("variable_conference_pin_room"),
 	}
 
 	// Fallback for domain if not in variable_domain_name